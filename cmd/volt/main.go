@@ -1,64 +1,269 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/owenHochwald/volt/internal/app"
 	"github.com/owenHochwald/volt/internal/cli"
+	"github.com/owenHochwald/volt/internal/secrets"
 	"github.com/owenHochwald/volt/internal/storage"
 )
 
 func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run runs volt with its arguments and returns the exit code, so that
+// deferred cleanup such as closing the store happens before exiting
+func run(args []string) int {
+	args, err := parseGlobalFlags(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+		return 1
+	}
+
 	// If any command line arguments are provided, use CLI mode
-	if len(os.Args) > 1 {
-		// Special handling for help
-		if os.Args[1] == "help" || os.Args[1] == "-h" || os.Args[1] == "--help" {
+	if len(args) > 0 {
+		switch args[0] {
+		case "help", "-h", "--help":
 			cli.PrintHelp()
-			return
+			return 0
+
+		// Single request mode
+		case "send":
+			config, err := cli.ParseSendFlags(args[1:])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+				return 1
+			}
+			if err := cli.RunSend(config); err != nil {
+				fmt.Fprintf(os.Stderr, "Error sending request: %v\n", err)
+				return 1
+			}
+			return 0
+
+		// Environment options
+		case "env":
+			return storeCommand(args[1:], cli.ParseEnvFlags, func(store storage.Storage, _ *secrets.Vault, config *cli.EnvConfig) error {
+				return cli.RunEnv(store, config, os.Stdout)
+			})
+
+		// Collections of saved requests, whose auth defaults are encrypted
+		// like secret headers
+		case "collection":
+			return storeCommand(args[1:], cli.ParseCollectionFlags, func(store storage.Storage, _ *secrets.Vault, config *cli.CollectionConfig) error {
+				return cli.RunCollection(store, config, os.Stdout)
+			})
+
+		// Import of other clients' collections, OpenAPI and HAR files
+		case "import":
+			return storeCommand(args[1:], cli.ParseImportFlags, func(store storage.Storage, _ *secrets.Vault, config *cli.ImportConfig) error {
+				return cli.RunImport(store, config, os.Stdout)
+			})
+
+		// History of sent requests
+		case "history":
+			return storeCommand(args[1:], cli.ParseHistoryFlags, func(store storage.Storage, _ *secrets.Vault, config *cli.HistoryConfig) error {
+				return cli.RunHistory(store, config, os.Stdout)
+			})
+
+		// Search of saved requests, and of history with its responses
+		case "search":
+			return storeCommand(args[1:], cli.ParseSearchFlags, func(store storage.Storage, _ *secrets.Vault, config *cli.SearchConfig) error {
+				return cli.RunSearch(store, config, os.Stdout)
+			})
+
+		// Saved requests as curl commands or code, -secrets decrypting
+		// secret headers
+		case "export":
+			return storeCommand(args[1:], cli.ParseExportFlags, func(store storage.Storage, vault *secrets.Vault, config *cli.ExportConfig) error {
+				return cli.RunExport(store, vault, config, os.Stdout)
+			})
 		}
 
 		// All other args go to bench mode
 		// Support both "volt bench ..." and "volt ..." (with bench implied)
-		args := os.Args[1:]
-		if os.Args[1] == "bench" {
-			args = os.Args[2:]
+		benchArgs := args
+		if args[0] == "bench" {
+			benchArgs = args[1:]
 		}
 
-		config, err := cli.ParseBenchFlags(args)
+		config, err := cli.ParseBenchFlags(benchArgs)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
-			os.Exit(1)
+			return 1
 		}
 
 		if err := cli.RunBench(config); err != nil {
 			fmt.Fprintf(os.Stderr, "Error running benchmark: %v\n", err)
-			os.Exit(1)
+			return 1
 		}
-		return
+		return 0
 	}
 
 	// TUI mode
-	homeDir, err := os.UserHomeDir()
+	store, vault, err := openStoreWithVault()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting home directory: %v", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Error %v\n", err)
+		return 1
 	}
+	defer store.Close()
 
-	dbPath := filepath.Join(homeDir, ".volt", "volt.db")
-	store, err := storage.NewSQLiteStorage(dbPath)
-	if err != nil {
-		fmt.Printf("Error connecting to database: %v", err)
-		return
+	config := app.Config{
+		Vault:       vault,
+		Environment: os.Getenv("VOLT_ENV"),
 	}
-	defer store.Close()
 
-	p := tea.NewProgram(app.SetupModel(store), tea.WithAltScreen())
+	p := tea.NewProgram(app.SetupModel(store, config), tea.WithAltScreen())
 
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Alas, there's been an error: %v", err)
-		os.Exit(1)
+		return 1
+	}
+	return 0
+}
+
+// errMemoryStore rejects subcommands given --db :memory:, what they save
+// would be gone as soon as they are done
+var errMemoryStore = errors.New("--db :memory: keeps nothing once volt exits, so it only works for the TUI")
+
+// storeCommand runs a subcommand reading or writing the store: it parses the
+// subcommand's flags, opens the store with the secret key loaded, and closes
+// it once the subcommand is done
+func storeCommand[C any](args []string, parse func([]string) (C, error), run func(storage.Storage, *secrets.Vault, C) error) int {
+	config, err := parse(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+		return 1
+	}
+	if dbPath == ":memory:" && workspace == "" {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", errMemoryStore)
+		return 1
+	}
+	store, vault, err := openStoreWithVault()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error %v\n", err)
+		return 1
+	}
+	defer store.Close()
+
+	if err := run(store, vault, config); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// openStoreWithVault opens the store, see openStore, and unlocks its
+// secrets with the key configured in the environment, if any
+func openStoreWithVault() (storage.Storage, *secrets.Vault, error) {
+	store, err := openStore()
+	if err != nil {
+		return nil, nil, fmt.Errorf("connecting to database: %w", err)
 	}
+	vault, err := secrets.LoadVaultFromEnv(store.SecretSalt)
+	if err != nil {
+		store.Close()
+		return nil, nil, fmt.Errorf("loading secret key: %w", err)
+	}
+	if err := store.SetVault(vault); err != nil {
+		store.Close()
+		return nil, nil, fmt.Errorf("unlocking secrets: %w", err)
+	}
+	return store, vault, nil
+}
+
+var (
+	// workspace is the directory of files requests are kept in, instead of
+	// the database in ~/.volt
+	workspace string
+
+	// dbPath is the database requests are kept in, :memory: keeping them
+	// until volt exits
+	dbPath = os.Getenv("VOLT_DB")
+)
+
+// parseGlobalFlags takes the --workspace and --db flags from before the
+// command, and returns the other arguments
+func parseGlobalFlags(args []string) ([]string, error) {
+	workspaceFlag, dbFlag := false, false
+flags:
+	for len(args) > 0 {
+		flag, value, hasValue := strings.Cut(args[0], "=")
+		var target *string
+		switch flag {
+		case "--workspace", "-workspace":
+			target, workspaceFlag = &workspace, true
+		case "--db", "-db":
+			target, dbFlag = &dbPath, true
+		default:
+			break flags
+		}
+		if hasValue {
+			*target, args = value, args[1:]
+			continue
+		}
+		if len(args) < 2 {
+			return nil, fmt.Errorf("%s needs a value", flag)
+		}
+		*target, args = args[1], args[2:]
+	}
+	if workspaceFlag && dbFlag {
+		return nil, fmt.Errorf("--workspace and --db can't be used together")
+	}
+	return args, nil
+}
+
+// findWorkspace returns the .volt directory of the current directory or of
+// one above it, up to the root of the git repository
+func findWorkspace() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	// ~/.volt holds the database
+	homeDir, _ := os.UserHomeDir()
+	for {
+		candidate := filepath.Join(dir, ".volt")
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() && dir != homeDir {
+			return candidate
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// openStore opens the workspace given with --workspace, the database given
+// with --db or $VOLT_DB, the workspace found in the repository, or else the
+// database in ~/.volt
+func openStore() (storage.Storage, error) {
+	switch {
+	case workspace != "":
+		return storage.NewFileStorage(workspace)
+	case dbPath == ":memory:":
+		return storage.NewMemoryStorage(), nil
+	case dbPath != "":
+		return storage.NewSQLiteStorage(dbPath)
+	}
+	if found := findWorkspace(); found != "" {
+		return storage.NewFileStorage(found)
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("getting home directory: %w", err)
+	}
+
+	return storage.NewSQLiteStorage(filepath.Join(homeDir, ".volt", "volt.db"))
 }
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRun_MemoryStoreSubcommands(t *testing.T) {
+	t.Cleanup(func() { dbPath, workspace = "", "" })
+	command := filepath.Join(t.TempDir(), "users.sh")
+	if err := os.WriteFile(command, []byte("curl https://api.example/users"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	subcommands := [][]string{
+		{"env", "set", "-e", "staging", "var.token=abc"},
+		{"import", command},
+		{"collection", "list"},
+		{"search", "users"},
+	}
+	for _, args := range subcommands {
+		dbPath = ""
+		if code := run(append([]string{"--db", ":memory:"}, args...)); code != 1 {
+			t.Errorf("%v with --db :memory: exited with %d, want 1", args, code)
+		}
+	}
+
+	// they work with a database that outlives them
+	db := filepath.Join(t.TempDir(), "volt.db")
+	for _, args := range subcommands {
+		if code := run(append([]string{"--db", db}, args...)); code != 0 {
+			t.Errorf("%v exited with %d, want 0", args, code)
+		}
+	}
+}
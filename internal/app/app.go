@@ -1,24 +1,44 @@
 package app
 
 import (
+	"context"
+	"time"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/secrets"
 	"github.com/owenHochwald/volt/internal/storage"
 	"github.com/owenHochwald/volt/internal/ui"
+	"github.com/owenHochwald/volt/internal/ui/cookiepane"
 	"github.com/owenHochwald/volt/internal/ui/requestpane"
 	"github.com/owenHochwald/volt/internal/ui/responsepane"
+	"github.com/owenHochwald/volt/internal/ui/searchpane"
 	"github.com/owenHochwald/volt/internal/ui/shortcutpane"
 	"github.com/owenHochwald/volt/internal/utils"
 )
 
+// Config holds the settings the TUI is started with
+type Config struct {
+	// Vault decrypts secrets at send time, nil when no key is configured
+	Vault *secrets.Vault
+
+	// Environment scopes persisted state such as cookies and default options
+	Environment string
+}
+
 type Model struct {
-	db *storage.SQLiteStorage
+	db storage.Storage
+
+	environment string
+	cookieJar   *http.CookieJar
 
 	sidebarPane  *ui.SidebarPane
 	requestPane  requestpane.RequestPane
 	responsePane *responsepane.ResponsePane
 	headerPane   *ui.Header
 	shortcutPane shortcutpane.ShortcutPane
+	cookiePane   cookiepane.CookiePane
+	searchPane   searchpane.SearchPane
 
 	savedRequests []http.Request
 
@@ -27,19 +47,48 @@ type Model struct {
 	width, height int
 
 	loadTestUpdates <-chan *http.LoadTestStats
+	loadTest        http.LoadTestStartMsg // the last load test started, saved once complete
+
+	// streamUpdates follows the request in flight, stopStream cancels it
+	streamUpdates <-chan http.StreamUpdate
+	stopStream    context.CancelFunc
+	downloading   bool // the request pane's status shows download progress
+
+	// sent is the request in flight and sentAt when it was sent, added to the history once answered
+	sent   *http.Request
+	sentAt time.Time
+
+	// webSocket is the open WebSocket connection, if any
+	webSocket *http.WSConn
+
 	showHelpModal   bool
+	showCookieModal bool
+	showSearchModal bool
 }
 
-func SetupModel(db *storage.SQLiteStorage) Model {
+func SetupModel(db storage.Storage, config Config) Model {
+	if config.Environment == "" {
+		config.Environment = storage.DefaultEnvironment
+	}
+
 	responsePane := responsepane.SetupResponsePane()
 	shortcutPane := shortcutpane.SetupShortcutPane()
 
+	cookieJar := http.NewCookieJar()
+	requestPane := requestpane.SetupRequestPane(db, config.Vault)
+	requestPane.CookieJar = cookieJar
+	requestPane.Client.SetCookieJar(cookieJar)
+
 	m := Model{
 		db:            db,
+		environment:   config.Environment,
+		cookieJar:     cookieJar,
 		sidebarPane:   ui.NewSidebar(db),
-		requestPane:   requestpane.SetupRequestPane(db),
+		requestPane:   requestPane,
 		responsePane:  &responsePane,
 		shortcutPane:  shortcutPane,
+		cookiePane:    cookiepane.SetupCookiePane(cookieJar),
+		searchPane:    searchpane.SetupSearchPane(db),
 		focusedPanel:  utils.SidebarPanel,
 		headerPane:    ui.SetupHeader(),
 		showHelpModal: false,
@@ -48,5 +97,14 @@ func SetupModel(db *storage.SQLiteStorage) Model {
 }
 
 func (m Model) Init() tea.Cmd {
-	return m.sidebarPane.Init()
+	cmds := []tea.Cmd{
+		m.sidebarPane.Init(),
+		ui.LoadCookiesCmd(m.db, m.environment),
+		ui.LoadEnvironmentCmd(m.db, m.environment),
+	}
+	// workspaces are reloaded when their files are edited or checked out
+	if watcher, ok := m.db.(storage.Watcher); ok {
+		cmds = append(cmds, ui.WatchStorageCmd(watcher))
+	}
+	return tea.Batch(cmds...)
 }
@@ -1,11 +1,21 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+	"time"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/storage"
 	"github.com/owenHochwald/volt/internal/ui"
+	"github.com/owenHochwald/volt/internal/ui/cookiepane"
 	"github.com/owenHochwald/volt/internal/ui/requestpane"
 	"github.com/owenHochwald/volt/internal/ui/responsepane"
+	"github.com/owenHochwald/volt/internal/ui/searchpane"
 	"github.com/owenHochwald/volt/internal/ui/shortcutpane"
 	"github.com/owenHochwald/volt/internal/utils"
 )
@@ -16,12 +26,44 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		// Handle '?' to toggle help modal
-		if msg.String() == "?" && !m.showHelpModal && m.focusedPanel != utils.RequestPanel {
+		sidebarEditing := m.focusedPanel == utils.SidebarPanel && m.sidebarPane.Editing()
+		if msg.String() == "?" && !m.showHelpModal && !m.showCookieModal && !m.showSearchModal && m.focusedPanel != utils.RequestPanel && !sidebarEditing {
 			m.showHelpModal = true
 			m.shortcutPane.SetFocused(true)
 			return m, nil
 		}
 
+		// Toggle the cookie jar modal from anywhere
+		if msg.String() == "alt+c" && !m.showHelpModal && !m.showCookieModal && !m.showSearchModal {
+			m.showCookieModal = true
+			m.cookiePane.Refresh()
+			m.cookiePane.SetFocused(true)
+			return m, nil
+		}
+
+		// Open the search palette from anywhere
+		if msg.String() == "ctrl+f" && !m.showHelpModal && !m.showCookieModal && !m.showSearchModal {
+			m.showSearchModal = true
+			m.searchPane.SetFocused(true)
+			return m, m.searchPane.Open()
+		}
+
+		// If the search palette is open, route ALL key messages to it
+		if m.showSearchModal {
+			var searchModel tea.Model
+			searchModel, cmd = m.searchPane.Update(msg)
+			m.searchPane = searchModel.(searchpane.SearchPane)
+			return m, cmd
+		}
+
+		// If cookie modal is open, route ALL key messages to it
+		if m.showCookieModal {
+			var cookieModel tea.Model
+			cookieModel, cmd = m.cookiePane.Update(msg)
+			m.cookiePane = cookieModel.(cookiepane.CookiePane)
+			return m, cmd
+		}
+
 		// If help modal is open, route ALL messages to it
 		if m.showHelpModal {
 			var shortcutModel tea.Model
@@ -38,17 +80,37 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		switch msg.String() {
 		case tea.KeyCtrlC.String():
+			if m.responsePane.Response != nil {
+				m.responsePane.Response.Close()
+			}
 			return m, tea.Quit
 		case tea.KeyEscape.String():
+			if m.focusedPanel == utils.ResponsePanel && m.stopStream != nil {
+				m.stopStream()
+				return m, nil
+			}
+			if m.focusedPanel == utils.ResponsePanel && m.webSocket != nil {
+				return m, ui.CloseWebSocketCmd(m.webSocket)
+			}
 			if m.focusedPanel == utils.RequestPanel {
 				m.focusedPanel = utils.SidebarPanel
 				return m, nil
 			}
 		case tea.KeyEnter.String(), " ":
-			if m.focusedPanel == utils.SidebarPanel {
+			if m.focusedPanel == utils.SidebarPanel && !sidebarEditing {
+				if entry, ok := m.sidebarPane.SelectedHistory(); ok {
+					m.focusedPanel = utils.RequestPanel
+					replay := entry.Request
+					return m, ui.SetRequestPaneRequestCmd(&replay, nil)
+				}
 				if item, ok := m.sidebarPane.SelectedItem(); ok {
 					m.focusedPanel = utils.RequestPanel
-					return m, ui.SetRequestPaneRequestCmd(item.Request)
+					setRequest := ui.SetRequestPaneRequestCmd(item.Request, m.sidebarPane.CollectionPath(item.Request))
+					// the connection belongs to the request being replaced
+					if m.webSocket != nil {
+						return m, tea.Batch(ui.CloseWebSocketCmd(m.webSocket), setRequest)
+					}
+					return m, setRequest
 				}
 			}
 		}
@@ -57,16 +119,221 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.shortcutPane.SetFocused(false)
 		return m, nil
 
+	case cookiepane.CloseCookieModalMsg:
+		m.showCookieModal = false
+		m.cookiePane.SetFocused(false)
+		return m, nil
+
+	case searchpane.CloseSearchModalMsg:
+		m.showSearchModal = false
+		m.searchPane.SetFocused(false)
+		return m, nil
+
+	case ui.SearchResultsMsg:
+		var searchModel tea.Model
+		searchModel, cmd = m.searchPane.Update(msg)
+		m.searchPane = searchModel.(searchpane.SearchPane)
+		return m, cmd
+
+	case searchpane.OpenResultMsg:
+		m.showSearchModal = false
+		m.searchPane.SetFocused(false)
+		if msg.Result.Kind == storage.SearchHistory {
+			return m, ui.LoadHistoryEntryCmd(m.db, msg.Result.ID)
+		}
+		request, ok := m.sidebarPane.SelectRequest(msg.Result.ID)
+		if !ok {
+			m.requestPane.SetStatus("Request not found, it may have been deleted")
+			return m, nil
+		}
+		m.focusedPanel = utils.RequestPanel
+		setRequest := ui.SetRequestPaneRequestCmd(request, m.sidebarPane.CollectionPath(request))
+		// the connection belongs to the request being replaced
+		if m.webSocket != nil {
+			return m, tea.Batch(ui.CloseWebSocketCmd(m.webSocket), setRequest)
+		}
+		return m, setRequest
+
+	case ui.HistoryEntryLoadedMsg:
+		if msg.Err != nil {
+			m.requestPane.SetStatus("Loading history failed: " + msg.Err.Error())
+			return m, nil
+		}
+		m.focusedPanel = utils.RequestPanel
+		replay := msg.Entry.Request
+		return m, ui.SetRequestPaneRequestCmd(&replay, nil)
+
+	case cookiepane.CookiesChangedMsg:
+		return m, ui.SaveCookiesCmd(m.db, m.environment, m.cookieJar.All())
+
+	case ui.CookiesLoadedMsg:
+		if msg.Err == nil {
+			m.cookieJar.Replace(msg.Cookies)
+		}
+		return m, nil
+
+	case ui.EnvironmentLoadedMsg:
+		if msg.Err != nil {
+			m.requestPane.SetStatus("Loading environment failed: " + msg.Err.Error())
+			return m, nil
+		}
+		m.requestPane.Client.Defaults = msg.Options
+		m.requestPane.Client.Variables = msg.Variables
+		return m, nil
+
+	case ui.CookiesSavedMsg:
+		if msg.Err != nil {
+			m.requestPane.SetStatus("Saving cookies failed: " + msg.Err.Error())
+		}
+		return m, nil
+
+	case http.RequestStartMsg:
+		ctx, cancel := context.WithCancel(context.Background())
+		updates := make(chan http.StreamUpdate, 64)
+		m.streamUpdates, m.stopStream = updates, cancel
+		m.sent, m.sentAt = msg.Request, time.Now()
+
+		go msg.Client.Stream(ctx, msg.Request, updates)
+		return m, ui.WaitForStreamCmd(updates)
+
+	case http.StreamMsg:
+		switch {
+		case msg.Update.Response != nil:
+			m.responsePane.StartStream(msg.Update.Response)
+			m.focusedPanel = utils.ResponsePanel
+		case msg.Update.Progress != nil:
+			m.downloading = true
+			m.requestPane.SetStatus(utils.FormatProgress(msg.Update.Progress.Received, msg.Update.Progress.Total))
+		default:
+			m.responsePane.AppendStream(msg.Update)
+		}
+		return m, ui.WaitForStreamCmd(m.streamUpdates)
+
 	case http.ResultMsg:
+		if m.stopStream != nil {
+			m.stopStream()
+		}
+		m.streamUpdates, m.stopStream = nil, nil
+		if m.downloading {
+			m.downloading = false
+			m.requestPane.SetStatus("")
+		}
 		m.requestPane.ResultMsgCleanup()
 		m.responsePane.SetResponse(msg.Response)
 		m.focusedPanel = utils.ResponsePanel
+		var cmds []tea.Cmd
+		if m.sent != nil {
+			cmds = append(cmds, ui.RecordHistoryCmd(m.db, storage.NewHistoryEntry(m.sent, msg.Response, m.sentAt)))
+			m.sent = nil
+		}
+		if m.requestPane.Client.CookiesEnabled() {
+			cmds = append(cmds, ui.SaveCookiesCmd(m.db, m.environment, m.cookieJar.All()))
+		}
+		return m, tea.Batch(cmds...)
+
+	case http.WSConnectMsg:
+		return m, ui.ConnectWebSocketCmd(msg.Client, msg.Request)
+
+	case http.WSConnectedMsg:
+		if msg.Err != nil {
+			response := msg.Response
+			if response == nil {
+				response = &http.Response{}
+			}
+			response.Error = msg.Err.Error()
+			m.requestPane.SetWebSocket(nil)
+			m.responsePane.SetResponse(response)
+			m.focusedPanel = utils.ResponsePanel
+			return m, nil
+		}
+		// focus stays on the request pane, where messages are typed
+		m.webSocket = msg.Conn
+		m.requestPane.SetWebSocket(msg.Conn)
+		m.requestPane.SetStatus("Connected to " + msg.Response.URL)
+		m.responsePane.StartWebSocket(msg.Response)
+		return m, ui.WaitForFrameCmd(msg.Conn)
+
+	case http.WSFrameMsg:
+		m.responsePane.AppendFrame(msg.Frame)
+		if m.webSocket != nil {
+			return m, ui.WaitForFrameCmd(m.webSocket)
+		}
+		return m, nil
+
+	case http.WSClosedMsg:
+		m.webSocket = nil
+		m.requestPane.SetWebSocket(nil)
+		m.requestPane.SetStatus("Disconnected")
+		m.responsePane.EndWebSocket()
+		return m, nil
+
+	case ui.BodySavedMsg:
+		if msg.Err != nil {
+			m.requestPane.SetStatus("Saving body failed: " + msg.Err.Error())
+			return m, nil
+		}
+		m.requestPane.SetStatus("Saved body to " + msg.Path)
+		return m, nil
+
+	case ui.CopiedMsg:
+		if msg.Err != nil {
+			m.requestPane.SetStatus("Copying failed: " + msg.Err.Error())
+			return m, nil
+		}
+		m.requestPane.SetStatus("Copied " + msg.What)
+		return m, nil
+
+	case ui.GRPCMethodsMsg:
+		if msg.Err != nil {
+			m.requestPane.SetStatus("Listing methods failed: " + msg.Err.Error())
+			return m, nil
+		}
+		lines := make([]string, len(msg.Methods))
+		for i, method := range msg.Methods {
+			lines[i] = method.String()
+		}
+		m.requestPane.SetStatus(fmt.Sprintf("Found %d methods", len(msg.Methods)))
+		m.responsePane.SetResponse(&http.Response{
+			Status:     fmt.Sprintf("%d methods", len(msg.Methods)),
+			StatusCode: 200,
+			Proto:      "gRPC",
+			Headers:    nethttp.Header{"Content-Type": {"text/plain"}},
+			Body:       strings.Join(lines, "\n"),
+			URL:        msg.URL,
+		})
+		m.focusedPanel = utils.ResponsePanel
+		return m, nil
+
+	case ui.GraphQLSchemaMsg:
+		if msg.Err != nil {
+			m.requestPane.SetStatus("Fetching schema failed: " + msg.Err.Error())
+			return m, nil
+		}
+		m.requestPane.SetGraphQLSchema(msg.URL, msg.Schema, msg.FetchedAt)
+		m.requestPane.SetStatus(fmt.Sprintf("Schema fetched: %d types", len(msg.Schema.Types)))
+		return m, nil
+
+	case ui.WSSentMsg:
+		if msg.Err != nil {
+			m.requestPane.SetStatus("Frame not sent: " + msg.Err.Error())
+		}
 		return m, nil
 
 	case ui.RequestSavedMsg:
+		if errors.Is(msg.Err, storage.ErrConflict) {
+			m.requestPane.SetStatus("Save failed: " + msg.Err.Error() + ", alt+s saves yours as a new request")
+			return m, nil
+		}
 		if msg.Err != nil {
+			m.requestPane.SetStatus("Save failed: " + msg.Err.Error())
 			return m, nil
 		}
+		m.requestPane.MarkSaved(msg)
+		if msg.AsNew {
+			m.requestPane.SetStatus("Saved as new request " + msg.Request.Name)
+		} else {
+			m.requestPane.SetStatus("Saved " + msg.Request.Name)
+		}
 		return m, ui.LoadRequestsCmd(m.db)
 
 	case ui.RequestDeletedMsg:
@@ -75,6 +342,64 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, ui.LoadRequestsCmd(m.db)
 
+	case ui.CollectionsChangedMsg:
+		if msg.Err != nil {
+			m.requestPane.SetStatus("Updating collections failed: " + msg.Err.Error())
+		}
+		return m, ui.LoadRequestsCmd(m.db)
+
+	case ui.StorageChangedMsg:
+		m.requestPane.SetStatus("Reloaded requests changed on disk")
+		return m, tea.Batch(
+			ui.LoadRequestsCmd(m.db),
+			ui.LoadEnvironmentCmd(m.db, m.environment),
+			ui.WatchStorageCmd(m.db.(storage.Watcher)),
+		)
+
+	case ui.ImportFileMsg:
+		m.requestPane.SetStatus("Importing " + msg.Path + "...")
+		return m, ui.ImportCmd(m.db, msg.Path, m.environment)
+
+	case ui.ImportCurlMsg:
+		m.focusedPanel = utils.RequestPanel
+		m.requestPane.LoadCurl(msg.Command)
+		// the connection belongs to the request being replaced
+		if m.webSocket != nil {
+			return m, ui.CloseWebSocketCmd(m.webSocket)
+		}
+		return m, nil
+
+	case ui.ImportedMsg:
+		switch {
+		case msg.Err != nil:
+			m.requestPane.SetStatus("Import failed: " + msg.Err.Error())
+		case len(msg.Warnings) > 0:
+			m.requestPane.SetStatus(fmt.Sprintf("%s, %d not converted: %s", msg.Summary, len(msg.Warnings), strings.Join(msg.Warnings, "; ")))
+		default:
+			m.requestPane.SetStatus(msg.Summary.String())
+		}
+		return m, tea.Batch(ui.LoadRequestsCmd(m.db), ui.LoadEnvironmentCmd(m.db, m.environment))
+
+	case ui.HistoryLoadedMsg:
+		if msg.Err != nil {
+			m.requestPane.SetStatus("Loading history failed: " + msg.Err.Error())
+			return m, nil
+		}
+		var sidebarModel tea.Model
+		sidebarModel, cmd = m.sidebarPane.Update(msg)
+		m.sidebarPane = sidebarModel.(*ui.SidebarPane)
+		return m, cmd
+
+	case ui.HistoryRecordedMsg:
+		if msg.Err != nil {
+			m.requestPane.SetStatus("Recording history failed: " + msg.Err.Error())
+			return m, nil
+		}
+		var sidebarModel tea.Model
+		sidebarModel, cmd = m.sidebarPane.Update(msg)
+		m.sidebarPane = sidebarModel.(*ui.SidebarPane)
+		return m, cmd
+
 	case ui.RequestsLoadingMsg:
 		if msg.Err != nil {
 			return m, nil
@@ -87,6 +412,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case http.LoadTestStartMsg:
 		updates := make(chan *http.LoadTestStats, 100)
 		m.loadTestUpdates = updates
+		m.loadTest = msg
 
 		// start load test in background
 		go func() {
@@ -94,6 +420,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}()
 
 		m.responsePane.ClearLoadTestStats()
+		m.responsePane.SetLoadTestRuns(msg.RequestID, nil)
 		return m, ui.WaitForLoadTestUpdatesCmd(updates, msg.Config.TotalRequests)
 
 	case http.LoadTestStatsMsg:
@@ -112,6 +439,39 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.requestPane.ExitLoadTestMode()
 		m.focusedPanel = utils.ResponsePanel // Switch focus to results
+		if msg.Stats == nil {
+			return m, nil
+		}
+		if m.loadTest.RequestID == 0 {
+			m.requestPane.SetStatus("Save the request to keep its load tests")
+			return m, nil
+		}
+		run := storage.NewLoadTestRun(m.loadTest.RequestID, m.loadTest.Config, msg.Stats, m.loadTest.Label, "")
+		return m, ui.SaveLoadTestCmd(m.db, run)
+
+	case ui.LoadTestSavedMsg:
+		if msg.Err != nil {
+			m.requestPane.SetStatus("Saving load test failed: " + msg.Err.Error())
+			return m, nil
+		}
+		m.responsePane.LoadTestSaved(*msg.Run)
+		return m, ui.LoadLoadTestsCmd(m.db, msg.Run.RequestID, false)
+
+	case ui.LoadTestsLoadedMsg:
+		if msg.Err != nil {
+			m.requestPane.SetStatus("Loading load tests failed: " + msg.Err.Error())
+			return m, nil
+		}
+		if !msg.Show {
+			m.responsePane.SetLoadTestRuns(msg.RequestID, msg.Runs)
+			return m, nil
+		}
+		if len(msg.Runs) == 0 {
+			m.requestPane.SetStatus("No load tests of this request yet")
+			return m, nil
+		}
+		m.responsePane.ShowLoadTestRuns(msg.RequestID, msg.Runs)
+		m.focusedPanel = utils.ResponsePanel
 		return m, nil
 
 	case http.LoadTestErrorMsg:
@@ -134,13 +494,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.shortcutPane.SetWidth(modalWidth)
 		m.shortcutPane.SetHeight(modalHeight)
+		m.cookiePane.SetWidth(min(m.width-10, 100))
+		m.cookiePane.SetHeight(modalHeight)
+		m.searchPane.SetWidth(min(m.width-10, 100))
+		m.searchPane.SetHeight(modalHeight)
 
 		// Existing size handling for other panels
 		m.sidebarPane.SetSize(m.width/2, (m.height-15)/2)
 	}
 
-	// Existing panel update routing (only when help modal is closed)
-	if !m.showHelpModal {
+	// Existing panel update routing (only when modals are closed)
+	if !m.showHelpModal && !m.showCookieModal && !m.showSearchModal {
 		if m.focusedPanel == utils.SidebarPanel {
 			var sidebarPaneModel tea.Model
 			sidebarPaneModel, cmd = m.sidebarPane.Update(msg)
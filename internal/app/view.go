@@ -42,10 +42,16 @@ func (m Model) View() string {
 	bottomPanels := lipgloss.JoinHorizontal(lipgloss.Top, sidebar, rightSide)
 	mainView := lipgloss.JoinVertical(lipgloss.Top, m.headerView(m.width), bottomPanels)
 
-	// If help modal is open, overlay it on top
+	// If a modal is open, overlay it on top
 	if m.showHelpModal {
 		return m.overlayHelpModal()
 	}
+	if m.showCookieModal {
+		return m.overlayModal(m.cookiePane.View())
+	}
+	if m.showSearchModal {
+		return m.overlayModal(m.searchPane.View())
+	}
 
 	return mainView
 }
@@ -79,15 +85,18 @@ func (m Model) responseView(height, width int) string {
 
 // overlayHelpModal renders the help modal centered over the main view
 func (m Model) overlayHelpModal() string {
-	helpModal := m.shortcutPane.View()
+	return m.overlayModal(m.shortcutPane.View())
+}
 
+// overlayModal renders a modal centered over the main view
+func (m Model) overlayModal(modal string) string {
 	// Position modal in center using Place
 	overlay := lipgloss.Place(
 		m.width,
 		m.height,
 		lipgloss.Center,
 		lipgloss.Center,
-		helpModal,
+		modal,
 		lipgloss.WithWhitespaceChars("░"),
 		lipgloss.WithWhitespaceForeground(lipgloss.Color("236")),
 	)
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -22,6 +23,41 @@ func RunBench(config *BenchConfig) error {
 		URL:     config.URL,
 		Headers: config.Headers,
 		Body:    config.Body,
+		Options: config.Options,
+	}
+
+	tlsConfig, err := http.LoadTLSConfig(config.Options)
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil && tlsConfig.InsecureSkipVerify {
+		fmt.Fprintln(os.Stderr, "Warning: TLS certificate verification is disabled")
+	}
+	dial, err := http.LoadProxyDialer(config.Options, config.Timeout)
+	if err != nil {
+		return err
+	}
+	redirects, err := http.ParseRedirectPolicy(config.Options)
+	if err != nil {
+		return err
+	}
+	protocol, err := http.ParseProtocol(config.Options)
+	if err != nil {
+		return err
+	}
+	acceptEncoding, err := http.ParseAcceptEncoding(config.Options)
+	if err != nil {
+		return err
+	}
+
+	// gRPC calls are resolved once, through reflection or the .proto files
+	var call *http.GRPCCall
+	if config.GRPC {
+		req.Method = http.GRPC
+		call, err = http.InitClient(config.Timeout, false).ResolveGRPC(context.Background(), req)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Calculate total requests if duration-based
@@ -36,12 +72,19 @@ func RunBench(config *BenchConfig) error {
 	}
 
 	jobConfig := &http.JobConfig{
-		Request:       req,
-		Concurrency:   config.Concurrency,
-		TotalRequests: totalRequests,
-		Timeout:       config.Timeout,
-		QPS:           float64(config.RateLimit),
-		StreamUpdates: false,
+		Request:        req,
+		Concurrency:    config.Concurrency,
+		TotalRequests:  totalRequests,
+		Timeout:        config.Timeout,
+		QPS:            float64(config.RateLimit),
+		StreamUpdates:  false,
+		Cookies:        config.Cookies,
+		TLSConfig:      tlsConfig,
+		Dial:           dial,
+		Redirects:      redirects,
+		Protocol:       protocol,
+		AcceptEncoding: acceptEncoding,
+		GRPC:           call,
 	}
 
 	updates := make(chan *http.LoadTestStats, 1000)
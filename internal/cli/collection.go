@@ -0,0 +1,225 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/owenHochwald/volt/internal/http"
+)
+
+// CollectionConfig holds parsed CLI arguments for the collection subcommand
+type CollectionConfig struct {
+	Action string // list, create, rename, move, delete, set or unset
+	Args   []string
+}
+
+// CollectionStore is the storage the collection subcommand reads and writes
+type CollectionStore interface {
+	CreateCollection(collection *http.Collection) error
+	LoadCollections() ([]http.Collection, error)
+	RenameCollection(id int64, name string) error
+	SetCollectionDefaults(collection *http.Collection) error
+	MoveCollection(id, parentID int64) error
+	DeleteCollection(id int64) error
+}
+
+// ParseCollectionFlags parses the action and arguments of the collection subcommand
+func ParseCollectionFlags(args []string) (*CollectionConfig, error) {
+	config := &CollectionConfig{Action: "list"}
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		config.Action, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet("collection "+config.Action, flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	config.Args = fs.Args()
+
+	return config, nil
+}
+
+// RunCollection lists or edits collections, which are named by their path,
+// e.g. API/users for the users folder of the API collection
+func RunCollection(store CollectionStore, config *CollectionConfig, out io.Writer) error {
+	collections, err := store.LoadCollections()
+	if err != nil {
+		return err
+	}
+	if usage, ok := collectionUsage[config.Action]; ok && len(config.Args) < usage.args {
+		return fmt.Errorf("usage: volt collection %s %s", config.Action, usage.text)
+	}
+
+	switch config.Action {
+	case "list":
+		if len(collections) == 0 {
+			fmt.Fprintln(out, "No collections")
+			return nil
+		}
+		printCollections(collections, 0, 0, out)
+		return nil
+
+	case "create":
+		parentPath, name := splitCollectionPath(config.Args[0])
+		collection := &http.Collection{Name: name}
+		if parentPath != "" {
+			parent, err := findCollection(collections, parentPath)
+			if err != nil {
+				return err
+			}
+			collection.ParentID = parent.ID
+		}
+		if err := store.CreateCollection(collection); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Created %s\n", config.Args[0])
+		return nil
+
+	case "rename":
+		collection, err := findCollection(collections, config.Args[0])
+		if err != nil {
+			return err
+		}
+		return store.RenameCollection(collection.ID, config.Args[1])
+
+	case "move":
+		collection, err := findCollection(collections, config.Args[0])
+		if err != nil {
+			return err
+		}
+		var parentID int64
+		if target := strings.Trim(config.Args[1], "/"); target != "" {
+			parent, err := findCollection(collections, target)
+			if err != nil {
+				return err
+			}
+			parentID = parent.ID
+		}
+		return store.MoveCollection(collection.ID, parentID)
+
+	case "delete":
+		collection, err := findCollection(collections, config.Args[0])
+		if err != nil {
+			return err
+		}
+		return store.DeleteCollection(collection.ID)
+
+	case "set", "unset":
+		collection, err := findCollection(collections, config.Args[0])
+		if err != nil {
+			return err
+		}
+		if err := editCollectionDefaults(collection, config.Action, config.Args[1:]); err != nil {
+			return err
+		}
+		if err := store.SetCollectionDefaults(collection); err != nil {
+			return err
+		}
+		printCollection(collection, 0, out)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown collection action: %s (use list, create, rename, move, delete, set or unset)", config.Action)
+	}
+}
+
+// collectionUsage is the arguments each action needs
+var collectionUsage = map[string]struct {
+	args int
+	text string
+}{
+	"create": {1, "path"},
+	"rename": {2, "path name"},
+	"move":   {2, "path parent (/ for the top level)"},
+	"delete": {1, "path"},
+	"set":    {2, "path base_url=url|auth=value|header.Name=value..."},
+	"unset":  {2, "path base_url|auth|header.Name..."},
+}
+
+// editCollectionDefaults sets or unsets the base URL, auth and headers of a collection
+func editCollectionDefaults(collection *http.Collection, action string, args []string) error {
+	if collection.Headers == nil {
+		collection.Headers = make(map[string]string)
+	}
+	for _, arg := range args {
+		key, value, found := strings.Cut(arg, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if action == "unset" {
+			value = ""
+		} else if !found {
+			return fmt.Errorf("default must be in format 'key=value': %s", arg)
+		}
+
+		switch {
+		case key == "base_url":
+			collection.BaseURL = value
+		case key == "auth":
+			collection.Auth = value
+		case strings.HasPrefix(key, "header."):
+			name := strings.TrimPrefix(key, "header.")
+			if value == "" {
+				delete(collection.Headers, name)
+			} else {
+				collection.Headers[name] = value
+			}
+		default:
+			return fmt.Errorf("unknown collection default: %s (use base_url, auth or header.Name)", key)
+		}
+	}
+	return nil
+}
+
+// splitCollectionPath splits a path into the path of the parent and the name
+func splitCollectionPath(path string) (string, string) {
+	path = strings.Trim(path, "/")
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[:i], path[i+1:]
+	}
+	return "", path
+}
+
+// findCollection returns the collection at a path of names, matched case-insensitively
+func findCollection(collections []http.Collection, path string) (*http.Collection, error) {
+	var parentID int64
+	var found *http.Collection
+	for _, name := range strings.Split(strings.Trim(path, "/"), "/") {
+		i := slices.IndexFunc(collections, func(c http.Collection) bool {
+			return c.ParentID == parentID && strings.EqualFold(c.Name, name)
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("collection not found: %s", path)
+		}
+		found = &collections[i]
+		parentID = found.ID
+	}
+	return found, nil
+}
+
+// printCollections writes the collections inside parentID, each followed by its folders
+func printCollections(collections []http.Collection, parentID int64, depth int, out io.Writer) {
+	for _, collection := range collections {
+		if collection.ParentID == parentID {
+			printCollection(&collection, depth, out)
+			printCollections(collections, collection.ID, depth+1, out)
+		}
+	}
+}
+
+// printCollection writes the name and defaults of a collection. Auth values are never shown.
+func printCollection(collection *http.Collection, depth int, out io.Writer) {
+	pad := strings.Repeat("  ", depth)
+	fmt.Fprintf(out, "%s%s\n", pad, collection.Name)
+	if collection.BaseURL != "" {
+		fmt.Fprintf(out, "%s  base_url = %s\n", pad, collection.BaseURL)
+	}
+	if collection.Auth != "" {
+		fmt.Fprintf(out, "%s  auth = (hidden)\n", pad)
+	}
+	for _, key := range slices.Sorted(maps.Keys(collection.Headers)) {
+		fmt.Fprintf(out, "%s  header.%s = %s\n", pad, key, collection.Headers[key])
+	}
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/owenHochwald/volt/internal/storage"
+)
+
+func TestRunCollection(t *testing.T) {
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "volt.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	run := func(args ...string) (string, error) {
+		config, err := ParseCollectionFlags(args)
+		if err != nil {
+			return "", err
+		}
+		var out bytes.Buffer
+		err = RunCollection(store, config, &out)
+		return out.String(), err
+	}
+
+	for _, args := range [][]string{
+		{"create", "API"},
+		{"create", "api/users"},
+		{"create", "Admin"},
+		{"set", "API", "base_url=https://api.example", "header.Accept=application/json"},
+		{"set", "API/users", "base_url=/users"},
+		{"move", "Admin", "API"},
+		{"rename", "API/Admin", "admin"},
+	} {
+		if _, err := run(args...); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	}
+	if _, err := run("create", "missing/folder"); err == nil {
+		t.Error("expected a folder of a missing collection to be rejected")
+	}
+	if _, err := run("move", "API", "API/users"); err == nil {
+		t.Error("expected a collection moved into its own folder to be rejected")
+	}
+	if _, err := run("set", "API", "colour=blue"); err == nil {
+		t.Error("expected unknown default to be rejected")
+	}
+	if _, err := run("set", "API", "auth=Bearer abc"); err == nil {
+		t.Error("expected auth to need a secret key")
+	}
+	if _, err := run("unset", "API", "header.Accept"); err != nil {
+		t.Fatalf("unset: %v", err)
+	}
+
+	out, err := run("list")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	want := "API\n  base_url = https://api.example\n  admin\n  users\n    base_url = /users\n"
+	if out != want {
+		t.Errorf("unexpected list output:\n%s", out)
+	}
+
+	if _, err := run("delete", "API"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if out, _ := run("list"); !strings.Contains(out, "No collections") {
+		t.Errorf("expected no collections left, got:\n%s", out)
+	}
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/storage"
+)
+
+// EnvConfig holds parsed CLI arguments for the env subcommand
+type EnvConfig struct {
+	Action      string // list, show, set or unset
+	Environment string
+	Args        []string
+}
+
+// EnvStore is the storage the env subcommand reads and writes
+type EnvStore interface {
+	LoadEnvironmentOptions(environment string) (map[string]string, error)
+	SaveEnvironmentOptions(environment string, options map[string]string) error
+	LoadEnvironmentVariables(environment string) (map[string]string, error)
+	SaveEnvironmentVariables(environment string, variables map[string]string) error
+	ListEnvironments() ([]string, error)
+}
+
+// variablePrefix marks the keys of env set and unset that are variables rather than options
+const variablePrefix = "var."
+
+// ParseEnvFlags parses the action and flags of the env subcommand
+func ParseEnvFlags(args []string) (*EnvConfig, error) {
+	config := &EnvConfig{Action: "list"}
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		config.Action, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet("env "+config.Action, flag.ExitOnError)
+	fs.StringVar(&config.Environment, "e", os.Getenv("VOLT_ENV"), "Environment name (default: $VOLT_ENV or default)")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if config.Environment == "" {
+		config.Environment = storage.DefaultEnvironment
+	}
+	config.Args = fs.Args()
+
+	return config, nil
+}
+
+// RunEnv lists or edits the options of an environment
+func RunEnv(store EnvStore, config *EnvConfig, out io.Writer) error {
+	switch config.Action {
+	case "list":
+		names, err := store.ListEnvironments()
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			fmt.Fprintln(out, "No environments configured")
+			return nil
+		}
+		for _, name := range names {
+			if err := printEnvironment(store, name, out); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case "show":
+		return printEnvironment(store, config.Environment, out)
+
+	case "set", "unset":
+		if len(config.Args) == 0 {
+			return fmt.Errorf("usage: volt env %s [-e name] %s", config.Action, envUsage(config.Action))
+		}
+		options, err := store.LoadEnvironmentOptions(config.Environment)
+		if err != nil {
+			return err
+		}
+		variables, err := store.LoadEnvironmentVariables(config.Environment)
+		if err != nil {
+			return err
+		}
+		if options == nil {
+			options = make(map[string]string)
+		}
+		if variables == nil {
+			variables = make(map[string]string)
+		}
+
+		for _, arg := range config.Args {
+			key, value, found := strings.Cut(arg, "=")
+			key = strings.TrimSpace(key)
+			if config.Action == "set" && !found {
+				return fmt.Errorf("option must be in format 'key=value': %s", arg)
+			}
+			target := options
+			if name, ok := strings.CutPrefix(key, variablePrefix); ok {
+				target, key = variables, name
+			}
+			if config.Action == "unset" {
+				delete(target, key)
+				continue
+			}
+			target[key] = strings.TrimSpace(value)
+		}
+
+		if err := http.ValidateOptions(options); err != nil {
+			return err
+		}
+		if err := store.SaveEnvironmentOptions(config.Environment, options); err != nil {
+			return err
+		}
+		if err := store.SaveEnvironmentVariables(config.Environment, variables); err != nil {
+			return err
+		}
+		return printEnvironment(store, config.Environment, out)
+
+	default:
+		return fmt.Errorf("unknown env action: %s (use list, show, set or unset)", config.Action)
+	}
+}
+
+func envUsage(action string) string {
+	if action == "unset" {
+		return "key..."
+	}
+	return "key=value..."
+}
+
+// printEnvironment writes an environment's options and then its variables, sorted by key
+func printEnvironment(store EnvStore, name string, out io.Writer) error {
+	options, err := store.LoadEnvironmentOptions(name)
+	if err != nil {
+		return err
+	}
+	variables, err := store.LoadEnvironmentVariables(name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "%s\n", name)
+	if len(options) == 0 && len(variables) == 0 {
+		fmt.Fprintln(out, "  (no options)")
+		return nil
+	}
+	for _, key := range slices.Sorted(maps.Keys(options)) {
+		fmt.Fprintf(out, "  %s = %s\n", key, options[key])
+	}
+	for _, key := range slices.Sorted(maps.Keys(variables)) {
+		fmt.Fprintf(out, "  %s%s = %s\n", variablePrefix, key, variables[key])
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/owenHochwald/volt/internal/storage"
+)
+
+func TestParseEnvFlags(t *testing.T) {
+	t.Setenv("VOLT_ENV", "")
+
+	config, err := ParseEnvFlags(nil)
+	if err != nil {
+		t.Fatalf("ParseEnvFlags() error = %v", err)
+	}
+	if config.Action != "list" || config.Environment != storage.DefaultEnvironment {
+		t.Errorf("got action %s env %s, want list default", config.Action, config.Environment)
+	}
+
+	config, err = ParseEnvFlags([]string{"set", "-e", "staging", "tls.insecure=true"})
+	if err != nil {
+		t.Fatalf("ParseEnvFlags() error = %v", err)
+	}
+	if config.Action != "set" || config.Environment != "staging" || len(config.Args) != 1 {
+		t.Errorf("unexpected config: %+v", config)
+	}
+}
+
+func TestRunEnv(t *testing.T) {
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "volt.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	run := func(args ...string) (string, error) {
+		config, err := ParseEnvFlags(args)
+		if err != nil {
+			return "", err
+		}
+		var out bytes.Buffer
+		err = RunEnv(store, config, &out)
+		return out.String(), err
+	}
+
+	if _, err := run("set", "-e", "staging", "tls.ca=/etc/ca.pem", "tls.min=1.2"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if _, err := run("set", "-e", "staging", "tls.min=9"); err == nil {
+		t.Error("expected invalid TLS version to be rejected")
+	}
+	if _, err := run("set", "-e", "staging", "colour=blue"); err == nil {
+		t.Error("expected unknown option to be rejected")
+	}
+	if _, err := run("unset", "-e", "staging", "tls.min"); err != nil {
+		t.Fatalf("unset: %v", err)
+	}
+	if _, err := run("set", "-e", "staging", "var.base_url=https://staging.example", "var.token=abc"); err != nil {
+		t.Fatalf("set variables: %v", err)
+	}
+	if _, err := run("unset", "-e", "staging", "var.token"); err != nil {
+		t.Fatalf("unset variable: %v", err)
+	}
+
+	out, err := run("list")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if !strings.Contains(out, "staging\n  tls.ca = /etc/ca.pem\n  var.base_url = https://staging.example\n") ||
+		strings.Contains(out, "tls.min") || strings.Contains(out, "var.token") {
+		t.Errorf("unexpected list output:\n%s", out)
+	}
+}
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/secrets"
+	"github.com/owenHochwald/volt/internal/storage"
+)
+
+// ExportConfig holds parsed CLI arguments for the export subcommand
+type ExportConfig struct {
+	Environment string
+	Format      string
+	Secrets     bool
+	Name        string
+}
+
+// ExportStore is the storage the export subcommand reads
+type ExportStore interface {
+	Load() ([]http.Request, error)
+	LoadCollections() ([]http.Collection, error)
+	LoadEnvironmentOptions(environment string) (map[string]string, error)
+	LoadEnvironmentVariables(environment string) (map[string]string, error)
+}
+
+// ParseExportFlags parses the flags and request name of the export
+// subcommand. The name may come before or after the flags.
+func ParseExportFlags(args []string) (*ExportConfig, error) {
+	config := &ExportConfig{}
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		config.Name, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	fs.StringVar(&config.Environment, "e", os.Getenv("VOLT_ENV"), "Environment whose variables are resolved (default: $VOLT_ENV or default)")
+	fs.StringVar(&config.Format, "as", "curl", "Snippet format: "+strings.Join(http.SnippetFormats, ", "))
+	fs.BoolVar(&config.Secrets, "secrets", false, "Include secret header values instead of masking them")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if config.Name == "" && fs.NArg() == 1 {
+		config.Name = fs.Arg(0)
+	} else if config.Name == "" || fs.NArg() > 0 {
+		return nil, fmt.Errorf("usage: volt export [-e name] [-as format] [-secrets] request")
+	}
+	if config.Environment == "" {
+		config.Environment = storage.DefaultEnvironment
+	}
+
+	return config, nil
+}
+
+// RunExport writes a saved request as a command or code snippet, with its
+// collection defaults and environment variables resolved. Secret headers are
+// masked unless config.Secrets is set, which needs the vault to decrypt them.
+func RunExport(store ExportStore, vault *secrets.Vault, config *ExportConfig, out io.Writer) error {
+	requests, err := store.Load()
+	if err != nil {
+		return err
+	}
+	collections, err := store.LoadCollections()
+	if err != nil {
+		return err
+	}
+	request, err := findRequest(requests, collections, config.Name)
+	if err != nil {
+		return err
+	}
+
+	options, err := store.LoadEnvironmentOptions(config.Environment)
+	if err != nil {
+		return err
+	}
+	variables, err := store.LoadEnvironmentVariables(config.Environment)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Vault: vault, Variables: variables}
+	request = request.Inherit(http.CollectionPath(collections, request.CollectionID))
+	if config.Secrets {
+		if request, err = client.Unseal(request); err != nil {
+			return err
+		}
+	} else {
+		request = client.Redact(request)
+	}
+	request.Options = http.MergeOptions(options, request.Options)
+
+	snippet, err := request.Snippet(config.Format)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, strings.TrimSuffix(snippet, "\n"))
+	return nil
+}
+
+// findRequest returns the saved request with a name, or with a path like
+// API/users/List users when it is in a collection, matched case-insensitively
+func findRequest(requests []http.Request, collections []http.Collection, path string) (*http.Request, error) {
+	collectionPath, name := splitCollectionPath(path)
+	var collectionID int64
+	if collectionPath != "" {
+		collection, err := findCollection(collections, collectionPath)
+		if err != nil {
+			return nil, err
+		}
+		collectionID = collection.ID
+	}
+
+	var found []*http.Request
+	for i, request := range requests {
+		if strings.EqualFold(request.Name, name) && (collectionPath == "" || request.CollectionID == collectionID) {
+			found = append(found, &requests[i])
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("request not found: %s", path)
+	case 1:
+		return found[0], nil
+	}
+
+	paths := make([]string, 0, len(found))
+	for _, request := range found {
+		paths = append(paths, requestPath(collections, request))
+	}
+	slices.Sort(paths)
+	return nil, fmt.Errorf("%d requests are named %s, use one of %s", len(found), name, strings.Join(paths, ", "))
+}
+
+// requestPath returns the name of a request after the names of the collections it is in
+func requestPath(collections []http.Collection, request *http.Request) string {
+	var names []string
+	for _, collection := range http.CollectionPath(collections, request.CollectionID) {
+		names = append(names, collection.Name)
+	}
+	return strings.Join(append(names, request.Name), "/")
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/secrets"
+	"github.com/owenHochwald/volt/internal/storage"
+)
+
+func TestRunExport(t *testing.T) {
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "volt.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	vault, err := secrets.NewVault([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetVault(vault); err != nil {
+		t.Fatal(err)
+	}
+
+	collection := &http.Collection{Name: "API", BaseURL: "https://api.example", Auth: "Bearer {{token}}"}
+	if err := store.CreateCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+	for _, request := range []*http.Request{
+		{Name: "List users", Method: http.GET, URL: "/{{version}}/users", Body: "{}", CollectionID: collection.ID},
+		{Name: "List users", Method: http.GET, URL: "https://other.example/users", Body: "{}"},
+	} {
+		if err := store.Save(request); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.SaveEnvironmentVariables("staging", map[string]string{"version": "v2", "token": "abc"}); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := ParseExportFlags([]string{"List users", "-e", "staging"})
+	if err != nil {
+		t.Fatalf("ParseExportFlags() error = %v", err)
+	}
+	var out bytes.Buffer
+	if err := RunExport(store, vault, config, &out); err == nil || !strings.Contains(err.Error(), "API/List users, List users") {
+		t.Errorf("expected an error listing both requests, got %v", err)
+	}
+
+	config, err = ParseExportFlags([]string{"-e", "staging", "api/List users"})
+	if err != nil {
+		t.Fatalf("ParseExportFlags() error = %v", err)
+	}
+	if err := RunExport(store, vault, config, &out); err != nil {
+		t.Fatalf("RunExport() error = %v", err)
+	}
+	want := "curl \\\n  https://api.example/v2/users \\\n  -H 'Authorization: " + secrets.Mask + "'\n"
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	out.Reset()
+	config.Secrets = true
+	config.Format = "httpie"
+	if err := RunExport(store, vault, config, &out); err != nil {
+		t.Fatalf("RunExport() error = %v", err)
+	}
+	want = "http \\\n  GET \\\n  https://api.example/v2/users \\\n  'Authorization:Bearer abc'\n"
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+	// secret variables are masked like secret headers, and decrypted with -secrets
+	token, err := vault.Seal("abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveEnvironmentVariables("staging", map[string]string{"version": "v2", "token": token}); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	config.Secrets = false
+	config.Format = "curl"
+	config.Name = "api/Search"
+	if err := store.Save(&http.Request{Name: "Search", Method: http.GET, URL: "/search?key={{token}}", CollectionID: collection.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunExport(store, vault, config, &out); err != nil {
+		t.Fatalf("RunExport() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "https://api.example/search?key="+secrets.Mask) || strings.Contains(out.String(), "enc:v1:") {
+		t.Errorf("expected the secret variable masked, got:\n%s", out.String())
+	}
+	out.Reset()
+	config.Secrets = true
+	if err := RunExport(store, vault, config, &out); err != nil {
+		t.Fatalf("RunExport() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "https://api.example/search?key=abc") || !strings.Contains(out.String(), "Bearer abc") {
+		t.Errorf("expected the secret variable decrypted, got:\n%s", out.String())
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/owenHochwald/volt/internal/http"
 )
 
 // BenchConfig holds parsed CLI flags for benchmarking
@@ -17,6 +19,9 @@ type BenchConfig struct {
 	// Headers (parsed from repeated -H flags)
 	Headers map[string]string
 
+	// Options holds transport settings such as tls.ca (see http.Opt* keys)
+	Options map[string]string
+
 	// Load parameters
 	Concurrency   int
 	Duration      time.Duration
@@ -26,6 +31,10 @@ type BenchConfig struct {
 	Timeout   time.Duration
 	RateLimit int // requests per second, 0 = unlimited
 	KeepAlive bool
+	Cookies   bool // per-worker cookie jars for session based endpoints
+
+	// GRPC calls the unary method named by the URL, grpc://host:port/pkg.Service/Method
+	GRPC bool
 
 	// Output options
 	Quiet  bool
@@ -52,6 +61,47 @@ func (h headerFlags) Set(value string) error {
 	return nil
 }
 
+// optionFlags implements flag.Value for repeated -opt flags
+type optionFlags map[string]string
+
+func (o optionFlags) String() string {
+	return ""
+}
+
+func (o optionFlags) Set(value string) error {
+	key, val, found := strings.Cut(value, "=")
+	if !found {
+		return fmt.Errorf("option must be in format 'key=value'")
+	}
+	o[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	return nil
+}
+
+// setOption returns a flag handler that stores its value under an option key
+func (o optionFlags) setOption(key string) func(string) error {
+	return func(value string) error {
+		o[key] = value
+		return nil
+	}
+}
+
+// addOptionFlags registers the transport option flags shared by bench and send
+func addOptionFlags(fs *flag.FlagSet, options optionFlags) {
+	fs.Func("cacert", "CA bundle to trust, PEM (tls.ca)", options.setOption(http.OptTLSCA))
+	fs.Func("cert", "Client certificate for mTLS, PEM (tls.cert)", options.setOption(http.OptTLSCert))
+	fs.Func("key", "Client private key for mTLS, PEM (tls.key)", options.setOption(http.OptTLSKey))
+	fs.Func("proxy", "Proxy URL: http://, https:// or socks5://, 'none' to ignore HTTP_PROXY (proxy)", options.setOption(http.OptProxy))
+	fs.BoolFunc("insecure", "Skip TLS certificate verification (tls.insecure)", options.setOption(http.OptTLSInsecure))
+	fs.BoolFunc("compressed", "Ask for a compressed response, decoded on receipt (accept-encoding=all)", func(string) error {
+		options[http.OptAcceptEncoding] = "all"
+		return nil
+	})
+	fs.Func("protocol", "HTTP version: auto, http/1.1, h2 or h2c (protocol)", options.setOption(http.OptProtocol))
+	fs.Func("redirects", "Follow redirects: follow, none or a maximum number of hops (redirects)", options.setOption(http.OptRedirects))
+	fs.Func("proto", ".proto files describing a gRPC service, instead of server reflection (grpc.proto)", options.setOption(http.OptGRPCProto))
+	fs.Var(options, "opt", "Request option (repeatable, format: 'key=value', e.g. tls.min=1.3)")
+}
+
 // ParseBenchFlags parses command-line flags for bench subcommand
 func ParseBenchFlags(args []string) (*BenchConfig, error) {
 	fs := flag.NewFlagSet("bench", flag.ExitOnError)
@@ -61,12 +111,14 @@ func ParseBenchFlags(args []string) (*BenchConfig, error) {
 	}
 
 	headers := make(headerFlags)
+	options := make(optionFlags)
 
 	// Target configuration
 	fs.StringVar(&config.URL, "url", "", "Target URL (required)")
 	fs.StringVar(&config.Method, "m", "GET", "HTTP method")
 	fs.StringVar(&config.Body, "b", "", "Request body")
 	fs.Var(headers, "H", "Custom header (repeatable, format: 'Key: Value')")
+	addOptionFlags(fs, options)
 
 	// Load parameters
 	fs.IntVar(&config.Concurrency, "c", 50, "Number of concurrent connections")
@@ -78,6 +130,8 @@ func ParseBenchFlags(args []string) (*BenchConfig, error) {
 	fs.IntVar(&config.RateLimit, "rate", 0, "Rate limit (requests/sec, 0 = unlimited)")
 	keepAlive := fs.Bool("keepalive", true, "Enable HTTP keep-alive")
 	noKeepAlive := fs.Bool("no-keepalive", false, "Disable HTTP keep-alive")
+	fs.BoolVar(&config.Cookies, "cookies", false, "Give every worker its own cookie jar")
+	fs.BoolVar(&config.GRPC, "grpc", false, "Call a gRPC method, -url grpc://host:port/pkg.Service/Method, -b the message as JSON")
 
 	// Output options
 	fs.BoolVar(&config.Quiet, "q", false, "Quiet mode (minimal output)")
@@ -101,6 +155,7 @@ func ParseBenchFlags(args []string) (*BenchConfig, error) {
 	}
 
 	config.Headers = headers
+	config.Options = options
 
 	return config, nil
 }
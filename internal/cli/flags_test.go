@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"reflect"
 	"testing"
 	"time"
 )
@@ -43,6 +44,48 @@ func TestParseBenchFlags(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "per-worker cookies",
+			args: []string{"-url", "http://example.com", "-cookies"},
+			check: func(t *testing.T, c *BenchConfig) {
+				if !c.Cookies {
+					t.Error("Cookies should be true")
+				}
+			},
+		},
+		{
+			name: "grpc with proto files",
+			args: []string{"-url", "grpc://localhost:50051/volt.test.Greeter/SayHello", "-grpc", "-proto", "greeter.proto"},
+			check: func(t *testing.T, c *BenchConfig) {
+				if !c.GRPC {
+					t.Error("GRPC should be true")
+				}
+				if c.Options["grpc.proto"] != "greeter.proto" {
+					t.Errorf("grpc.proto = %q, want greeter.proto", c.Options["grpc.proto"])
+				}
+			},
+		},
+		{
+			name: "tls and proxy options",
+			args: []string{
+				"-url", "https://example.com",
+				"-cacert", "ca.pem",
+				"-insecure",
+				"-opt", "tls.min=1.3",
+				"-proxy", "socks5://localhost:1080",
+			},
+			check: func(t *testing.T, c *BenchConfig) {
+				want := map[string]string{
+					"tls.ca":       "ca.pem",
+					"tls.insecure": "true",
+					"tls.min":      "1.3",
+					"proxy":        "socks5://localhost:1080",
+				}
+				if !reflect.DeepEqual(c.Options, want) {
+					t.Errorf("Options = %v, want %v", c.Options, want)
+				}
+			},
+		},
 		{
 			name: "duration parsing",
 			args: []string{"-url", "http://example.com", "-d", "5m"},
@@ -0,0 +1,257 @@
+package cli
+
+import (
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/storage"
+	"github.com/owenHochwald/volt/internal/utils"
+)
+
+// HistoryConfig holds parsed CLI arguments for the history subcommand
+type HistoryConfig struct {
+	Action string // list, show, clear or retention
+	Filter storage.HistoryFilter
+	JSON   bool
+	Args   []string
+
+	// Retention limits to set, empty to leave them as they are
+	MaxEntries string
+	MaxAge     string
+	MaxSize    string
+}
+
+// HistoryStore is the storage the history subcommand reads and writes
+type HistoryStore interface {
+	LoadHistory(filter storage.HistoryFilter) ([]storage.HistoryEntry, error)
+	GetHistory(id int64) (*storage.HistoryEntry, error)
+	ClearHistory() error
+	HistoryRetention() (storage.HistoryRetention, error)
+	SetHistoryRetention(retention storage.HistoryRetention) error
+}
+
+// ParseHistoryFlags parses the action and flags of the history subcommand
+func ParseHistoryFlags(args []string) (*HistoryConfig, error) {
+	config := &HistoryConfig{Action: "list"}
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		config.Action, args = args[0], args[1:]
+	}
+
+	var since, until, date string
+	fs := flag.NewFlagSet("history "+config.Action, flag.ExitOnError)
+	fs.StringVar(&config.Filter.Query, "q", "", "Part of the URL or name")
+	fs.StringVar(&config.Filter.Method, "m", "", "HTTP method")
+	fs.StringVar(&config.Filter.Status, "status", "", "Status code, class such as 4xx, or error")
+	fs.StringVar(&since, "since", "", "Sent on or after this day (YYYY-MM-DD)")
+	fs.StringVar(&until, "until", "", "Sent on or before this day (YYYY-MM-DD)")
+	fs.StringVar(&date, "date", "", "Sent on this day (YYYY-MM-DD)")
+	fs.IntVar(&config.Filter.Limit, "n", 50, "Most entries to list, 0 for all")
+	fs.BoolVar(&config.JSON, "json", false, "Output as JSON")
+	fs.StringVar(&config.MaxEntries, "max-entries", "", "Most entries kept (retention)")
+	fs.StringVar(&config.MaxAge, "max-age", "", "Oldest entries kept, e.g. 30d or 12h (retention)")
+	fs.StringVar(&config.MaxSize, "max-size", "", "Most space used, e.g. 50MB (retention)")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	config.Args = fs.Args()
+	config.Filter.Method = strings.ToUpper(config.Filter.Method)
+
+	// Dates share the syntax of the sidebar's history filter
+	var words []string
+	for _, word := range []struct{ key, value string }{{"since", since}, {"until", until}, {"date", date}} {
+		if word.value != "" {
+			words = append(words, word.key+":"+word.value)
+		}
+	}
+	filter, err := storage.ParseHistoryQuery(strings.Join(words, " "))
+	if err != nil {
+		return nil, err
+	}
+	config.Filter.Since, config.Filter.Until = filter.Since, filter.Until
+
+	return config, nil
+}
+
+// RunHistory lists, shows or clears the history of sent requests, or changes how much of it is kept
+func RunHistory(store HistoryStore, config *HistoryConfig, out io.Writer) error {
+	switch config.Action {
+	case "list":
+		entries, err := store.LoadHistory(config.Filter)
+		if err != nil {
+			return err
+		}
+		if config.JSON {
+			return writeJSON(out, entries)
+		}
+		if len(entries) == 0 {
+			fmt.Fprintln(out, "No history")
+			return nil
+		}
+		for _, entry := range entries {
+			fmt.Fprintf(out, "%-6d %s  %-7s %-5s %8s  %s\n", entry.ID, entry.SentAt.Local().Format("2006-01-02 15:04:05"),
+				entry.Request.Method, historyStatus(&entry), entry.Duration.Round(time.Millisecond), entry.Request.URL)
+		}
+		return nil
+
+	case "show":
+		if len(config.Args) != 1 {
+			return fmt.Errorf("usage: volt history show id")
+		}
+		id, err := strconv.ParseInt(config.Args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid history id: %s", config.Args[0])
+		}
+		entry, err := store.GetHistory(id)
+		if err != nil {
+			return err
+		}
+		entry.Request = *entry.Request.Redacted()
+		if config.JSON {
+			return writeJSON(out, entry)
+		}
+		printHistoryEntry(entry, out)
+		return nil
+
+	case "clear":
+		if err := store.ClearHistory(); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "History cleared")
+		return nil
+
+	case "retention":
+		retention, err := store.HistoryRetention()
+		if err != nil {
+			return err
+		}
+		if config.MaxEntries != "" || config.MaxAge != "" || config.MaxSize != "" {
+			if err := config.applyRetention(&retention); err != nil {
+				return err
+			}
+			if err := store.SetHistoryRetention(retention); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(out, "max entries: %s\nmax age:     %s\nmax size:    %s\n",
+			limitString(retention.MaxEntries, strconv.Itoa(retention.MaxEntries)),
+			limitString(int(retention.MaxAge), formatAge(retention.MaxAge)),
+			limitString(int(retention.MaxSize), utils.FormatSize(int(retention.MaxSize))))
+		return nil
+
+	default:
+		return fmt.Errorf("unknown history action: %s (use list, show, clear or retention)", config.Action)
+	}
+}
+
+// applyRetention sets the retention limits given as flags, 0 removing a limit
+func (c *HistoryConfig) applyRetention(retention *storage.HistoryRetention) error {
+	if c.MaxEntries != "" {
+		n, err := strconv.Atoi(c.MaxEntries)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid -max-entries %q", c.MaxEntries)
+		}
+		retention.MaxEntries = n
+	}
+	if c.MaxAge != "" {
+		age, err := parseAge(c.MaxAge)
+		if err != nil {
+			return err
+		}
+		retention.MaxAge = age
+	}
+	if c.MaxSize != "" {
+		size, err := http.ParseSize(c.MaxSize)
+		if err != nil {
+			return fmt.Errorf("invalid -max-size: %w", err)
+		}
+		retention.MaxSize = size
+	}
+	return nil
+}
+
+// parseAge reads a duration, which may also be in days such as 30d
+func parseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n >= 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid -max-age %q, expected e.g. 30d or 12h", value)
+	}
+	return age, nil
+}
+
+// formatAge writes whole days as such
+func formatAge(age time.Duration) string {
+	if age%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", age/(24*time.Hour))
+	}
+	return age.String()
+}
+
+func limitString(limit int, formatted string) string {
+	if limit == 0 {
+		return "no limit"
+	}
+	return formatted
+}
+
+// historyStatus is the status code of an entry, or ERR when it got no response
+func historyStatus(entry *storage.HistoryEntry) string {
+	if entry.StatusCode == 0 {
+		return "ERR"
+	}
+	return strconv.Itoa(entry.StatusCode)
+}
+
+// printHistoryEntry writes a request and its response as recorded
+func printHistoryEntry(entry *storage.HistoryEntry, out io.Writer) {
+	fmt.Fprintf(out, "%s %s\n", entry.Request.Method, entry.Request.URL)
+	fmt.Fprintf(out, "Sent %s, took %s\n", entry.SentAt.Local().Format("2006-01-02 15:04:05"), entry.Duration.Round(time.Millisecond))
+	for _, key := range slices.Sorted(maps.Keys(entry.Request.Headers)) {
+		fmt.Fprintf(out, "%s: %s\n", key, entry.Request.Headers[key])
+	}
+	if entry.Request.Body != "" {
+		fmt.Fprintf(out, "\n%s\n", entry.Request.Body)
+	}
+
+	fmt.Fprintln(out)
+	if entry.Error != "" {
+		fmt.Fprintf(out, "Error: %s\n", entry.Error)
+		return
+	}
+	fmt.Fprintln(out, cmp.Or(entry.Status, strconv.Itoa(entry.StatusCode)))
+	for _, key := range slices.Sorted(maps.Keys(entry.Headers)) {
+		for _, value := range entry.Headers[key] {
+			fmt.Fprintf(out, "%s: %s\n", key, value)
+		}
+	}
+	if entry.Body != "" {
+		fmt.Fprintf(out, "\n%s\n", entry.Body)
+	}
+	if entry.Truncated() {
+		fmt.Fprintf(out, "(first %s of %s kept)\n", utils.FormatSize(len(entry.Body)), utils.FormatSize(int(entry.BodySize)))
+	}
+}
+
+func writeJSON(out io.Writer, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
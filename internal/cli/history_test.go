@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/storage"
+)
+
+func TestRunHistory(t *testing.T) {
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "volt.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	run := func(args ...string) (string, error) {
+		config, err := ParseHistoryFlags(args)
+		if err != nil {
+			return "", err
+		}
+		var out bytes.Buffer
+		err = RunHistory(store, config, &out)
+		return out.String(), err
+	}
+
+	sentAt := time.Now().Add(-time.Hour)
+	for _, entry := range []*storage.HistoryEntry{
+		{SentAt: sentAt, Request: http.Request{Method: "GET", URL: "https://api.example/users"}, StatusCode: 200, Status: "200 OK", Body: `{"id":1}`, BodySize: 100},
+		{SentAt: sentAt.Add(time.Minute), Request: http.Request{Method: "POST", URL: "https://api.example/orders"}, StatusCode: 503},
+		{SentAt: sentAt.Add(2 * time.Minute), Request: http.Request{Method: "GET", URL: "https://down.example"}, Error: "connection refused"},
+	} {
+		if err := store.AddHistory(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := run()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 3 || !strings.Contains(lines[0], "ERR") {
+		t.Errorf("expected 3 entries, newest first, got:\n%s", out)
+	}
+
+	out, err = run("-m", "post", "-status", "5xx")
+	if err != nil {
+		t.Fatalf("filtered list: %v", err)
+	}
+	if !strings.Contains(out, "/orders") || strings.Contains(out, "/users") {
+		t.Errorf("expected only the failed POST, got:\n%s", out)
+	}
+	if out, _ := run("-date", sentAt.AddDate(0, 0, -1).Format(time.DateOnly)); !strings.Contains(out, "No history") {
+		t.Errorf("expected nothing sent the day before, got:\n%s", out)
+	}
+	if _, err := run("-since", "yesterday"); err == nil {
+		t.Error("expected invalid date to be rejected")
+	}
+
+	out, err = run("-q", "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := strings.Fields(out)[0]
+	out, err = run("show", id)
+	if err != nil {
+		t.Fatalf("show: %v", err)
+	}
+	if !strings.Contains(out, "200 OK") || !strings.Contains(out, `{"id":1}`) || !strings.Contains(out, "of 100 B kept") {
+		t.Errorf("unexpected show output:\n%s", out)
+	}
+	if _, err := run("show", "999"); err == nil {
+		t.Error("expected missing entry to be an error")
+	}
+
+	out, err = run("retention", "-max-entries", "2", "-max-age", "7d", "-max-size", "0")
+	if err != nil {
+		t.Fatalf("retention: %v", err)
+	}
+	want := "max entries: 2\nmax age:     7d\nmax size:    no limit\n"
+	if out != want {
+		t.Errorf("unexpected retention output:\n%s", out)
+	}
+	if _, err := run("retention", "-max-age", "soon"); err == nil {
+		t.Error("expected invalid age to be rejected")
+	}
+
+	if _, err := run("clear"); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if out, _ := run(); !strings.Contains(out, "No history") {
+		t.Errorf("expected no history left, got:\n%s", out)
+	}
+}
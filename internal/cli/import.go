@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/owenHochwald/volt/internal/importer"
+	"github.com/owenHochwald/volt/internal/storage"
+)
+
+// ImportConfig holds parsed CLI arguments for the import subcommand
+type ImportConfig struct {
+	Environment string
+	File        string
+}
+
+// ParseImportFlags parses the flags and file of the import subcommand
+func ParseImportFlags(args []string) (*ImportConfig, error) {
+	config := &ImportConfig{}
+
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	fs.StringVar(&config.Environment, "e", os.Getenv("VOLT_ENV"), "Environment the variables go to (default: $VOLT_ENV or default)")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != 1 {
+		return nil, fmt.Errorf("usage: volt import [-e name] file")
+	}
+	if config.Environment == "" {
+		config.Environment = storage.DefaultEnvironment
+	}
+	config.File = fs.Arg(0)
+
+	return config, nil
+}
+
+// RunImport imports a Postman or Insomnia export, an OpenAPI description, a
+// HAR file or a curl command, listing what couldn't be converted
+func RunImport(store storage.Storage, config *ImportConfig, out io.Writer) error {
+	result, summary, err := importer.ImportFile(store, config.File, config.Environment)
+	if result != nil {
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(out, "warning: %s\n", warning)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%s (%s)\n", summary, result.Format)
+	if result.Variables != nil {
+		fmt.Fprintf(out, "Variables were added to environment %s, see volt env show -e %s\n", config.Environment, config.Environment)
+	}
+	return nil
+}
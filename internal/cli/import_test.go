@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/owenHochwald/volt/internal/storage"
+)
+
+func TestRunImport(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewSQLiteStorage(filepath.Join(dir, "volt.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	archive := filepath.Join(dir, "checkout.har")
+	data := `{"log": {"entries": [
+		{"request": {"method": "GET", "url": "https://shop.example/cart"}},
+		{"request": {"method": "HEAD", "url": "https://shop.example/cart"}}
+	]}}`
+	if err := os.WriteFile(archive, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := ParseImportFlags([]string{"-e", "staging", archive})
+	if err != nil {
+		t.Fatalf("ParseImportFlags() error = %v", err)
+	}
+	var out bytes.Buffer
+	if err := RunImport(store, config, &out); err != nil {
+		t.Fatalf("RunImport() error = %v", err)
+	}
+	want := "warning: skipped 1 HEAD requests\nImported 1 collection, 1 request and 0 variables (har)\n"
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	collections, err := store.LoadCollections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collections) != 1 || collections[0].Name != "checkout" {
+		t.Errorf("expected a collection named after the file, got %+v", collections)
+	}
+
+	if _, err := ParseImportFlags(nil); err == nil {
+		t.Error("expected a missing file to be rejected")
+	}
+	config.File = filepath.Join(dir, "notes.txt")
+	os.WriteFile(config.File, []byte("hello"), 0o600)
+	if err := RunImport(store, config, &out); err == nil || !strings.Contains(err.Error(), "unrecognised") {
+		t.Errorf("expected an unrecognised file to be rejected, got %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/utils"
 )
 
 // FormatOutput writes results in requested format
@@ -54,7 +55,8 @@ func formatTable(stats *http.LoadTestStats) string {
 	// Calculate data transfer rate
 	totalBytes := stats.BytesSent + stats.BytesRecv
 	dataSec := float64(totalBytes) / duration.Seconds() / (1024 * 1024) // MB/s
-	out.WriteString(fmt.Sprintf("  Data/sec:     %.2f MB\n\n", dataSec))
+	out.WriteString(fmt.Sprintf("  Data/sec:     %.2f MB\n", dataSec))
+	out.WriteString(fmt.Sprintf("  Received:     %s\n\n", formatBytesReceived(stats)))
 
 	out.WriteString("Latency:\n")
 	out.WriteString(fmt.Sprintf("  Min:          %s\n", formatDuration(stats.MinDuration)))
@@ -98,6 +100,10 @@ func formatJSON(stats *http.LoadTestStats) string {
 			"p99Ms": stats.Percentiles.Percentile(99).Milliseconds(),
 			"maxMs": stats.MaxDuration.Milliseconds(),
 		},
+		"transfer": map[string]interface{}{
+			"bytesReceived": stats.BytesRecv,
+			"bytesDecoded":  stats.BytesDecoded,
+		},
 		"errors": stats.Errors,
 	}
 
@@ -116,6 +122,15 @@ func formatQuiet(stats *http.LoadTestStats) string {
 		stats.CompletedRequests, rps, formatDuration(p50), formatDuration(p99), stats.FailedRequests)
 }
 
+// formatBytesReceived shows the body bytes received, and their decoded size when compressed
+func formatBytesReceived(stats *http.LoadTestStats) string {
+	received := utils.FormatSize(int(stats.BytesRecv))
+	if stats.BytesDecoded == stats.BytesRecv {
+		return received
+	}
+	return fmt.Sprintf("%s (~%s decoded)", received, utils.FormatSize(int(stats.BytesDecoded)))
+}
+
 func formatNumber(n int) string {
 	// Add thousand separators
 	s := fmt.Sprintf("%d", n)
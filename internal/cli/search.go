@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/owenHochwald/volt/internal/storage"
+)
+
+// SearchConfig holds parsed CLI arguments for the search subcommand
+type SearchConfig struct {
+	Query storage.SearchQuery
+	JSON  bool
+	Color bool // matches are shown in bold
+}
+
+// SearchStore is the storage the search subcommand reads
+type SearchStore interface {
+	Search(query storage.SearchQuery) ([]storage.SearchResult, error)
+}
+
+// ParseSearchFlags parses the words and flags of the search subcommand
+func ParseSearchFlags(args []string) (*SearchConfig, error) {
+	config := &SearchConfig{Color: isTerminal(os.Stdout)}
+
+	// words may come before the flags too
+	var words []string
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		words, args = append(words, args[0]), args[1:]
+	}
+
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	fs.BoolVar(&config.Query.History, "history", false, "Also search sent requests and their responses")
+	fs.IntVar(&config.Query.Limit, "n", 20, "Most results to list, 0 for all")
+	fs.BoolVar(&config.JSON, "json", false, "Output as JSON")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	config.Query.Text = strings.Join(append(words, fs.Args()...), " ")
+	if strings.TrimSpace(config.Query.Text) == "" {
+		return nil, fmt.Errorf("usage: volt search [-history] [-n limit] [-json] words")
+	}
+
+	return config, nil
+}
+
+// RunSearch lists the saved requests, and sent ones when asked, matching the
+// words searched for, the best matches first
+func RunSearch(store SearchStore, config *SearchConfig, out io.Writer) error {
+	results, err := store.Search(config.Query)
+	if err != nil {
+		return err
+	}
+	if config.JSON {
+		for i := range results {
+			results[i].Name = storage.Highlight(results[i].Name, plainMatch)
+			results[i].URL = storage.Highlight(results[i].URL, plainMatch)
+			results[i].Snippet = storage.Highlight(results[i].Snippet, plainMatch)
+		}
+		return writeJSON(out, results)
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(out, "No matches")
+		return nil
+	}
+
+	mark := plainMatch
+	if config.Color {
+		mark = boldMatch
+	}
+	for _, result := range results {
+		name := storage.Highlight(result.Name, mark)
+		if result.Kind == storage.SearchHistory {
+			// when it was sent matters more than the name it had
+			status := historyStatus(&storage.HistoryEntry{StatusCode: result.StatusCode})
+			name = strings.TrimSpace(fmt.Sprintf("%s %s %s", result.SentAt.Local().Format("2006-01-02 15:04:05"), status, name))
+		}
+		fmt.Fprintf(out, "%-7s %-6d %-7s %s  %s\n", result.Kind, result.ID, result.Method, name, storage.Highlight(result.URL, mark))
+		if result.Snippet != "" {
+			fmt.Fprintf(out, "        %s\n", strings.Join(strings.Fields(storage.Highlight(result.Snippet, mark)), " "))
+		}
+	}
+	return nil
+}
+
+func plainMatch(match string) string {
+	return match
+}
+
+func boldMatch(match string) string {
+	return "\033[1m" + match + "\033[0m"
+}
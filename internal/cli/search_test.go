@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/storage"
+)
+
+func TestRunSearch(t *testing.T) {
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "volt.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	run := func(args ...string) (string, error) {
+		config, err := ParseSearchFlags(args)
+		if err != nil {
+			return "", err
+		}
+		var out bytes.Buffer
+		err = RunSearch(store, config, &out)
+		return out.String(), err
+	}
+
+	for _, request := range []*http.Request{
+		{Name: "List users", Method: http.GET, URL: "https://api.example/users"},
+		{Name: "Create order", Method: http.POST, URL: "https://api.example/orders", Body: `{"user": 1}`},
+	} {
+		if err := store.Save(request); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entry := &storage.HistoryEntry{SentAt: time.Now(), Request: http.Request{Method: http.GET, URL: "https://api.example/users/1"},
+		StatusCode: 200, Body: `{"name": "Ada Lovelace"}`}
+	if err := store.AddHistory(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := run("user")
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "List users") || !strings.Contains(lines[2], `{"user": 1}`) {
+		t.Errorf("expected the name match first, then the body match with its snippet, got:\n%s", out)
+	}
+
+	if out, _ := run("lovelace"); !strings.Contains(out, "No matches") {
+		t.Errorf("expected the history left out, got:\n%s", out)
+	}
+	out, err = run("lovelace", "-history")
+	if err != nil {
+		t.Fatalf("history search: %v", err)
+	}
+	if !strings.HasPrefix(out, "history") || !strings.Contains(out, " 200 ") {
+		t.Errorf("expected the history entry, got:\n%s", out)
+	}
+
+	// JSON leaves the matches unmarked
+	out, err = run("-json", "-n", "1", "user")
+	if err != nil {
+		t.Fatalf("json search: %v", err)
+	}
+	var results []storage.SearchResult
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(results) != 1 || results[0].Name != "List users" {
+		t.Errorf("expected the best result unmarked, got %+v", results)
+	}
+
+	config, err := ParseSearchFlags([]string{"user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Color = true
+	var colored bytes.Buffer
+	if err := RunSearch(store, config, &colored); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(colored.String(), "List \033[1musers\033[0m") {
+		t.Errorf("expected the match in bold, got:\n%s", colored.String())
+	}
+
+	if _, err := ParseSearchFlags([]string{"-history"}); err == nil {
+		t.Error("expected an error without words to search for")
+	}
+}
@@ -0,0 +1,284 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/utils"
+)
+
+// SendConfig holds parsed CLI flags for sending a single request
+type SendConfig struct {
+	URL     string
+	Method  string
+	Body    string
+	Headers map[string]string
+	Options map[string]string
+	Timeout time.Duration
+
+	// Output options
+	Include bool   // print response headers
+	Timing  bool   // print the timing breakdown
+	JSON    bool   // print the whole response as JSON
+	Output  string // write the body to this file instead of stdout
+}
+
+// ParseSendFlags parses command-line flags for the send subcommand
+func ParseSendFlags(args []string) (*SendConfig, error) {
+	fs := flag.NewFlagSet("send", flag.ExitOnError)
+
+	config := &SendConfig{}
+	headers := make(headerFlags)
+	options := make(optionFlags)
+
+	fs.StringVar(&config.URL, "url", "", "Target URL (required)")
+	fs.StringVar(&config.Method, "m", "GET", "HTTP method")
+	fs.StringVar(&config.Body, "b", "", "Request body")
+	fs.Var(headers, "H", "Custom header (repeatable, format: 'Key: Value')")
+	fs.DurationVar(&config.Timeout, "t", http.TIMEOUT, "Request timeout")
+	addOptionFlags(fs, options)
+	fs.Func("retries", "Retry failed attempts up to this many times (retries)", options.setOption(http.OptRetries))
+	fs.Func("connect-timeout", "Time to establish a connection, e.g. 2s (timeout.connect)", options.setOption(http.OptTimeoutConnect))
+
+	fs.BoolVar(&config.Include, "i", false, "Include response headers in the output")
+	fs.BoolVar(&config.Timing, "timing", true, "Show the DNS/connect/TLS/TTFB/transfer breakdown")
+	fs.BoolVar(&config.JSON, "json", false, "Output the response as JSON")
+	fs.StringVar(&config.Output, "o", "", "Write the body to file instead of stdout")
+	fs.Func("max-body", "Most of the body kept in memory, e.g. 10MB (body.limit)", options.setOption(http.OptBodyLimit))
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// Allow the URL as a positional argument: volt send https://example.com
+	if config.URL == "" && fs.NArg() > 0 {
+		config.URL = fs.Arg(0)
+	}
+
+	config.Method = strings.ToUpper(config.Method)
+	config.Headers = headers
+	config.Options = options
+	return config, nil
+}
+
+// RunSend sends one request. The body goes to stdout, the status line,
+// headers and timing go to stderr so the body can be piped. Streamed
+// responses are printed as they arrive, until the server or Ctrl+C ends them.
+func RunSend(config *SendConfig) error {
+	if config.URL == "" {
+		return errors.New("--url is required")
+	}
+
+	req := &http.Request{
+		Method:  config.Method,
+		URL:     config.URL,
+		Headers: config.Headers,
+		Body:    config.Body,
+		Options: config.Options,
+	}
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	if insecure, _ := strconv.ParseBool(config.Options[http.OptTLSInsecure]); insecure {
+		fmt.Fprintln(os.Stderr, "Warning: TLS certificate verification is disabled")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := http.InitClient(config.Timeout, true)
+	updates := make(chan http.StreamUpdate, 64)
+	go client.Stream(ctx, req, updates)
+
+	var res *http.Response
+	progress := isTerminal(os.Stderr) && !config.JSON
+	for update := range updates {
+		switch {
+		case update.Done:
+			res = update.Response
+		case update.Progress != nil:
+			if progress {
+				fmt.Fprintf(os.Stderr, "\r\033[K%s", utils.FormatProgress(update.Progress.Received, update.Progress.Total))
+			}
+		case config.JSON || update.Response != nil:
+			// JSON is written once the response is complete
+		case update.Event != nil:
+			fmt.Fprint(os.Stdout, formatEvent(*update.Event))
+		default:
+			io.WriteString(os.Stdout, update.Chunk)
+		}
+	}
+	if progress {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+	defer res.Close()
+
+	if res.Error != "" {
+		if !config.JSON {
+			fmt.Fprint(os.Stderr, formatAttempts(res))
+		}
+		return errors.New(res.Error)
+	}
+
+	if config.JSON {
+		res.Close() // the file of a large body doesn't outlive the command, size tells it was cut
+		data, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Fprint(os.Stderr, FormatSendSummary(res, config))
+	if config.Output != "" {
+		if err := res.SaveBody(config.Output); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Saved %s to %s\n", utils.FormatSize(int(res.BodySize())), config.Output)
+		return nil
+	}
+	if res.Streamed {
+		return nil // already written
+	}
+	return res.WriteBody(os.Stdout)
+}
+
+// isTerminal reports whether f is a terminal rather than a pipe or a file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// formatEvent renders a Server-Sent Event with the time it arrived
+func formatEvent(event http.StreamEvent) string {
+	header := fmt.Sprintf("[%s] %s", event.Time.Format("15:04:05.000"), event.Type())
+	if event.ID != "" {
+		header += " id=" + event.ID
+	}
+	return header + "\n" + event.Data + "\n\n"
+}
+
+// FormatSendSummary renders the status line, headers and timing breakdown
+func FormatSendSummary(res *http.Response, config *SendConfig) string {
+	var out strings.Builder
+
+	out.WriteString(formatAttempts(res))
+	for _, hop := range res.Redirects {
+		out.WriteString(fmt.Sprintf("%s  %s -> %s  %s\n", hop.Status, hop.Method, hop.Location, formatDuration(hop.Duration)))
+	}
+	out.WriteString(fmt.Sprintf("%s %s  %s\n", res.Proto, res.Status, formatDuration(res.Duration)))
+	if len(res.Events) > 0 {
+		kind := "events"
+		if res.GRPC {
+			kind = "messages"
+		}
+		out.WriteString(fmt.Sprintf("%d %s received\n", len(res.Events), kind))
+	}
+
+	if config.Include {
+		writeHeaders(&out, res.Headers)
+		if len(res.Trailers) > 0 {
+			out.WriteString("\nTrailers:\n")
+			writeHeaders(&out, res.Trailers)
+		}
+	}
+
+	if res.TLS != nil {
+		out.WriteString(fmt.Sprintf("\nTLS:      %s, %s\n", res.TLS.Version, res.TLS.CipherSuite))
+		if len(res.TLS.Chain) > 0 {
+			leaf := res.TLS.Chain[0]
+			out.WriteString(fmt.Sprintf("  Subject: %s\n", leaf.Subject))
+			out.WriteString(fmt.Sprintf("  Expires: %s\n", leaf.NotAfter.Format(time.DateOnly)))
+		}
+	}
+
+	if encoding := res.ContentEncoding(); encoding != "" {
+		out.WriteString(fmt.Sprintf("\nBody:     %s, %s decoded from %s\n",
+			encoding, utils.FormatSize(int(res.BodySize())), utils.FormatSize(res.WireSize)))
+	}
+
+	if config.Timing && res.Timing != nil {
+		out.WriteString("\nTiming:\n")
+		out.WriteString(formatWaterfall(res.Timing, 30))
+
+		connection := "new connection"
+		if res.Timing.ConnReused {
+			connection = "reused connection"
+		}
+		out.WriteString(fmt.Sprintf("  Remote:            %s (%s)\n", res.Timing.RemoteAddr, connection))
+	}
+
+	out.WriteString("\n")
+	return out.String()
+}
+
+// formatAttempts renders the attempts that were retried, one per line
+func formatAttempts(res *http.Response) string {
+	var out strings.Builder
+	for _, attempt := range res.Attempts {
+		outcome := attempt.Status
+		if attempt.Error != "" {
+			outcome = attempt.Error
+		}
+		wait := "retrying in " + formatDuration(attempt.Delay)
+		if attempt.Delay == 0 {
+			wait = "retrying now"
+		}
+		if attempt.RetryAfter {
+			wait += " (Retry-After)"
+		}
+		out.WriteString(fmt.Sprintf("%s  %s  %s\n", outcome, formatDuration(attempt.Duration), wait))
+	}
+	return out.String()
+}
+
+// writeHeaders writes headers one per line, sorted by key
+func writeHeaders(out *strings.Builder, headers nethttp.Header) {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range headers[key] {
+			out.WriteString(fmt.Sprintf("%s: %s\n", key, value))
+		}
+	}
+}
+
+// formatWaterfall draws a plain text bar per request phase
+func formatWaterfall(timing *http.Timing, barWidth int) string {
+	var out strings.Builder
+	total := timing.Total
+
+	for _, phase := range timing.Phases() {
+		offset, length := 0, 0
+		if total > 0 {
+			offset = int(float64(phase.Start) / float64(total) * float64(barWidth))
+			length = int(float64(phase.Duration) / float64(total) * float64(barWidth))
+		}
+		if phase.Duration > 0 && length == 0 {
+			length = 1
+		}
+		offset = max(min(offset, barWidth-length), 0)
+
+		bar := strings.Repeat(" ", offset) + strings.Repeat("#", length) +
+			strings.Repeat(" ", max(barWidth-offset-length, 0))
+		out.WriteString(fmt.Sprintf("  %-18s |%s| %s\n", phase.Name, bar, formatDuration(phase.Duration)))
+	}
+	out.WriteString(fmt.Sprintf("  %-18s  %s\n", "Total", formatDuration(total)))
+	return out.String()
+}
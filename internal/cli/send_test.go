@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	volthttp "github.com/owenHochwald/volt/internal/http"
+)
+
+func TestParseSendFlags(t *testing.T) {
+	config, err := ParseSendFlags([]string{"-m", "post", "-H", "Accept: text/plain", "-i", "http://example.com"})
+	if err != nil {
+		t.Fatalf("ParseSendFlags() error = %v", err)
+	}
+
+	if config.URL != "http://example.com" {
+		t.Errorf("URL = %s, want positional http://example.com", config.URL)
+	}
+	if config.Method != "POST" {
+		t.Errorf("Method = %s, want POST", config.Method)
+	}
+	if config.Headers["Accept"] != "text/plain" {
+		t.Errorf("Accept header not parsed: %v", config.Headers)
+	}
+	if !config.Include || !config.Timing {
+		t.Errorf("Include and Timing should be true")
+	}
+}
+
+func TestFormatSendSummary(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Test", "yes")
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := volthttp.InitClient(time.Second, true)
+	result := make(chan *volthttp.Response)
+	go client.Send(&volthttp.Request{Method: volthttp.GET, URL: server.URL}, result)
+	res := <-result
+
+	if res.Error != "" {
+		t.Fatalf("unexpected error: %s", res.Error)
+	}
+
+	summary := FormatSendSummary(res, &SendConfig{Include: true, Timing: true})
+	for _, want := range []string{"HTTP/1.1 200 OK", "X-Test: yes", "TCP Connect", "Waiting (TTFB)", "Content Transfer", "Remote:"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary missing %q:\n%s", want, summary)
+		}
+	}
+}
+
+func TestFormatAttempts(t *testing.T) {
+	res := &volthttp.Response{
+		Status: "200 OK",
+		Attempts: []volthttp.Attempt{
+			{Status: "503 Service Unavailable", Duration: 2 * time.Millisecond, Delay: time.Second, RetryAfter: true},
+			{Error: "connection refused", Delay: 0},
+		},
+	}
+
+	lines := strings.Split(strings.TrimSpace(formatAttempts(res)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want one per attempt:\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+	if !strings.HasPrefix(lines[0], "503 Service Unavailable") || !strings.Contains(lines[0], "retrying in 1.00s (Retry-After)") {
+		t.Errorf("first attempt = %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "connection refused") || !strings.Contains(lines[1], "retrying now") {
+		t.Errorf("second attempt = %q", lines[1])
+	}
+}
@@ -3,6 +3,8 @@ package cli
 import (
 	"errors"
 	"strings"
+
+	"github.com/owenHochwald/volt/internal/http"
 )
 
 // Validate checks BenchConfig for errors
@@ -12,18 +14,32 @@ func (c *BenchConfig) Validate() error {
 		return errors.New("--url is required")
 	}
 
-	// URL must start with http:// or https://
-	if !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
-		return errors.New("URL must start with http:// or https://")
-	}
+	if c.GRPC {
+		// The URL names the method, so -m doesn't apply
+		target, err := http.ParseGRPCTarget(c.URL)
+		if err != nil {
+			return err
+		}
+		if target.Method == "" {
+			return errors.New("URL must name a method, e.g. grpc://localhost:50051/pkg.Service/Method")
+		}
+	} else {
+		// URL must start with http:// or https://
+		if !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
+			if strings.HasPrefix(c.URL, "grpc") {
+				return errors.New("gRPC URLs need -grpc")
+			}
+			return errors.New("URL must start with http:// or https://")
+		}
 
-	// Method must be valid
-	validMethods := map[string]bool{
-		"GET": true, "POST": true, "PUT": true,
-		"DELETE": true, "PATCH": true, "HEAD": true,
-	}
-	if !validMethods[strings.ToUpper(c.Method)] {
-		return errors.New("invalid HTTP method")
+		// Method must be valid
+		validMethods := map[string]bool{
+			"GET": true, "POST": true, "PUT": true,
+			"DELETE": true, "PATCH": true, "HEAD": true,
+		}
+		if !validMethods[strings.ToUpper(c.Method)] {
+			return errors.New("invalid HTTP method")
+		}
 	}
 
 	// Concurrency must be positive
@@ -49,5 +65,9 @@ func (c *BenchConfig) Validate() error {
 		return errors.New("rate limit must be >= 0")
 	}
 
+	if err := http.ValidateOptions(c.Options); err != nil {
+		return err
+	}
+
 	return nil
 }
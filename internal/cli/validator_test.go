@@ -134,6 +134,39 @@ func TestBenchConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "grpc method",
+			config: &BenchConfig{
+				URL:         "grpc://localhost:50051/volt.test.Greeter/SayHello",
+				GRPC:        true,
+				Concurrency: 10,
+				Duration:    10 * time.Second,
+				Timeout:     30 * time.Second,
+			},
+			wantErr: false,
+		},
+		{
+			name: "grpc url without a method",
+			config: &BenchConfig{
+				URL:         "grpc://localhost:50051",
+				GRPC:        true,
+				Concurrency: 10,
+				Duration:    10 * time.Second,
+				Timeout:     30 * time.Second,
+			},
+			wantErr: true,
+		},
+		{
+			name: "grpc url without -grpc",
+			config: &BenchConfig{
+				URL:         "grpc://localhost:50051/volt.test.Greeter/SayHello",
+				Method:      "GET",
+				Concurrency: 10,
+				Duration:    10 * time.Second,
+				Timeout:     30 * time.Second,
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,278 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// defaultBodyLimit is the most of a body kept in memory, the rest is kept on disk
+const defaultBodyLimit = 10 << 20
+
+// progressInterval is how often download progress is reported
+const progressInterval = 100 * time.Millisecond
+
+// binarySniffLen is how much of a body is looked at to tell binary from text
+const binarySniffLen = 8 << 10
+
+// Progress is how much of a body has been received
+type Progress struct {
+	Received int64 // bytes received so far, before decoding
+	Total    int64 // Content-Length, -1 when the server didn't send it
+}
+
+// ParseBodyLimit reads the body.limit option
+func ParseBodyLimit(options map[string]string) (int64, error) {
+	value := options[OptBodyLimit]
+	if value == "" {
+		return defaultBodyLimit, nil
+	}
+	limit, err := ParseSize(value)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("%s: expected a size such as 512KB or 10MB, got %q", OptBodyLimit, value)
+	}
+	return limit, nil
+}
+
+// ParseSize reads a size such as 512KB, 10MB or 1GB. Units are powers of
+// 1024, and a plain number is in bytes.
+func ParseSize(value string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for i, unit := range []string{"KB", "MB", "GB"} {
+		if trimmed, ok := strings.CutSuffix(s, unit); ok {
+			s, multiplier = trimmed, 1<<(10*(i+1))
+			break
+		}
+	}
+	s = strings.TrimSpace(strings.TrimSuffix(s, "B"))
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 || n > (1<<62)/multiplier {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return n * multiplier, nil
+}
+
+// spillBuffer keeps a body in memory up to limit bytes. Past that, the whole
+// body is written to a temporary file and only its first limit bytes are kept.
+type spillBuffer struct {
+	limit int64
+	head  []byte
+	file  *os.File
+	size  int64
+}
+
+func (b *spillBuffer) Write(p []byte) (int, error) {
+	b.size += int64(len(p))
+	if b.file == nil && int64(len(b.head)+len(p)) > b.limit {
+		file, err := os.CreateTemp("", "volt-body-*")
+		if err != nil {
+			return 0, err
+		}
+		b.file = file
+		if _, err := file.Write(b.head); err != nil {
+			return 0, err
+		}
+	}
+	if b.file == nil {
+		b.head = append(b.head, p...)
+		return len(p), nil
+	}
+
+	if room := b.limit - int64(len(b.head)); room > 0 {
+		b.head = append(b.head, p[:min(room, int64(len(p)))]...)
+	}
+	return b.file.Write(p)
+}
+
+// close closes the file the body spilled to, removing it when the body couldn't be read
+func (b *spillBuffer) close(failed bool) (string, error) {
+	if b.file == nil {
+		return "", nil
+	}
+	err := b.file.Close()
+	if failed || err != nil {
+		os.Remove(b.file.Name())
+		return "", err
+	}
+	return b.file.Name(), nil
+}
+
+// cappedBuffer keeps what is written to it, unless that grows past limit
+type cappedBuffer struct {
+	limit int64
+	data  []byte
+	over  bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if !b.over {
+		if int64(len(b.data)+len(p)) > b.limit {
+			b.data, b.over = nil, true
+		} else {
+			b.data = append(b.data, p...)
+		}
+	}
+	return len(p), nil
+}
+
+// progressReader reports how much was read through it, at most once per
+// progressInterval, so bodies read faster than that are never reported
+type progressReader struct {
+	r        io.Reader
+	total    int64
+	received int64
+	last     time.Time
+	report   func(Progress)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.received += int64(n)
+	if now := time.Now(); now.Sub(p.last) >= progressInterval {
+		p.last = now
+		p.report(Progress{Received: p.received, Total: p.total})
+	}
+	return n, err
+}
+
+// BodySize returns the size of the decoded body, which is more than
+// len(Body) when the body was too large to keep in memory
+func (r *Response) BodySize() int64 {
+	return max(r.Size, int64(len(r.Body)))
+}
+
+// Truncated reports whether Body only has the start of the body, the whole
+// of it being in BodyFile until the response is closed
+func (r *Response) Truncated() bool {
+	return r.Size > int64(len(r.Body))
+}
+
+// Binary reports whether the body isn't text, going by its Content-Type
+// and, when that is missing or generic, by its first bytes
+func (r *Response) Binary() bool {
+	if r.GRPC || r.Body == "" {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.ParseContentType())
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "json"), strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "xml"), strings.HasSuffix(mediaType, "javascript"),
+		mediaType == "application/x-www-form-urlencoded", mediaType == "application/graphql":
+		return false
+	case strings.HasPrefix(mediaType, "image/"), strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"), strings.HasPrefix(mediaType, "font/"):
+		return true
+	}
+	return isBinary(r.Body[:min(len(r.Body), binarySniffLen)])
+}
+
+// isBinary reports whether a sample of a body looks binary: it has NUL
+// bytes or isn't UTF-8, ignoring a rune cut in half at its end
+func isBinary(sample string) bool {
+	if strings.IndexByte(sample, 0) >= 0 {
+		return true
+	}
+	for i := 1; i < utf8.UTFMax && i <= len(sample); i++ {
+		if tail := sample[len(sample)-i:]; utf8.RuneStart(tail[0]) {
+			if !utf8.FullRuneInString(tail) {
+				sample = sample[:len(sample)-i]
+			}
+			break
+		}
+	}
+	return !utf8.ValidString(sample)
+}
+
+// DetectedType returns the type of the body sniffed from its first bytes,
+// for bodies whose Content-Type is missing or wrong
+func (r *Response) DetectedType() string {
+	return http.DetectContentType([]byte(r.Body[:min(len(r.Body), 512)]))
+}
+
+// Filename suggests a name to save the body under: the one given by
+// Content-Disposition, or the last segment of the URL ("response" when
+// there is none) with an extension matching the Content-Type
+func (r *Response) Filename() string {
+	if _, params, err := mime.ParseMediaType(r.Headers.Get("Content-Disposition")); err == nil {
+		if name := path.Base(strings.ReplaceAll(params["filename"], `\`, "/")); name != "." && name != "/" {
+			return name
+		}
+	}
+
+	name := "response"
+	if u, err := url.Parse(r.URL); err == nil {
+		if base := path.Base(u.Path); base != "." && base != "/" {
+			name = base
+		}
+	}
+	if path.Ext(name) != "" {
+		return name
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.ParseContentType())
+	switch {
+	case r.GRPC, mediaType == "application/json":
+		return name + ".json"
+	case mediaType == "text/plain":
+		return name + ".txt"
+	}
+	if extensions, _ := mime.ExtensionsByType(mediaType); len(extensions) > 0 {
+		return name + extensions[0]
+	}
+	return name
+}
+
+// SaveBody writes the whole body to path, from BodyFile when it was too
+// large to keep in memory
+func (r *Response) SaveBody(path string) error {
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := r.WriteBody(out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// WriteBody writes the whole body to w, from BodyFile when it was too
+// large to keep in memory
+func (r *Response) WriteBody(w io.Writer) error {
+	if r.BodyFile == "" && r.Truncated() {
+		return errors.New("the body was too large to keep and has been discarded")
+	}
+	if r.BodyFile == "" {
+		_, err := io.WriteString(w, r.Body)
+		return err
+	}
+	in, err := os.Open(r.BodyFile)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	_, err = io.Copy(w, in)
+	return err
+}
+
+// Close removes the file a large body was kept in. The response keeps the start of the body.
+func (r *Response) Close() error {
+	if r.BodyFile == "" {
+		return nil
+	}
+	err := os.Remove(r.BodyFile)
+	r.BodyFile = ""
+	return err
+}
@@ -0,0 +1,143 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	for value, want := range map[string]int64{
+		"100":    100,
+		"100B":   100,
+		"512KB":  512 << 10,
+		"10mb":   10 << 20,
+		" 1 GB ": 1 << 30,
+	} {
+		got, err := ParseSize(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, want, got, value)
+	}
+	for _, value := range []string{"", "MB", "-1KB", "ten", "10TB", "99999999999GB"} {
+		_, err := ParseSize(value)
+		assert.Error(t, err, value)
+	}
+
+	limit, err := ParseBodyLimit(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(defaultBodyLimit), limit)
+	assert.Error(t, ValidateOptions(map[string]string{OptBodyLimit: "0"}))
+}
+
+func TestClient_SendBodyLimit(t *testing.T) {
+	body := strings.Repeat("0123456789", 1000)
+	server := httptest.NewServer(compressingHandler(body))
+	defer server.Close()
+	client := InitClient(5*time.Second, false)
+
+	res := send(client, &Request{Method: GET, URL: server.URL, Options: map[string]string{OptBodyLimit: "1KB"}})
+	require.Empty(t, res.Error)
+	defer res.Close()
+	assert.Equal(t, body[:1024], res.Body, "only the start is kept in memory")
+	assert.True(t, res.Truncated())
+	assert.Equal(t, int64(len(body)), res.BodySize())
+	require.NotEmpty(t, res.BodyFile)
+	spilled, err := os.ReadFile(res.BodyFile)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(spilled), "the whole body is on disk")
+
+	path := filepath.Join(t.TempDir(), "body.txt")
+	require.NoError(t, res.SaveBody(path))
+	saved, _ := os.ReadFile(path)
+	assert.Equal(t, body, string(saved))
+
+	file := res.BodyFile
+	assert.NoError(t, res.Close())
+	assert.NoFileExists(t, file)
+	assert.Error(t, res.SaveBody(path), "the rest of the body is gone")
+
+	// Encoded bodies are limited once decoded
+	res = send(client, &Request{Method: GET, URL: server.URL, Options: map[string]string{OptBodyLimit: "2KB", OptAcceptEncoding: "gzip"}})
+	require.Empty(t, res.Error)
+	defer res.Close()
+	assert.True(t, res.Truncated())
+	assert.Len(t, res.Body, 2048)
+	assert.Less(t, res.WireSize, 2048)
+	assert.NotNil(t, res.Raw, "the encoded body fits in the limit")
+
+	// Bodies under the limit stay in memory
+	res = send(client, &Request{Method: GET, URL: server.URL})
+	assert.Equal(t, body, res.Body)
+	assert.Empty(t, res.BodyFile)
+	assert.False(t, res.Truncated())
+}
+
+func TestClient_StreamProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(5*1024))
+		for i := 0; i < 5; i++ {
+			w.Write([]byte(strings.Repeat("x", 1024)))
+			w.(http.Flusher).Flush()
+			time.Sleep(60 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+	client := InitClient(5*time.Second, false)
+
+	updates, res := collect(client, context.Background(), &Request{Method: GET, URL: server.URL})
+	assert.Empty(t, res.Error)
+	assert.False(t, res.Streamed)
+	require.NotEmpty(t, updates)
+	for _, update := range updates {
+		require.NotNil(t, update.Progress)
+		assert.Equal(t, int64(5*1024), update.Progress.Total)
+		assert.Positive(t, update.Progress.Received)
+	}
+}
+
+func TestResponse_Binary(t *testing.T) {
+	tests := []struct {
+		contentType string
+		body        string
+		want        bool
+	}{
+		{"application/json", `{"a":1}`, false},
+		{"text/plain; charset=utf-8", "héllo", false},
+		{"image/png", "\x89PNG\r\n", true},
+		{"application/octet-stream", "plain text", false},
+		{"application/octet-stream", "\x00\x01\x02", true},
+		{"", "\xff\xfe\xfd", true},
+		{"", "a" + strings.Repeat("é", binarySniffLen), false}, // a rune cut in half by the sample
+	}
+	for _, tt := range tests {
+		res := &Response{Headers: http.Header{"Content-Type": {tt.contentType}}, Body: tt.body}
+		assert.Equal(t, tt.want, res.Binary(), "%s %q", tt.contentType, tt.body[:min(len(tt.body), 10)])
+	}
+}
+
+func TestResponse_Filename(t *testing.T) {
+	tests := []struct {
+		url     string
+		headers http.Header
+		want    string
+	}{
+		{"http://example.com/files/report.pdf", nil, "report.pdf"},
+		{"http://example.com/api/users", http.Header{"Content-Type": {"application/json"}}, "users.json"},
+		{"http://example.com/", http.Header{"Content-Type": {"text/plain"}}, "response.txt"},
+		{"http://example.com/", nil, "response"},
+		{"http://example.com/download", http.Header{"Content-Disposition": {`attachment; filename="../../data.csv"`}}, "data.csv"},
+	}
+	for _, tt := range tests {
+		res := &Response{URL: tt.url, Headers: tt.headers}
+		assert.Equal(t, tt.want, res.Filename(), tt.url)
+	}
+}
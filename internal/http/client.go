@@ -1,11 +1,18 @@
 package http
 
 import (
+	"cmp"
 	"context"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptrace"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/owenHochwald/volt/internal/secrets"
 )
 
 const (
@@ -18,11 +25,97 @@ type Client struct {
 
 	Transport *http.Transport
 	Client    *http.Client
+
+	// Vault decrypts secret header values right before sending
+	Vault *secrets.Vault
+
+	// Defaults are the environment's options, overridden by each request's Options
+	Defaults map[string]string
+
+	// Variables are the environment's variables, referenced by requests as
+	// {{name}} and expanded once secrets are decrypted
+	Variables map[string]string
+
+	// transports caches one transport per distinct TLS and proxy configuration
+	mu         sync.Mutex
+	transports map[transportOptions]*http.Transport
+}
+
+// transportOptions are the options that need a dedicated transport
+type transportOptions struct {
+	TLS      TLSOptions
+	Proxy    ProxyOptions
+	Protocol string
+	Connect  time.Duration // connect timeout, zero for none
+}
+
+// parseTransportOptions reads the transport settings of the request, falling back to the client's defaults
+func (c *Client) parseTransportOptions(req *Request) (transportOptions, error) {
+	options := MergeOptions(c.Defaults, req.Options)
+
+	tlsOptions, err := ParseTLSOptions(options)
+	if err != nil {
+		return transportOptions{}, err
+	}
+	proxyOptions, err := ParseProxyOptions(options)
+	if err != nil {
+		return transportOptions{}, err
+	}
+	protocol, err := ParseProtocol(options)
+	if err != nil {
+		return transportOptions{}, err
+	}
+	if protocol == ProtocolAuto {
+		protocol = "" // the default transport
+	}
+	timeouts, err := ParseTimeouts(options)
+	if err != nil {
+		return transportOptions{}, err
+	}
+	return transportOptions{TLS: tlsOptions, Proxy: proxyOptions, Protocol: protocol, Connect: timeouts.Connect}, nil
 }
 
-func (c *Client) makeCustomRequest(req *Request) (*http.Response, error) {
+// clientFor returns the http.Client to use for the given transport settings.
+// Clients share the cookie jar, and connections are pooled per configuration.
+func (c *Client) clientFor(opts transportOptions) (*http.Client, error) {
+	if opts == (transportOptions{}) {
+		return c.Client, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tr, ok := c.transports[opts]
+	if !ok {
+		tr = c.Transport.Clone()
+		if !opts.TLS.IsZero() {
+			config, err := opts.TLS.Config()
+			if err != nil {
+				return nil, err
+			}
+			tr.TLSClientConfig = config
+		}
+		if !opts.Proxy.IsZero() {
+			tr.Proxy = opts.Proxy.ProxyFunc()
+		}
+		tr.Protocols = transportProtocols(opts.Protocol)
+		if opts.Connect > 0 {
+			tr.DialContext = (&net.Dialer{Timeout: opts.Connect, KeepAlive: 30 * time.Second}).DialContext
+		}
+		if c.transports == nil {
+			c.transports = make(map[transportOptions]*http.Transport)
+		}
+		c.transports[opts] = tr
+	}
+
+	client := *c.Client
+	client.Transport = tr
+	return &client, nil
+}
+
+func (c *Client) makeCustomRequest(ctx context.Context, client *http.Client, req *Request) (*http.Response, error) {
 	payload := strings.NewReader(req.Body)
-	customReq, err := http.NewRequest(req.Method, req.URL, payload)
+	customReq, err := http.NewRequestWithContext(ctx, req.Method, req.URL, payload)
 	if err != nil {
 		return nil, err
 	}
@@ -31,13 +124,7 @@ func (c *Client) makeCustomRequest(req *Request) (*http.Response, error) {
 		customReq.Header.Set(key, val)
 	}
 
-	if c.Timeout > 0 {
-		ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
-		defer cancel()
-		customReq = customReq.WithContext(ctx)
-	}
-
-	res, err := c.Client.Do(customReq)
+	res, err := client.Do(customReq)
 
 	if err != nil {
 		return nil, err
@@ -46,18 +133,188 @@ func (c *Client) makeCustomRequest(req *Request) (*http.Response, error) {
 	return res, err
 }
 
+// follow sends the request and follows redirects according to the policy,
+// recording each redirect that was followed. The tracer times the final hop.
+func (c *Client) follow(
+	ctx context.Context,
+	client *http.Client,
+	req *Request,
+	policy RedirectPolicy,
+) (*http.Response, *timingTracer, []RedirectHop, error) {
+	var redirects []RedirectHop
+
+	for {
+		tracer := newTimingTracer()
+		res, err := c.makeCustomRequest(httptrace.WithClientTrace(ctx, tracer.clientTrace()), client, req)
+		if err != nil {
+			return nil, nil, redirects, err
+		}
+
+		location := res.Header.Get("Location")
+		if !isRedirect(res.StatusCode) || location == "" || len(redirects) >= policy.Max {
+			return res, tracer, redirects, nil
+		}
+
+		next, err := policy.nextRequest(req, res.StatusCode, location)
+		if err != nil {
+			res.Body.Close()
+			return nil, nil, redirects, err
+		}
+
+		// drain the body so the connection can be reused for the next hop
+		io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+		res.Body.Close()
+
+		redirects = append(redirects, RedirectHop{
+			Method:     req.Method,
+			URL:        req.URL,
+			StatusCode: res.StatusCode,
+			Status:     res.Status,
+			Location:   next.URL,
+			Headers:    res.Header,
+			Duration:   time.Since(tracer.start),
+		})
+		req = next
+	}
+}
+
+// Send sends the request and delivers the complete response
 func (c *Client) Send(req *Request, result chan<- *Response) {
+	result <- c.do(context.Background(), req, nil)
+}
+
+// Stream sends the request like Send, but streaming responses are delivered
+// as they arrive (see StreamUpdate). Canceling ctx stops the stream, keeping
+// what was received so far. updates is closed once the response is complete.
+func (c *Client) Stream(ctx context.Context, req *Request, updates chan<- StreamUpdate) {
+	defer close(updates)
+	updates <- StreamUpdate{Response: c.do(ctx, req, updates), Done: true}
+}
+
+// do sends the request, retrying failed attempts as its retry options allow.
+// Streams are never retried, part of them was already delivered.
+func (c *Client) do(parent context.Context, req *Request, updates chan<- StreamUpdate) *Response {
+	policy, err := ParseRetryPolicy(MergeOptions(c.Defaults, req.Options))
+	if err != nil {
+		return &Response{Error: err.Error()}
+	}
+
+	var attempts []Attempt
+	for n := 0; ; n++ {
+		start := time.Now()
+		response := c.attempt(parent, req, updates)
+		if n >= policy.Max || response.Streamed || parent.Err() != nil || !policy.Retryable(response) {
+			response.Attempts = attempts
+			return response
+		}
+
+		delay, fromHeader := policy.Delay(n, response.Headers.Get("Retry-After"))
+		attempts = append(attempts, newAttempt(response, time.Since(start), delay, fromHeader))
+		select {
+		case <-time.After(delay):
+		case <-parent.Done():
+			response.Attempts = attempts
+			return response
+		}
+	}
+}
+
+// timeout returns the request's timeout, the client's unless the options set one
+func (c *Client) timeout(options map[string]string) time.Duration {
+	if timeouts, err := ParseTimeouts(options); err == nil && timeouts.Total > 0 {
+		return timeouts.Total
+	}
+	return c.Timeout
+}
+
+// Unseal decrypts the secret header values of a request and then expands the
+// variables the request references, including the ones in secret values.
+// Secret variables are decrypted too. Requests are sent as Unseal returns
+// them, so their variables are expanded here and nowhere earlier.
+func (c *Client) Unseal(req *Request) (*Request, error) {
+	unsealed, err := req.Unseal(c.Vault)
+	if err != nil {
+		return nil, err
+	}
+	variables, err := openVariables(c.Vault, c.Variables)
+	if err != nil {
+		return nil, err
+	}
+	return unsealed.Expand(variables), nil
+}
+
+// Redact returns the request as it is shown or copied, its variables
+// expanded and the values of secret headers and secret variables masked
+func (c *Client) Redact(req *Request) *Request {
+	return req.Expand(maskVariables(c.Variables)).Redacted()
+}
+
+// attempt sends the request once, streaming the body to updates when it is non-nil and the response is a stream
+func (c *Client) attempt(parent context.Context, req *Request, updates chan<- StreamUpdate) *Response {
 	var start time.Time
 
+	if req.Method == WS {
+		return &Response{Error: "WebSocket requests can only be opened from the TUI"}
+	}
+	if req.Method == GRPC {
+		return c.doGRPC(parent, req, updates)
+	}
+
+	graphQL := req.Method == GRAPHQL
+	if graphQL {
+		req = req.asPost()
+	}
+
+	req, err := c.Unseal(req)
+	if err != nil {
+		return &Response{Error: err.Error()}
+	}
+
+	transport, err := c.parseTransportOptions(req)
+	if err != nil {
+		return &Response{Error: err.Error()}
+	}
+	client, err := c.clientFor(transport)
+	if err != nil {
+		return &Response{Error: err.Error()}
+	}
+	options := MergeOptions(c.Defaults, req.Options)
+	policy, err := ParseRedirectPolicy(options)
+	if err != nil {
+		return &Response{Error: err.Error()}
+	}
+	acceptEncoding, err := ParseAcceptEncoding(options)
+	if err != nil {
+		return &Response{Error: err.Error()}
+	}
+	streamMode, err := ParseStreamMode(options)
+	if err != nil {
+		return &Response{Error: err.Error()}
+	}
+	limit, err := ParseBodyLimit(options)
+	if err != nil {
+		return &Response{Error: err.Error()}
+	}
+	req = req.withDefaultHeader("Accept-Encoding", acceptEncoding)
+
+	// The timeout covers reading the body too, so the context must outlive
+	// makeCustomRequest. Streams are only timed until their headers arrive.
+	ctx, cancel := context.WithCancelCause(parent)
+	defer cancel(nil)
+	var deadline *time.Timer
+	if timeout := c.timeout(options); timeout > 0 {
+		deadline = time.AfterFunc(timeout, func() { cancel(context.DeadlineExceeded) })
+		defer deadline.Stop()
+	}
+
 	if c.RoundTrip {
 		// time for connections, headers, and body
 		start = time.Now()
 	}
 
-	res, err := c.makeCustomRequest(req)
+	res, tracer, redirects, err := c.follow(ctx, client, req, policy)
 	if err != nil {
-		result <- &Response{Error: err.Error()}
-		return
+		return &Response{Error: err.Error(), Redirects: redirects, failure: failureOf(ctx, err)}
 	}
 
 	if !c.RoundTrip {
@@ -67,22 +324,140 @@ func (c *Client) Send(req *Request, result chan<- *Response) {
 
 	// close body from earlier call
 	defer res.Body.Close()
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		result <- &Response{Error: err.Error()}
-		return
-	}
 
-	duration := time.Since(start)
-
-	result <- &Response{
+	response := &Response{
 		Status:     res.Status,
 		StatusCode: res.StatusCode,
-		Body:       string(body),
+		Proto:      res.Proto,
 		Headers:    res.Header,
-		Duration:   duration,
+		Cookies:    res.Cookies(),
+		URL:        res.Request.URL.String(),
+		Redirects:  redirects,
+		TLS:        newTLSInfo(res.TLS, transport.TLS.Insecure),
 		RoundTrip:  c.RoundTrip,
+		GraphQL:    graphQL,
+	}
+
+	eventStream := isEventStream(res.Header.Get("Content-Type"))
+	if updates != nil && (streamMode == StreamOn || (streamMode == StreamAuto && eventStream)) {
+		if deadline != nil {
+			deadline.Stop()
+		}
+		c.readStream(parent, res, response, eventStream, updates)
+		end := time.Now()
+		response.Duration = end.Sub(start)
+		response.Timing = tracer.timing(end)
+		return response
 	}
+
+	var progress func(Progress)
+	if updates != nil {
+		progress = func(p Progress) { updates <- StreamUpdate{Progress: &p} }
+	}
+	if connErr, err := readBody(res, response, limit, progress); err != nil {
+		failed := &Response{Error: err.Error()}
+		if connErr {
+			failed.failure = failureOf(ctx, err)
+		}
+		return failed
+	}
+
+	end := time.Now()
+	response.Duration = end.Sub(start)
+	response.Timing = tracer.timing(end)
+	return response
+}
+
+// readBody reads the whole body into response, undoing its Content-Encoding.
+// Compression is handled here rather than by the transport, so that both
+// sizes are known. Past limit bytes, the body goes to a temporary file,
+// see Response.BodyFile. connErr tells failed connections from bodies that
+// couldn't be decoded.
+func readBody(res *http.Response, response *Response, limit int64, progress func(Progress)) (connErr bool, err error) {
+	wire := &countingReader{r: res.Body}
+	var r io.Reader = wire
+	if progress != nil {
+		r = &progressReader{r: wire, total: res.ContentLength, last: time.Now(), report: progress}
+	}
+
+	encoding := res.Header.Get("Content-Encoding")
+	var raw *cappedBuffer
+	if encoding != "" {
+		raw = &cappedBuffer{limit: limit}
+		r = io.TeeReader(r, raw)
+	}
+
+	decoded, err := decodingReader(encoding, r)
+	if err != nil {
+		return wire.err != nil, cmp.Or(wire.err, err)
+	}
+	body := &spillBuffer{limit: limit}
+	_, err = io.Copy(body, decoded)
+	file, closeErr := body.close(err != nil)
+	switch {
+	case wire.err != nil:
+		return true, wire.err
+	case err != nil && encoding != "":
+		return false, fmt.Errorf("decoding %s body: %w", encoding, err)
+	case err != nil:
+		return false, err
+	case closeErr != nil:
+		return false, closeErr
+	}
+
+	if raw != nil && !raw.over {
+		response.Raw = raw.data
+		if response.Raw == nil {
+			response.Raw = []byte{}
+		}
+	}
+	response.Body = string(body.head)
+	response.BodyFile = file
+	response.Size = body.size
+	response.WireSize = wire.n
+	return false, nil
+}
+
+// readStream delivers the body to updates as it arrives, filling in the
+// response's body. Stopping the stream through parent isn't an error.
+func (c *Client) readStream(parent context.Context, res *http.Response, response *Response, eventStream bool, updates chan<- StreamUpdate) {
+	headers := *response
+	updates <- StreamUpdate{Response: &headers}
+
+	wire := &countingReader{r: res.Body}
+	body, err := decodingReader(res.Header.Get("Content-Encoding"), wire)
+	if err == nil {
+		if eventStream {
+			response.Body, err = readEvents(body, func(event StreamEvent) {
+				response.Events = append(response.Events, event)
+				updates <- StreamUpdate{Event: &event}
+			})
+		} else {
+			response.Body, err = readChunks(body, func(chunk string) {
+				updates <- StreamUpdate{Chunk: chunk}
+			})
+		}
+	}
+
+	response.Streamed = true
+	response.WireSize = wire.n
+	if err != nil && parent.Err() == nil {
+		response.Error = err.Error()
+	}
+}
+
+// SetCookieJar attaches a cookie jar to the client, or detaches it when jar is nil
+func (c *Client) SetCookieJar(jar *CookieJar) {
+	if jar == nil {
+		c.Client.Jar = nil
+		return
+	}
+	c.Client.Jar = jar
+}
+
+// CookiesEnabled reports whether a cookie jar is attached
+func (c *Client) CookiesEnabled() bool {
+	return c.Client.Jar != nil
 }
 
 func (c *Client) ToggleRoundTrip() {
@@ -96,9 +471,17 @@ func InitClient(timeout time.Duration, roundTrip bool) *Client {
 	tr := &http.Transport{
 		MaxIdleConns:       10,
 		IdleConnTimeout:    30 * time.Second,
-		DisableCompression: true,
+		DisableCompression: true,                       // bodies are decoded while read, see readBody and decodingReader
+		Proxy:              ProxyOptions{}.ProxyFunc(), // HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+		ForceAttemptHTTP2:  true,                       // keep offering h2 with a custom TLS config
+	}
+	client := &http.Client{
+		Transport: tr,
+		// Send follows redirects itself, see RedirectPolicy
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
 	}
-	client := &http.Client{Transport: tr}
 
 	return &Client{
 		Timeout:   timeout,
@@ -104,3 +104,53 @@ func TestClient_Send(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_SendTiming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := InitClient(time.Second, true)
+	send := func() *Response {
+		res := make(chan *Response)
+		go client.Send(&Request{Method: GET, URL: server.URL}, res)
+		return <-res
+	}
+
+	first := send()
+	if first.Timing == nil {
+		t.Fatal("expected timing breakdown")
+	}
+	if first.Timing.ConnReused {
+		t.Error("first request should open a new connection")
+	}
+	if first.Timing.TCPConnect <= 0 {
+		t.Error("expected TCP connect time on a new connection")
+	}
+	if first.Timing.Waiting < 20*time.Millisecond {
+		t.Errorf("waiting = %v, want at least the server delay", first.Timing.Waiting)
+	}
+	if first.Timing.RemoteAddr != server.Listener.Addr().String() {
+		t.Errorf("remote addr = %s, want %s", first.Timing.RemoteAddr, server.Listener.Addr())
+	}
+
+	second := send()
+	if !second.Timing.ConnReused {
+		t.Error("second request should reuse the keep-alive connection")
+	}
+	if second.Timing.TCPConnect != 0 {
+		t.Errorf("reused connection should not connect, got %v", second.Timing.TCPConnect)
+	}
+
+	phases := second.Timing.Phases()
+	if len(phases) != 5 {
+		t.Fatalf("got %d phases, want 5", len(phases))
+	}
+	for i := 1; i < len(phases); i++ {
+		if phases[i].Start < phases[i-1].Start {
+			t.Errorf("phase %s starts before %s", phases[i].Name, phases[i-1].Name)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Collection groups saved requests. A collection inside another one is a
+// folder of it, and the requests inside inherit the defaults of every
+// collection they are in, see Request.Inherit.
+type Collection struct {
+	ID       int64  `json:"id,omitempty"`
+	ParentID int64  `json:"parent_id,omitempty"` // 0 for top level collections
+	Name     string `json:"name"`
+
+	// BaseURL is prefixed to the relative URLs of the requests inside, e.g.
+	// https://api.example.com, or /v2 for a folder of a collection that has one
+	BaseURL string `json:"base_url,omitempty"`
+
+	// Headers are added to the requests inside, unless they set them
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Auth is the Authorization header of the requests inside, stored encrypted
+	Auth string `json:"auth,omitempty"`
+}
+
+// Validate checks the name and defaults of a collection
+func (c *Collection) Validate() error {
+	name := strings.TrimSpace(c.Name)
+	if name == "" {
+		return fmt.Errorf("collection name is required")
+	}
+	if len(name) > 40 {
+		return fmt.Errorf("name too long: %s", name)
+	}
+	if c.BaseURL != "" && !strings.Contains(c.BaseURL, "://") && !strings.HasPrefix(c.BaseURL, "/") {
+		return fmt.Errorf("invalid base url: %s, expected an absolute URL or a path starting with /", c.BaseURL)
+	}
+	if c.ParentID != 0 && c.ParentID == c.ID {
+		return fmt.Errorf("a collection can't be inside itself")
+	}
+	return nil
+}
+
+// CollectionPath returns the collection with the given ID and the ones it
+// is in, outermost first. It is empty for 0 or an unknown ID.
+func CollectionPath(collections []Collection, id int64) []Collection {
+	var path []Collection
+	for id != 0 && len(path) <= len(collections) {
+		i := slices.IndexFunc(collections, func(c Collection) bool { return c.ID == id })
+		if i < 0 {
+			break
+		}
+		path = append(path, collections[i])
+		id = collections[i].ParentID
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// Inherit returns the request with the defaults of the collections it is
+// in, given outermost first as returned by CollectionPath. Inner folders
+// win over outer ones, and the request's own URL and headers win over all
+// of them.
+func (r *Request) Inherit(path []Collection) *Request {
+	inherited := r
+	for i := len(path) - 1; i >= 0; i-- {
+		collection := path[i]
+
+		if collection.BaseURL != "" && !strings.Contains(inherited.URL, "://") {
+			copied := *inherited
+			copied.URL = joinURL(collection.BaseURL, inherited.URL)
+			inherited = &copied
+		}
+		for key, value := range collection.Headers {
+			inherited = inherited.withDefaultHeader(key, value)
+		}
+		if collection.Auth != "" {
+			withAuth := inherited.withDefaultHeader("Authorization", collection.Auth)
+			if withAuth != inherited {
+				withAuth.Secrets = append(slices.Clone(withAuth.Secrets), "Authorization")
+				inherited = withAuth
+			}
+		}
+	}
+	return inherited
+}
+
+// joinURL appends a relative URL, a path or a query, to a base URL
+func joinURL(base, relative string) string {
+	switch {
+	case relative == "":
+		return base
+	case strings.HasPrefix(relative, "?"):
+		return base + relative
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(relative, "/")
+}
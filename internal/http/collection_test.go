@@ -0,0 +1,65 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectionPath(t *testing.T) {
+	collections := []Collection{
+		{ID: 1, Name: "api"},
+		{ID: 2, ParentID: 1, Name: "users"},
+		{ID: 3, ParentID: 2, Name: "admin"},
+		{ID: 4, Name: "other"},
+	}
+
+	path := CollectionPath(collections, 3)
+	if assert.Len(t, path, 3) {
+		assert.Equal(t, "api", path[0].Name)
+		assert.Equal(t, "admin", path[2].Name)
+	}
+	assert.Empty(t, CollectionPath(collections, 0))
+	assert.Empty(t, CollectionPath(collections, 99))
+
+	// a cycle, which storage refuses to create, doesn't loop forever
+	cycle := []Collection{{ID: 1, ParentID: 2}, {ID: 2, ParentID: 1}}
+	assert.LessOrEqual(t, len(CollectionPath(cycle, 1)), 3)
+}
+
+func TestRequest_Inherit(t *testing.T) {
+	path := []Collection{
+		{ID: 1, Name: "api", BaseURL: "https://api.example.com/", Headers: map[string]string{"Accept": "application/json", "X-Team": "core"}, Auth: "Bearer outer"},
+		{ID: 2, ParentID: 1, Name: "users", BaseURL: "/v2", Headers: map[string]string{"X-Team": "users"}},
+	}
+
+	req := &Request{Method: GET, URL: "/users?page=2", Headers: map[string]string{"accept": "text/plain"}}
+	inherited := req.Inherit(path)
+	assert.Equal(t, "https://api.example.com/v2/users?page=2", inherited.URL)
+	assert.Equal(t, "text/plain", inherited.Headers["accept"], "the request's headers win")
+	assert.NotContains(t, inherited.Headers, "Accept")
+	assert.Equal(t, "users", inherited.Headers["X-Team"], "inner folders win")
+	assert.Equal(t, "Bearer outer", inherited.Headers["Authorization"])
+	assert.True(t, inherited.IsSecret("Authorization"))
+
+	assert.Equal(t, "/users?page=2", req.URL, "the saved request is unchanged")
+	assert.Len(t, req.Headers, 1)
+	assert.Empty(t, req.Secrets)
+
+	// absolute URLs and the request's own auth are kept
+	req = &Request{Method: GET, URL: "http://localhost:8080/health", Headers: map[string]string{"Authorization": "Basic abc"}}
+	inherited = req.Inherit(path)
+	assert.Equal(t, "http://localhost:8080/health", inherited.URL)
+	assert.Equal(t, "Basic abc", inherited.Headers["Authorization"])
+	assert.False(t, inherited.IsSecret("Authorization"))
+
+	assert.Same(t, req, req.Inherit(nil))
+}
+
+func TestCollection_Validate(t *testing.T) {
+	assert.NoError(t, (&Collection{Name: "api", BaseURL: "https://api.example.com"}).Validate())
+	assert.NoError(t, (&Collection{Name: "v2", BaseURL: "/v2"}).Validate())
+	assert.Error(t, (&Collection{Name: " "}).Validate())
+	assert.Error(t, (&Collection{Name: "api", BaseURL: "api.example.com"}).Validate())
+	assert.Error(t, (&Collection{ID: 1, ParentID: 1, Name: "loop"}).Validate())
+}
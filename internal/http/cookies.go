@@ -0,0 +1,244 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cookie is a cookie held by a CookieJar
+type Cookie struct {
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain"`
+	Path     string    `json:"path"`
+	Expires  time.Time `json:"expires,omitzero"` // zero for session cookies
+	Secure   bool      `json:"secure,omitempty"`
+	HttpOnly bool      `json:"http_only,omitempty"`
+	HostOnly bool      `json:"host_only,omitempty"` // only sent to Domain, not its subdomains
+	SameSite string    `json:"same_site,omitempty"`
+}
+
+// Expired reports whether the cookie has expired at the given time
+func (c Cookie) Expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !c.Expires.After(now)
+}
+
+func (c Cookie) key() string {
+	return c.Domain + ";" + c.Path + ";" + c.Name
+}
+
+// CookieJar is an http.CookieJar whose contents can be listed, edited and persisted
+type CookieJar struct {
+	mu      sync.RWMutex
+	cookies map[string]Cookie
+}
+
+// NewCookieJar creates an empty cookie jar
+func NewCookieJar() *CookieJar {
+	return &CookieJar{cookies: make(map[string]Cookie)}
+}
+
+// SetCookies stores cookies received in a response from u
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	now := time.Now()
+	host := canonicalHost(u.Host)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, c := range cookies {
+		stored := Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+			SameSite: sameSiteString(c.SameSite),
+		}
+
+		if c.Domain == "" {
+			stored.Domain = host
+			stored.HostOnly = true
+		} else {
+			domain := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+			if !domainMatch(host, domain) {
+				continue // a host may only set cookies for itself or a parent domain
+			}
+			stored.Domain = domain
+		}
+
+		if stored.Path == "" || stored.Path[0] != '/' {
+			stored.Path = defaultPath(u.Path)
+		}
+
+		switch {
+		case c.MaxAge < 0:
+			stored.Expires = now
+		case c.MaxAge > 0:
+			stored.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		case !c.Expires.IsZero():
+			stored.Expires = c.Expires
+		}
+
+		if stored.Expired(now) {
+			delete(j.cookies, stored.key())
+			continue
+		}
+		j.cookies[stored.key()] = stored
+	}
+}
+
+// Cookies returns the cookies to send in a request to u
+func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	now := time.Now()
+	host := canonicalHost(u.Host)
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	https := u.Scheme == "https" || u.Scheme == "wss"
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	var matched []Cookie
+	for _, c := range j.cookies {
+		if c.Expired(now) || (c.Secure && !https) || !pathMatch(path, c.Path) {
+			continue
+		}
+		if c.HostOnly && c.Domain != host {
+			continue
+		}
+		if !c.HostOnly && !domainMatch(host, c.Domain) {
+			continue
+		}
+		matched = append(matched, c)
+	}
+
+	// More specific paths first, as browsers do
+	sort.Slice(matched, func(a, b int) bool {
+		return len(matched[a].Path) > len(matched[b].Path)
+	})
+
+	result := make([]*http.Cookie, 0, len(matched))
+	for _, c := range matched {
+		result = append(result, &http.Cookie{Name: c.Name, Value: c.Value})
+	}
+	return result
+}
+
+// All returns every unexpired cookie in the jar, sorted by domain, path and name
+func (j *CookieJar) All() []Cookie {
+	now := time.Now()
+
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	all := make([]Cookie, 0, len(j.cookies))
+	for _, c := range j.cookies {
+		if !c.Expired(now) {
+			all = append(all, c)
+		}
+	}
+	sort.Slice(all, func(a, b int) bool {
+		return all[a].key() < all[b].key()
+	})
+	return all
+}
+
+// Set adds or replaces a cookie
+func (j *CookieJar) Set(c Cookie) {
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	c.Domain = strings.ToLower(c.Domain)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.cookies[c.key()] = c
+}
+
+// Delete removes a cookie
+func (j *CookieJar) Delete(c Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.cookies, c.key())
+}
+
+// Clear removes every cookie
+func (j *CookieJar) Clear() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	clear(j.cookies)
+}
+
+// Replace swaps the contents of the jar for the given cookies
+func (j *CookieJar) Replace(cookies []Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	clear(j.cookies)
+	for _, c := range cookies {
+		j.cookies[c.key()] = c
+	}
+}
+
+// canonicalHost lowercases the host and strips the port
+func canonicalHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.Trim(host, "[]"))
+}
+
+// domainMatch implements RFC 6265 section 5.1.3
+func domainMatch(host, domain string) bool {
+	if host == domain {
+		return true
+	}
+	if net.ParseIP(host) != nil {
+		return false
+	}
+	return strings.HasSuffix(host, "."+domain)
+}
+
+// pathMatch implements RFC 6265 section 5.1.4
+func pathMatch(requestPath, cookiePath string) bool {
+	if requestPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(requestPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || requestPath[len(cookiePath)] == '/'
+}
+
+// defaultPath implements RFC 6265 section 5.1.4
+func defaultPath(path string) string {
+	if path == "" || path[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(path, "/")
+	if i == 0 {
+		return "/"
+	}
+	return path[:i]
+}
+
+func sameSiteString(s http.SameSite) string {
+	switch s {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	default:
+		return ""
+	}
+}
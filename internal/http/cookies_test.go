@@ -0,0 +1,119 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("failed to parse url: %v", err)
+	}
+	return u
+}
+
+func cookieNames(cookies []*http.Cookie) []string {
+	names := make([]string, 0, len(cookies))
+	for _, c := range cookies {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func TestCookieJar_Matching(t *testing.T) {
+	jar := NewCookieJar()
+	jar.SetCookies(mustParseURL(t, "https://api.example.com/v1/login"), []*http.Cookie{
+		{Name: "host", Value: "1"},
+		{Name: "domain", Value: "2", Domain: ".example.com", Path: "/"},
+		{Name: "secure", Value: "3", Path: "/", Secure: true},
+		{Name: "scoped", Value: "4", Path: "/admin"},
+		{Name: "foreign", Value: "5", Domain: "other.com"},
+	})
+
+	tests := []struct {
+		name string
+		url  string
+		want []string
+	}{
+		{"same host and path", "https://api.example.com/v1/users", []string{"host", "domain", "secure"}},
+		{"subdomain gets domain cookie only", "https://www.example.com/", []string{"domain"}},
+		{"plain http drops secure cookie", "http://api.example.com/v1/users", []string{"host", "domain"}},
+		{"path scoped cookie", "https://api.example.com/admin/panel", []string{"domain", "secure", "scoped"}},
+		{"unrelated host", "https://other.com/", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cookieNames(jar.Cookies(mustParseURL(t, tt.url)))
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
+
+func TestCookieJar_ExpiryAndEditing(t *testing.T) {
+	jar := NewCookieJar()
+	u := mustParseURL(t, "http://localhost:8080/")
+
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "session", Value: "abc"},
+		{Name: "old", Value: "x", Expires: time.Now().Add(-time.Hour)},
+	})
+	assert.Len(t, jar.All(), 1)
+
+	// Max-Age < 0 deletes the cookie
+	jar.SetCookies(u, []*http.Cookie{{Name: "session", MaxAge: -1}})
+	assert.Len(t, jar.All(), 0)
+
+	jar.Set(Cookie{Name: "manual", Value: "1", Domain: "localhost", HostOnly: true})
+	assert.Equal(t, []string{"manual"}, cookieNames(jar.Cookies(u)))
+
+	all := jar.All()
+	jar.Delete(all[0])
+	assert.Len(t, jar.All(), 0)
+
+	jar.Replace([]Cookie{{Name: "a", Value: "1", Domain: "localhost", Path: "/"}})
+	assert.Len(t, jar.All(), 1)
+	jar.Clear()
+	assert.Len(t, jar.All(), 0)
+}
+
+func TestClient_SendWithCookieJar(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cr3t", Path: "/", HttpOnly: true})
+			return
+		}
+		c, err := r.Cookie("session")
+		if err != nil {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(c.Value))
+	}))
+	defer server.Close()
+
+	client := InitClient(time.Second, false)
+	client.SetCookieJar(NewCookieJar())
+
+	send := func(path string) *Response {
+		res := make(chan *Response)
+		go client.Send(&Request{Method: GET, URL: server.URL + path}, res)
+		return <-res
+	}
+
+	login := send("/login")
+	assert.Empty(t, login.Error)
+	assert.Len(t, login.Cookies, 1)
+	assert.True(t, login.Cookies[0].HttpOnly)
+
+	me := send("/me")
+	assert.Equal(t, http.StatusOK, me.StatusCode)
+	assert.Equal(t, "s3cr3t", me.Body)
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Encodings that responses can be decoded from
+var supportedEncodings = []string{"gzip", "deflate", "br", "zstd"}
+
+// ParseAcceptEncoding reads the accept-encoding option into an Accept-Encoding
+// header value. Empty means the header isn't added, so the server decides.
+func ParseAcceptEncoding(options map[string]string) (string, error) {
+	value := strings.ToLower(strings.TrimSpace(options[OptAcceptEncoding]))
+	switch value {
+	case "":
+		return "", nil
+	case "all":
+		return strings.Join(supportedEncodings, ", "), nil
+	case "none", "identity":
+		return "identity", nil
+	}
+
+	// commas separate options, so encodings are separated by spaces or "+"
+	encodings := strings.FieldsFunc(value, func(r rune) bool { return r == ' ' || r == '+' })
+	for _, encoding := range encodings {
+		if !slices.Contains(supportedEncodings, encoding) {
+			return "", fmt.Errorf("%s: unsupported encoding %q, expected all, none or any of %s",
+				OptAcceptEncoding, encoding, strings.Join(supportedEncodings, ", "))
+		}
+	}
+	return strings.Join(encodings, ", "), nil
+}
+
+// decodeBody undoes the Content-Encoding of a complete body
+func decodeBody(contentEncoding string, body []byte) ([]byte, error) {
+	reader, err := decodingReader(contentEncoding, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s body: %w", contentEncoding, err)
+	}
+	return decoded, nil
+}
+
+// decodingReader wraps r to undo a Content-Encoding as the body is read.
+// Encodings are listed in the order they were applied, so they are removed
+// from last to first.
+func decodingReader(contentEncoding string, r io.Reader) (io.Reader, error) {
+	encodings := strings.Split(contentEncoding, ",")
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+		if encoding == "" || encoding == "identity" {
+			continue
+		}
+
+		var err error
+		if r, err = decoder(encoding, r); err != nil {
+			return nil, fmt.Errorf("decoding %s body: %w", encoding, err)
+		}
+	}
+	return r, nil
+}
+
+func decoder(encoding string, r io.Reader) (io.Reader, error) {
+	switch encoding {
+	case "gzip", "x-gzip":
+		return gzip.NewReader(r)
+	case "deflate":
+		// deflate is meant to be zlib wrapped, but some servers send raw deflate
+		buffered := bufio.NewReader(r)
+		if header, err := buffered.Peek(2); err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			return zlib.NewReader(buffered)
+		}
+		return flate.NewReader(buffered), nil
+	case "br":
+		return brotli.NewReader(r), nil
+	case "zstd":
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+}
+
+// withDefaultHeader returns a copy of the request with the header set, unless it already has it
+func (r *Request) withDefaultHeader(key, value string) *Request {
+	if value == "" {
+		return r
+	}
+	for existing := range r.Headers {
+		if strings.EqualFold(existing, key) {
+			return r
+		}
+	}
+
+	copied := *r
+	copied.Headers = maps.Clone(r.Headers)
+	if copied.Headers == nil {
+		copied.Headers = make(map[string]string, 1)
+	}
+	copied.Headers[key] = value
+	return &copied
+}
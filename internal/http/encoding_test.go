@@ -0,0 +1,180 @@
+package http
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+func compress(encoding string, data []byte) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "br":
+		w = brotli.NewWriter(&buf)
+	case "zstd":
+		w, _ = zstd.NewWriter(&buf)
+	default:
+		panic("unknown encoding " + encoding)
+	}
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+// compressingHandler encodes body with the first encoding the client accepts
+func compressingHandler(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accepted := r.Header.Get("Accept-Encoding")
+		w.Header().Set("X-Accept-Encoding", accepted)
+		encoding, _, _ := strings.Cut(accepted, ",")
+		if encoding == "" || encoding == "identity" {
+			w.Write([]byte(body))
+			return
+		}
+		w.Header().Set("Content-Encoding", encoding)
+		w.Write(compress(encoding, []byte(body)))
+	}
+}
+
+func TestParseAcceptEncoding(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "", want: ""},
+		{value: "all", want: "gzip, deflate, br, zstd"},
+		{value: "none", want: "identity"},
+		{value: "br gzip", want: "br, gzip"},
+		{value: "zstd+gzip", want: "zstd, gzip"},
+		{value: "compress", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseAcceptEncoding(map[string]string{OptAcceptEncoding: tt.value})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDecodeBody(t *testing.T) {
+	body := []byte(strings.Repeat("volt ", 100))
+
+	for _, encoding := range supportedEncodings {
+		t.Run(encoding, func(t *testing.T) {
+			decoded, err := decodeBody(encoding, compress(encoding, body))
+			assert.NoError(t, err)
+			assert.Equal(t, body, decoded)
+		})
+	}
+
+	t.Run("raw deflate", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+		w.Write(body)
+		w.Close()
+		decoded, err := decodeBody("deflate", buf.Bytes())
+		assert.NoError(t, err)
+		assert.Equal(t, body, decoded)
+	})
+
+	t.Run("stacked", func(t *testing.T) {
+		encoded := compress("br", compress("gzip", body))
+		decoded, err := decodeBody("gzip, br", encoded)
+		assert.NoError(t, err)
+		assert.Equal(t, body, decoded)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := decodeBody("compress", body)
+		assert.Error(t, err)
+	})
+}
+
+func TestClient_SendDecodes(t *testing.T) {
+	body := strings.Repeat(`{"id":1,"name":"volt"}`, 50)
+	server := httptest.NewServer(compressingHandler(body))
+	defer server.Close()
+
+	client := InitClient(5*time.Second, false)
+
+	res := send(client, &Request{Method: GET, URL: server.URL})
+	assert.Empty(t, res.Headers.Get("X-Accept-Encoding"), "no Accept-Encoding unless asked for")
+	assert.Equal(t, body, res.Body)
+	assert.Empty(t, res.ContentEncoding())
+
+	for _, encoding := range supportedEncodings {
+		t.Run(encoding, func(t *testing.T) {
+			res := send(client, &Request{Method: GET, URL: server.URL, Options: map[string]string{OptAcceptEncoding: encoding}})
+			assert.Empty(t, res.Error)
+			assert.Equal(t, body, res.Body)
+			assert.Equal(t, encoding, res.ContentEncoding())
+			assert.Less(t, res.WireSize, len(body))
+			assert.Equal(t, res.WireSize, len(res.RawBody()))
+		})
+	}
+
+	// a header set on the request wins over the option
+	res = send(client, &Request{
+		Method:  GET,
+		URL:     server.URL,
+		Headers: map[string]string{"accept-encoding": "br"},
+		Options: map[string]string{OptAcceptEncoding: "all"},
+	})
+	assert.Equal(t, "br", res.Headers.Get("X-Accept-Encoding"))
+	assert.Equal(t, body, res.Body)
+}
+
+func TestJobConfig_RunCountsDecodedBytes(t *testing.T) {
+	body := strings.Repeat("volt ", 1000)
+	server, _ := h2cServer(t, compressingHandler(body))
+
+	// both load test backends
+	for _, protocol := range []string{ProtocolAuto, ProtocolH2C} {
+		t.Run(protocol, func(t *testing.T) {
+			config := &JobConfig{
+				Request:        &Request{Method: GET, URL: server.URL},
+				Concurrency:    2,
+				TotalRequests:  20,
+				Timeout:        5 * time.Second,
+				AcceptEncoding: "gzip",
+				Protocol:       protocol,
+			}
+			updates := make(chan *LoadTestStats, 10)
+			go config.Run(updates)
+			var final *LoadTestStats
+			for stats := range updates {
+				final = stats
+			}
+
+			if assert.NotNil(t, final) {
+				assert.Equal(t, 0, final.FailedRequests)
+				assert.Greater(t, final.BytesRecv, int64(0))
+				assert.Less(t, final.BytesRecv, int64(20*len(body)))
+				assert.Equal(t, int64(20*len(body)), final.BytesDecoded)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/valyala/fasthttp"
@@ -19,19 +20,77 @@ type FastRequest struct {
 }
 
 type FastClient struct {
-	client  *fasthttp.Client
-	timeout time.Duration
+	client    *fasthttp.Client
+	timeout   time.Duration
+	redirects RedirectPolicy
+	secrets   []string // headers dropped with the sensitive ones on redirects to other hosts
+}
+
+// WorkerCookieJar holds the cookies of a single load test worker, so that
+// every worker behaves like an independent session
+type WorkerCookieJar struct {
+	cookies map[string][]byte
+	parsed  fasthttp.Cookie
+}
+
+// NewWorkerCookieJar creates an empty per-worker cookie jar
+func NewWorkerCookieJar() *WorkerCookieJar {
+	return &WorkerCookieJar{cookies: make(map[string][]byte, 4)}
+}
+
+// apply adds the stored cookies to an outgoing request
+func (j *WorkerCookieJar) apply(req *fasthttp.Request) {
+	for key, value := range j.cookies {
+		req.Header.SetCookieBytesKV([]byte(key), value)
+	}
+}
+
+// update stores the cookies set by a response
+func (j *WorkerCookieJar) update(res *fasthttp.Response) {
+	for _, value := range res.Header.Cookies() {
+		if err := j.parsed.ParseBytes(value); err != nil {
+			continue
+		}
+		key := string(j.parsed.Key())
+		if j.parsed.MaxAge() < 0 || len(j.parsed.Value()) == 0 {
+			delete(j.cookies, key)
+			continue
+		}
+		j.cookies[key] = append(j.cookies[key][:0], j.parsed.Value()...)
+	}
+}
+
+// applyHTTP adds the stored cookies to an outgoing net/http request
+func (j *WorkerCookieJar) applyHTTP(req *http.Request) {
+	for key, value := range j.cookies {
+		req.AddCookie(&http.Cookie{Name: key, Value: string(value)})
+	}
+}
+
+// updateHTTP stores the cookies set by a net/http response
+func (j *WorkerCookieJar) updateHTTP(res *http.Response) {
+	for _, c := range res.Cookies() {
+		if c.MaxAge < 0 || c.Value == "" {
+			delete(j.cookies, c.Name)
+			continue
+		}
+		j.cookies[c.Name] = append(j.cookies[c.Name][:0], c.Value...)
+	}
 }
 
 func NewFastClient(timeout time.Duration, s *JobConfig) *FastClient {
 	return &FastClient{
-		timeout: timeout,
+		timeout:   timeout,
+		redirects: s.Redirects,
+		secrets:   s.secretHeaders(),
 		client: &fasthttp.Client{
 			MaxConnsPerHost:     max(500, s.Concurrency),
 			MaxIdleConnDuration: 10 * time.Second,
 			ReadTimeout:         s.Timeout,
 			WriteTimeout:        s.Timeout,
 			MaxConnDuration:     0,
+			TLSConfig:           s.TLSConfig,
+			Dial:                s.Dial,
 		},
 	}
 }
@@ -40,7 +99,9 @@ func (f *FastClient) Do(
 	fr *FastRequest,
 	req *fasthttp.Request,
 	res *fasthttp.Response,
-) (status int, contentLen int64, err error) {
+	jar *WorkerCookieJar,
+	decode bool,
+) (status int, size BodySize, err error) {
 	req.Reset()
 	res.Reset()
 
@@ -54,11 +115,69 @@ func (f *FastClient) Do(
 	if fr.Body != nil {
 		req.SetBodyRaw(fr.Body)
 	}
+	if jar != nil {
+		jar.apply(req)
+	}
+
+	for hops := 0; ; hops++ {
+		err = f.client.DoTimeout(req, res, f.timeout)
+		if err != nil {
+			return 0, BodySize{}, err
+		}
+
+		if jar != nil {
+			jar.update(res)
+		}
+
+		if hops >= f.redirects.Max || !isRedirect(res.StatusCode()) {
+			break
+		}
+		location := res.Header.Peek("Location")
+		if len(location) == 0 {
+			break
+		}
+		f.prepareRedirect(req, res.StatusCode(), location)
+		if jar != nil {
+			jar.apply(req)
+		}
+	}
+
+	body := res.Body()
+	if encoding := res.Header.ContentEncoding(); decode && len(encoding) > 0 {
+		return res.StatusCode(), decodedSize(string(encoding), body), nil
+	}
+	return res.StatusCode(), BodySize{Received: int64(len(body)), Decoded: int64(len(body))}, nil
+}
 
-	err = f.client.DoTimeout(req, res, f.timeout)
-	if err != nil {
-		return 0, 0, err
+// decodedSize measures a body before and after decoding. A body that fails
+// to decode counts as is, load tests report sizes, not content errors.
+func decodedSize(encoding string, body []byte) BodySize {
+	size := BodySize{Received: int64(len(body)), Decoded: int64(len(body))}
+	if decoded, err := decodeBody(encoding, body); err == nil {
+		size.Decoded = int64(len(decoded))
 	}
+	return size
+}
+
+// prepareRedirect turns req into the next hop of a redirect, following the
+// same method and credential rules as the interactive client
+func (f *FastClient) prepareRedirect(req *fasthttp.Request, statusCode int, location []byte) {
+	from := string(req.URI().Host())
+	req.URI().UpdateBytes(location)
 
-	return res.StatusCode(), int64(res.Header.ContentLength()), nil
+	method, keepBody := f.redirects.nextMethod(string(req.Header.Method()), statusCode)
+	req.Header.SetMethod(method)
+	if !keepBody {
+		req.ResetBody()
+		req.Header.Del("Content-Type")
+	}
+
+	if f.redirects.stripAuth(from, string(req.URI().Host())) {
+		for _, key := range sensitiveHeaders {
+			req.Header.Del(key)
+		}
+		for _, key := range f.secrets {
+			req.Header.Del(key)
+		}
+	}
 }
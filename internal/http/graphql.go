@@ -0,0 +1,432 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// GRAPHQL is the method of GraphQL requests, which are sent as a POST whose
+// body is the JSON envelope of the query (see GraphQLQuery)
+const GRAPHQL = "GRAPHQL"
+
+// GraphQLQuery is the body of a GraphQL request, as defined by the GraphQL over HTTP spec
+type GraphQLQuery struct {
+	Query         string          `json:"query"`
+	Variables     json.RawMessage `json:"variables,omitempty"`
+	OperationName string          `json:"operationName,omitempty"`
+}
+
+// NewGraphQLBody builds the envelope sent for a query. variables must be a
+// JSON object, or empty.
+func NewGraphQLBody(query, variables, operationName string) (string, error) {
+	body := GraphQLQuery{Query: query, OperationName: strings.TrimSpace(operationName)}
+	if variables = strings.TrimSpace(variables); variables != "" {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(variables), &object); err != nil {
+			return "", errors.New("variables must be a JSON object")
+		}
+		body.Variables = json.RawMessage(variables)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ParseGraphQLBody reads the envelope of a GraphQL request. An empty body is an empty query.
+func ParseGraphQLBody(body string) (GraphQLQuery, error) {
+	var query GraphQLQuery
+	if strings.TrimSpace(body) == "" {
+		return query, nil
+	}
+	if err := json.Unmarshal([]byte(body), &query); err != nil {
+		return query, fmt.Errorf("invalid GraphQL body: %w", err)
+	}
+	return query, nil
+}
+
+// asPost returns the HTTP request a GraphQL request is sent as
+func (r *Request) asPost() *Request {
+	post := r.withDefaultHeader("Content-Type", "application/json")
+	post = post.withDefaultHeader("Accept", "application/graphql-response+json, application/json")
+	if post == r {
+		copied := *r
+		post = &copied
+	}
+	post.Method = POST
+	return post
+}
+
+// GraphQLError is an entry of the errors of a GraphQL response
+type GraphQLError struct {
+	Message   string `json:"message"`
+	Path      []any  `json:"path,omitempty"`
+	Locations []struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	} `json:"locations,omitempty"`
+}
+
+// Where describes where the error happened, e.g. "user.posts.0 at 3:5"
+func (e GraphQLError) Where() string {
+	var parts []string
+	if len(e.Path) > 0 {
+		path := make([]string, len(e.Path))
+		for i, segment := range e.Path {
+			path[i] = fmt.Sprint(segment)
+		}
+		parts = append(parts, strings.Join(path, "."))
+	}
+	if len(e.Locations) > 0 {
+		parts = append(parts, fmt.Sprintf("at %d:%d", e.Locations[0].Line, e.Locations[0].Column))
+	}
+	return strings.Join(parts, " ")
+}
+
+// GraphQLResult is the body of a GraphQL response
+type GraphQLResult struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors []GraphQLError  `json:"errors,omitempty"`
+}
+
+// ParseGraphQLResult reads a GraphQL response body, reporting false when it isn't one
+func ParseGraphQLResult(body string) (*GraphQLResult, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(body), &fields); err != nil {
+		return nil, false
+	}
+	_, hasData := fields["data"]
+	_, hasErrors := fields["errors"]
+	if !hasData && !hasErrors {
+		return nil, false
+	}
+
+	var result GraphQLResult
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		return nil, false
+	}
+	if string(result.Data) == "null" {
+		result.Data = nil
+	}
+	return &result, true
+}
+
+// IntrospectionQuery asks for the types of a schema and their fields
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types {
+      kind
+      name
+      description
+      fields(includeDeprecated: true) {
+        name
+        description
+        args { name }
+        type { ...TypeRef }
+      }
+    }
+  }
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } }
+}`
+
+// GraphQLSchema is what completion needs from an introspected schema
+type GraphQLSchema struct {
+	QueryType        string        `json:"query_type"`
+	MutationType     string        `json:"mutation_type,omitempty"`
+	SubscriptionType string        `json:"subscription_type,omitempty"`
+	Types            []GraphQLType `json:"types"`
+}
+
+// GraphQLType is a named type of a schema
+type GraphQLType struct {
+	Kind        string         `json:"kind"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Fields      []GraphQLField `json:"fields,omitempty"`
+}
+
+// GraphQLField is a field of an object or interface type
+type GraphQLField struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Args        []string `json:"args,omitempty"`
+	Type        string   `json:"type"` // as written in a schema, e.g. [User!]!
+}
+
+// typeRef is a possibly wrapped type in an introspection result
+type typeRef struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	OfType *typeRef `json:"ofType"`
+}
+
+func (t *typeRef) String() string {
+	switch {
+	case t == nil:
+		return ""
+	case t.Kind == "NON_NULL":
+		return t.OfType.String() + "!"
+	case t.Kind == "LIST":
+		return "[" + t.OfType.String() + "]"
+	}
+	return t.Name
+}
+
+// ParseIntrospection reads the response to IntrospectionQuery
+func ParseIntrospection(body []byte) (*GraphQLSchema, error) {
+	var result struct {
+		Data struct {
+			Schema *struct {
+				QueryType        *struct{ Name string } `json:"queryType"`
+				MutationType     *struct{ Name string } `json:"mutationType"`
+				SubscriptionType *struct{ Name string } `json:"subscriptionType"`
+				Types            []struct {
+					Kind        string `json:"kind"`
+					Name        string `json:"name"`
+					Description string `json:"description"`
+					Fields      []struct {
+						Name        string `json:"name"`
+						Description string `json:"description"`
+						Args        []struct{ Name string }
+						Type        *typeRef `json:"type"`
+					} `json:"fields"`
+				} `json:"types"`
+			} `json:"__schema"`
+		} `json:"data"`
+		Errors []GraphQLError `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("invalid introspection response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return nil, fmt.Errorf("introspection failed: %s", result.Errors[0].Message)
+	}
+	raw := result.Data.Schema
+	if raw == nil || raw.QueryType == nil {
+		return nil, errors.New("introspection response has no schema")
+	}
+
+	schema := &GraphQLSchema{QueryType: raw.QueryType.Name}
+	if raw.MutationType != nil {
+		schema.MutationType = raw.MutationType.Name
+	}
+	if raw.SubscriptionType != nil {
+		schema.SubscriptionType = raw.SubscriptionType.Name
+	}
+	for _, t := range raw.Types {
+		graphQLType := GraphQLType{Kind: t.Kind, Name: t.Name, Description: t.Description}
+		for _, f := range t.Fields {
+			field := GraphQLField{Name: f.Name, Description: f.Description, Type: f.Type.String()}
+			for _, arg := range f.Args {
+				field.Args = append(field.Args, arg.Name)
+			}
+			graphQLType.Fields = append(graphQLType.Fields, field)
+		}
+		schema.Types = append(schema.Types, graphQLType)
+	}
+	return schema, nil
+}
+
+// Introspect fetches the schema of the GraphQL endpoint of the request,
+// sending it with the request's headers and options
+func (c *Client) Introspect(req *Request) (*GraphQLSchema, error) {
+	body, err := NewGraphQLBody(IntrospectionQuery, "", "IntrospectionQuery")
+	if err != nil {
+		return nil, err
+	}
+	introspection := *req
+	introspection.Method = GRAPHQL
+	introspection.Body = body
+
+	res := c.do(context.Background(), &introspection, nil)
+	if res.Error != "" {
+		return nil, errors.New(res.Error)
+	}
+	if res.StatusCode >= 400 {
+		if _, ok := ParseGraphQLResult(res.Body); !ok {
+			return nil, fmt.Errorf("introspection failed: %s", res.Status)
+		}
+	}
+	return ParseIntrospection([]byte(res.Body))
+}
+
+// Type returns the named type, or nil
+func (s *GraphQLSchema) Type(name string) *GraphQLType {
+	for i := range s.Types {
+		if s.Types[i].Name == name {
+			return &s.Types[i]
+		}
+	}
+	return nil
+}
+
+// Field returns the named field of the type, or nil
+func (t *GraphQLType) Field(name string) *GraphQLField {
+	for i := range t.Fields {
+		if t.Fields[i].Name == name {
+			return &t.Fields[i]
+		}
+	}
+	return nil
+}
+
+// NamedType returns the type a field holds, without list and non-null wrappers
+func (f *GraphQLField) NamedType() string {
+	return strings.Trim(f.Type, "[]!")
+}
+
+// graphQLKeywords are suggested outside of any selection set
+var graphQLKeywords = []string{"query", "mutation", "subscription", "fragment"}
+
+// Complete suggests what may be typed at the end of before, the query up
+// to the cursor: fields of the enclosing selection set, type names after
+// "on", or operation keywords at the top level. It returns the partial
+// word being completed along with the matching suggestions.
+func (s *GraphQLSchema) Complete(before string) (string, []string) {
+	end := len(before)
+	for end > 0 && (before[end-1] == '_' || isLetter(before[end-1]) || isDigit(before[end-1])) {
+		end--
+	}
+	prefix := before[end:]
+	tokens := graphQLTokens(before[:end])
+
+	var candidates []string
+	if n := len(tokens); n > 0 && tokens[n-1] == "on" {
+		for _, t := range s.Types {
+			if (t.Kind == "OBJECT" || t.Kind == "INTERFACE" || t.Kind == "UNION") && !strings.HasPrefix(t.Name, "__") {
+				candidates = append(candidates, t.Name)
+			}
+		}
+	} else if scope, nested := s.scope(tokens); !nested {
+		candidates = graphQLKeywords
+	} else if t := s.Type(scope); t != nil {
+		for _, field := range t.Fields {
+			candidates = append(candidates, field.Name)
+		}
+	}
+
+	var suggestions []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) && candidate != prefix {
+			suggestions = append(suggestions, candidate)
+		}
+	}
+	return prefix, suggestions
+}
+
+// scope returns the type whose selection set the tokens end in, which is
+// empty when it's unknown. nested is false at the top level.
+func (s *GraphQLSchema) scope(tokens []string) (string, bool) {
+	var stack []string
+	pending := "" // the type the next "{" opens
+	operation := s.QueryType
+	parens := 0
+
+	for i, token := range tokens {
+		switch {
+		case token == "(":
+			parens++
+		case token == ")":
+			parens--
+		case parens > 0:
+			// arguments and variable definitions don't change the scope
+		case token == "{":
+			if pending == "" && len(stack) == 0 {
+				pending = operation
+			}
+			stack = append(stack, pending)
+			pending = ""
+		case token == "}":
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			operation = s.QueryType
+		case len(stack) == 0:
+			switch token {
+			case "query":
+				operation = s.QueryType
+			case "mutation":
+				operation = s.MutationType
+			case "subscription":
+				operation = s.SubscriptionType
+			}
+			if i > 0 && tokens[i-1] == "on" {
+				pending = token // fragment Name on Type
+			}
+		case i > 0 && tokens[i-1] == "on":
+			pending = token // ... on Type
+		case isGraphQLName(token):
+			pending = ""
+			if t := s.Type(stack[len(stack)-1]); t != nil {
+				if field := t.Field(token); field != nil {
+					pending = field.NamedType()
+				}
+			}
+		}
+	}
+
+	if len(stack) == 0 {
+		return "", false
+	}
+	return stack[len(stack)-1], true
+}
+
+// graphQLTokens splits a query into names and punctuation, skipping strings and comments
+func graphQLTokens(query string) []string {
+	var tokens []string
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '#':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case c == '"':
+			i++
+			for i < len(query) && query[i] != '"' {
+				if query[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+		case c == '_' || c == '$' || isLetter(c) || isDigit(c):
+			start := i
+			for i < len(query) && (query[i] == '_' || query[i] == '$' || isLetter(query[i]) || isDigit(query[i])) {
+				i++
+			}
+			tokens = append(tokens, query[start:i])
+		case strings.IndexByte("{}():", c) >= 0:
+			tokens = append(tokens, string(c))
+			i++
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+func isGraphQLName(token string) bool {
+	return token != "" && (token[0] == '_' || isLetter(token[0]))
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
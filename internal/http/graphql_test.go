@@ -0,0 +1,192 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// introspectionResponse describes a schema with users and their posts
+const introspectionResponse = `{"data": {"__schema": {
+	"queryType": {"name": "Query"},
+	"mutationType": {"name": "Mutation"},
+	"subscriptionType": null,
+	"types": [
+		{"kind": "OBJECT", "name": "Query", "fields": [
+			{"name": "user", "args": [{"name": "id"}], "type": {"kind": "OBJECT", "name": "User"}},
+			{"name": "users", "args": [], "type": {"kind": "NON_NULL", "ofType": {"kind": "LIST", "ofType": {"kind": "NON_NULL", "ofType": {"kind": "OBJECT", "name": "User"}}}}}
+		]},
+		{"kind": "OBJECT", "name": "Mutation", "fields": [
+			{"name": "createPost", "args": [{"name": "title"}], "type": {"kind": "OBJECT", "name": "Post"}}
+		]},
+		{"kind": "OBJECT", "name": "User", "fields": [
+			{"name": "id", "args": [], "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}},
+			{"name": "name", "args": [], "type": {"kind": "SCALAR", "name": "String"}},
+			{"name": "posts", "args": [{"name": "first"}], "type": {"kind": "LIST", "ofType": {"kind": "OBJECT", "name": "Post"}}}
+		]},
+		{"kind": "OBJECT", "name": "Post", "fields": [
+			{"name": "id", "args": [], "type": {"kind": "SCALAR", "name": "ID"}},
+			{"name": "title", "args": [], "type": {"kind": "SCALAR", "name": "String"}}
+		]},
+		{"kind": "SCALAR", "name": "ID"},
+		{"kind": "SCALAR", "name": "String"}
+	]
+}}}`
+
+// graphQLServer answers introspection with introspectionResponse and echoes other queries
+func graphQLServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "expected a JSON POST", http.StatusBadRequest)
+			return
+		}
+		var query GraphQLQuery
+		if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/graphql-response+json")
+		if query.OperationName == "IntrospectionQuery" {
+			io.WriteString(w, introspectionResponse)
+			return
+		}
+		echo, _ := json.Marshal(query)
+		w.Write([]byte(`{"data": {"echo": ` + string(echo) + `}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewGraphQLBody(t *testing.T) {
+	body, err := NewGraphQLBody("query User($id: ID!) { user(id: $id) { name } }", `{"id": "1"}`, "User")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"query": "query User($id: ID!) { user(id: $id) { name } }",
+		"variables": {"id": "1"},
+		"operationName": "User"
+	}`, body)
+
+	body, err = NewGraphQLBody("{ users { id } }", " ", "")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"query": "{ users { id } }"}`, body)
+
+	_, err = NewGraphQLBody("{ users { id } }", `[1, 2]`, "")
+	assert.Error(t, err)
+
+	query, err := ParseGraphQLBody(body)
+	assert.NoError(t, err)
+	assert.Equal(t, "{ users { id } }", query.Query)
+
+	assert.Error(t, (&Request{Method: GRAPHQL, URL: "http://localhost", Body: "{not json"}).Validate())
+}
+
+func TestClient_SendGraphQL(t *testing.T) {
+	server := graphQLServer(t)
+	body, _ := NewGraphQLBody("{ users { id } }", `{"first": 2}`, "")
+
+	res := send(InitClient(time.Second, false), &Request{Method: GRAPHQL, URL: server.URL, Body: body})
+	assert.Empty(t, res.Error)
+	assert.True(t, res.GraphQL)
+
+	result, ok := ParseGraphQLResult(res.Body)
+	if assert.True(t, ok) {
+		assert.JSONEq(t, `{"echo": {"query": "{ users { id } }", "variables": {"first": 2}}}`, string(result.Data))
+		assert.Empty(t, result.Errors)
+	}
+}
+
+func TestParseGraphQLResult(t *testing.T) {
+	result, ok := ParseGraphQLResult(`{
+		"data": null,
+		"errors": [{"message": "boom", "path": ["user", "posts", 0], "locations": [{"line": 3, "column": 5}]}]
+	}`)
+	if assert.True(t, ok) {
+		assert.Nil(t, result.Data)
+		if assert.Len(t, result.Errors, 1) {
+			assert.Equal(t, "boom", result.Errors[0].Message)
+			assert.Equal(t, "user.posts.0 at 3:5", result.Errors[0].Where())
+		}
+	}
+
+	_, ok = ParseGraphQLResult(`{"id": 1}`)
+	assert.False(t, ok)
+	_, ok = ParseGraphQLResult(`[1]`)
+	assert.False(t, ok)
+}
+
+func TestClient_Introspect(t *testing.T) {
+	server := graphQLServer(t)
+
+	schema, err := InitClient(time.Second, false).Introspect(&Request{Method: GRAPHQL, URL: server.URL})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "Query", schema.QueryType)
+	assert.Equal(t, "Mutation", schema.MutationType)
+	assert.Empty(t, schema.SubscriptionType)
+
+	users := schema.Type("Query").Field("users")
+	if assert.NotNil(t, users) {
+		assert.Equal(t, "[User!]!", users.Type)
+		assert.Equal(t, "User", users.NamedType())
+	}
+	assert.Equal(t, []string{"first"}, schema.Type("User").Field("posts").Args)
+
+	_, err = ParseIntrospection([]byte(`{"errors": [{"message": "introspection is disabled"}]}`))
+	assert.ErrorContains(t, err, "introspection is disabled")
+}
+
+func TestGraphQLSchema_Complete(t *testing.T) {
+	schema, err := ParseIntrospection([]byte(introspectionResponse))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name   string
+		before string
+		prefix string
+		want   []string
+	}{
+		{"top level", "", "", graphQLKeywords},
+		{"keyword prefix", "mu", "mu", []string{"mutation"}},
+		{"anonymous query", "{ ", "", []string{"user", "users"}},
+		{"root field prefix", "query Users {\n  us", "us", []string{"user", "users"}},
+		{"nested", "{ user(id: \"1\") { ", "", []string{"id", "name", "posts"}},
+		{"nested prefix", "{ users { na", "na", []string{"name"}},
+		{"list of objects", "{ users { posts(first: 2) { ", "", []string{"id", "title"}},
+		{"after a closed selection", "{ users { posts { id } n", "n", []string{"name"}},
+		{"mutation", "mutation { ", "", []string{"createPost"}},
+		{"alias", "{ me: user(id: 1) { po", "po", []string{"posts"}},
+		{"variables are skipped", "query ($id: ID!) { user(id: $id) { ", "", []string{"id", "name", "posts"}},
+		{"fragment", "fragment F on User { ", "", []string{"id", "name", "posts"}},
+		{"type condition", "{ users { ... on P", "P", []string{"Post"}},
+		{"strings are skipped", `{ user(id: "{ }") { i`, "i", []string{"id"}},
+		{"complete word", "{ users { name", "name", nil},
+		{"unknown field", "{ users { friends { ", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix, suggestions := schema.Complete(tt.before)
+			assert.Equal(t, tt.prefix, prefix)
+			assert.Equal(t, tt.want, suggestions)
+		})
+	}
+}
+
+func TestRequest_AsPost(t *testing.T) {
+	req := &Request{Method: GRAPHQL, URL: "http://localhost", Headers: map[string]string{"content-type": "application/vnd.custom+json"}}
+	post := req.asPost()
+
+	assert.Equal(t, POST, post.Method)
+	assert.Equal(t, GRAPHQL, req.Method, "the request itself is left alone")
+	assert.Equal(t, "application/vnd.custom+json", post.Headers["content-type"])
+	assert.True(t, strings.HasPrefix(post.Headers["Accept"], "application/graphql-response+json"))
+}
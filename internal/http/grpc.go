@@ -0,0 +1,865 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/textproto"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// GRPC is the method of gRPC requests. Their URL names the method to call,
+// e.g. grpc://localhost:50051/helloworld.Greeter/SayHello (grpcs:// for TLS),
+// their body is the request message as JSON and their headers are metadata.
+const GRPC = "GRPC"
+
+// reflectionMethods are the server reflection streams, newest first
+var reflectionMethods = []string{
+	"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+}
+
+// GRPCTarget is the parsed URL of a gRPC request
+type GRPCTarget struct {
+	Address string // host:port
+	TLS     bool
+	Service string // fully qualified, e.g. helloworld.Greeter
+	Method  string // empty when only the server is named
+}
+
+// ParseGRPCTarget parses grpc://host:port/pkg.Service/Method URLs
+func ParseGRPCTarget(raw string) (GRPCTarget, error) {
+	var target GRPCTarget
+	rest, ok := strings.CutPrefix(raw, "grpc://")
+	if !ok {
+		if rest, ok = strings.CutPrefix(raw, "grpcs://"); !ok {
+			return target, fmt.Errorf("invalid url: %s (expected grpc:// or grpcs://)", raw)
+		}
+		target.TLS = true
+	}
+
+	address, path, _ := strings.Cut(rest, "/")
+	if address == "" {
+		return target, fmt.Errorf("invalid url: %s (missing host)", raw)
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		port := "80"
+		if target.TLS {
+			port = "443"
+		}
+		address = net.JoinHostPort(address, port)
+	}
+	target.Address = address
+	target.Service, target.Method, _ = strings.Cut(strings.Trim(path, "/"), "/")
+	if strings.Contains(target.Method, "/") {
+		return target, fmt.Errorf("invalid url: %s (expected /pkg.Service/Method)", raw)
+	}
+	return target, nil
+}
+
+// FullMethod returns the method's path, e.g. /helloworld.Greeter/SayHello
+func (t GRPCTarget) FullMethod() string {
+	return "/" + t.Service + "/" + t.Method
+}
+
+// GRPCOptions are the settings of a gRPC request
+type GRPCOptions struct {
+	Protos      []string // .proto files describing the service, instead of reflection
+	ImportPaths []string // where the imports of Protos are looked up
+	Web         bool     // call through gRPC-Web rather than native gRPC
+}
+
+// ParseGRPCOptions reads the grpc.* options
+func ParseGRPCOptions(options map[string]string) (GRPCOptions, error) {
+	web, err := optionBool(options, OptGRPCWeb)
+	if err != nil {
+		return GRPCOptions{}, err
+	}
+	return GRPCOptions{
+		Protos:      strings.Fields(options[OptGRPCProto]),
+		ImportPaths: strings.Fields(options[OptGRPCImport]),
+		Web:         web,
+	}, nil
+}
+
+// GRPCMethod describes a method of a gRPC service
+type GRPCMethod struct {
+	Service         string
+	Name            string
+	Input           string // request message type
+	Output          string // response message type
+	ClientStreaming bool
+	ServerStreaming bool
+}
+
+// FullName returns the method as it appears in URLs, e.g. helloworld.Greeter/SayHello
+func (m GRPCMethod) FullName() string {
+	return m.Service + "/" + m.Name
+}
+
+// String renders the method as in a .proto file
+func (m GRPCMethod) String() string {
+	input, output := m.Input, m.Output
+	if m.ClientStreaming {
+		input = "stream " + input
+	}
+	if m.ServerStreaming {
+		output = "stream " + output
+	}
+	return fmt.Sprintf("%s(%s) returns (%s)", m.FullName(), input, output)
+}
+
+// GRPCCall is a unary call prepared for load tests (see Client.ResolveGRPC)
+type GRPCCall struct {
+	Target   GRPCTarget
+	Payload  []byte // the encoded request message
+	Metadata metadata.MD
+}
+
+// rawMessage carries encoded messages through rawCodec, so that calls don't need generated types
+type rawMessage struct {
+	data []byte
+}
+
+// rawCodec passes messages through as already encoded protobuf
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	return v.(*rawMessage).data, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	// data is only valid until Unmarshal returns
+	v.(*rawMessage).data = bytes.Clone(data)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
+
+// grpcDialOptions returns the credentials and dialer for the target, from the
+// same TLS and proxy options as other requests
+func grpcDialOptions(target GRPCTarget, tlsConfig *tls.Config, dial fasthttp.DialFunc) []grpc.DialOption {
+	creds := insecure.NewCredentials()
+	if target.TLS {
+		config := &tls.Config{}
+		if tlsConfig != nil {
+			config = tlsConfig.Clone()
+		}
+		creds = credentials.NewTLS(config)
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if dial != nil {
+		opts = append(opts, grpc.WithContextDialer(func(_ context.Context, addr string) (net.Conn, error) {
+			return dial(addr)
+		}))
+	}
+	return opts
+}
+
+// dialGRPC opens a connection to the target
+func (c *Client) dialGRPC(req *Request, target GRPCTarget) (*grpc.ClientConn, error) {
+	options := MergeOptions(c.Defaults, req.Options)
+	tlsConfig, err := LoadTLSConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	timeouts, err := ParseTimeouts(options)
+	if err != nil {
+		return nil, err
+	}
+	var dial fasthttp.DialFunc
+	if proxy, err := ParseProxyOptions(options); err != nil {
+		return nil, err
+	} else if !proxy.IsZero() {
+		if dial, err = proxy.DialFunc(cmp.Or(timeouts.Connect, c.Timeout)); err != nil {
+			return nil, err
+		}
+	} else if timeouts.Connect > 0 {
+		dialer := &net.Dialer{Timeout: timeouts.Connect}
+		dial = func(addr string) (net.Conn, error) {
+			return dialer.Dial("tcp", addr)
+		}
+	}
+	return grpc.NewClient(target.Address, grpcDialOptions(target, tlsConfig, dial)...)
+}
+
+// grpcFiles returns the descriptors of the server's services: those compiled
+// from the grpc.proto files, or else those the server describes through
+// reflection. service limits reflection to one service, when not empty.
+func grpcFiles(ctx context.Context, conn *grpc.ClientConn, opts GRPCOptions, service string) (*protoregistry.Files, error) {
+	if len(opts.Protos) > 0 {
+		return compileProtos(ctx, opts)
+	}
+	if conn == nil {
+		return nil, fmt.Errorf("gRPC-Web can't use server reflection, set %s to the service's .proto files", OptGRPCProto)
+	}
+
+	for _, method := range reflectionMethods {
+		files, err := reflectFiles(ctx, conn, method, service)
+		if status.Code(err) == codes.Unimplemented {
+			continue
+		}
+		return files, err
+	}
+	return nil, fmt.Errorf("the server doesn't support reflection, set %s to the service's .proto files", OptGRPCProto)
+}
+
+// compileProtos parses the .proto files, looking up imports next to each file and in the import paths
+func compileProtos(ctx context.Context, opts GRPCOptions) (*protoregistry.Files, error) {
+	importPaths := slices.Clone(opts.ImportPaths)
+	names := make([]string, 0, len(opts.Protos))
+	for _, path := range opts.Protos {
+		dir, name := filepath.Split(path)
+		if dir == "" {
+			dir = "."
+		}
+		if !slices.Contains(importPaths, dir) {
+			importPaths = append(importPaths, dir)
+		}
+		names = append(names, name)
+	}
+
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: importPaths}),
+	}
+	compiled, err := compiler.Compile(ctx, names...)
+	if err != nil {
+		return nil, err
+	}
+	files := new(protoregistry.Files)
+	for _, file := range compiled {
+		if err := files.RegisterFile(file); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// reflectFiles asks the server for the files declaring its services, and those they import
+func reflectFiles(ctx context.Context, conn *grpc.ClientConn, method, service string) (*protoregistry.Files, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}, method)
+	if err != nil {
+		return nil, err
+	}
+	ask := func(req *rpb.ServerReflectionRequest) (*rpb.ServerReflectionResponse, error) {
+		if err := stream.SendMsg(req); err != nil {
+			return nil, err
+		}
+		res := new(rpb.ServerReflectionResponse)
+		if err := stream.RecvMsg(res); err != nil {
+			return nil, err
+		}
+		if e := res.GetErrorResponse(); e != nil {
+			return nil, status.Error(codes.Code(e.GetErrorCode()), e.GetErrorMessage())
+		}
+		return res, nil
+	}
+
+	services := []string{service}
+	if service == "" {
+		res, err := ask(&rpb.ServerReflectionRequest{
+			MessageRequest: &rpb.ServerReflectionRequest_ListServices{ListServices: "*"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		services = services[:0]
+		for _, s := range res.GetListServicesResponse().GetService() {
+			if !strings.HasPrefix(s.GetName(), "grpc.reflection.") {
+				services = append(services, s.GetName())
+			}
+		}
+	}
+
+	protos := make(map[string]*descriptorpb.FileDescriptorProto)
+	add := func(res *rpb.ServerReflectionResponse) error {
+		for _, raw := range res.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			file := new(descriptorpb.FileDescriptorProto)
+			if err := proto.Unmarshal(raw, file); err != nil {
+				return err
+			}
+			protos[file.GetName()] = file
+		}
+		return nil
+	}
+	for _, symbol := range services {
+		res, err := ask(&rpb.ServerReflectionRequest{
+			MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+		})
+		if status.Code(err) == codes.NotFound {
+			return nil, fmt.Errorf("unknown service: %s", symbol)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := add(res); err != nil {
+			return nil, err
+		}
+	}
+
+	// Servers usually send the imports along, but aren't required to
+	for {
+		var missing []string
+		for _, file := range protos {
+			for _, dep := range file.GetDependency() {
+				if _, ok := protos[dep]; !ok && !slices.Contains(missing, dep) {
+					missing = append(missing, dep)
+				}
+			}
+		}
+		if len(missing) == 0 {
+			break
+		}
+		for _, name := range missing {
+			res, err := ask(&rpb.ServerReflectionRequest{
+				MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: name},
+			})
+			if err == nil {
+				err = add(res)
+			}
+			if _, ok := protos[name]; ok {
+				continue
+			}
+			// Well-known types are compiled in
+			known, findErr := protoregistry.GlobalFiles.FindFileByPath(name)
+			if findErr != nil {
+				if err == nil {
+					err = findErr
+				}
+				return nil, fmt.Errorf("resolving %s: %w", name, err)
+			}
+			protos[name] = protodesc.ToFileDescriptorProto(known)
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, file := range protos {
+		set.File = append(set.File, file)
+	}
+	return protodesc.NewFiles(set)
+}
+
+// findGRPCMethod looks the target's method up in the descriptors
+func findGRPCMethod(files *protoregistry.Files, target GRPCTarget) (protoreflect.MethodDescriptor, error) {
+	if target.Service == "" || target.Method == "" {
+		return nil, fmt.Errorf("the url must name a method, e.g. grpc://%s/pkg.Service/Method", target.Address)
+	}
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(target.Service))
+	if err != nil {
+		return nil, fmt.Errorf("unknown service: %s", target.Service)
+	}
+	service, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("not a service: %s", target.Service)
+	}
+	method := service.Methods().ByName(protoreflect.Name(target.Method))
+	if method == nil {
+		return nil, fmt.Errorf("unknown method: %s/%s", target.Service, target.Method)
+	}
+	if method.IsStreamingClient() {
+		return nil, fmt.Errorf("%s/%s: client streaming calls aren't supported", target.Service, target.Method)
+	}
+	return method, nil
+}
+
+// encodeGRPCMessage builds the method's request message from JSON, an empty body being an empty message
+func encodeGRPCMessage(method protoreflect.MethodDescriptor, body string) ([]byte, error) {
+	message := dynamicpb.NewMessage(method.Input())
+	if strings.TrimSpace(body) != "" {
+		if err := protojson.Unmarshal([]byte(body), message); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", method.Input().FullName(), err)
+		}
+	}
+	return proto.Marshal(message)
+}
+
+// decodeGRPCMessage renders a response message as indented JSON
+func decodeGRPCMessage(method protoreflect.MethodDescriptor, data []byte) (string, error) {
+	message := dynamicpb.NewMessage(method.Output())
+	if err := proto.Unmarshal(data, message); err != nil {
+		return "", err
+	}
+	out, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(message)
+	return string(out), err
+}
+
+// grpcMetadata turns request headers into metadata, whose keys are lowercase
+func grpcMetadata(headers map[string]string) metadata.MD {
+	md := make(metadata.MD, len(headers))
+	for key, value := range headers {
+		md.Append(strings.ToLower(key), value)
+	}
+	return md
+}
+
+// headerFromMetadata turns metadata into headers, for the headers tab
+func headerFromMetadata(md metadata.MD) http.Header {
+	header := make(http.Header, len(md))
+	for key, values := range md {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+	return header
+}
+
+// grpcHTTPStatus maps a gRPC status code to the closest HTTP status, following
+// the gRPC gateway, so that responses are colored like any other
+func grpcHTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ListGRPCMethods lists the methods of the services at the request's address
+func (c *Client) ListGRPCMethods(ctx context.Context, req *Request) ([]GRPCMethod, error) {
+	target, err := ParseGRPCTarget(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := ParseGRPCOptions(MergeOptions(c.Defaults, req.Options))
+	if err != nil {
+		return nil, err
+	}
+	var conn *grpc.ClientConn
+	if !opts.Web {
+		if conn, err = c.dialGRPC(req, target); err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+	}
+	if timeout := c.timeout(MergeOptions(c.Defaults, req.Options)); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	files, err := grpcFiles(ctx, conn, opts, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var methods []GRPCMethod
+	files.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		for i := range file.Services().Len() {
+			service := file.Services().Get(i)
+			for j := range service.Methods().Len() {
+				method := service.Methods().Get(j)
+				methods = append(methods, GRPCMethod{
+					Service:         string(service.FullName()),
+					Name:            string(method.Name()),
+					Input:           string(method.Input().FullName()),
+					Output:          string(method.Output().FullName()),
+					ClientStreaming: method.IsStreamingClient(),
+					ServerStreaming: method.IsStreamingServer(),
+				})
+			}
+		}
+		return true
+	})
+	slices.SortFunc(methods, func(a, b GRPCMethod) int {
+		return strings.Compare(a.FullName(), b.FullName())
+	})
+	return methods, nil
+}
+
+// ResolveGRPC prepares the request's call for load tests, which only support
+// unary methods of native gRPC servers
+func (c *Client) ResolveGRPC(ctx context.Context, req *Request) (*GRPCCall, error) {
+	req, err := c.Unseal(req)
+	if err != nil {
+		return nil, err
+	}
+	target, err := ParseGRPCTarget(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := ParseGRPCOptions(MergeOptions(c.Defaults, req.Options))
+	if err != nil {
+		return nil, err
+	}
+	if opts.Web {
+		return nil, errors.New("load tests don't support gRPC-Web")
+	}
+	conn, err := c.dialGRPC(req, target)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if timeout := c.timeout(MergeOptions(c.Defaults, req.Options)); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	files, err := grpcFiles(ctx, conn, opts, target.Service)
+	if err != nil {
+		return nil, err
+	}
+	method, err := findGRPCMethod(files, target)
+	if err != nil {
+		return nil, err
+	}
+	if method.IsStreamingServer() {
+		return nil, fmt.Errorf("%s: load tests only support unary calls", target.FullMethod())
+	}
+	payload, err := encodeGRPCMessage(method, req.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &GRPCCall{Target: target, Payload: payload, Metadata: grpcMetadata(req.Headers)}, nil
+}
+
+// doGRPC calls the request's method, delivering the messages of server
+// streams to updates when it is non-nil
+func (c *Client) doGRPC(parent context.Context, req *Request, updates chan<- StreamUpdate) *Response {
+	req, err := c.Unseal(req)
+	if err != nil {
+		return &Response{Error: err.Error()}
+	}
+	target, err := ParseGRPCTarget(req.URL)
+	if err != nil {
+		return &Response{Error: err.Error()}
+	}
+	opts, err := ParseGRPCOptions(MergeOptions(c.Defaults, req.Options))
+	if err != nil {
+		return &Response{Error: err.Error()}
+	}
+
+	// Like other streams, server streams are only timed until their headers arrive
+	ctx, cancel := context.WithCancelCause(parent)
+	defer cancel(nil)
+	var deadline *time.Timer
+	if timeout := c.timeout(MergeOptions(c.Defaults, req.Options)); timeout > 0 {
+		deadline = time.AfterFunc(timeout, func() { cancel(context.DeadlineExceeded) })
+		defer deadline.Stop()
+	}
+
+	var conn *grpc.ClientConn
+	if !opts.Web {
+		if conn, err = c.dialGRPC(req, target); err != nil {
+			return &Response{Error: err.Error()}
+		}
+		defer conn.Close()
+	}
+	files, err := grpcFiles(ctx, conn, opts, target.Service)
+	if err != nil {
+		return &Response{Error: err.Error()}
+	}
+	method, err := findGRPCMethod(files, target)
+	if err != nil {
+		return &Response{Error: err.Error()}
+	}
+	payload, err := encodeGRPCMessage(method, req.Body)
+	if err != nil {
+		return &Response{Error: err.Error()}
+	}
+
+	protocol := "gRPC"
+	if opts.Web {
+		protocol = "gRPC-Web"
+	}
+	response := &Response{Proto: protocol, URL: req.URL, GRPC: true}
+	streaming := method.IsStreamingServer()
+	var messages []string
+
+	onHeader := func(header http.Header) {
+		response.Headers = header
+		if streaming {
+			if deadline != nil {
+				deadline.Stop()
+			}
+			if updates != nil {
+				headers := *response
+				headers.Status, headers.StatusCode = "streaming", http.StatusOK
+				updates <- StreamUpdate{Response: &headers}
+			}
+		}
+	}
+	onMessage := func(data []byte) error {
+		message, err := decodeGRPCMessage(method, data)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, message)
+		if streaming {
+			event := StreamEvent{Time: time.Now(), Event: "message", Data: message}
+			response.Events = append(response.Events, event)
+			if updates != nil {
+				updates <- StreamUpdate{Event: &event}
+			}
+		}
+		return nil
+	}
+
+	start := time.Now()
+	var trailer http.Header
+	if opts.Web {
+		trailer, err = c.callGRPCWeb(ctx, req, target, payload, onHeader, onMessage)
+	} else {
+		trailer, err = callGRPC(ctx, conn, target, streaming, payload, grpcMetadata(req.Headers), onHeader, onMessage)
+	}
+	response.Duration = time.Since(start)
+
+	// Stopping a stream keeps what was received, like for Server-Sent Events
+	if streaming && parent.Err() != nil {
+		err = nil
+	} else if errors.Is(context.Cause(ctx), context.DeadlineExceeded) {
+		err = status.Error(codes.DeadlineExceeded, "timed out")
+	}
+
+	st := status.Convert(err)
+	response.Status = fmt.Sprintf("%d %s", st.Code(), st.Code())
+	response.StatusCode = grpcHTTPStatus(st.Code())
+	switch st.Code() {
+	case codes.DeadlineExceeded:
+		response.failure = FailureTimeout
+	case codes.Unavailable:
+		response.failure = FailureConnection
+	}
+	response.Trailers = trailer
+	if response.Trailers == nil {
+		response.Trailers = make(http.Header)
+	}
+	response.Trailers.Set("Grpc-Status", strconv.Itoa(int(st.Code())))
+	if st.Message() != "" {
+		response.Trailers.Set("Grpc-Message", st.Message())
+	}
+	response.Streamed = streaming && updates != nil
+
+	if st.Code() != codes.OK && len(messages) == 0 {
+		// The status, with its details, stands in for the missing message
+		body, _ := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(st.Proto())
+		response.Body = string(body)
+	} else {
+		response.Body = strings.Join(messages, "\n")
+	}
+	return response
+}
+
+// callGRPC makes a native gRPC call, returning its trailers and status error
+func callGRPC(
+	ctx context.Context,
+	conn *grpc.ClientConn,
+	target GRPCTarget,
+	streaming bool,
+	payload []byte,
+	md metadata.MD,
+	onHeader func(http.Header),
+	onMessage func([]byte) error,
+) (http.Header, error) {
+	ctx = metadata.NewOutgoingContext(ctx, md)
+	codec := grpc.ForceCodec(rawCodec{})
+
+	if !streaming {
+		var header, trailer metadata.MD
+		out := new(rawMessage)
+		err := conn.Invoke(ctx, target.FullMethod(), &rawMessage{data: payload}, out, codec, grpc.Header(&header), grpc.Trailer(&trailer))
+		onHeader(headerFromMetadata(header))
+		if err == nil {
+			err = onMessage(out.data)
+		}
+		return headerFromMetadata(trailer), err
+	}
+
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, target.FullMethod(), codec)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(&rawMessage{data: payload}); err != nil && err != io.EOF {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	header, err := stream.Header()
+	onHeader(headerFromMetadata(header))
+	for err == nil {
+		out := new(rawMessage)
+		if err = stream.RecvMsg(out); err == nil {
+			err = onMessage(out.data)
+		}
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	return headerFromMetadata(stream.Trailer()), err
+}
+
+// callGRPCWeb makes a gRPC-Web call over HTTP, returning its trailers and status error
+func (c *Client) callGRPCWeb(
+	ctx context.Context,
+	req *Request,
+	target GRPCTarget,
+	payload []byte,
+	onHeader func(http.Header),
+	onMessage func([]byte) error,
+) (http.Header, error) {
+	transport, err := c.parseTransportOptions(req)
+	if err != nil {
+		return nil, err
+	}
+	client, err := c.clientFor(transport)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := "http://"
+	if target.TLS {
+		scheme = "https://"
+	}
+	body := appendGRPCFrame(nil, 0, payload)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, scheme+target.Address+target.FullMethod(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range req.Headers {
+		httpReq.Header.Set(key, value)
+	}
+	httpReq.Header.Set("Content-Type", "application/grpc-web+proto")
+	httpReq.Header.Set("Accept", "application/grpc-web+proto")
+	httpReq.Header.Set("X-Grpc-Web", "1")
+
+	res, err := client.Do(httpReq)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	defer res.Body.Close()
+	onHeader(res.Header)
+	if res.StatusCode != http.StatusOK {
+		return nil, status.Error(httpStatusCode(res.StatusCode), res.Status)
+	}
+
+	// A trailers-only answer carries the status in its headers
+	trailer := make(http.Header)
+	reader := bufio.NewReader(res.Body)
+	for {
+		flags, data, err := readGRPCFrame(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return trailer, err
+		}
+		if flags&0x80 != 0 {
+			parsed, err := textproto.NewReader(bufio.NewReader(io.MultiReader(bytes.NewReader(data), strings.NewReader("\r\n")))).ReadMIMEHeader()
+			if err != nil && err != io.EOF {
+				return trailer, err
+			}
+			trailer = http.Header(parsed)
+			continue
+		}
+		if err := onMessage(data); err != nil {
+			return trailer, err
+		}
+	}
+
+	statusHeader := trailer
+	if statusHeader.Get("Grpc-Status") == "" {
+		statusHeader = res.Header
+	}
+	code, err := strconv.Atoi(statusHeader.Get("Grpc-Status"))
+	if err != nil {
+		return trailer, status.Error(codes.Internal, "missing grpc-status")
+	}
+	if code != int(codes.OK) {
+		return trailer, status.Error(codes.Code(code), statusHeader.Get("Grpc-Message"))
+	}
+	return trailer, nil
+}
+
+// httpStatusCode maps HTTP errors of gRPC-Web proxies to gRPC codes
+func httpStatusCode(code int) codes.Code {
+	switch code {
+	case http.StatusBadRequest:
+		return codes.Internal
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.Unimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
+
+// appendGRPCFrame appends a length-prefixed message, as framed on the wire
+func appendGRPCFrame(b []byte, flags byte, data []byte) []byte {
+	b = append(b, flags)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(data)))
+	return append(b, data...)
+}
+
+// readGRPCFrame reads a length-prefixed message, io.EOF meaning there are no more
+func readGRPCFrame(r io.Reader) (flags byte, data []byte, err error) {
+	var prefix [5]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = errors.New("truncated gRPC-Web frame")
+		}
+		return 0, nil, err
+	}
+	data = make([]byte, binary.BigEndian.Uint32(prefix[1:]))
+	if _, err := io.ReadFull(r, data); err != nil {
+		return 0, nil, errors.New("truncated gRPC-Web frame")
+	}
+	return prefix[0], data, nil
+}
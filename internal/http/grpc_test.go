@@ -0,0 +1,342 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	rpbalpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// greeterProto declares a service with a unary and a server-streaming method
+const greeterProto = `syntax = "proto3";
+package volt.test;
+
+import "google/protobuf/timestamp.proto";
+
+service Greeter {
+  rpc SayHello(HelloRequest) returns (HelloReply);
+  rpc Count(HelloRequest) returns (stream HelloReply);
+}
+
+message HelloRequest {
+  string name = 1;
+  int32 times = 2;
+}
+
+message HelloReply {
+  string message = 1;
+  google.protobuf.Timestamp at = 2;
+}
+`
+
+// writeGreeterProto writes greeterProto to a temporary directory and compiles it
+func writeGreeterProto(t *testing.T) (string, *protoregistry.Files, protoreflect.ServiceDescriptor) {
+	path := filepath.Join(t.TempDir(), "greeter.proto")
+	require.NoError(t, os.WriteFile(path, []byte(greeterProto), 0o600))
+
+	files, err := compileProtos(context.Background(), GRPCOptions{Protos: []string{path}})
+	require.NoError(t, err)
+	descriptor, err := files.FindDescriptorByName("volt.test.Greeter")
+	require.NoError(t, err)
+	return path, files, descriptor.(protoreflect.ServiceDescriptor)
+}
+
+// helloReply builds the HelloReply greeting name
+func helloReply(service protoreflect.ServiceDescriptor, name string) *dynamicpb.Message {
+	out := dynamicpb.NewMessage(service.Methods().ByName("SayHello").Output())
+	out.Set(out.Descriptor().Fields().ByName("message"), protoreflect.ValueOfString("hello "+name))
+	return out
+}
+
+// grpcServer serves the Greeter of greeterProto, returning its address and
+// the path of its .proto file. SayHello fails with NotFound for "nobody" and
+// echoes the x-user metadata in its header. Reflection is served by the v1
+// service, or only by v1alpha when alpha is set.
+func grpcServer(t *testing.T, alpha bool) (string, string) {
+	path, files, service := writeGreeterProto(t)
+	hello := service.Methods().ByName("SayHello")
+	field := func(in *dynamicpb.Message, name protoreflect.Name) protoreflect.Value {
+		return in.Get(in.Descriptor().Fields().ByName(name))
+	}
+
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: string(service.FullName()),
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "SayHello",
+			Handler: func(_ any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+				in := dynamicpb.NewMessage(hello.Input())
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				md, _ := metadata.FromIncomingContext(ctx)
+				grpc.SetHeader(ctx, metadata.Pairs("x-user", strings.Join(md.Get("x-user"), ",")))
+				grpc.SetTrailer(ctx, metadata.Pairs("x-served-by", "greeter"))
+
+				if name := field(in, "name").String(); name != "nobody" {
+					return helloReply(service, name), nil
+				}
+				return nil, status.Error(codes.NotFound, "nobody to greet")
+			},
+		}},
+		Streams: []grpc.StreamDesc{{
+			StreamName:    "Count",
+			ServerStreams: true,
+			Handler: func(_ any, stream grpc.ServerStream) error {
+				in := dynamicpb.NewMessage(hello.Input())
+				if err := stream.RecvMsg(in); err != nil {
+					return err
+				}
+				for i := range field(in, "times").Int() {
+					if err := stream.SendMsg(helloReply(service, fmt.Sprintf("%s #%d", field(in, "name").String(), i+1))); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		}},
+	}, nil)
+
+	opts := reflection.ServerOptions{Services: server, DescriptorResolver: files}
+	if alpha {
+		rpbalpha.RegisterServerReflectionServer(server, reflection.NewServer(opts))
+	} else {
+		rpb.RegisterServerReflectionServer(server, reflection.NewServerV1(opts))
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return lis.Addr().String(), path
+}
+
+func TestParseGRPCTarget(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    GRPCTarget
+		wantErr bool
+	}{
+		{"grpc://localhost:50051/volt.test.Greeter/SayHello", GRPCTarget{Address: "localhost:50051", Service: "volt.test.Greeter", Method: "SayHello"}, false},
+		{"grpcs://api.example.com/volt.test.Greeter/SayHello", GRPCTarget{Address: "api.example.com:443", TLS: true, Service: "volt.test.Greeter", Method: "SayHello"}, false},
+		{"grpc://localhost:50051", GRPCTarget{Address: "localhost:50051"}, false},
+		{"grpc://localhost:50051/volt.test.Greeter", GRPCTarget{Address: "localhost:50051", Service: "volt.test.Greeter"}, false},
+		{"http://localhost:50051/volt.test.Greeter/SayHello", GRPCTarget{}, true},
+		{"grpc:///volt.test.Greeter/SayHello", GRPCTarget{}, true},
+		{"grpc://localhost/a/b/c", GRPCTarget{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			target, err := ParseGRPCTarget(tt.raw)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, target)
+		})
+	}
+}
+
+func TestClient_SendGRPC(t *testing.T) {
+	address, _ := grpcServer(t, false)
+	client := InitClient(5*time.Second, false)
+
+	res := send(client, &Request{
+		Method:  GRPC,
+		URL:     "grpc://" + address + "/volt.test.Greeter/SayHello",
+		Headers: map[string]string{"X-User": "volt"},
+		Body:    `{"name": "volt"}`,
+	})
+	require.Empty(t, res.Error)
+	assert.True(t, res.GRPC)
+	assert.Equal(t, "gRPC", res.Proto)
+	assert.Equal(t, "0 OK", res.Status)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.JSONEq(t, `{"message": "hello volt"}`, res.Body)
+	assert.Equal(t, "volt", res.Headers.Get("X-User"), "metadata is sent, and header metadata shown")
+	assert.Equal(t, "greeter", res.Trailers.Get("X-Served-By"))
+	assert.Equal(t, "0", res.Trailers.Get("Grpc-Status"))
+
+	res = send(client, &Request{Method: GRPC, URL: "grpc://" + address + "/volt.test.Greeter/SayHello", Body: `{"name": "nobody"}`})
+	require.Empty(t, res.Error, "a status other than OK is still a response")
+	assert.Equal(t, "5 NotFound", res.Status)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	assert.Equal(t, "nobody to greet", res.Trailers.Get("Grpc-Message"))
+	assert.Contains(t, res.Body, "nobody to greet")
+
+	res = send(client, &Request{Method: GRPC, URL: "grpc://" + address + "/volt.test.Greeter/SayHello", Body: `{"nickname": "volt"}`})
+	assert.Contains(t, res.Error, "volt.test.HelloRequest")
+
+	res = send(client, &Request{Method: GRPC, URL: "grpc://" + address + "/volt.test.Greeter/Wave"})
+	assert.Equal(t, "unknown method: volt.test.Greeter/Wave", res.Error)
+}
+
+func TestClient_StreamGRPC(t *testing.T) {
+	// v1alpha reflection only, and proto files take precedence over it anyway
+	address, path := grpcServer(t, true)
+	client := InitClient(5*time.Second, false)
+
+	for _, options := range []map[string]string{nil, {OptGRPCProto: path}} {
+		received, final := collect(client, context.Background(), &Request{
+			Method:  GRPC,
+			URL:     "grpc://" + address + "/volt.test.Greeter/Count",
+			Body:    `{"name": "volt", "times": 3}`,
+			Options: options,
+		})
+		require.Empty(t, final.Error)
+		assert.Equal(t, "0 OK", final.Status)
+		assert.True(t, final.Streamed)
+		if assert.Len(t, received, 4, "headers, then one update per message") {
+			assert.Equal(t, "streaming", received[0].Response.Status)
+			assert.JSONEq(t, `{"message": "hello volt #3"}`, received[3].Event.Data)
+		}
+		assert.Len(t, final.Events, 3)
+	}
+}
+
+func TestClient_ListGRPCMethods(t *testing.T) {
+	address, path := grpcServer(t, false)
+	client := InitClient(5*time.Second, false)
+
+	for _, options := range []map[string]string{nil, {OptGRPCProto: path}} {
+		methods, err := client.ListGRPCMethods(context.Background(), &Request{Method: GRPC, URL: "grpc://" + address, Options: options})
+		require.NoError(t, err)
+		if assert.Len(t, methods, 2) {
+			assert.Equal(t, "volt.test.Greeter/Count(volt.test.HelloRequest) returns (stream volt.test.HelloReply)", methods[0].String())
+			assert.Equal(t, "volt.test.Greeter/SayHello", methods[1].FullName())
+		}
+	}
+}
+
+func TestClient_SendGRPCWeb(t *testing.T) {
+	path, _, service := writeGreeterProto(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/volt.test.Greeter/SayHello" || r.Header.Get("Content-Type") != "application/grpc-web+proto" {
+			http.Error(w, "not a gRPC-Web call", http.StatusNotFound)
+			return
+		}
+		_, data, err := readGRPCFrame(r.Body)
+		require.NoError(t, err)
+		in := dynamicpb.NewMessage(service.Methods().ByName("SayHello").Input())
+		require.NoError(t, proto.Unmarshal(data, in))
+		out, _ := proto.Marshal(helloReply(service, in.Get(in.Descriptor().Fields().ByName("name")).String()))
+
+		w.Header().Set("Content-Type", "application/grpc-web+proto")
+		body := appendGRPCFrame(nil, 0, out)
+		body = appendGRPCFrame(body, 0x80, []byte("grpc-status: 0\r\nx-served-by: web\r\n"))
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	address := strings.TrimPrefix(server.URL, "http://")
+	res := send(InitClient(5*time.Second, false), &Request{
+		Method:  GRPC,
+		URL:     "grpc://" + address + "/volt.test.Greeter/SayHello",
+		Body:    `{"name": "web"}`,
+		Options: map[string]string{OptGRPCWeb: "true", OptGRPCProto: path},
+	})
+	require.Empty(t, res.Error)
+	assert.Equal(t, "gRPC-Web", res.Proto)
+	assert.Equal(t, "0 OK", res.Status)
+	assert.JSONEq(t, `{"message": "hello web"}`, res.Body)
+	assert.Equal(t, "web", res.Trailers.Get("X-Served-By"))
+
+	res = send(InitClient(5*time.Second, false), &Request{
+		Method:  GRPC,
+		URL:     "grpc://" + address + "/volt.test.Greeter/SayHello",
+		Options: map[string]string{OptGRPCWeb: "true"},
+	})
+	assert.Contains(t, res.Error, OptGRPCProto, "gRPC-Web needs proto files")
+}
+
+func TestGRPCFrames(t *testing.T) {
+	body := appendGRPCFrame(nil, 0, []byte("hi"))
+	body = appendGRPCFrame(body, 0x80, nil)
+	r := bytes.NewReader(body)
+
+	flags, data, err := readGRPCFrame(r)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(0), flags)
+	assert.Equal(t, "hi", string(data))
+
+	flags, data, err = readGRPCFrame(r)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(0x80), flags)
+	assert.Empty(t, data)
+
+	_, _, err = readGRPCFrame(r)
+	assert.Equal(t, io.EOF, err)
+	_, _, err = readGRPCFrame(bytes.NewReader(body[:4]))
+	assert.Error(t, err)
+}
+
+func TestJobConfig_RunGRPC(t *testing.T) {
+	address, _ := grpcServer(t, false)
+	client := InitClient(5*time.Second, false)
+
+	for _, tt := range []struct {
+		name     string
+		body     string
+		failures int
+	}{
+		{"ok", `{"name": "bench"}`, 0},
+		{"not found", `{"name": "nobody"}`, 20},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			call, err := client.ResolveGRPC(context.Background(), &Request{
+				Method: GRPC,
+				URL:    "grpc://" + address + "/volt.test.Greeter/SayHello",
+				Body:   tt.body,
+			})
+			require.NoError(t, err)
+
+			config := &JobConfig{
+				Request:       &Request{Method: GRPC, URL: "grpc://" + address},
+				Concurrency:   4,
+				TotalRequests: 20,
+				Timeout:       5 * time.Second,
+				GRPC:          call,
+			}
+			updates := make(chan *LoadTestStats, 10)
+			go config.Run(updates)
+			var stats *LoadTestStats
+			for stats = range updates {
+			}
+
+			assert.Equal(t, 20, stats.CompletedRequests)
+			assert.Equal(t, tt.failures, stats.FailedRequests)
+			if tt.failures > 0 {
+				assert.Equal(t, map[string]int64{"gRPC NotFound": int64(tt.failures)}, stats.Errors,
+					"gRPC codes aren't reported as HTTP status codes")
+			}
+		})
+	}
+
+	_, err := client.ResolveGRPC(context.Background(), &Request{Method: GRPC, URL: "grpc://" + address + "/volt.test.Greeter/Count"})
+	assert.ErrorContains(t, err, "unary")
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"context"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCClient is the gRPC load test backend. Like H2Client, every worker's
+// calls are multiplexed over a shared connection.
+type GRPCClient struct {
+	conn    *grpc.ClientConn
+	err     error // why the connection couldn't be set up, returned by every call
+	call    *GRPCCall
+	timeout time.Duration
+}
+
+// NewGRPCClient creates a client making the job's gRPC call
+func NewGRPCClient(timeout time.Duration, s *JobConfig) *GRPCClient {
+	conn, err := grpc.NewClient(s.GRPC.Target.Address, grpcDialOptions(s.GRPC.Target, s.TLSConfig, s.Dial)...)
+	return &GRPCClient{conn: conn, err: err, call: s.GRPC, timeout: timeout}
+}
+
+// Do makes the call. A status other than OK is an error, reported with its
+// gRPC code as the status. fr, req, res and jar are unused.
+func (g *GRPCClient) Do(
+	_ *FastRequest,
+	_ *fasthttp.Request,
+	_ *fasthttp.Response,
+	_ *WorkerCookieJar,
+	_ bool,
+) (int, BodySize, error) {
+	if g.err != nil {
+		return 0, BodySize{}, g.err
+	}
+
+	ctx := metadata.NewOutgoingContext(context.Background(), g.call.Metadata)
+	if g.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.timeout)
+		defer cancel()
+	}
+
+	out := new(rawMessage)
+	err := g.conn.Invoke(ctx, g.call.Target.FullMethod(), &rawMessage{data: g.call.Payload}, out, grpc.ForceCodec(rawCodec{}))
+	size := BodySize{Received: int64(len(out.data)), Decoded: int64(len(out.data))}
+	if err != nil {
+		return int(status.Code(err)), size, err
+	}
+	return 0, size, nil
+}
@@ -0,0 +1,147 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// LoadClient sends the requests of a load test. The fasthttp request and
+// response are owned by the worker and reused across calls. Decoding the
+// body costs CPU, so it is only measured when decode is set.
+type LoadClient interface {
+	Do(fr *FastRequest, req *fasthttp.Request, res *fasthttp.Response, jar *WorkerCookieJar, decode bool) (status int, size BodySize, err error)
+}
+
+// BodySize is the size of a load test response body
+type BodySize struct {
+	Received int64 // bytes on the wire
+	Decoded  int64 // after undoing Content-Encoding, equal to Received unless decoded
+}
+
+// H2Client is the HTTP/2 load test backend. fasthttp only speaks HTTP/1.1,
+// so this one uses net/http, multiplexing every worker's requests as streams
+// over a few shared connections.
+type H2Client struct {
+	client    *http.Client
+	timeout   time.Duration
+	redirects RedirectPolicy
+	secrets   []string // like FastClient's
+}
+
+// NewH2Client creates an HTTP/2 client for the job, over TLS or h2c depending on its protocol
+func NewH2Client(timeout time.Duration, s *JobConfig) *H2Client {
+	tr := &http.Transport{
+		TLSClientConfig: s.TLSConfig,
+		Protocols:       transportProtocols(s.Protocol),
+		IdleConnTimeout: 10 * time.Second,
+		// For HTTP/2 this only limits dials in flight, so workers starting
+		// together wait for one connection instead of each opening their own
+		MaxConnsPerHost:    1,
+		DisableCompression: true,
+	}
+	if s.Dial != nil {
+		dial := s.Dial
+		tr.DialContext = func(_ context.Context, _, addr string) (net.Conn, error) {
+			return dial(addr)
+		}
+	}
+
+	return &H2Client{
+		timeout:   timeout,
+		redirects: s.Redirects,
+		secrets:   s.secretHeaders(),
+		client: &http.Client{
+			Transport: tr,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Do sends fr, following redirects like FastClient. req and res are unused.
+func (h *H2Client) Do(
+	fr *FastRequest,
+	_ *fasthttp.Request,
+	_ *fasthttp.Response,
+	jar *WorkerCookieJar,
+	decode bool,
+) (status int, size BodySize, err error) {
+	current := &Request{
+		Method:  string(fr.Method),
+		URL:     string(fr.URL),
+		Headers: make(map[string]string, len(fr.Headers)),
+		Body:    string(fr.Body),
+		Secrets: h.secrets,
+	}
+	for _, entry := range fr.Headers {
+		current.Headers[string(entry.Key)] = string(entry.Value)
+	}
+
+	ctx := context.Background()
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	for hops := 0; ; hops++ {
+		status, size, location, err := h.send(ctx, current, jar, decode)
+		if err != nil {
+			return 0, BodySize{}, err
+		}
+		if hops >= h.redirects.Max || !isRedirect(status) || location == "" {
+			return status, size, nil
+		}
+		if current, err = h.redirects.nextRequest(current, status, location); err != nil {
+			return status, size, err
+		}
+	}
+}
+
+// send makes a single request and reads the whole body, so the stream is released
+func (h *H2Client) send(ctx context.Context, r *Request, jar *WorkerCookieJar, decode bool) (int, BodySize, string, error) {
+	req, err := http.NewRequestWithContext(ctx, r.Method, r.URL, strings.NewReader(r.Body))
+	if err != nil {
+		return 0, BodySize{}, "", err
+	}
+	for key, value := range r.Headers {
+		req.Header.Set(key, value)
+	}
+	if jar != nil {
+		jar.applyHTTP(req)
+	}
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		return 0, BodySize{}, "", err
+	}
+	defer res.Body.Close()
+
+	var size BodySize
+	encoding := res.Header.Get("Content-Encoding")
+	if decode && encoding != "" {
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			return 0, BodySize{}, "", err
+		}
+		size = decodedSize(encoding, body)
+	} else {
+		n, err := io.Copy(io.Discard, res.Body)
+		if err != nil {
+			return 0, BodySize{}, "", err
+		}
+		size = BodySize{Received: n, Decoded: n}
+	}
+
+	if jar != nil {
+		jar.updateHTTP(res)
+	}
+	return res.StatusCode, size, res.Header.Get("Location"), nil
+}
@@ -4,6 +4,11 @@ import "time"
 
 type LoadTestStartMsg struct {
 	Config *JobConfig
+
+	// RequestID is the saved request under test, 0 when it isn't saved, and
+	// Label names the run in its history
+	RequestID int64
+	Label     string
 }
 
 type LoadTestStatsMsg struct {
@@ -19,3 +24,36 @@ type LoadTestCompleteMsg struct {
 type LoadTestErrorMsg struct {
 	Error error
 }
+
+// RequestStartMsg asks for a request to be sent, streaming the response when it is one
+type RequestStartMsg struct {
+	Client  *Client
+	Request *Request
+}
+
+// StreamMsg carries one update of a streamed response, before its final ResultMsg
+type StreamMsg struct {
+	Update StreamUpdate
+}
+
+// WSConnectMsg asks for a WebSocket connection to be opened for the request
+type WSConnectMsg struct {
+	Client  *Client
+	Request *Request
+}
+
+// WSConnectedMsg reports the outcome of the handshake. Response is set even
+// when it failed, if the server answered.
+type WSConnectedMsg struct {
+	Conn     *WSConn
+	Response *Response
+	Err      error
+}
+
+// WSFrameMsg carries one frame of an open WebSocket connection
+type WSFrameMsg struct {
+	Frame WSFrame
+}
+
+// WSClosedMsg reports that the WebSocket connection ended
+type WSClosedMsg struct{}
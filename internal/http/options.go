@@ -0,0 +1,138 @@
+package http
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// Option keys accepted in a request's or an environment's options
+const (
+	OptTLSCA         = "tls.ca"         // PEM bundle of extra trusted CAs
+	OptTLSCert       = "tls.cert"       // client certificate for mTLS
+	OptTLSKey        = "tls.key"        // client private key for mTLS
+	OptTLSInsecure   = "tls.insecure"   // skip certificate verification
+	OptTLSMin        = "tls.min"        // minimum TLS version, e.g. 1.2
+	OptTLSMax        = "tls.max"        // maximum TLS version, e.g. 1.3
+	OptTLSServerName = "tls.servername" // SNI and verification name override
+
+	OptProxy       = "proxy"        // proxy URL, or "none" to ignore HTTP_PROXY
+	OptProxyBypass = "proxy.bypass" // hosts that skip the proxy, separated by spaces
+
+	OptRedirects      = "redirects"        // follow, none or the maximum number of hops
+	OptRedirectMethod = "redirects.method" // auto, keep or get
+	OptRedirectAuth   = "redirects.auth"   // strip or keep credentials on cross-host redirects
+
+	OptProtocol = "protocol" // auto, http/1.1, h2 or h2c
+
+	OptAcceptEncoding = "accept-encoding" // all, none, or encodings separated by spaces
+
+	OptStream = "stream" // auto (Server-Sent Events only), on or off
+
+	OptBodyLimit = "body.limit" // most of a body kept in memory, e.g. 10MB; the rest goes to a temporary file
+
+	OptWSSubprotocols = "ws.subprotocols" // WebSocket subprotocols to offer, separated by spaces
+
+	OptTimeout        = "timeout"         // per attempt, e.g. 30s, instead of the client's default
+	OptTimeoutConnect = "timeout.connect" // to establish a connection, e.g. 2s
+
+	OptRetries       = "retries"         // attempts after the first, 0 by default
+	OptRetryOn       = "retry.on"        // status codes, classes (5xx), connection and timeout, separated by spaces
+	OptRetryBackoff  = "retry.backoff"   // delay before the first retry, doubled for each one after
+	OptRetryMaxDelay = "retry.max-delay" // cap on delays, Retry-After included
+
+	OptGRPCProto  = "grpc.proto"  // .proto files describing the service, separated by spaces
+	OptGRPCImport = "grpc.import" // import paths for grpc.proto, separated by spaces
+	OptGRPCWeb    = "grpc.web"    // call through gRPC-Web
+)
+
+var knownOptions = []string{
+	OptTLSCA,
+	OptTLSCert,
+	OptTLSKey,
+	OptTLSInsecure,
+	OptTLSMin,
+	OptTLSMax,
+	OptTLSServerName,
+	OptProxy,
+	OptProxyBypass,
+	OptRedirects,
+	OptRedirectMethod,
+	OptRedirectAuth,
+	OptProtocol,
+	OptAcceptEncoding,
+	OptStream,
+	OptBodyLimit,
+	OptWSSubprotocols,
+	OptTimeout,
+	OptTimeoutConnect,
+	OptRetries,
+	OptRetryOn,
+	OptRetryBackoff,
+	OptRetryMaxDelay,
+	OptGRPCProto,
+	OptGRPCImport,
+	OptGRPCWeb,
+}
+
+// MergeOptions returns the defaults overridden by the given options. Either may be nil.
+func MergeOptions(defaults, overrides map[string]string) map[string]string {
+	merged := maps.Clone(defaults)
+	if merged == nil {
+		merged = make(map[string]string, len(overrides))
+	}
+	maps.Copy(merged, overrides)
+	return merged
+}
+
+// ValidateOptions checks that every option is known and well formed
+func ValidateOptions(options map[string]string) error {
+	for key := range options {
+		if !slices.Contains(knownOptions, key) {
+			return fmt.Errorf("unknown option: %s", key)
+		}
+	}
+	if _, err := ParseTLSOptions(options); err != nil {
+		return err
+	}
+	if _, err := ParseProxyOptions(options); err != nil {
+		return err
+	}
+	if _, err := ParseRedirectPolicy(options); err != nil {
+		return err
+	}
+	if _, err := ParseProtocol(options); err != nil {
+		return err
+	}
+	if _, err := ParseAcceptEncoding(options); err != nil {
+		return err
+	}
+	if _, err := ParseStreamMode(options); err != nil {
+		return err
+	}
+	if _, err := ParseBodyLimit(options); err != nil {
+		return err
+	}
+	if _, err := ParseTimeouts(options); err != nil {
+		return err
+	}
+	if _, err := ParseRetryPolicy(options); err != nil {
+		return err
+	}
+	_, err := ParseGRPCOptions(options)
+	return err
+}
+
+// optionBool parses a boolean option, treating a missing key as false
+func optionBool(options map[string]string, key string) (bool, error) {
+	value, ok := options[key]
+	if !ok || value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s: expected true or false, got %q", key, value)
+	}
+	return b, nil
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Protocols that can be requested with the protocol option
+const (
+	ProtocolAuto  = "auto"     // HTTP/2 when the server offers it over TLS, HTTP/1.1 otherwise
+	ProtocolHTTP1 = "http/1.1" // HTTP/1.1 only
+	ProtocolHTTP2 = "h2"       // HTTP/2 over TLS
+	ProtocolH2C   = "h2c"      // HTTP/2 over cleartext, with prior knowledge
+	ProtocolHTTP3 = "h3"       // HTTP/3 over QUIC
+)
+
+// ErrHTTP3Unsupported is returned when HTTP/3 is requested
+var ErrHTTP3Unsupported = errors.New("HTTP/3 is not supported yet")
+
+var protocolAliases = map[string]string{
+	"":         ProtocolAuto,
+	"auto":     ProtocolAuto,
+	"1.1":      ProtocolHTTP1,
+	"h1":       ProtocolHTTP1,
+	"http1":    ProtocolHTTP1,
+	"http/1.1": ProtocolHTTP1,
+	"2":        ProtocolHTTP2,
+	"h2":       ProtocolHTTP2,
+	"http2":    ProtocolHTTP2,
+	"http/2":   ProtocolHTTP2,
+	"h2c":      ProtocolH2C,
+	"3":        ProtocolHTTP3,
+	"h3":       ProtocolHTTP3,
+	"http3":    ProtocolHTTP3,
+	"http/3":   ProtocolHTTP3,
+}
+
+// ParseProtocol reads the protocol option
+func ParseProtocol(options map[string]string) (string, error) {
+	protocol, ok := protocolAliases[strings.ToLower(options[OptProtocol])]
+	if !ok {
+		return "", fmt.Errorf("%s: expected auto, http/1.1, h2, h2c or h3, got %q", OptProtocol, options[OptProtocol])
+	}
+	if protocol == ProtocolHTTP3 {
+		return "", fmt.Errorf("%s: %w", OptProtocol, ErrHTTP3Unsupported)
+	}
+	return protocol, nil
+}
+
+// transportProtocols returns the protocols a transport may use, nil keeps the default
+func transportProtocols(protocol string) *http.Protocols {
+	protocols := new(http.Protocols)
+	switch protocol {
+	case ProtocolHTTP1:
+		protocols.SetHTTP1(true)
+	case ProtocolHTTP2:
+		protocols.SetHTTP2(true)
+	case ProtocolH2C:
+		protocols.SetUnencryptedHTTP2(true)
+	default:
+		return nil
+	}
+	return protocols
+}
+
+// isHTTP2 reports whether the protocol needs the HTTP/2 load test backend
+func isHTTP2(protocol string) bool {
+	return protocol == ProtocolHTTP2 || protocol == ProtocolH2C
+}
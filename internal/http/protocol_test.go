@@ -0,0 +1,143 @@
+package http
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// h2cServer starts a cleartext server that accepts HTTP/1.1 and h2c, counting connections
+func h2cServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *atomic.Int32) {
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(handler)
+	server.Config.Protocols = new(http.Protocols)
+	server.Config.Protocols.SetHTTP1(true)
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+	return server, &conns
+}
+
+func protoHandler(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(r.Proto))
+}
+
+func TestParseProtocol(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "", want: ProtocolAuto},
+		{value: "auto", want: ProtocolAuto},
+		{value: "HTTP/1.1", want: ProtocolHTTP1},
+		{value: "1.1", want: ProtocolHTTP1},
+		{value: "http2", want: ProtocolHTTP2},
+		{value: "h2c", want: ProtocolH2C},
+		{value: "spdy", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseProtocol(map[string]string{OptProtocol: tt.value})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := ParseProtocol(map[string]string{OptProtocol: "h3"})
+	assert.True(t, errors.Is(err, ErrHTTP3Unsupported))
+}
+
+func TestClient_SendProtocol(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(protoHandler))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	client := InitClient(5*time.Second, false)
+	client.Defaults = map[string]string{OptTLSCA: writeServerCA(t, server)}
+
+	tests := []struct {
+		protocol string
+		want     string
+	}{
+		{protocol: "auto", want: "HTTP/2.0"},
+		{protocol: "h2", want: "HTTP/2.0"},
+		{protocol: "http/1.1", want: "HTTP/1.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.protocol, func(t *testing.T) {
+			res := send(client, &Request{Method: GET, URL: server.URL, Options: map[string]string{OptProtocol: tt.protocol}})
+			assert.Empty(t, res.Error)
+			assert.Equal(t, tt.want, res.Proto)
+			assert.Equal(t, tt.want, res.Body)
+		})
+	}
+}
+
+func TestClient_SendH2C(t *testing.T) {
+	server, _ := h2cServer(t, protoHandler)
+	client := InitClient(5*time.Second, false)
+
+	res := send(client, &Request{Method: GET, URL: server.URL})
+	assert.Equal(t, "HTTP/1.1", res.Proto)
+
+	res = send(client, &Request{Method: GET, URL: server.URL, Options: map[string]string{OptProtocol: "h2c"}})
+	assert.Empty(t, res.Error)
+	assert.Equal(t, "HTTP/2.0", res.Proto)
+	assert.Equal(t, "HTTP/2.0", res.Body)
+}
+
+func TestJobConfig_RunH2C(t *testing.T) {
+	var http2 atomic.Int32
+	server, conns := h2cServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 {
+			http2.Add(1)
+		}
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusFound)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		w.Write([]byte("ok"))
+	})
+
+	config := &JobConfig{
+		Request:       &Request{Method: GET, URL: server.URL + "/old"},
+		Concurrency:   10,
+		TotalRequests: 100,
+		Timeout:       5 * time.Second,
+		Cookies:       true,
+		Redirects:     RedirectPolicy{Max: defaultMaxRedirects},
+		Protocol:      ProtocolH2C,
+	}
+	updates := make(chan *LoadTestStats, 10)
+	go config.Run(updates)
+	var final *LoadTestStats
+	for stats := range updates {
+		final = stats
+	}
+
+	if assert.NotNil(t, final) {
+		assert.Equal(t, 100, final.CompletedRequests)
+		assert.Equal(t, 0, final.FailedRequests)
+	}
+	assert.Equal(t, int32(200), http2.Load(), "every request and redirect should use HTTP/2")
+	assert.Less(t, conns.Load(), int32(10), "streams should share connections")
+}
@@ -0,0 +1,156 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpproxy"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ProxyOptions configures how the clients reach the network. The zero value
+// honors the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+type ProxyOptions struct {
+	URL    string // http://, https:// or socks5:// proxy, with optional user:password@
+	Direct bool   // ignore the environment and connect directly
+	Bypass string // hosts that skip the proxy, NO_PROXY style
+}
+
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// ParseProxyOptions reads the proxy keys of a set of options
+func ParseProxyOptions(options map[string]string) (ProxyOptions, error) {
+	opts := ProxyOptions{
+		URL: options[OptProxy],
+		// Options are comma separated, so bypass lists use spaces or semicolons
+		Bypass: strings.Join(strings.FieldsFunc(options[OptProxyBypass], func(r rune) bool {
+			return r == ';' || r == ' ' || r == ','
+		}), ","),
+	}
+
+	switch strings.ToLower(opts.URL) {
+	case "":
+		return opts, nil
+	case "none", "off", "direct":
+		return ProxyOptions{Direct: true}, nil
+	}
+
+	u, err := url.Parse(opts.URL)
+	if err != nil || u.Host == "" {
+		return ProxyOptions{}, fmt.Errorf("%s: invalid proxy URL %q", OptProxy, opts.URL)
+	}
+	if !slices.Contains(proxySchemes, u.Scheme) {
+		return ProxyOptions{}, fmt.Errorf("%s: unsupported scheme %q (use http, https or socks5)", OptProxy, u.Scheme)
+	}
+	return opts, nil
+}
+
+// IsZero reports whether the environment decides the proxy
+func (p ProxyOptions) IsZero() bool {
+	return p == ProxyOptions{}
+}
+
+// LoadProxyDialer builds the load test dialer described by a set of options.
+// Without proxy options it follows the environment, like the interactive client.
+func LoadProxyDialer(options map[string]string, timeout time.Duration) (fasthttp.DialFunc, error) {
+	opts, err := ParseProxyOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	return opts.DialFunc(timeout)
+}
+
+// ProxyFunc returns the proxy selection function for an http.Transport
+func (p ProxyOptions) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	if p.Direct {
+		return nil
+	}
+	if p.URL == "" {
+		// the bypass list adds to NO_PROXY
+		proxyFunc := httpproxy.FromEnvironment().ProxyFunc()
+		return func(req *http.Request) (*url.URL, error) {
+			if p.bypass(req.URL.Host) {
+				return nil, nil
+			}
+			return proxyFunc(req.URL)
+		}
+	}
+
+	proxyURL, _ := url.Parse(p.URL)
+	return func(req *http.Request) (*url.URL, error) {
+		if p.bypass(req.URL.Host) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}
+}
+
+// DialFunc returns a fasthttp dialer that tunnels through the proxy, or nil to dial directly
+func (p ProxyOptions) DialFunc(timeout time.Duration) (fasthttp.DialFunc, error) {
+	if p.Direct {
+		return nil, nil
+	}
+
+	dialer := &fasthttpproxy.Dialer{Timeout: timeout, ConnectTimeout: timeout}
+	var proxyDial fasthttp.DialFunc
+	var err error
+	if p.URL == "" {
+		if env := httpproxy.FromEnvironment(); env.HTTPProxy == "" && env.HTTPSProxy == "" {
+			return nil, nil // keep fasthttp's own dialer
+		}
+		proxyDial, err = dialer.GetDialFunc(true)
+	} else {
+		proxyURL, _ := url.Parse(p.URL)
+		if proxyURL.Scheme == "https" {
+			return nil, fmt.Errorf("%s: https proxies are not supported for load tests, use http or socks5", OptProxy)
+		}
+		dialer.Config = httpproxy.Config{HTTPProxy: p.URL, HTTPSProxy: p.URL}
+		proxyDial, err = dialer.GetDialFunc(false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.Bypass == "" {
+		return proxyDial, nil
+	}
+
+	return func(addr string) (net.Conn, error) {
+		if p.bypass(addr) {
+			return fasthttp.DialTimeout(addr, timeout)
+		}
+		return proxyDial(addr)
+	}, nil
+}
+
+// bypass reports whether a host matches the bypass list. Entries are host
+// names, which also match their subdomains, IPs, CIDR ranges or "*".
+func (p ProxyOptions) bypass(hostport string) bool {
+	if p.Bypass == "" {
+		return false
+	}
+	host := canonicalHost(hostport)
+	ip := net.ParseIP(host)
+
+	for entry := range strings.SplitSeq(p.Bypass, ",") {
+		entry = strings.ToLower(entry)
+		switch {
+		case entry == "*":
+			return true
+		case strings.Contains(entry, "/"):
+			if _, network, err := net.ParseCIDR(entry); err == nil && ip != nil && network.Contains(ip) {
+				return true
+			}
+		case host == strings.TrimPrefix(entry, "."):
+			return true
+		case ip == nil && strings.HasSuffix(host, "."+strings.TrimPrefix(entry, ".")):
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,370 @@
+package http
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testProxy is a forward proxy stand-in supporting absolute-form requests and CONNECT
+type testProxy struct {
+	*httptest.Server
+	requests atomic.Int64
+
+	mu   sync.Mutex
+	auth string // last Proxy-Authorization seen
+}
+
+func newTestProxy(t *testing.T) *testProxy {
+	t.Helper()
+	p := &testProxy{}
+	upstream := &http.Transport{}
+
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.requests.Add(1)
+		p.mu.Lock()
+		p.auth = r.Header.Get("Proxy-Authorization")
+		p.mu.Unlock()
+
+		if r.Method == http.MethodConnect {
+			target, err := net.Dial("tcp", r.Host)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				target.Close()
+				return
+			}
+			conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+			pipe(conn, target)
+			return
+		}
+
+		r.RequestURI = ""
+		r.Header.Del("Proxy-Authorization")
+		res, err := upstream.RoundTrip(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer res.Body.Close()
+		for key, values := range res.Header {
+			w.Header()[key] = values
+		}
+		w.Header().Set("Via", "test-proxy")
+		w.WriteHeader(res.StatusCode)
+		io.Copy(w, res.Body)
+	}))
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *testProxy) lastAuth() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.auth
+}
+
+// startSOCKS5 runs a minimal SOCKS5 server, requiring a password when user is set
+func startSOCKS5(t *testing.T, user, password string) (string, *atomic.Int64) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	var connections atomic.Int64
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			connections.Add(1)
+			go serveSOCKS5(conn, user, password)
+		}
+	}()
+	return ln.Addr().String(), &connections
+}
+
+func serveSOCKS5(conn net.Conn, user, password string) {
+	buf := make([]byte, 262)
+
+	// greeting: version, methods
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		conn.Close()
+		return
+	}
+	if _, err := io.ReadFull(conn, buf[:buf[1]]); err != nil {
+		conn.Close()
+		return
+	}
+
+	if user == "" {
+		conn.Write([]byte{5, 0})
+	} else {
+		conn.Write([]byte{5, 2})
+		// username/password sub-negotiation
+		io.ReadFull(conn, buf[:2])
+		gotUser := make([]byte, buf[1])
+		io.ReadFull(conn, gotUser)
+		io.ReadFull(conn, buf[:1])
+		gotPass := make([]byte, buf[0])
+		io.ReadFull(conn, gotPass)
+		if string(gotUser) != user || string(gotPass) != password {
+			conn.Write([]byte{1, 1})
+			conn.Close()
+			return
+		}
+		conn.Write([]byte{1, 0})
+	}
+
+	// request: version, command, reserved, address type
+	if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+		conn.Close()
+		return
+	}
+	var host string
+	switch buf[3] {
+	case 1:
+		io.ReadFull(conn, buf[:4])
+		host = net.IP(buf[:4]).String()
+	case 3:
+		io.ReadFull(conn, buf[:1])
+		name := make([]byte, buf[0])
+		io.ReadFull(conn, name)
+		host = string(name)
+	case 4:
+		io.ReadFull(conn, buf[:16])
+		host = net.IP(buf[:16]).String()
+	}
+	io.ReadFull(conn, buf[:2])
+	port := binary.BigEndian.Uint16(buf[:2])
+
+	target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(port))))
+	if err != nil {
+		conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+		conn.Close()
+		return
+	}
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	pipe(conn, target)
+}
+
+// pipe copies between two connections until either side closes
+func pipe(a, b net.Conn) {
+	go func() {
+		io.Copy(a, b)
+		a.Close()
+	}()
+	io.Copy(b, a)
+	b.Close()
+}
+
+func TestParseProxyOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]string
+		want    ProxyOptions
+		wantErr bool
+	}{
+		{name: "environment", options: nil, want: ProxyOptions{}},
+		{name: "direct", options: map[string]string{OptProxy: "none"}, want: ProxyOptions{Direct: true}},
+		{
+			name:    "http with bypass",
+			options: map[string]string{OptProxy: "http://u:p@proxy:8080", OptProxyBypass: "localhost .internal;10.0.0.0/8"},
+			want:    ProxyOptions{URL: "http://u:p@proxy:8080", Bypass: "localhost,.internal,10.0.0.0/8"},
+		},
+		{name: "socks5", options: map[string]string{OptProxy: "socks5://proxy:1080"}, want: ProxyOptions{URL: "socks5://proxy:1080"}},
+		{name: "missing host", options: map[string]string{OptProxy: "proxy:8080"}, wantErr: true},
+		{name: "bad scheme", options: map[string]string{OptProxy: "ftp://proxy:21"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseProxyOptions(tt.options)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProxyOptions_Bypass(t *testing.T) {
+	opts := ProxyOptions{URL: "http://proxy:8080", Bypass: "localhost,.internal,example.com,10.0.0.0/8"}
+
+	assert.True(t, opts.bypass("localhost:3000"))
+	assert.True(t, opts.bypass("api.internal"))
+	assert.True(t, opts.bypass("example.com"))
+	assert.True(t, opts.bypass("www.example.com:443"))
+	assert.True(t, opts.bypass("10.1.2.3:80"))
+	assert.False(t, opts.bypass("notexample.com"))
+	assert.False(t, opts.bypass("192.168.1.1"))
+	assert.True(t, ProxyOptions{Bypass: "*"}.bypass("anything"))
+}
+
+func TestProxyOptions_Environment(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "http://env-proxy:3128")
+	t.Setenv("NO_PROXY", "skip.example")
+
+	proxyFunc := ProxyOptions{}.ProxyFunc()
+	check := func(rawURL string) *url.URL {
+		req, _ := http.NewRequest(GET, rawURL, nil)
+		u, err := proxyFunc(req)
+		assert.NoError(t, err)
+		return u
+	}
+
+	if u := check("http://api.example"); assert.NotNil(t, u) {
+		assert.Equal(t, "env-proxy:3128", u.Host)
+	}
+	assert.Nil(t, check("http://skip.example"))
+	assert.Nil(t, ProxyOptions{Direct: true}.ProxyFunc(), "none ignores the environment")
+
+	// proxy.bypass adds to NO_PROXY
+	req, _ := http.NewRequest(GET, "http://api.example", nil)
+	u, err := ProxyOptions{Bypass: "api.example"}.ProxyFunc()(req)
+	assert.NoError(t, err)
+	assert.Nil(t, u)
+}
+
+func TestProxyOptions_EnvironmentDialBypass(t *testing.T) {
+	proxy := newTestProxy(t)
+	defer proxy.Close()
+	t.Setenv("HTTP_PROXY", proxy.URL)
+
+	// the environment never proxies loopback addresses, so the target is a
+	// name that doesn't resolve: only a dial through the proxy reaches it
+	dial, err := ProxyOptions{Bypass: "bypassed.invalid"}.DialFunc(time.Second)
+	if assert.NoError(t, err) && assert.NotNil(t, dial) {
+		if conn, err := dial("bypassed.invalid:80"); err == nil {
+			conn.Close()
+		}
+	}
+	assert.Zero(t, proxy.requests.Load(), "bypassed host went through the proxy")
+}
+
+func TestClient_SendThroughProxy(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	defer target.Close()
+
+	client := InitClient(time.Second, true)
+
+	t.Run("http proxy with auth", func(t *testing.T) {
+		proxy := newTestProxy(t)
+		proxyURL, _ := url.Parse(proxy.URL)
+		proxyURL.User = url.UserPassword("volt", "s3cret")
+
+		res := send(client, &Request{Method: GET, URL: target.URL, Options: map[string]string{OptProxy: proxyURL.String()}})
+		assert.Empty(t, res.Error)
+		assert.Equal(t, "hello", res.Body)
+		assert.Equal(t, "test-proxy", res.Headers.Get("Via"))
+		assert.Equal(t, int64(1), proxy.requests.Load())
+		assert.Equal(t, "Basic dm9sdDpzM2NyZXQ=", proxy.lastAuth())
+	})
+
+	t.Run("bypass", func(t *testing.T) {
+		proxy := newTestProxy(t)
+		options := map[string]string{OptProxy: proxy.URL, OptProxyBypass: "127.0.0.1"}
+
+		res := send(client, &Request{Method: GET, URL: target.URL, Options: options})
+		assert.Empty(t, res.Error)
+		assert.Equal(t, int64(0), proxy.requests.Load())
+	})
+
+	t.Run("https through CONNECT", func(t *testing.T) {
+		secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("tunnelled"))
+		}))
+		defer secure.Close()
+		proxy := newTestProxy(t)
+
+		options := map[string]string{OptProxy: proxy.URL, OptTLSInsecure: "true"}
+		res := send(client, &Request{Method: GET, URL: secure.URL, Options: options})
+		assert.Empty(t, res.Error)
+		assert.Equal(t, "tunnelled", res.Body)
+		assert.Equal(t, int64(1), proxy.requests.Load())
+	})
+
+	t.Run("socks5 with auth", func(t *testing.T) {
+		addr, connections := startSOCKS5(t, "volt", "s3cret")
+
+		res := send(client, &Request{Method: GET, URL: target.URL, Options: map[string]string{OptProxy: "socks5://volt:s3cret@" + addr}})
+		assert.Empty(t, res.Error)
+		assert.Equal(t, "hello", res.Body)
+		assert.Equal(t, int64(1), connections.Load())
+
+		res = send(client, &Request{Method: GET, URL: target.URL + "/x", Options: map[string]string{OptProxy: "socks5://volt:wrong@" + addr}})
+		assert.NotEmpty(t, res.Error)
+	})
+}
+
+func TestJobConfig_RunThroughProxy(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer target.Close()
+
+	run := func(t *testing.T, options map[string]string) *LoadTestStats {
+		dial, err := LoadProxyDialer(options, 5*time.Second)
+		assert.NoError(t, err)
+
+		config := &JobConfig{
+			Request:       &Request{Method: GET, URL: target.URL},
+			Concurrency:   2,
+			TotalRequests: 20,
+			Timeout:       5 * time.Second,
+			Dial:          dial,
+		}
+		updates := make(chan *LoadTestStats, 10)
+		go config.Run(updates)
+		var final *LoadTestStats
+		for stats := range updates {
+			final = stats
+		}
+		return final
+	}
+
+	t.Run("http proxy", func(t *testing.T) {
+		proxy := newTestProxy(t)
+		stats := run(t, map[string]string{OptProxy: proxy.URL})
+		if assert.NotNil(t, stats) {
+			assert.Equal(t, 20, stats.CompletedRequests)
+			assert.Equal(t, 0, stats.FailedRequests)
+		}
+		// one CONNECT per pooled connection
+		assert.True(t, proxy.requests.Load() >= 1 && proxy.requests.Load() <= 2)
+	})
+
+	t.Run("socks5 proxy", func(t *testing.T) {
+		addr, connections := startSOCKS5(t, "", "")
+		stats := run(t, map[string]string{OptProxy: "socks5://" + addr})
+		if assert.NotNil(t, stats) {
+			assert.Equal(t, 20, stats.CompletedRequests)
+		}
+		assert.True(t, connections.Load() >= 1)
+	})
+
+	t.Run("https proxy is rejected", func(t *testing.T) {
+		_, err := LoadProxyDialer(map[string]string{OptProxy: "https://proxy:443"}, time.Second)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,147 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultMaxRedirects matches net/http's limit
+const defaultMaxRedirects = 10
+
+// Redirect method policies
+const (
+	RedirectMethodAuto = "auto" // 301/302 turn POST into GET, 303 turns anything into GET
+	RedirectMethodKeep = "keep" // always resend the same method and body
+	RedirectMethodGet  = "get"  // always follow with a GET and no body
+)
+
+// RedirectPolicy decides whether and how redirects are followed. The zero
+// value does not follow redirects; use ParseRedirectPolicy for the defaults.
+type RedirectPolicy struct {
+	Max      int    // hops to follow, 0 returns the first redirect response
+	Method   string // RedirectMethodAuto, RedirectMethodKeep or RedirectMethodGet
+	KeepAuth bool   // forward credentials to other hosts
+}
+
+// RedirectHop is one redirect response that was followed
+type RedirectHop struct {
+	Method     string        `json:"method"`
+	URL        string        `json:"url"`
+	StatusCode int           `json:"status_code"`
+	Status     string        `json:"status"`
+	Location   string        `json:"location"` // resolved against URL
+	Headers    http.Header   `json:"headers,omitempty"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// sensitiveHeaders carry credentials and are dropped when a redirect leaves
+// the original host, as are the headers of a request marked as secret
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Www-Authenticate", "X-Api-Key", "Api-Key"}
+
+// ParseRedirectPolicy reads the redirects.* keys of a set of options
+func ParseRedirectPolicy(options map[string]string) (RedirectPolicy, error) {
+	policy := RedirectPolicy{Max: defaultMaxRedirects, Method: RedirectMethodAuto}
+
+	switch value := strings.ToLower(options[OptRedirects]); value {
+	case "", "follow":
+	case "none", "off", "0":
+		policy.Max = 0
+	default:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return RedirectPolicy{}, fmt.Errorf("%s: expected follow, none or a number of hops, got %q", OptRedirects, options[OptRedirects])
+		}
+		policy.Max = n
+	}
+
+	switch method := strings.ToLower(options[OptRedirectMethod]); method {
+	case "", RedirectMethodAuto:
+	case RedirectMethodKeep, RedirectMethodGet:
+		policy.Method = method
+	default:
+		return RedirectPolicy{}, fmt.Errorf("%s: expected auto, keep or get, got %q", OptRedirectMethod, options[OptRedirectMethod])
+	}
+
+	switch auth := strings.ToLower(options[OptRedirectAuth]); auth {
+	case "", "strip":
+	case "keep":
+		policy.KeepAuth = true
+	default:
+		return RedirectPolicy{}, fmt.Errorf("%s: expected strip or keep, got %q", OptRedirectAuth, options[OptRedirectAuth])
+	}
+
+	return policy, nil
+}
+
+// isRedirect reports whether a status code is a redirect with a Location to follow
+func isRedirect(statusCode int) bool {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// nextMethod returns the method of the next hop and whether the body is resent
+func (p RedirectPolicy) nextMethod(method string, statusCode int) (string, bool) {
+	switch p.Method {
+	case RedirectMethodKeep:
+		return method, true
+	case RedirectMethodGet:
+		return http.MethodGet, false
+	}
+
+	switch {
+	case statusCode == http.StatusSeeOther && method != http.MethodHead:
+		return http.MethodGet, false
+	case (statusCode == http.StatusMovedPermanently || statusCode == http.StatusFound) && method == http.MethodPost:
+		return http.MethodGet, false
+	}
+	return method, true
+}
+
+// stripAuth reports whether credentials must be dropped when redirecting between hosts
+func (p RedirectPolicy) stripAuth(from, to string) bool {
+	return !p.KeepAuth && canonicalHost(from) != canonicalHost(to)
+}
+
+// nextRequest builds the request that follows a redirect to location
+func (p RedirectPolicy) nextRequest(current *Request, statusCode int, location string) (*Request, error) {
+	base, err := url.Parse(current.URL)
+	if err != nil {
+		return nil, err
+	}
+	target, err := base.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redirect location %q: %w", location, err)
+	}
+
+	next := *current
+	next.URL = target.String()
+	next.Headers = make(map[string]string, len(current.Headers))
+
+	method, keepBody := p.nextMethod(current.Method, statusCode)
+	next.Method = method
+	if !keepBody {
+		next.Body = ""
+	}
+
+	strip := p.stripAuth(base.Host, target.Host)
+	for key, value := range current.Headers {
+		canonical := http.CanonicalHeaderKey(key)
+		if strip && (slices.Contains(sensitiveHeaders, canonical) || current.IsSecret(key)) {
+			continue
+		}
+		if !keepBody && (canonical == "Content-Type" || canonical == "Content-Length") {
+			continue
+		}
+		next.Headers[key] = value
+	}
+	return &next, nil
+}
@@ -0,0 +1,251 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRedirectPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]string
+		want    RedirectPolicy
+		wantErr bool
+	}{
+		{name: "default follows", options: nil, want: RedirectPolicy{Max: 10, Method: RedirectMethodAuto}},
+		{name: "none", options: map[string]string{OptRedirects: "none"}, want: RedirectPolicy{Max: 0, Method: RedirectMethodAuto}},
+		{
+			name:    "max hops, keep method and auth",
+			options: map[string]string{OptRedirects: "3", OptRedirectMethod: "keep", OptRedirectAuth: "keep"},
+			want:    RedirectPolicy{Max: 3, Method: RedirectMethodKeep, KeepAuth: true},
+		},
+		{name: "negative hops", options: map[string]string{OptRedirects: "-1"}, wantErr: true},
+		{name: "bad method", options: map[string]string{OptRedirectMethod: "post"}, wantErr: true},
+		{name: "bad auth", options: map[string]string{OptRedirectAuth: "maybe"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRedirectPolicy(tt.options)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRedirectPolicy_NextMethod(t *testing.T) {
+	auto := RedirectPolicy{Method: RedirectMethodAuto}
+	tests := []struct {
+		policy   RedirectPolicy
+		method   string
+		status   int
+		want     string
+		keepBody bool
+	}{
+		{auto, POST, http.StatusFound, GET, false},
+		{auto, POST, http.StatusMovedPermanently, GET, false},
+		{auto, PUT, http.StatusFound, PUT, true},
+		{auto, PUT, http.StatusSeeOther, GET, false},
+		{auto, POST, http.StatusTemporaryRedirect, POST, true},
+		{auto, POST, http.StatusPermanentRedirect, POST, true},
+		{RedirectPolicy{Method: RedirectMethodKeep}, POST, http.StatusSeeOther, POST, true},
+		{RedirectPolicy{Method: RedirectMethodGet}, PUT, http.StatusTemporaryRedirect, GET, false},
+	}
+
+	for _, tt := range tests {
+		method, keepBody := tt.policy.nextMethod(tt.method, tt.status)
+		assert.Equal(t, tt.want, method, "%s %s after %d", tt.policy.Method, tt.method, tt.status)
+		assert.Equal(t, tt.keepBody, keepBody)
+	}
+}
+
+func TestClient_SendRedirects(t *testing.T) {
+	var lastMethod, lastBody, lastAuth, lastKey, lastSession atomic.Value
+	mux := http.NewServeMux()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/middle", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/middle", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Hop", "middle")
+		http.Redirect(w, r, "/final", http.StatusFound)
+	})
+	mux.HandleFunc("/temporary", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final", http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		lastMethod.Store(r.Method)
+		lastBody.Store(string(body))
+		lastAuth.Store(r.Header.Get("Authorization"))
+		lastKey.Store(r.Header.Get("X-Api-Key"))
+		lastSession.Store(r.Header.Get("X-Session"))
+		w.Write([]byte("done"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := InitClient(time.Second, true)
+
+	t.Run("follows and records the chain", func(t *testing.T) {
+		res := send(client, &Request{Method: GET, URL: server.URL + "/start"})
+		assert.Empty(t, res.Error)
+		assert.Equal(t, "done", res.Body)
+		assert.Equal(t, server.URL+"/final", res.URL)
+
+		if assert.Len(t, res.Redirects, 2) {
+			assert.Equal(t, http.StatusMovedPermanently, res.Redirects[0].StatusCode)
+			assert.Equal(t, server.URL+"/start", res.Redirects[0].URL)
+			assert.Equal(t, server.URL+"/middle", res.Redirects[0].Location)
+			assert.Equal(t, "middle", res.Redirects[1].Headers.Get("X-Hop"))
+			assert.True(t, res.Redirects[1].Duration > 0)
+		}
+	})
+
+	t.Run("does not follow", func(t *testing.T) {
+		res := send(client, &Request{Method: GET, URL: server.URL + "/start", Options: map[string]string{OptRedirects: "none"}})
+		assert.Equal(t, http.StatusMovedPermanently, res.StatusCode)
+		assert.Empty(t, res.Redirects)
+	})
+
+	t.Run("stops at the limit", func(t *testing.T) {
+		res := send(client, &Request{Method: GET, URL: server.URL + "/start", Options: map[string]string{OptRedirects: "1"}})
+		assert.Equal(t, http.StatusFound, res.StatusCode)
+		assert.Len(t, res.Redirects, 1)
+	})
+
+	t.Run("POST becomes GET on 302", func(t *testing.T) {
+		res := send(client, &Request{Method: POST, URL: server.URL + "/middle", Body: "payload"})
+		assert.Empty(t, res.Error)
+		assert.Equal(t, GET, lastMethod.Load())
+		assert.Equal(t, "", lastBody.Load())
+	})
+
+	t.Run("keep method", func(t *testing.T) {
+		options := map[string]string{OptRedirectMethod: RedirectMethodKeep}
+		send(client, &Request{Method: POST, URL: server.URL + "/middle", Body: "payload", Options: options})
+		assert.Equal(t, POST, lastMethod.Load())
+		assert.Equal(t, "payload", lastBody.Load())
+	})
+
+	t.Run("307 keeps method", func(t *testing.T) {
+		send(client, &Request{Method: POST, URL: server.URL + "/temporary", Body: "payload"})
+		assert.Equal(t, POST, lastMethod.Load())
+		assert.Equal(t, "payload", lastBody.Load())
+	})
+
+	t.Run("strips auth across hosts", func(t *testing.T) {
+		// the same server under another host name
+		other := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+		cross := httptest.NewServer(http.RedirectHandler(other+"/final", http.StatusFound))
+		defer cross.Close()
+
+		headers := map[string]string{"Authorization": "Bearer token"}
+		send(client, &Request{Method: GET, URL: cross.URL, Headers: headers})
+		assert.Equal(t, "", lastAuth.Load())
+
+		send(client, &Request{Method: GET, URL: cross.URL, Headers: headers, Options: map[string]string{OptRedirectAuth: "keep"}})
+		assert.Equal(t, "Bearer token", lastAuth.Load())
+
+		// same host keeps credentials
+		send(client, &Request{Method: GET, URL: server.URL + "/start", Headers: headers})
+		assert.Equal(t, "Bearer token", lastAuth.Load())
+
+		// and so do headers marked as secret
+		headers = map[string]string{"X-Api-Key": "key", "X-Session": "session", "Accept": "*/*"}
+		send(client, &Request{Method: GET, URL: cross.URL, Headers: headers, Secrets: []string{"x-session"}})
+		assert.Equal(t, "", lastKey.Load())
+		assert.Equal(t, "", lastSession.Load())
+		send(client, &Request{Method: GET, URL: server.URL + "/start", Headers: headers, Secrets: []string{"x-session"}})
+		assert.Equal(t, "key", lastKey.Load())
+		assert.Equal(t, "session", lastSession.Load())
+	})
+}
+
+func TestJobConfig_RunStripsSecretsAcrossHosts(t *testing.T) {
+	var forwarded atomic.Int64
+	final := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Session") != "" || r.Header.Get("X-Api-Key") != "" || r.Header.Get("Authorization") != "" {
+			forwarded.Add(1)
+		}
+	}))
+	defer final.Close()
+	// the same server under another host name
+	other := strings.Replace(final.URL, "127.0.0.1", "localhost", 1)
+	cross := httptest.NewServer(http.RedirectHandler(other, http.StatusFound))
+	defer cross.Close()
+
+	policy, err := ParseRedirectPolicy(nil)
+	assert.NoError(t, err)
+	config := &JobConfig{
+		Request: &Request{
+			Method:  GET,
+			URL:     cross.URL,
+			Headers: map[string]string{"Authorization": "Bearer token", "X-Api-Key": "key", "X-Session": "session"},
+			Secrets: []string{"X-Session"},
+		},
+		Concurrency:   2,
+		TotalRequests: 10,
+		Timeout:       5 * time.Second,
+		Redirects:     policy,
+	}
+	updates := make(chan *LoadTestStats, 10)
+	go config.Run(updates)
+	var stats *LoadTestStats
+	for s := range updates {
+		stats = s
+	}
+	if assert.NotNil(t, stats) {
+		assert.Equal(t, 10, stats.CompletedRequests)
+	}
+	assert.Equal(t, int64(0), forwarded.Load())
+}
+
+func TestJobConfig_RunFollowsRedirects(t *testing.T) {
+	var final atomic.Int64
+	mux := http.NewServeMux()
+	mux.Handle("/", http.RedirectHandler("/final", http.StatusFound))
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {
+		final.Add(1)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	run := func(policy RedirectPolicy) *LoadTestStats {
+		config := &JobConfig{
+			Request:       &Request{Method: GET, URL: server.URL},
+			Concurrency:   2,
+			TotalRequests: 10,
+			Timeout:       5 * time.Second,
+			Redirects:     policy,
+		}
+		updates := make(chan *LoadTestStats, 10)
+		go config.Run(updates)
+		var stats *LoadTestStats
+		for s := range updates {
+			stats = s
+		}
+		return stats
+	}
+
+	run(RedirectPolicy{})
+	assert.Equal(t, int64(0), final.Load(), "zero policy does not follow")
+
+	policy, err := ParseRedirectPolicy(nil)
+	assert.NoError(t, err)
+	stats := run(policy)
+	assert.Equal(t, int64(10), final.Load())
+	if assert.NotNil(t, stats) {
+		assert.Equal(t, 10, stats.CompletedRequests)
+	}
+}
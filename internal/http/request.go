@@ -2,7 +2,12 @@ package http
 
 import (
 	"fmt"
+	"maps"
 	"slices"
+	"strings"
+	"time"
+
+	"github.com/owenHochwald/volt/internal/secrets"
 )
 
 const (
@@ -24,6 +29,9 @@ var validMethods = []string{
 	PUT,
 	PATCH,
 	DELETE,
+	GRAPHQL,
+	WS,
+	GRPC,
 	//CONNECT,
 	//OPTIONS,
 	//TRACE,
@@ -36,6 +44,22 @@ type Request struct {
 	URL     string            `json:"url"`
 	Headers map[string]string `json:"headers,omitempty"`
 	Body    string            `json:"body,omitempty"`
+
+	// Secrets lists the header keys whose values are secret
+	Secrets []string `json:"secrets,omitempty"`
+
+	// Options holds transport settings such as tls.ca, overriding the environment's
+	Options map[string]string `json:"options,omitempty"`
+
+	// CollectionID is the collection or folder the request is saved in, 0 for none
+	CollectionID int64 `json:"collection_id,omitempty"`
+
+	// Version counts the saves of the request, to detect changes saved
+	// elsewhere since it was loaded. 0 skips the check.
+	Version int64 `json:"version,omitempty"`
+
+	// UpdatedAt is when the request was last saved
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
 }
 
 func NewBlankRequest() *Request {
@@ -77,8 +101,16 @@ func (r *Request) Validate() error {
 		return fmt.Errorf("invalid method: %s", r.Method)
 	}
 
-	if r.URL != "" {
-		if r.URL[0:4] != "http" {
+	if r.Method == WS {
+		if !strings.HasPrefix(r.URL, "ws") && !strings.HasPrefix(r.URL, "http") {
+			return fmt.Errorf("invalid url: %s", r.URL)
+		}
+	} else if r.Method == GRPC {
+		if _, err := ParseGRPCTarget(r.URL); err != nil {
+			return err
+		}
+	} else if r.URL != "" {
+		if !strings.HasPrefix(r.URL, "http") {
 			return fmt.Errorf("invalid url: %s", r.URL)
 		}
 	}
@@ -89,6 +121,47 @@ func (r *Request) Validate() error {
 	if r.Body != "" && len(r.Body) > 10000 {
 		return fmt.Errorf("body too long: %d", len(r.Body))
 	}
+	if r.Method == GRAPHQL {
+		if _, err := ParseGraphQLBody(r.Body); err != nil {
+			return err
+		}
+	}
+	if err := ValidateOptions(r.Options); err != nil {
+		return err
+	}
 
 	return nil
 }
+
+// IsSecret reports whether the header key is marked as secret
+func (r *Request) IsSecret(key string) bool {
+	return slices.ContainsFunc(r.Secrets, func(s string) bool {
+		return strings.EqualFold(s, key)
+	})
+}
+
+// Redacted returns a copy of the request with secret header values masked
+func (r *Request) Redacted() *Request {
+	redacted := *r
+	redacted.Headers = maps.Clone(r.Headers)
+	for key := range redacted.Headers {
+		if r.IsSecret(key) || secrets.IsSealed(redacted.Headers[key]) {
+			redacted.Headers[key] = secrets.Mask
+		}
+	}
+	return &redacted
+}
+
+// Unseal returns a copy of the request with sealed header values decrypted
+func (r *Request) Unseal(vault *secrets.Vault) (*Request, error) {
+	unsealed := *r
+	unsealed.Headers = maps.Clone(r.Headers)
+	for key, value := range unsealed.Headers {
+		opened, err := vault.Open(value)
+		if err != nil {
+			return nil, fmt.Errorf("header %s: %w", key, err)
+		}
+		unsealed.Headers[key] = opened
+	}
+	return &unsealed, nil
+}
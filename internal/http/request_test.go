@@ -2,6 +2,8 @@ package http
 
 import (
 	"testing"
+
+	"github.com/owenHochwald/volt/internal/secrets"
 )
 
 func TestRequest_Validate(t *testing.T) {
@@ -28,6 +30,12 @@ func TestRequest_Validate(t *testing.T) {
 		{"valid with body", fields{Method: GET, URL: "http://localhost", Body: "test"}, false},
 		{"invalid method", fields{Method: "GETT", URL: "http://localhost"}, true},
 		{"invalid url", fields{Method: GET, URL: "htt://localhost:8080"}, true},
+		{"short url", fields{Method: GET, URL: "ht"}, true},
+		{"websocket", fields{Method: WS, URL: "wss://localhost:8080/ws"}, false},
+		{"websocket over http url", fields{Method: WS, URL: "http://localhost:8080/ws"}, false},
+		{"invalid websocket url", fields{Method: WS, URL: "ftp://localhost"}, true},
+		{"grpc", fields{Method: GRPC, URL: "grpc://localhost:50051/volt.test.Greeter/SayHello"}, false},
+		{"grpc over http url", fields{Method: GRPC, URL: "http://localhost:50051/volt.test.Greeter/SayHello"}, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -45,3 +53,44 @@ func TestRequest_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestRequest_RedactedAndUnseal(t *testing.T) {
+	vault, err := secrets.NewVault([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("failed to create vault: %v", err)
+	}
+	sealed, err := vault.Seal("Bearer token")
+	if err != nil {
+		t.Fatalf("failed to seal: %v", err)
+	}
+
+	r := &Request{
+		Method:  GET,
+		URL:     "http://localhost",
+		Headers: map[string]string{"Authorization": sealed, "X-Key": "plain", "Accept": "*/*"},
+		Secrets: []string{"authorization", "X-Key"},
+	}
+
+	redacted := r.Redacted()
+	if redacted.Headers["Authorization"] != secrets.Mask || redacted.Headers["X-Key"] != secrets.Mask {
+		t.Errorf("Redacted() did not mask secrets: %v", redacted.Headers)
+	}
+	if redacted.Headers["Accept"] != "*/*" {
+		t.Errorf("Redacted() changed a public header: %v", redacted.Headers)
+	}
+	if r.Headers["X-Key"] != "plain" {
+		t.Errorf("Redacted() modified the original request")
+	}
+
+	unsealed, err := r.Unseal(vault)
+	if err != nil {
+		t.Fatalf("Unseal() error = %v", err)
+	}
+	if unsealed.Headers["Authorization"] != "Bearer token" {
+		t.Errorf("Unseal() = %q, want %q", unsealed.Headers["Authorization"], "Bearer token")
+	}
+
+	if _, err := r.Unseal(nil); err == nil {
+		t.Errorf("Unseal() without a vault should fail for sealed values")
+	}
+}
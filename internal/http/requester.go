@@ -5,13 +5,17 @@
 package http
 
 import (
+	"crypto/tls"
+	"encoding/json"
 	"math"
+	"slices"
 	"strconv"
 	"sync"
 	"time"
 
 	"github.com/influxdata/tdigest"
 	"github.com/valyala/fasthttp"
+	"google.golang.org/grpc/codes"
 )
 
 // largest size of the buffer for the result channel
@@ -22,6 +26,26 @@ type PercentileCalculator struct {
 	digest *tdigest.TDigest
 }
 
+// TimelineBucket holds what happened during one second of a load test. Workers
+// report in batches, so requests count in the second their batch arrived.
+type TimelineBucket struct {
+	Offset   time.Duration `json:"offset"` // since the start of the test
+	Requests int           `json:"requests"`
+	Failures int           `json:"failures,omitempty"`
+
+	// latency of the sampled requests
+	Samples      int           `json:"samples,omitempty"`
+	LatencyTotal time.Duration `json:"latency_total,omitempty"`
+}
+
+// Latency returns the mean latency of the sampled requests
+func (b TimelineBucket) Latency() time.Duration {
+	if b.Samples == 0 {
+		return 0
+	}
+	return b.LatencyTotal / time.Duration(b.Samples)
+}
+
 // LoadTestStats holds aggregated stats about the load test
 type LoadTestStats struct {
 	StartTime         time.Time
@@ -39,11 +63,18 @@ type LoadTestStats struct {
 	Percentiles *PercentileCalculator
 
 	// network stats
-	BytesSent int64
-	BytesRecv int64
+	BytesSent    int64
+	BytesRecv    int64 // response body bytes on the wire
+	BytesDecoded int64 // BytesRecv after decompression, estimated from sampled responses
+
+	sampledRecv    int64 // body sizes of the sampled responses, to estimate BytesDecoded
+	sampledDecoded int64
 
 	// error tracking
-	Errors map[string]int64 // error code -> count
+	Errors map[string]int64 // HTTP status code, or gRPC code such as "gRPC Unavailable" -> count
+
+	// Timeline splits the test into seconds, to show how it went over time
+	Timeline []TimelineBucket
 
 	// system metrics
 	CPUUsage    float64 // percentage
@@ -63,6 +94,10 @@ type workerStats struct {
 	sampledMax   uint64 // nanoseconds
 	sampledTotal uint64 // sum for average calculation
 
+	bytesRecv      uint64
+	sampledRecv    uint64 // body bytes of sampled responses, before and after decoding
+	sampledDecoded uint64
+
 	errorCodes map[int]uint64
 }
 
@@ -78,15 +113,22 @@ type JobConfig struct {
 	FastRequest *FastRequest
 
 	// Load parameters
-	Concurrency   int           // number of concurrent requests
-	TotalRequests int           // total requests to send
-	RateLimit     int           // max requests per second
-	Timeout       time.Duration // time per request
-	QPS           float64       // rate limit for queries per second
-	StreamUpdates bool          // if false, only send final result (for CLI mode)
+	Concurrency    int               // number of concurrent requests
+	TotalRequests  int               // total requests to send
+	RateLimit      int               // max requests per second
+	Timeout        time.Duration     // time per request
+	QPS            float64           // rate limit for queries per second
+	StreamUpdates  bool              // if false, only send final result (for CLI mode)
+	Cookies        bool              // give every worker its own cookie jar
+	TLSConfig      *tls.Config       // custom CAs, client certificates, etc. (see LoadTLSConfig)
+	Dial           fasthttp.DialFunc // proxy dialer (see LoadProxyDialer), nil dials directly
+	Redirects      RedirectPolicy    // zero does not follow redirects (see ParseRedirectPolicy)
+	AcceptEncoding string            // Accept-Encoding sent unless the request sets one (see ParseAcceptEncoding)
+	Protocol       string            // h2 or h2c use the HTTP/2 backend, anything else HTTP/1.1 (see ParseProtocol)
+	GRPC           *GRPCCall         // makes gRPC calls instead of HTTP requests (see Client.ResolveGRPC)
 
 	// Internal state
-	client        *FastClient
+	client        LoadClient
 	workerStatsCh chan workerStatsMsg
 	stopCh        chan struct{}
 	start         time.Time
@@ -111,8 +153,14 @@ func NewLoadTestStats(totalRequests int) *LoadTestStats {
 func (s *JobConfig) Run(updates chan<- *LoadTestStats) {
 	s.start = time.Now()
 	s.stats = NewLoadTestStats(s.TotalRequests)
-	s.FastRequest = compileRequest(s.Request)
-	s.client = NewFastClient(s.Timeout, s)
+	s.FastRequest = compileRequest(s.Request.withDefaultHeader("Accept-Encoding", s.AcceptEncoding))
+	if s.GRPC != nil {
+		s.client = NewGRPCClient(s.Timeout, s)
+	} else if isHTTP2(s.Protocol) {
+		s.client = NewH2Client(s.Timeout, s)
+	} else {
+		s.client = NewFastClient(s.Timeout, s)
+	}
 
 	// aggregation channel (buffered for batch flushes)
 	s.workerStatsCh = make(chan workerStatsMsg, s.Concurrency*4)
@@ -155,6 +203,43 @@ func (p *PercentileCalculator) Percentile(percentile float64) time.Duration {
 	return time.Duration(ms) * time.Millisecond
 }
 
+// MarshalJSON writes the centroids of the digest, so that percentiles can be
+// computed again once the stats are loaded
+func (p *PercentileCalculator) MarshalJSON() ([]byte, error) {
+	centroids := p.digest.Centroids()
+	pairs := make([][2]float64, len(centroids))
+	for i, c := range centroids {
+		pairs[i] = [2]float64{c.Mean, c.Weight}
+	}
+	return json.Marshal(pairs)
+}
+
+// UnmarshalJSON rebuilds a digest written by MarshalJSON
+func (p *PercentileCalculator) UnmarshalJSON(data []byte) error {
+	var pairs [][2]float64
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		return err
+	}
+	p.digest = tdigest.NewWithCompression(100)
+	for _, pair := range pairs {
+		p.digest.Add(pair[0], pair[1])
+	}
+	return nil
+}
+
+// addToTimeline counts a worker's flush in the second it arrived
+func (s *LoadTestStats) addToTimeline(at time.Time, stats *workerStats) {
+	second := max(int(at.Sub(s.StartTime)/time.Second), 0)
+	for len(s.Timeline) <= second {
+		s.Timeline = append(s.Timeline, TimelineBucket{Offset: time.Duration(len(s.Timeline)) * time.Second})
+	}
+	bucket := &s.Timeline[second]
+	bucket.Requests += int(stats.requests)
+	bucket.Failures += int(stats.failures)
+	bucket.Samples += int(stats.sampledCount)
+	bucket.LatencyTotal += time.Duration(stats.sampledTotal)
+}
+
 func (s *LoadTestStats) GetSnapshot() LoadTestStats {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -176,7 +261,9 @@ func (s *LoadTestStats) GetSnapshot() LoadTestStats {
 		Percentiles:       s.Percentiles, // can share, it's thread safe
 		BytesSent:         s.BytesSent,
 		BytesRecv:         s.BytesRecv,
+		BytesDecoded:      s.BytesDecoded,
 		Errors:            errorsCopy,
+		Timeline:          slices.Clone(s.Timeline),
 		CPUUsage:          s.CPUUsage,
 		MemoryUsage:       s.MemoryUsage,
 	}
@@ -189,6 +276,12 @@ func (s *JobConfig) runWorker(workerID int, wg *sync.WaitGroup) {
 	req := &fasthttp.Request{}
 	res := &fasthttp.Response{}
 
+	// Per-worker cookie jar, so session based endpoints see one client per worker
+	var jar *WorkerCookieJar
+	if s.Cookies {
+		jar = NewWorkerCookieJar()
+	}
+
 	// Local stats
 	var stats workerStats
 	stats.errorCodes = make(map[int]uint64, 8)
@@ -232,7 +325,7 @@ func (s *JobConfig) runWorker(workerID int, wg *sync.WaitGroup) {
 			start = time.Now()
 		}
 
-		status, _, err := s.client.Do(s.FastRequest, req, res)
+		status, size, err := s.client.Do(s.FastRequest, req, res, jar, sample)
 
 		if sample {
 			elapsed := uint64(time.Since(start).Nanoseconds())
@@ -245,7 +338,10 @@ func (s *JobConfig) runWorker(workerID int, wg *sync.WaitGroup) {
 				stats.sampledMax = elapsed
 			}
 			stats.sampledTotal += elapsed
+			stats.sampledRecv += uint64(size.Received)
+			stats.sampledDecoded += uint64(size.Decoded)
 		}
+		stats.bytesRecv += uint64(size.Received)
 
 		stats.requests++
 		if err != nil {
@@ -267,6 +363,9 @@ func (s *JobConfig) runWorker(workerID int, wg *sync.WaitGroup) {
 			stats.sampledMin = 0
 			stats.sampledMax = 0
 			stats.sampledTotal = 0
+			stats.bytesRecv = 0
+			stats.sampledRecv = 0
+			stats.sampledDecoded = 0
 		}
 	}
 
@@ -330,10 +429,20 @@ func (s *JobConfig) aggregateStats(updates chan<- *LoadTestStats) {
 				s.stats.Percentiles.digest.Add(avgLatency/1e6, float64(msg.stats.sampledCount))
 			}
 
+			// Decoded bytes are only measured on samples, so scale by their ratio
+			s.stats.BytesRecv += int64(msg.stats.bytesRecv)
+			s.stats.sampledRecv += int64(msg.stats.sampledRecv)
+			s.stats.sampledDecoded += int64(msg.stats.sampledDecoded)
+			s.stats.BytesDecoded = s.stats.BytesRecv
+			if s.stats.sampledRecv > 0 {
+				s.stats.BytesDecoded = int64(float64(s.stats.BytesRecv) * float64(s.stats.sampledDecoded) / float64(s.stats.sampledRecv))
+			}
+
 			// Merge error codes
 			for code, count := range msg.stats.errorCodes {
-				s.stats.Errors[strconv.Itoa(code)] += int64(count)
+				s.stats.Errors[s.errorKey(code)] += int64(count)
 			}
+			s.stats.addToTimeline(time.Now(), &msg.stats)
 			s.stats.mu.Unlock()
 
 		case <-tickerCh:
@@ -344,6 +453,32 @@ func (s *JobConfig) aggregateStats(updates chan<- *LoadTestStats) {
 	}
 }
 
+// secretHeaders are the headers of the request marked as secret
+func (s *JobConfig) secretHeaders() []string {
+	if s.Request == nil {
+		return nil
+	}
+	return s.Request.Secrets
+}
+
+// errorKey is the key of a failed call's status in LoadTestStats.Errors. gRPC
+// codes are named, so that they aren't taken for HTTP status codes.
+func (s *JobConfig) errorKey(status int) string {
+	if s.GRPC != nil {
+		return "gRPC " + codes.Code(status).String()
+	}
+	return strconv.Itoa(status)
+}
+
+// ErrorLabel names a key of LoadTestStats.Errors in reports, e.g. "HTTP 503"
+// or "gRPC Unavailable"
+func ErrorLabel(key string) string {
+	if _, err := strconv.Atoi(key); err == nil {
+		return "HTTP " + key
+	}
+	return key
+}
+
 func (s *JobConfig) runWorkers() {
 	var wg sync.WaitGroup
 
@@ -1,8 +1,10 @@
 package http
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -86,6 +88,11 @@ loop:
 	assert.Truef(t, finalStats.MinDuration < finalStats.MaxDuration, "Min duration is greater than max duration (%d > %d)", finalStats.MinDuration, finalStats.MaxDuration)
 	assert.NotNil(t, finalStats.Percentiles, "Percentiles are nil")
 
+	timelineRequests := 0
+	for _, bucket := range finalStats.Timeline {
+		timelineRequests += bucket.Requests
+	}
+	assert.Equal(t, expectedRequests, timelineRequests, "Timeline doesn't add up to the completed requests")
 }
 
 func TestJobConfig_RunHard(t *testing.T) {
@@ -159,3 +166,70 @@ func TestNewLoadTestStats(t *testing.T) {
 		t.Fatal("Errors map not initialized")
 	}
 }
+
+func TestLoadTestStats_JSON(t *testing.T) {
+	stats := NewLoadTestStats(100)
+	for i := 1; i <= 100; i++ {
+		stats.Percentiles.digest.Add(float64(i), 1)
+	}
+	stats.CompletedRequests = 100
+	stats.Errors["500"] = 3
+	stats.addToTimeline(stats.StartTime.Add(1500*time.Millisecond), &workerStats{requests: 100, failures: 3, sampledCount: 2, sampledTotal: uint64(30 * time.Millisecond)})
+
+	data, err := json.Marshal(stats)
+	assert.NoError(t, err)
+
+	var loaded LoadTestStats
+	assert.NoError(t, json.Unmarshal(data, &loaded))
+	assert.Equal(t, 100, loaded.CompletedRequests)
+	assert.Equal(t, int64(3), loaded.Errors["500"])
+	assert.Equal(t, stats.Percentiles.Percentile(50), loaded.Percentiles.Percentile(50))
+	assert.Equal(t, stats.Percentiles.Percentile(99), loaded.Percentiles.Percentile(99))
+
+	// one bucket per second, the first one empty
+	assert.Len(t, loaded.Timeline, 2)
+	assert.Equal(t, 0, loaded.Timeline[0].Requests)
+	assert.Equal(t, time.Second, loaded.Timeline[1].Offset)
+	assert.Equal(t, 100, loaded.Timeline[1].Requests)
+	assert.Equal(t, 15*time.Millisecond, loaded.Timeline[1].Latency())
+}
+
+func TestJobConfig_RunWithWorkerCookies(t *testing.T) {
+	var withCookie, withoutCookie atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("session"); err == nil {
+			withCookie.Add(1)
+			return
+		}
+		withoutCookie.Add(1)
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "worker"})
+	}))
+	defer server.Close()
+
+	request := NewDefaultRequest()
+	request.URL = server.URL
+
+	config := &JobConfig{
+		Request:       request,
+		Concurrency:   4,
+		TotalRequests: 40,
+		Timeout:       5 * time.Second,
+		Cookies:       true,
+	}
+
+	updates := make(chan *LoadTestStats, 10)
+	go config.Run(updates)
+	for range updates {
+	}
+
+	// Every worker logs in exactly once, then reuses its own session
+	assert.Equal(t, int64(4), withoutCookie.Load())
+	assert.Equal(t, int64(36), withCookie.Load())
+}
+
+func TestErrorLabel(t *testing.T) {
+	assert.Equal(t, "HTTP 503", ErrorLabel("503"))
+	assert.Equal(t, "gRPC Unavailable", ErrorLabel("gRPC Unavailable"))
+	assert.Equal(t, "gRPC DeadlineExceeded", (&JobConfig{GRPC: &GRPCCall{}}).errorKey(4))
+	assert.Equal(t, "4", (&JobConfig{}).errorKey(4))
+}
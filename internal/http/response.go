@@ -22,12 +22,47 @@ func (r *Response) ParseContentType() string {
 	return r.Headers.Get("Content-Type")
 }
 
+// ContentEncoding returns the encoding the body was received with, empty when it wasn't encoded
+func (r *Response) ContentEncoding() string {
+	if r.Raw == nil {
+		return ""
+	}
+	return r.Headers.Get("Content-Encoding")
+}
+
+// RawBody returns the body as it was received, before decoding
+func (r *Response) RawBody() []byte {
+	if r.Raw != nil {
+		return r.Raw
+	}
+	return []byte(r.Body)
+}
+
 type Response struct {
-	StatusCode int           `json:"status_code"`
-	Status     string        `json:"status,omitempty"`
-	Headers    http.Header   `json:"headers,omitempty"`
-	Body       string        `json:"body,omitempty"`
-	Duration   time.Duration `json:"duration,omitempty"`
-	Error      string        `json:"error,omitempty"`
-	RoundTrip  bool          `json:"round_trip,omitempty"`
+	StatusCode int            `json:"status_code"`
+	Status     string         `json:"status,omitempty"`
+	Proto      string         `json:"proto,omitempty"` // negotiated protocol, e.g. HTTP/2.0
+	Headers    http.Header    `json:"headers,omitempty"`
+	Cookies    []*http.Cookie `json:"cookies,omitempty"`   // parsed Set-Cookie headers
+	Body       string         `json:"body,omitempty"`      // decoded body, only its start when it was over body.limit
+	BodyFile   string         `json:"body_file,omitempty"` // temporary file with the whole body, when it was over body.limit
+	Size       int64          `json:"size,omitempty"`      // decoded body bytes, kept or not
+	Raw        []byte         `json:"-"`                   // body as received, only set when it was encoded and fit in body.limit
+	WireSize   int            `json:"wire_size,omitempty"` // body bytes received, before decoding
+	Duration   time.Duration  `json:"duration,omitempty"`
+	Timing     *Timing        `json:"timing,omitempty"`    // phase breakdown from httptrace
+	TLS        *TLSInfo       `json:"tls,omitempty"`       // negotiated connection, nil for plain http
+	URL        string         `json:"url,omitempty"`       // final URL, after redirects
+	Redirects  []RedirectHop  `json:"redirects,omitempty"` // followed redirects, in order
+	Events     []StreamEvent  `json:"events,omitempty"`    // Server-Sent Events, in order
+	Streamed   bool           `json:"streamed,omitempty"`  // body was delivered as it arrived
+	Frames     []WSFrame      `json:"frames,omitempty"`    // WebSocket transcript, in order
+	GraphQL    bool           `json:"graphql,omitempty"`   // answer to a GraphQL request
+	GRPC       bool           `json:"grpc,omitempty"`      // answer to a gRPC call, whose body is JSON
+	Trailers   http.Header    `json:"trailers,omitempty"`  // gRPC trailers, with the status
+	Attempts   []Attempt      `json:"attempts,omitempty"`  // earlier attempts that were retried, in order
+	Error      string         `json:"error,omitempty"`
+	RoundTrip  bool           `json:"round_trip,omitempty"`
+
+	failure string // FailureConnection or FailureTimeout when no response arrived
 }
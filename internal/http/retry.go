@@ -0,0 +1,194 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults of the retry options
+const (
+	defaultRetryOn       = "connection timeout 429 502 503 504"
+	defaultRetryBackoff  = 500 * time.Millisecond
+	defaultRetryMaxDelay = 30 * time.Second
+)
+
+// Kinds of failures before a response arrived
+const (
+	FailureConnection = "connection"
+	FailureTimeout    = "timeout"
+)
+
+// Timeouts are the time limits of a request. Zero leaves the client's default.
+type Timeouts struct {
+	Total   time.Duration // per attempt, from sending to reading the whole body
+	Connect time.Duration // to establish a connection, proxies included
+}
+
+// ParseTimeouts reads the timeout keys of a set of options
+func ParseTimeouts(options map[string]string) (Timeouts, error) {
+	total, err := optionDuration(options, OptTimeout)
+	if err != nil {
+		return Timeouts{}, err
+	}
+	connect, err := optionDuration(options, OptTimeoutConnect)
+	if err != nil {
+		return Timeouts{}, err
+	}
+	return Timeouts{Total: total, Connect: connect}, nil
+}
+
+// optionDuration parses a positive duration option, treating a missing key as zero
+func optionDuration(options map[string]string, key string) (time.Duration, error) {
+	value, ok := options[key]
+	if !ok || value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s: expected a positive duration such as 5s, got %q", key, value)
+	}
+	return d, nil
+}
+
+// RetryPolicy decides which failed attempts are tried again, and when.
+// The zero value never retries; use ParseRetryPolicy for the defaults.
+type RetryPolicy struct {
+	Max        int           // attempts after the first
+	Statuses   []int         // status codes to retry, e.g. 503
+	Classes    []int         // classes of status codes to retry, e.g. 5 for 5xx
+	Connection bool          // retry when no connection could be made or it broke
+	Timeout    bool          // retry attempts that timed out
+	Backoff    time.Duration // delay before the first retry, doubled for each one after
+	MaxDelay   time.Duration // cap on delays, including those asked for by Retry-After
+}
+
+// Attempt is a failed attempt that was retried
+type Attempt struct {
+	StatusCode int           `json:"status_code,omitempty"`
+	Status     string        `json:"status,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	Delay      time.Duration `json:"delay"`                 // wait before the next attempt
+	RetryAfter bool          `json:"retry_after,omitempty"` // Delay came from the Retry-After header
+}
+
+// ParseRetryPolicy reads the retries and retry.* keys of a set of options
+func ParseRetryPolicy(options map[string]string) (RetryPolicy, error) {
+	policy := RetryPolicy{Backoff: defaultRetryBackoff, MaxDelay: defaultRetryMaxDelay}
+
+	if value := options[OptRetries]; value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return RetryPolicy{}, fmt.Errorf("%s: expected a number of retries, got %q", OptRetries, value)
+		}
+		policy.Max = n
+	}
+
+	on := options[OptRetryOn]
+	if on == "" {
+		on = defaultRetryOn
+	}
+	for _, condition := range strings.Fields(strings.ToLower(on)) {
+		switch {
+		case condition == FailureConnection:
+			policy.Connection = true
+		case condition == FailureTimeout:
+			policy.Timeout = true
+		case len(condition) == 3 && condition[0] >= '1' && condition[0] <= '5' && condition[1:] == "xx":
+			policy.Classes = append(policy.Classes, int(condition[0]-'0'))
+		default:
+			code, err := strconv.Atoi(condition)
+			if err != nil || code < 100 || code > 599 {
+				return RetryPolicy{}, fmt.Errorf("%s: expected status codes, classes such as 5xx, connection or timeout, got %q", OptRetryOn, condition)
+			}
+			policy.Statuses = append(policy.Statuses, code)
+		}
+	}
+
+	backoff, err := optionDuration(options, OptRetryBackoff)
+	if err != nil {
+		return RetryPolicy{}, err
+	}
+	if backoff > 0 {
+		policy.Backoff = backoff
+	}
+	maxDelay, err := optionDuration(options, OptRetryMaxDelay)
+	if err != nil {
+		return RetryPolicy{}, err
+	}
+	if maxDelay > 0 {
+		policy.MaxDelay = maxDelay
+	}
+	return policy, nil
+}
+
+// Retryable reports whether the response of an attempt should be retried
+func (p RetryPolicy) Retryable(res *Response) bool {
+	if (res.failure == FailureConnection && p.Connection) || (res.failure == FailureTimeout && p.Timeout) {
+		return true
+	}
+	if res.StatusCode == 0 {
+		return false // no response, and not a failure trying again could fix
+	}
+	return slices.Contains(p.Statuses, res.StatusCode) || slices.Contains(p.Classes, res.StatusCode/100)
+}
+
+// Delay returns the wait before retry number n, counting from 0. The
+// server's Retry-After wins when it is set, otherwise the backoff doubles
+// with each retry and is jittered, so that clients don't retry in lockstep.
+func (p RetryPolicy) Delay(n int, retryAfter string) (delay time.Duration, fromHeader bool) {
+	if d, ok := parseRetryAfter(retryAfter, time.Now()); ok {
+		return min(d, p.MaxDelay), true
+	}
+
+	delay = p.MaxDelay
+	if n < 32 && p.Backoff<<n > 0 && p.Backoff<<n < p.MaxDelay {
+		delay = p.Backoff << n
+	}
+	// Equal jitter: between half and all of the delay
+	half := delay / 2
+	return half + rand.N(delay-half+1), false
+}
+
+// parseRetryAfter reads a Retry-After header, given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// failureOf tells timeouts from other failures of an attempt without a response
+func failureOf(ctx context.Context, err error) string {
+	var netErr net.Error
+	if errors.Is(context.Cause(ctx), context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return FailureTimeout
+	}
+	return FailureConnection
+}
+
+// newAttempt records the response of an attempt that is about to be retried
+func newAttempt(res *Response, duration, delay time.Duration, fromHeader bool) Attempt {
+	return Attempt{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Error:      res.Error,
+		Duration:   duration,
+		Delay:      delay,
+		RetryAfter: fromHeader,
+	}
+}
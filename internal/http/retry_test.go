@@ -0,0 +1,167 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRetryPolicy(t *testing.T) {
+	policy, err := ParseRetryPolicy(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, policy.Max, "no retries unless asked for")
+	assert.True(t, policy.Connection)
+	assert.True(t, policy.Timeout)
+	assert.Equal(t, []int{429, 502, 503, 504}, policy.Statuses)
+	assert.Equal(t, defaultRetryBackoff, policy.Backoff)
+
+	policy, err = ParseRetryPolicy(map[string]string{
+		OptRetries:       "3",
+		OptRetryOn:       "5xx 409 timeout",
+		OptRetryBackoff:  "100ms",
+		OptRetryMaxDelay: "2s",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, RetryPolicy{
+		Max:      3,
+		Statuses: []int{409},
+		Classes:  []int{5},
+		Timeout:  true,
+		Backoff:  100 * time.Millisecond,
+		MaxDelay: 2 * time.Second,
+	}, policy)
+
+	for _, options := range []map[string]string{
+		{OptRetries: "-1"},
+		{OptRetryOn: "teapot"},
+		{OptRetryOn: "600"},
+		{OptRetryBackoff: "soon"},
+		{OptTimeout: "0s"},
+		{OptTimeoutConnect: "fast"},
+	} {
+		assert.Error(t, ValidateOptions(options), "%v", options)
+	}
+}
+
+func TestRetryPolicy_Retryable(t *testing.T) {
+	policy, _ := ParseRetryPolicy(map[string]string{OptRetryOn: "5xx connection"})
+
+	assert.True(t, policy.Retryable(&Response{StatusCode: 502}))
+	assert.False(t, policy.Retryable(&Response{StatusCode: 429}))
+	assert.True(t, policy.Retryable(&Response{Error: "connection refused", failure: FailureConnection}))
+	assert.False(t, policy.Retryable(&Response{Error: "deadline exceeded", failure: FailureTimeout}))
+	assert.False(t, policy.Retryable(&Response{Error: "unknown option: foo"}))
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{Backoff: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for n, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		delay, fromHeader := policy.Delay(n, "")
+		assert.False(t, fromHeader)
+		assert.GreaterOrEqual(t, delay, want/2, "retry %d", n)
+		assert.LessOrEqual(t, delay, want, "retry %d", n)
+	}
+	delay, _ := policy.Delay(100, "")
+	assert.LessOrEqual(t, delay, time.Second, "no overflow on long runs")
+
+	delay, fromHeader := policy.Delay(0, "1")
+	assert.True(t, fromHeader)
+	assert.Equal(t, time.Second, delay)
+
+	delay, _ = policy.Delay(0, "120")
+	assert.Equal(t, time.Second, delay, "Retry-After is capped")
+
+	delay, fromHeader = policy.Delay(0, time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, fromHeader)
+	assert.Zero(t, delay, "dates in the past mean now")
+}
+
+func TestClient_SendRetries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+	client := InitClient(time.Second, false)
+	options := map[string]string{OptRetries: "3", OptRetryBackoff: "1ms"}
+
+	res := send(client, &Request{Method: GET, URL: server.URL, Options: options})
+	assert.Empty(t, res.Error)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "ok", res.Body)
+	if assert.Len(t, res.Attempts, 2) {
+		assert.Equal(t, http.StatusBadGateway, res.Attempts[0].StatusCode)
+		assert.False(t, res.Attempts[0].RetryAfter)
+		assert.Equal(t, http.StatusServiceUnavailable, res.Attempts[1].StatusCode)
+		assert.True(t, res.Attempts[1].RetryAfter)
+		assert.Zero(t, res.Attempts[1].Delay)
+	}
+
+	// Out of retries, the last response is kept
+	calls.Store(0)
+	res = send(client, &Request{Method: GET, URL: server.URL, Options: map[string]string{OptRetries: "1", OptRetryBackoff: "1ms"}})
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.Len(t, res.Attempts, 1)
+
+	// Statuses outside retry.on are final
+	calls.Store(0)
+	res = send(client, &Request{Method: GET, URL: server.URL, Options: map[string]string{OptRetries: "3", OptRetryOn: "503"}})
+	assert.Equal(t, http.StatusBadGateway, res.StatusCode)
+	assert.Empty(t, res.Attempts)
+}
+
+func TestClient_SendRetriesFailures(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	closed := "http://" + lis.Addr().String()
+	lis.Close()
+	client := InitClient(time.Second, false)
+
+	res := send(client, &Request{Method: GET, URL: closed, Options: map[string]string{OptRetries: "2", OptRetryBackoff: "1ms"}})
+	assert.NotEmpty(t, res.Error)
+	if assert.Len(t, res.Attempts, 2) {
+		assert.NotEmpty(t, res.Attempts[0].Error)
+	}
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+
+	start := time.Now()
+	res = send(client, &Request{Method: GET, URL: slow.URL, Options: map[string]string{
+		OptTimeout:      "50ms",
+		OptRetries:      "1",
+		OptRetryBackoff: "1ms",
+	}})
+	assert.NotEmpty(t, res.Error)
+	assert.Len(t, res.Attempts, 1, "timeouts are retried")
+	assert.Less(t, time.Since(start), time.Second, "the request's timeout overrides the client's")
+
+	res = send(client, &Request{Method: GET, URL: slow.URL, Options: map[string]string{
+		OptTimeout: "50ms",
+		OptRetries: "1",
+		OptRetryOn: "connection",
+	}})
+	assert.NotEmpty(t, res.Error)
+	assert.Empty(t, res.Attempts)
+}
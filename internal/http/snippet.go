@@ -0,0 +1,179 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SnippetFormats are the languages a request can be exported to, see Request.Snippet
+var SnippetFormats = []string{"curl", "httpie", "go", "python", "js"}
+
+// Snippet returns a command or code sending the request: a curl or HTTPie
+// command, Go net/http code, Python requests code or a JavaScript fetch.
+// The request is written as it is, so variables should be expanded and
+// secrets unsealed or redacted first. The empty JSON object the request
+// form gives bodyless requests is left out of GET requests.
+func (r *Request) Snippet(format string) (string, error) {
+	switch r.Method {
+	case WS, GRPC:
+		return "", fmt.Errorf("%s requests can't be exported", r.Method)
+	case GRAPHQL:
+		r = r.asPost()
+	case GET:
+		if r.Body == "{}" {
+			bodyless := *r
+			bodyless.Body = ""
+			r = &bodyless
+		}
+	}
+	opts, err := ParseTLSOptions(r.Options)
+	if err != nil {
+		return "", err
+	}
+
+	switch format {
+	case "curl":
+		return r.curlSnippet(opts.Insecure), nil
+	case "httpie":
+		return r.httpieSnippet(opts.Insecure), nil
+	case "go":
+		return r.goSnippet(opts.Insecure)
+	case "python":
+		return r.pythonSnippet(opts.Insecure), nil
+	case "js", "javascript":
+		return r.jsSnippet(), nil
+	}
+	return "", fmt.Errorf("unknown snippet format: %s (use %s)", format, strings.Join(SnippetFormats, ", "))
+}
+
+// sortedHeaders returns the header keys in a stable order
+func (r *Request) sortedHeaders() []string {
+	return slices.Sorted(maps.Keys(r.Headers))
+}
+
+// shellQuote quotes a word for POSIX shells
+func shellQuote(word string) string {
+	if word != "" && strings.Trim(word, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@,+%") == "" {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+func (r *Request) curlSnippet(insecure bool) string {
+	words := []string{"curl"}
+	if r.Method != GET || r.Body != "" {
+		words = append(words, "-X "+r.Method)
+	}
+	words = append(words, shellQuote(r.URL))
+	for _, key := range r.sortedHeaders() {
+		words = append(words, "-H "+shellQuote(key+": "+r.Headers[key]))
+	}
+	if r.Body != "" {
+		words = append(words, "--data-raw "+shellQuote(r.Body))
+	}
+	if insecure {
+		words = append(words, "-k")
+	}
+	return strings.Join(words, " \\\n  ")
+}
+
+func (r *Request) httpieSnippet(insecure bool) string {
+	words := []string{"http"}
+	if insecure {
+		words = append(words, "--verify=no")
+	}
+	words = append(words, r.Method, shellQuote(r.URL))
+	for _, key := range r.sortedHeaders() {
+		words = append(words, shellQuote(key+":"+r.Headers[key]))
+	}
+	if r.Body != "" {
+		words = append(words, "--raw "+shellQuote(r.Body))
+	}
+	return strings.Join(words, " \\\n  ")
+}
+
+func (r *Request) goSnippet(insecure bool) (string, error) {
+	var code strings.Builder
+	code.WriteString("package main\n\nimport (\n")
+	if insecure {
+		code.WriteString("\"crypto/tls\"\n")
+	}
+	code.WriteString("\"fmt\"\n\"io\"\n\"net/http\"\n")
+	if r.Body != "" {
+		code.WriteString("\"strings\"\n")
+	}
+	code.WriteString(")\n\nfunc main() {\n")
+
+	body := "nil"
+	if r.Body != "" {
+		body = "strings.NewReader(" + strconv.Quote(r.Body) + ")"
+	}
+	fmt.Fprintf(&code, "req, err := http.NewRequest(%q, %q, %s)\nif err != nil {\npanic(err)\n}\n", r.Method, r.URL, body)
+	for _, key := range r.sortedHeaders() {
+		fmt.Fprintf(&code, "req.Header.Set(%q, %q)\n", key, r.Headers[key])
+	}
+
+	client := "http.DefaultClient"
+	if insecure {
+		code.WriteString("\nclient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}\n")
+		client = "client"
+	}
+	fmt.Fprintf(&code, "\nres, err := %s.Do(req)\nif err != nil {\npanic(err)\n}\ndefer res.Body.Close()\n", client)
+	code.WriteString("\ndata, err := io.ReadAll(res.Body)\nif err != nil {\npanic(err)\n}\nfmt.Println(res.Status)\nfmt.Println(string(data))\n}\n")
+
+	formatted, err := format.Source([]byte(code.String()))
+	if err != nil {
+		return "", err
+	}
+	return string(formatted), nil
+}
+
+// scriptQuote quotes a string for Python and JavaScript, whose string
+// literals accept JSON's escapes
+func scriptQuote(value string) string {
+	quoted, _ := json.Marshal(value)
+	return string(quoted)
+}
+
+func (r *Request) pythonSnippet(insecure bool) string {
+	var code strings.Builder
+	code.WriteString("import requests\n\nresponse = requests.request(\n")
+	fmt.Fprintf(&code, "    %s,\n    %s,\n", scriptQuote(r.Method), scriptQuote(r.URL))
+	if len(r.Headers) > 0 {
+		code.WriteString("    headers={\n")
+		for _, key := range r.sortedHeaders() {
+			fmt.Fprintf(&code, "        %s: %s,\n", scriptQuote(key), scriptQuote(r.Headers[key]))
+		}
+		code.WriteString("    },\n")
+	}
+	if r.Body != "" {
+		fmt.Fprintf(&code, "    data=%s,\n", scriptQuote(r.Body))
+	}
+	if insecure {
+		code.WriteString("    verify=False,\n")
+	}
+	code.WriteString(")\nprint(response.status_code)\nprint(response.text)\n")
+	return code.String()
+}
+
+func (r *Request) jsSnippet() string {
+	var code strings.Builder
+	fmt.Fprintf(&code, "const response = await fetch(%s, {\n  method: %s,\n", scriptQuote(r.URL), scriptQuote(r.Method))
+	if len(r.Headers) > 0 {
+		code.WriteString("  headers: {\n")
+		for _, key := range r.sortedHeaders() {
+			fmt.Fprintf(&code, "    %s: %s,\n", scriptQuote(key), scriptQuote(r.Headers[key]))
+		}
+		code.WriteString("  },\n")
+	}
+	if r.Body != "" {
+		fmt.Fprintf(&code, "  body: %s,\n", scriptQuote(r.Body))
+	}
+	code.WriteString("});\nconsole.log(response.status);\nconsole.log(await response.text());\n")
+	return code.String()
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequest_SnippetCurl(t *testing.T) {
+	req := &Request{
+		Method:  POST,
+		URL:     "https://api.example.com/users?q=a b",
+		Headers: map[string]string{"X-Note": "it's", "Content-Type": "application/json"},
+		Body:    `{"name": "O'Brien"}`,
+		Options: map[string]string{OptTLSInsecure: "true"},
+	}
+
+	snippet, err := req.Snippet("curl")
+	require.NoError(t, err)
+	assert.Equal(t, `curl \
+  -X POST \
+  'https://api.example.com/users?q=a b' \
+  -H 'Content-Type: application/json' \
+  -H 'X-Note: it'\''s' \
+  --data-raw '{"name": "O'\''Brien"}' \
+  -k`, snippet)
+}
+
+func TestRequest_SnippetLeavesOutEmptyGetBody(t *testing.T) {
+	req := &Request{Method: GET, URL: "https://api.example.com/users", Body: "{}"}
+
+	snippet, err := req.Snippet("curl")
+	require.NoError(t, err)
+	assert.Equal(t, "curl \\\n  https://api.example.com/users", snippet)
+
+	snippet, err = req.Snippet("httpie")
+	require.NoError(t, err)
+	assert.Equal(t, "http \\\n  GET \\\n  https://api.example.com/users", snippet)
+}
+
+func TestRequest_SnippetCode(t *testing.T) {
+	req := &Request{
+		Method:  PUT,
+		URL:     "https://api.example.com/users/7",
+		Headers: map[string]string{"Authorization": "Bearer abc"},
+		Body:    "{\"note\": \"line\\nbreak\"}",
+		Options: map[string]string{OptTLSInsecure: "true"},
+	}
+
+	code, err := req.Snippet("go")
+	require.NoError(t, err)
+	assert.Contains(t, code, `http.NewRequest("PUT", "https://api.example.com/users/7", strings.NewReader("{\"note\": \"line\\nbreak\"}"))`)
+	assert.Contains(t, code, `req.Header.Set("Authorization", "Bearer abc")`)
+	assert.Contains(t, code, "InsecureSkipVerify: true")
+
+	code, err = req.Snippet("python")
+	require.NoError(t, err)
+	assert.Contains(t, code, `"Authorization": "Bearer abc",`)
+	assert.Contains(t, code, `data="{\"note\": \"line\\nbreak\"}",`)
+	assert.Contains(t, code, "verify=False,")
+
+	code, err = req.Snippet("js")
+	require.NoError(t, err)
+	assert.Contains(t, code, `fetch("https://api.example.com/users/7", {`)
+	assert.Contains(t, code, `method: "PUT",`)
+}
+
+func TestRequest_SnippetGraphQLAndUnsupported(t *testing.T) {
+	body, err := NewGraphQLBody("{ me { id } }", "", "")
+	require.NoError(t, err)
+	req := &Request{Method: GRAPHQL, URL: "https://api.example.com/graphql", Body: body}
+
+	snippet, err := req.Snippet("curl")
+	require.NoError(t, err)
+	assert.Contains(t, snippet, "-X POST")
+	assert.Contains(t, snippet, "-H 'Content-Type: application/json'")
+
+	_, err = req.Snippet("perl")
+	assert.Error(t, err)
+
+	_, err = (&Request{Method: WS, URL: "wss://api.example.com"}).Snippet("curl")
+	assert.Error(t, err)
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Values of the stream option
+const (
+	StreamAuto = "auto" // stream Server-Sent Events, buffer everything else
+	StreamOn   = "on"   // stream any response body as it arrives
+	StreamOff  = "off"  // always wait for the complete body
+)
+
+// StreamEvent is one Server-Sent Event
+type StreamEvent struct {
+	Time  time.Time     `json:"time"` // when the event was received
+	ID    string        `json:"id,omitempty"`
+	Event string        `json:"event,omitempty"` // empty means "message"
+	Data  string        `json:"data"`
+	Retry time.Duration `json:"retry,omitempty"`
+}
+
+// Type returns the event type, "message" unless the server named it
+func (e StreamEvent) Type() string {
+	if e.Event == "" {
+		return "message"
+	}
+	return e.Event
+}
+
+// StreamUpdate is sent by Client.Stream. The first update of a streamed
+// response carries its headers, then every event or chunk of the body gets
+// its own update, and the last one has the complete response. Bodies that
+// aren't streamed report their download progress instead.
+type StreamUpdate struct {
+	Response *Response    // headers on the first update, the complete response when Done
+	Event    *StreamEvent // a Server-Sent Event
+	Chunk    string       // a piece of any other body
+	Progress *Progress    // how much of a body that isn't streamed was received
+	Done     bool         // last update, the channel is closed after it
+}
+
+// ParseStreamMode reads the stream option
+func ParseStreamMode(options map[string]string) (string, error) {
+	value := strings.ToLower(options[OptStream])
+	switch value {
+	case "", StreamAuto:
+		return StreamAuto, nil
+	case StreamOn, StreamOff:
+		return value, nil
+	}
+	if on, err := strconv.ParseBool(value); err == nil {
+		if on {
+			return StreamOn, nil
+		}
+		return StreamOff, nil
+	}
+	return "", fmt.Errorf("%s: expected auto, on or off, got %q", OptStream, options[OptStream])
+}
+
+// isEventStream reports whether the response is a Server-Sent Events stream
+func isEventStream(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "text/event-stream"
+}
+
+// readEvents parses Server-Sent Events from r, calling emit for each one,
+// and returns the raw text that was read
+func readEvents(r io.Reader, emit func(StreamEvent)) (string, error) {
+	reader := bufio.NewReader(r)
+	var raw strings.Builder
+	var event StreamEvent
+	var data []string
+
+	for {
+		line, err := reader.ReadString('\n')
+		raw.WriteString(line)
+
+		if line != "" || err == nil {
+			line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+			if line == "" {
+				// a blank line dispatches the event, if it had any data
+				if len(data) > 0 {
+					event.Time = time.Now()
+					event.Data = strings.Join(data, "\n")
+					emit(event)
+				}
+				event, data = StreamEvent{ID: event.ID}, nil
+			} else if !strings.HasPrefix(line, ":") { // lines starting with a colon are comments
+				field, value, _ := strings.Cut(line, ":")
+				value = strings.TrimPrefix(value, " ")
+				switch field {
+				case "data":
+					data = append(data, value)
+				case "event":
+					event.Event = value
+				case "id":
+					event.ID = value
+				case "retry":
+					if ms, err := strconv.Atoi(value); err == nil {
+						event.Retry = time.Duration(ms) * time.Millisecond
+					}
+				}
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return raw.String(), nil
+		}
+		if err != nil {
+			return raw.String(), err
+		}
+	}
+}
+
+// readChunks reads r as it arrives, calling emit for each chunk, and returns the whole body
+func readChunks(r io.Reader, emit func(string)) (string, error) {
+	var body strings.Builder
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			chunk := string(buf[:n])
+			body.WriteString(chunk)
+			emit(chunk)
+		}
+		if errors.Is(err, io.EOF) {
+			return body.String(), nil
+		}
+		if err != nil {
+			return body.String(), err
+		}
+	}
+}
+
+// countingReader counts the bytes read through it, and keeps the error that ended them
+type countingReader struct {
+	r   io.Reader
+	n   int
+	err error
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	if err != nil && !errors.Is(err, io.EOF) {
+		c.err = err
+	}
+	return n, err
+}
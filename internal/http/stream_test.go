@@ -0,0 +1,185 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// eventServer sends count events, one every interval, then ends the stream
+func eventServer(count int, interval time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+		for i := 1; i <= count; i++ {
+			fmt.Fprintf(w, "id: %d\nevent: tick\ndata: {\"n\":%d}\n\n", i, i)
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+	}))
+}
+
+// collect runs Client.Stream and gathers its updates
+func collect(client *Client, ctx context.Context, req *Request) ([]StreamUpdate, *Response) {
+	updates := make(chan StreamUpdate)
+	go client.Stream(ctx, req, updates)
+
+	var received []StreamUpdate
+	var final *Response
+	for update := range updates {
+		if update.Done {
+			final = update.Response
+			continue
+		}
+		received = append(received, update)
+	}
+	return received, final
+}
+
+func TestReadEvents(t *testing.T) {
+	input := ": comment\n" +
+		"data: first\n\n" +
+		"event: update\r\nid: 7\r\ndata: line one\r\ndata:line two\r\n\r\n" +
+		"retry: 3000\n\n" + // no data, not dispatched
+		"data: keeps the last id\n\n" +
+		"data: unterminated"
+
+	var events []StreamEvent
+	raw, err := readEvents(strings.NewReader(input), func(e StreamEvent) {
+		events = append(events, e)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, input, raw)
+
+	if assert.Len(t, events, 3) {
+		assert.Equal(t, "message", events[0].Type())
+		assert.Equal(t, "first", events[0].Data)
+
+		assert.Equal(t, "update", events[1].Type())
+		assert.Equal(t, "7", events[1].ID)
+		assert.Equal(t, "line one\nline two", events[1].Data)
+
+		assert.Equal(t, "7", events[2].ID)
+		assert.Equal(t, "keeps the last id", events[2].Data)
+		assert.False(t, events[2].Time.IsZero())
+	}
+}
+
+func TestParseStreamMode(t *testing.T) {
+	for value, want := range map[string]string{"": StreamAuto, "auto": StreamAuto, "on": StreamOn, "true": StreamOn, "OFF": StreamOff, "0": StreamOff} {
+		got, err := ParseStreamMode(map[string]string{OptStream: value})
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, value)
+	}
+	_, err := ParseStreamMode(map[string]string{OptStream: "sometimes"})
+	assert.Error(t, err)
+}
+
+func TestClient_StreamEvents(t *testing.T) {
+	server := eventServer(3, 10*time.Millisecond)
+	defer server.Close()
+
+	// the timeout only covers the headers, the stream takes longer
+	client := InitClient(15*time.Millisecond, false)
+	updates, res := collect(client, context.Background(), &Request{Method: GET, URL: server.URL})
+
+	if assert.Len(t, updates, 4) {
+		assert.Equal(t, 200, updates[0].Response.StatusCode)
+		for i, update := range updates[1:] {
+			if assert.NotNil(t, update.Event) {
+				assert.Equal(t, fmt.Sprint(i+1), update.Event.ID)
+				assert.Equal(t, fmt.Sprintf(`{"n":%d}`, i+1), update.Event.Data)
+			}
+		}
+	}
+
+	assert.Empty(t, res.Error)
+	assert.True(t, res.Streamed)
+	assert.Len(t, res.Events, 3)
+	assert.Contains(t, res.Body, "event: tick")
+	assert.Equal(t, len(res.Body), res.WireSize)
+}
+
+func TestClient_StreamStop(t *testing.T) {
+	server := eventServer(1000, 5*time.Millisecond)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := InitClient(time.Second, false)
+	updates := make(chan StreamUpdate)
+	go client.Stream(ctx, &Request{Method: GET, URL: server.URL}, updates)
+
+	var final *Response
+	events := 0
+	for update := range updates {
+		if update.Event != nil {
+			if events++; events == 3 {
+				cancel()
+			}
+		}
+		if update.Done {
+			final = update.Response
+		}
+	}
+
+	// stopping keeps what was received, without an error
+	assert.Empty(t, final.Error)
+	assert.GreaterOrEqual(t, len(final.Events), 3)
+	assert.Less(t, len(final.Events), 1000)
+}
+
+func TestClient_StreamChunks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(w, "{\"line\":%d}\n", i)
+			w.(http.Flusher).Flush()
+			time.Sleep(5 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	client := InitClient(time.Second, false)
+
+	// without the option, only event streams are streamed
+	updates, res := collect(client, context.Background(), &Request{Method: GET, URL: server.URL})
+	assert.Empty(t, updates)
+	assert.False(t, res.Streamed)
+
+	updates, res = collect(client, context.Background(), &Request{Method: GET, URL: server.URL, Options: map[string]string{OptStream: "on"}})
+	assert.Greater(t, len(updates), 1)
+	var body strings.Builder
+	for _, update := range updates[1:] {
+		body.WriteString(update.Chunk)
+	}
+	assert.Equal(t, "{\"line\":0}\n{\"line\":1}\n{\"line\":2}\n", body.String())
+	assert.Equal(t, body.String(), res.Body)
+	assert.True(t, res.Streamed)
+}
+
+func TestClient_StreamCompressed(t *testing.T) {
+	body := "data: compressed\n\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(compress("gzip", []byte(body)))
+	}))
+	defer server.Close()
+
+	_, res := collect(InitClient(time.Second, false), context.Background(), &Request{Method: GET, URL: server.URL})
+	assert.Empty(t, res.Error)
+	if assert.Len(t, res.Events, 1) {
+		assert.Equal(t, "compressed", res.Events[0].Data)
+	}
+	assert.Equal(t, body, res.Body)
+	assert.Less(t, 0, res.WireSize)
+}
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timing is the breakdown of where the time of a request went
+type Timing struct {
+	DNSLookup       time.Duration `json:"dns_lookup"`
+	TCPConnect      time.Duration `json:"tcp_connect"`
+	TLSHandshake    time.Duration `json:"tls_handshake"`
+	Waiting         time.Duration `json:"waiting"`            // request written until first response byte
+	TimeToFirstByte time.Duration `json:"time_to_first_byte"` // start until first response byte
+	ContentTransfer time.Duration `json:"content_transfer"`
+	Total           time.Duration `json:"total"`
+
+	ConnReused bool   `json:"conn_reused"`
+	RemoteAddr string `json:"remote_addr,omitempty"`
+}
+
+// TimingPhase is one consecutive segment of a request, used to draw a waterfall
+type TimingPhase struct {
+	Name     string
+	Start    time.Duration // offset from the start of the request
+	Duration time.Duration
+}
+
+// Phases returns the request phases in order. Phases that didn't happen,
+// like DNS on a reused connection, have a zero duration.
+func (t *Timing) Phases() []TimingPhase {
+	names := []string{"DNS Lookup", "TCP Connect", "TLS Handshake", "Waiting (TTFB)", "Content Transfer"}
+	durations := []time.Duration{t.DNSLookup, t.TCPConnect, t.TLSHandshake, t.Waiting, t.ContentTransfer}
+
+	phases := make([]TimingPhase, len(names))
+	var offset time.Duration
+	for i, name := range names {
+		phases[i] = TimingPhase{Name: name, Start: offset, Duration: durations[i]}
+		offset += durations[i]
+	}
+
+	// Anything unaccounted for (e.g. time spent waiting for a pooled
+	// connection) happens before the request is written
+	if gap := t.TimeToFirstByte - (t.DNSLookup + t.TCPConnect + t.TLSHandshake + t.Waiting); gap > 0 {
+		for i := 3; i < len(phases); i++ {
+			phases[i].Start += gap
+		}
+	}
+	return phases
+}
+
+// timingTracer records httptrace events for a single request
+type timingTracer struct {
+	mu sync.Mutex
+
+	start        time.Time
+	dnsStart     time.Time
+	dnsDone      time.Time
+	connectStart time.Time
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+
+	reused     bool
+	remoteAddr string
+}
+
+func newTimingTracer() *timingTracer {
+	return &timingTracer{start: time.Now()}
+}
+
+// clientTrace returns the hooks that feed the tracer
+func (t *timingTracer) clientTrace() *httptrace.ClientTrace {
+	record := func(field *time.Time) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		*field = time.Now()
+	}
+
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { record(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { record(&t.dnsDone) },
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			// Happy eyeballs may dial more than once; keep the first attempt
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(string, string, error) { record(&t.connectDone) },
+		TLSHandshakeStart: func() {
+			record(&t.tlsStart)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) { record(&t.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.reused = info.Reused
+			if info.Conn != nil {
+				t.remoteAddr = info.Conn.RemoteAddr().String()
+			}
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { record(&t.wroteRequest) },
+		GotFirstResponseByte: func() { record(&t.firstByte) },
+	}
+}
+
+// timing computes the breakdown, with end being the moment the body was read
+func (t *timingTracer) timing(end time.Time) *Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return &Timing{
+		DNSLookup:       between(t.dnsStart, t.dnsDone),
+		TCPConnect:      between(t.connectStart, t.connectDone),
+		TLSHandshake:    between(t.tlsStart, t.tlsDone),
+		Waiting:         between(t.wroteRequest, t.firstByte),
+		TimeToFirstByte: between(t.start, t.firstByte),
+		ContentTransfer: between(t.firstByte, end),
+		Total:           end.Sub(t.start),
+		ConnReused:      t.reused,
+		RemoteAddr:      t.remoteAddr,
+	}
+}
+
+// between returns end - start, or zero when either event never happened
+func between(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
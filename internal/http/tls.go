@@ -0,0 +1,176 @@
+package http
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// TLSOptions configures how the clients establish TLS connections
+type TLSOptions struct {
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	Insecure   bool
+	MinVersion uint16
+	MaxVersion uint16
+	ServerName string
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseTLSOptions reads the tls.* keys of a set of options
+func ParseTLSOptions(options map[string]string) (TLSOptions, error) {
+	opts := TLSOptions{
+		CAFile:     options[OptTLSCA],
+		CertFile:   options[OptTLSCert],
+		KeyFile:    options[OptTLSKey],
+		ServerName: options[OptTLSServerName],
+	}
+
+	insecure, err := optionBool(options, OptTLSInsecure)
+	if err != nil {
+		return TLSOptions{}, err
+	}
+	opts.Insecure = insecure
+
+	if opts.MinVersion, err = parseTLSVersion(options, OptTLSMin); err != nil {
+		return TLSOptions{}, err
+	}
+	if opts.MaxVersion, err = parseTLSVersion(options, OptTLSMax); err != nil {
+		return TLSOptions{}, err
+	}
+	if opts.MinVersion != 0 && opts.MaxVersion != 0 && opts.MinVersion > opts.MaxVersion {
+		return TLSOptions{}, fmt.Errorf("%s is higher than %s", OptTLSMin, OptTLSMax)
+	}
+
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		return TLSOptions{}, fmt.Errorf("%s and %s must be set together", OptTLSCert, OptTLSKey)
+	}
+
+	return opts, nil
+}
+
+func parseTLSVersion(options map[string]string, key string) (uint16, error) {
+	value := strings.TrimPrefix(strings.ToLower(options[key]), "tls")
+	if value == "" {
+		return 0, nil
+	}
+	version, ok := tlsVersions[value]
+	if !ok {
+		return 0, fmt.Errorf("%s: unsupported TLS version %q (use 1.0 to 1.3)", key, options[key])
+	}
+	return version, nil
+}
+
+// IsZero reports whether no TLS settings are configured
+func (o TLSOptions) IsZero() bool {
+	return o == TLSOptions{}
+}
+
+// Config builds a tls.Config, loading the CA bundle and client certificate from disk
+func (o TLSOptions) Config() (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: o.Insecure,
+		MinVersion:         o.MinVersion,
+		MaxVersion:         o.MaxVersion,
+		ServerName:         o.ServerName,
+	}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", o.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if o.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// LoadTLSConfig builds the tls.Config described by a set of options, or
+// returns nil when the options have no TLS settings
+func LoadTLSConfig(options map[string]string) (*tls.Config, error) {
+	opts, err := ParseTLSOptions(options)
+	if err != nil || opts.IsZero() {
+		return nil, err
+	}
+	return opts.Config()
+}
+
+// TLSInfo describes the negotiated TLS connection of a response
+type TLSInfo struct {
+	Version     string            `json:"version"`
+	CipherSuite string            `json:"cipher_suite"`
+	ServerName  string            `json:"server_name,omitempty"`
+	ALPN        string            `json:"alpn,omitempty"`
+	Insecure    bool              `json:"insecure,omitempty"` // certificate verification was skipped
+	Chain       []CertificateInfo `json:"chain,omitempty"`
+}
+
+// CertificateInfo is a summary of one certificate of the peer's chain
+type CertificateInfo struct {
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	DNSNames  []string  `json:"dns_names,omitempty"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+	SHA256    string    `json:"sha256"`
+}
+
+// ExpiresIn returns the time left until the certificate expires
+func (c CertificateInfo) ExpiresIn(now time.Time) time.Duration {
+	return c.NotAfter.Sub(now)
+}
+
+// newTLSInfo summarizes a connection state, or returns nil for plain connections
+func newTLSInfo(state *tls.ConnectionState, insecure bool) *TLSInfo {
+	if state == nil {
+		return nil
+	}
+
+	info := &TLSInfo{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ServerName:  state.ServerName,
+		ALPN:        state.NegotiatedProtocol,
+		Insecure:    insecure,
+	}
+	for _, cert := range state.PeerCertificates {
+		fingerprint := sha256.Sum256(cert.Raw)
+		info.Chain = append(info.Chain, CertificateInfo{
+			Subject:   cert.Subject.String(),
+			Issuer:    cert.Issuer.String(),
+			DNSNames:  cert.DNSNames,
+			NotBefore: cert.NotBefore,
+			NotAfter:  cert.NotAfter,
+			SHA256:    hex.EncodeToString(fingerprint[:]),
+		})
+	}
+	return info
+}
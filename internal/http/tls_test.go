@@ -0,0 +1,213 @@
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeServerCA writes the certificate of a test TLS server as a CA bundle
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeClientCert creates a self-signed client certificate and returns its
+// pool along with the cert and key file paths
+func writeClientCert(t *testing.T) (*x509.CertPool, string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "volt-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "client.pem")
+	keyPath := filepath.Join(dir, "client-key.pem")
+	assert.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return pool, certPath, keyPath
+}
+
+func send(client *Client, req *Request) *Response {
+	result := make(chan *Response)
+	go client.Send(req, result)
+	return <-result
+}
+
+func TestParseTLSOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string]string
+		want    TLSOptions
+		wantErr bool
+	}{
+		{name: "empty", options: nil, want: TLSOptions{}},
+		{
+			name:    "versions and server name",
+			options: map[string]string{OptTLSMin: "1.2", OptTLSMax: "TLS1.3", OptTLSServerName: "api.internal"},
+			want:    TLSOptions{MinVersion: tls.VersionTLS12, MaxVersion: tls.VersionTLS13, ServerName: "api.internal"},
+		},
+		{name: "insecure", options: map[string]string{OptTLSInsecure: "true"}, want: TLSOptions{Insecure: true}},
+		{name: "bad bool", options: map[string]string{OptTLSInsecure: "maybe"}, wantErr: true},
+		{name: "bad version", options: map[string]string{OptTLSMin: "2.0"}, wantErr: true},
+		{name: "min above max", options: map[string]string{OptTLSMin: "1.3", OptTLSMax: "1.2"}, wantErr: true},
+		{name: "cert without key", options: map[string]string{OptTLSCert: "client.pem"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTLSOptions(tt.options)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	assert.Error(t, ValidateOptions(map[string]string{"tls.cipher": "x"}), "unknown keys are rejected")
+}
+
+func TestClient_SendTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secure"))
+	}))
+	defer server.Close()
+	caPath := writeServerCA(t, server)
+
+	client := InitClient(time.Second, true)
+
+	t.Run("untrusted certificate fails", func(t *testing.T) {
+		res := send(client, &Request{Method: GET, URL: server.URL})
+		assert.Contains(t, res.Error, "certificate")
+	})
+
+	t.Run("custom CA", func(t *testing.T) {
+		res := send(client, &Request{Method: GET, URL: server.URL, Options: map[string]string{OptTLSCA: caPath}})
+		assert.Empty(t, res.Error)
+		assert.Equal(t, "secure", res.Body)
+		if assert.NotNil(t, res.TLS) {
+			assert.False(t, res.TLS.Insecure)
+			assert.NotEmpty(t, res.TLS.Version)
+			assert.NotEmpty(t, res.TLS.CipherSuite)
+			assert.Len(t, res.TLS.Chain, 1)
+			assert.Equal(t, server.Certificate().NotAfter, res.TLS.Chain[0].NotAfter)
+		}
+	})
+
+	t.Run("environment defaults", func(t *testing.T) {
+		envClient := InitClient(time.Second, true)
+		envClient.Defaults = map[string]string{OptTLSInsecure: "true", OptTLSMax: "1.2"}
+
+		res := send(envClient, &Request{Method: GET, URL: server.URL})
+		assert.Empty(t, res.Error)
+		assert.True(t, res.TLS.Insecure)
+		assert.Equal(t, "TLS 1.2", res.TLS.Version)
+
+		// the request overrides the environment
+		res = send(envClient, &Request{Method: GET, URL: server.URL, Options: map[string]string{OptTLSMax: "1.3"}})
+		assert.Empty(t, res.Error)
+		assert.Equal(t, "TLS 1.3", res.TLS.Version)
+	})
+
+	t.Run("plain http has no TLS info", func(t *testing.T) {
+		plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer plain.Close()
+		res := send(client, &Request{Method: GET, URL: plain.URL})
+		assert.Nil(t, res.TLS)
+	})
+}
+
+func TestClient_SendMutualTLS(t *testing.T) {
+	pool, certPath, keyPath := writeClientCert(t)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	server.StartTLS()
+	defer server.Close()
+
+	client := InitClient(time.Second, true)
+
+	res := send(client, &Request{Method: GET, URL: server.URL, Options: map[string]string{OptTLSInsecure: "true"}})
+	assert.NotEmpty(t, res.Error, "server requires a client certificate")
+
+	options := map[string]string{
+		OptTLSCA:   writeServerCA(t, server),
+		OptTLSCert: certPath,
+		OptTLSKey:  keyPath,
+	}
+	res = send(client, &Request{Method: GET, URL: server.URL, Options: options})
+	assert.Empty(t, res.Error)
+	assert.Equal(t, "volt-client", res.Body)
+
+	// the load test engine presents the same certificate
+	tlsConfig, err := LoadTLSConfig(options)
+	assert.NoError(t, err)
+
+	config := &JobConfig{
+		Request:       &Request{Method: GET, URL: server.URL},
+		Concurrency:   2,
+		TotalRequests: 10,
+		Timeout:       5 * time.Second,
+		TLSConfig:     tlsConfig,
+	}
+	updates := make(chan *LoadTestStats, 10)
+	go config.Run(updates)
+	var final *LoadTestStats
+	for stats := range updates {
+		final = stats
+	}
+	if assert.NotNil(t, final) {
+		assert.Equal(t, 10, final.CompletedRequests)
+		assert.Equal(t, 0, final.FailedRequests)
+	}
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+
+	"github.com/owenHochwald/volt/internal/secrets"
+)
+
+// variablePattern matches a {{name}} reference to an environment variable
+var variablePattern = regexp.MustCompile(`\{\{\s*([\w.-]+)\s*\}\}`)
+
+// ExpandVariables replaces the {{name}} references in text with the values
+// of variables, leaving unknown ones as they are
+func ExpandVariables(text string, variables map[string]string) string {
+	if len(variables) == 0 || !strings.Contains(text, "{{") {
+		return text
+	}
+	return variablePattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := variablePattern.FindStringSubmatch(match)[1]
+		if value, ok := variables[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// Expand returns the request with the variables referenced in its URL,
+// headers and body replaced. Sealed header values are left alone.
+func (r *Request) Expand(variables map[string]string) *Request {
+	if len(variables) == 0 {
+		return r
+	}
+	expanded := *r
+	expanded.URL = ExpandVariables(r.URL, variables)
+	expanded.Body = ExpandVariables(r.Body, variables)
+	expanded.Headers = maps.Clone(r.Headers)
+	for key, value := range expanded.Headers {
+		expanded.Headers[key] = ExpandVariables(value, variables)
+	}
+	return &expanded
+}
+
+// maskVariables masks the values of secret variables
+func maskVariables(variables map[string]string) map[string]string {
+	masked := maps.Clone(variables)
+	for key, value := range variables {
+		if secrets.IsSealed(value) {
+			masked[key] = secrets.Mask
+		}
+	}
+	return masked
+}
+
+// openVariables decrypts the values of secret variables, which are saved
+// sealed like secret header values
+func openVariables(vault *secrets.Vault, variables map[string]string) (map[string]string, error) {
+	opened := maps.Clone(variables)
+	for key, value := range variables {
+		if !secrets.IsSealed(value) {
+			continue
+		}
+		plaintext, err := vault.Open(value)
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %w", key, err)
+		}
+		opened[key] = plaintext
+	}
+	return opened, nil
+}
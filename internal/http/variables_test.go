@@ -0,0 +1,63 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/owenHochwald/volt/internal/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequest_Expand(t *testing.T) {
+	variables := map[string]string{"base_url": "https://api.example.com", "token": "abc", "user.id": "7"}
+	req := &Request{
+		Method:  POST,
+		URL:     "{{base_url}}/users/{{ user.id }}?q={{missing}}",
+		Headers: map[string]string{"Authorization": "Bearer {{token}}"},
+		Body:    `{"id": {{user.id}}}`,
+	}
+
+	expanded := req.Expand(variables)
+	assert.Equal(t, "https://api.example.com/users/7?q={{missing}}", expanded.URL, "unknown variables are left as they are")
+	assert.Equal(t, "Bearer abc", expanded.Headers["Authorization"])
+	assert.Equal(t, `{"id": 7}`, expanded.Body)
+	assert.Equal(t, "Bearer {{token}}", req.Headers["Authorization"], "the request isn't changed")
+
+	assert.Same(t, req, req.Expand(nil))
+}
+
+func TestClient_UnsealExpandsSecrets(t *testing.T) {
+	vault, err := secrets.NewVault([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	sealed, err := vault.Seal("Bearer {{token}}")
+	require.NoError(t, err)
+
+	client := &Client{Vault: vault, Variables: map[string]string{"token": "abc"}}
+	req := &Request{Method: GET, URL: "https://api.example", Headers: map[string]string{"Authorization": sealed}, Secrets: []string{"Authorization"}}
+
+	// the reference can't be expanded until the value is decrypted
+	assert.Equal(t, sealed, req.Expand(client.Variables).Headers["Authorization"])
+
+	unsealed, err := client.Unseal(req)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer abc", unsealed.Headers["Authorization"])
+}
+
+func TestClient_UnsealOpensSecretVariables(t *testing.T) {
+	vault, err := secrets.NewVault([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	token, err := vault.Seal("abc")
+	require.NoError(t, err)
+
+	client := &Client{Vault: vault, Variables: map[string]string{"token": token}}
+	req := &Request{Method: GET, URL: "https://api.example", Headers: map[string]string{"Authorization": "Bearer {{token}}"}}
+
+	unsealed, err := client.Unseal(req)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer abc", unsealed.Headers["Authorization"])
+	assert.Equal(t, token, client.Variables["token"], "the variables aren't changed")
+
+	client.Vault = nil
+	_, err = client.Unseal(req)
+	assert.ErrorIs(t, err, secrets.ErrNoKey)
+}
@@ -0,0 +1,312 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WS is the method of WebSocket requests
+const WS = "WS"
+
+// Kinds of WebSocket frames
+const (
+	FrameText   = "text"
+	FrameJSON   = "json" // a text frame whose payload is validated as JSON
+	FrameBinary = "binary"
+	FramePing   = "ping"
+	FramePong   = "pong"
+	FrameClose  = "close"
+)
+
+// CloseNormal is the close code of a normal closure
+const CloseNormal = websocket.CloseNormalClosure
+
+// FrameKinds are the frames that can be typed into the message box
+var FrameKinds = []string{FrameText, FrameJSON, FrameBinary}
+
+// WSFrame is a frame of a WebSocket transcript
+type WSFrame struct {
+	Time     time.Time `json:"time"`
+	Outgoing bool      `json:"outgoing,omitempty"` // sent by us
+	Kind     string    `json:"kind"`
+	Data     string    `json:"data,omitempty"`  // raw bytes for binary frames
+	Code     int       `json:"code,omitempty"`  // close code
+	Error    string    `json:"error,omitempty"` // why the connection ended, if not cleanly
+}
+
+// WSConn is an open WebSocket connection. Every frame, in either direction,
+// is reported on Frames, which is closed when the connection ends.
+type WSConn struct {
+	conn   *websocket.Conn
+	frames chan WSFrame
+
+	mu     sync.Mutex // serializes writes and guards closed
+	closed bool       // a close frame was sent, nothing else may be written
+
+	emitMu sync.Mutex // guards ended, so no frame is reported after Frames is closed
+	ended  bool
+}
+
+// ParseSubprotocols reads the ws.subprotocols option, separated by spaces
+func ParseSubprotocols(options map[string]string) []string {
+	return strings.Fields(options[OptWSSubprotocols])
+}
+
+// websocketURL turns http(s) URLs into ws(s) ones
+func websocketURL(raw string) string {
+	switch {
+	case strings.HasPrefix(raw, "https://"):
+		return "wss://" + strings.TrimPrefix(raw, "https://")
+	case strings.HasPrefix(raw, "http://"):
+		return "ws://" + strings.TrimPrefix(raw, "http://")
+	}
+	return raw
+}
+
+// DialWebSocket opens a WebSocket connection for the request, using the
+// same TLS, proxy and cookie settings as Send. The response describes the
+// handshake, even when it failed.
+func (c *Client) DialWebSocket(ctx context.Context, req *Request) (*WSConn, *Response, error) {
+	req, err := c.Unseal(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	transport, err := c.parseTransportOptions(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := c.clientFor(transport)
+	if err != nil {
+		return nil, nil, err
+	}
+	tr := client.Transport.(*http.Transport)
+
+	dialer := &websocket.Dialer{
+		Proxy:            tr.Proxy,
+		TLSClientConfig:  tr.TLSClientConfig,
+		NetDialContext:   tr.DialContext,
+		HandshakeTimeout: c.timeout(MergeOptions(c.Defaults, req.Options)),
+		Subprotocols:     ParseSubprotocols(MergeOptions(c.Defaults, req.Options)),
+		Jar:              client.Jar,
+	}
+	header := make(http.Header, len(req.Headers))
+	for key, value := range req.Headers {
+		header.Set(key, value)
+	}
+
+	start := time.Now()
+	conn, res, err := dialer.DialContext(ctx, websocketURL(req.URL), header)
+
+	var response *Response
+	if res != nil {
+		response = &Response{
+			Status:     res.Status,
+			StatusCode: res.StatusCode,
+			Proto:      res.Proto,
+			Headers:    res.Header,
+			Cookies:    res.Cookies(),
+			URL:        websocketURL(req.URL),
+			Duration:   time.Since(start),
+		}
+	}
+	if err != nil {
+		if response != nil {
+			err = fmt.Errorf("%w (%s)", err, response.Status)
+		}
+		return nil, response, err
+	}
+	if tlsConn, ok := conn.NetConn().(*tls.Conn); ok {
+		state := tlsConn.ConnectionState()
+		response.TLS = newTLSInfo(&state, transport.TLS.Insecure)
+	}
+
+	ws := &WSConn{conn: conn, frames: make(chan WSFrame, 64)}
+	ws.watchControlFrames()
+	go ws.readLoop()
+	return ws, response, nil
+}
+
+// Subprotocol returns the subprotocol the server picked, if any
+func (w *WSConn) Subprotocol() string {
+	return w.conn.Subprotocol()
+}
+
+// Frames reports every frame of the connection until it ends
+func (w *WSConn) Frames() <-chan WSFrame {
+	return w.frames
+}
+
+// Send writes a frame typed into the message box. JSON is validated and
+// sent as text, binary payloads are given as hex.
+func (w *WSConn) Send(kind, payload string) error {
+	messageType := websocket.TextMessage
+	switch kind {
+	case FrameText:
+	case FrameJSON:
+		if !json.Valid([]byte(payload)) {
+			return errors.New("message is not valid JSON")
+		}
+	case FrameBinary:
+		data, err := hex.DecodeString(strings.Join(strings.Fields(payload), ""))
+		if err != nil {
+			return fmt.Errorf("binary messages are written as hex: %w", err)
+		}
+		payload, messageType = string(data), websocket.BinaryMessage
+	default:
+		return fmt.Errorf("unknown frame kind %q", kind)
+	}
+
+	if err := w.write(messageType, []byte(payload)); err != nil {
+		return err
+	}
+	w.emit(WSFrame{Time: time.Now(), Outgoing: true, Kind: kind, Data: payload})
+	return nil
+}
+
+// Ping sends a ping, whose pong shows up on Frames
+func (w *WSConn) Ping() error {
+	if err := w.write(websocket.PingMessage, nil); err != nil {
+		return err
+	}
+	w.emit(WSFrame{Time: time.Now(), Outgoing: true, Kind: FramePing})
+	return nil
+}
+
+// Close starts the closing handshake. Frames is closed once the server answers.
+func (w *WSConn) Close(code int, reason string) error {
+	message := websocket.FormatCloseMessage(code, reason)
+	if err := w.write(websocket.CloseMessage, message); err != nil {
+		return err
+	}
+	w.emit(WSFrame{Time: time.Now(), Outgoing: true, Kind: FrameClose, Code: code, Data: reason})
+
+	// don't wait forever on servers that never answer
+	time.AfterFunc(5*time.Second, func() { w.conn.Close() })
+	return nil
+}
+
+func (w *WSConn) write(messageType int, data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errors.New("connection is closed")
+	}
+	if messageType == websocket.CloseMessage {
+		w.closed = true
+	}
+	if messageType == websocket.CloseMessage || messageType == websocket.PingMessage || messageType == websocket.PongMessage {
+		return w.conn.WriteControl(messageType, data, time.Now().Add(5*time.Second))
+	}
+	return w.conn.WriteMessage(messageType, data)
+}
+
+// watchControlFrames records pings, pongs and close frames, which gorilla
+// handles inside ReadMessage
+func (w *WSConn) watchControlFrames() {
+	w.conn.SetPingHandler(func(data string) error {
+		w.emit(WSFrame{Time: time.Now(), Kind: FramePing, Data: data})
+		if err := w.write(websocket.PongMessage, []byte(data)); err != nil {
+			return err
+		}
+		w.emit(WSFrame{Time: time.Now(), Outgoing: true, Kind: FramePong, Data: data})
+		return nil
+	})
+	w.conn.SetPongHandler(func(data string) error {
+		w.emit(WSFrame{Time: time.Now(), Kind: FramePong, Data: data})
+		return nil
+	})
+	w.conn.SetCloseHandler(func(code int, text string) error {
+		w.emit(WSFrame{Time: time.Now(), Kind: FrameClose, Code: code, Data: text})
+
+		// echo the close frame, unless we started the closing handshake
+		w.mu.Lock()
+		alreadyClosed := w.closed
+		w.closed = true
+		w.mu.Unlock()
+		if !alreadyClosed {
+			message := websocket.FormatCloseMessage(code, "")
+			if code == websocket.CloseNoStatusReceived {
+				message = websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+			}
+			w.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+			w.emit(WSFrame{Time: time.Now(), Outgoing: true, Kind: FrameClose, Code: code})
+		}
+		return nil
+	})
+}
+
+// emit reports a frame, unless the connection already ended
+func (w *WSConn) emit(frame WSFrame) {
+	w.emitMu.Lock()
+	defer w.emitMu.Unlock()
+	if !w.ended {
+		w.frames <- frame
+	}
+}
+
+// readLoop reports incoming messages until the connection ends
+func (w *WSConn) readLoop() {
+	defer func() {
+		w.conn.Close()
+		w.emitMu.Lock()
+		w.ended = true
+		close(w.frames)
+		w.emitMu.Unlock()
+	}()
+
+	for {
+		messageType, data, err := w.conn.ReadMessage()
+		if err != nil {
+			var closeErr *websocket.CloseError
+			if !errors.As(err, &closeErr) {
+				w.mu.Lock()
+				closing := w.closed
+				w.mu.Unlock()
+				if !closing {
+					w.emit(WSFrame{Time: time.Now(), Kind: FrameClose, Code: websocket.CloseAbnormalClosure, Error: err.Error()})
+				}
+			}
+			return
+		}
+
+		kind := FrameText
+		if messageType == websocket.BinaryMessage {
+			kind = FrameBinary
+		}
+		w.emit(WSFrame{Time: time.Now(), Kind: kind, Data: string(data)})
+	}
+}
+
+// CloseCodeText describes a close code, e.g. "1000 normal closure"
+func CloseCodeText(code int) string {
+	names := map[int]string{
+		websocket.CloseNormalClosure:           "normal closure",
+		websocket.CloseGoingAway:               "going away",
+		websocket.CloseProtocolError:           "protocol error",
+		websocket.CloseUnsupportedData:         "unsupported data",
+		websocket.CloseNoStatusReceived:        "no status",
+		websocket.CloseAbnormalClosure:         "abnormal closure",
+		websocket.CloseInvalidFramePayloadData: "invalid payload",
+		websocket.ClosePolicyViolation:         "policy violation",
+		websocket.CloseMessageTooBig:           "message too big",
+		websocket.CloseMandatoryExtension:      "mandatory extension",
+		websocket.CloseInternalServerErr:       "internal error",
+		websocket.CloseServiceRestart:          "service restart",
+		websocket.CloseTryAgainLater:           "try again later",
+		websocket.CloseTLSHandshake:            "TLS handshake failed",
+	}
+	if name, ok := names[code]; ok {
+		return fmt.Sprintf("%d %s", code, name)
+	}
+	return fmt.Sprint(code)
+}
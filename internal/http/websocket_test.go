@@ -0,0 +1,162 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// echoServer echoes every message back, pings once on connect and closes
+// with 4000 when it receives "bye"
+func echoServer(t *testing.T) *httptest.Server {
+	upgrader := websocket.Upgrader{Subprotocols: []string{"chat.v2", "chat.v1"}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, http.Header{"X-Token-Seen": {r.Header.Get("X-Token")}})
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.WriteControl(websocket.PingMessage, []byte("hello"), time.Now().Add(time.Second))
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if string(data) == "bye" {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4000, "see you"))
+				continue
+			}
+			conn.WriteMessage(messageType, data)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// next waits for the next frame
+func next(t *testing.T, conn *WSConn) WSFrame {
+	t.Helper()
+	select {
+	case frame, ok := <-conn.Frames():
+		if !ok {
+			t.Fatal("connection ended")
+		}
+		return frame
+	case <-time.After(2 * time.Second):
+		t.Fatal("no frame")
+	}
+	return WSFrame{}
+}
+
+func TestClient_DialWebSocket(t *testing.T) {
+	server := echoServer(t)
+	client := InitClient(time.Second, false)
+
+	conn, res, err := client.DialWebSocket(context.Background(), &Request{
+		Method:  WS,
+		URL:     server.URL, // http is turned into ws
+		Headers: map[string]string{"X-Token": "abc"},
+		Options: map[string]string{OptWSSubprotocols: "chat.v1"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode)
+	assert.Equal(t, "abc", res.Headers.Get("X-Token-Seen"))
+	assert.Equal(t, "chat.v1", conn.Subprotocol())
+
+	// the server's ping is answered automatically
+	ping := next(t, conn)
+	assert.Equal(t, FramePing, ping.Kind)
+	assert.Equal(t, "hello", ping.Data)
+	pong := next(t, conn)
+	assert.Equal(t, FramePong, pong.Kind)
+	assert.True(t, pong.Outgoing)
+
+	tests := []struct {
+		kind    string
+		payload string
+		want    WSFrame
+	}{
+		{kind: FrameText, payload: "hi", want: WSFrame{Kind: FrameText, Data: "hi"}},
+		{kind: FrameJSON, payload: `{"a":1}`, want: WSFrame{Kind: FrameText, Data: `{"a":1}`}},
+		{kind: FrameBinary, payload: "de ad be ef", want: WSFrame{Kind: FrameBinary, Data: "\xde\xad\xbe\xef"}},
+	}
+	for _, tt := range tests {
+		assert.NoError(t, conn.Send(tt.kind, tt.payload))
+		sent := next(t, conn)
+		assert.True(t, sent.Outgoing)
+		assert.Equal(t, tt.kind, sent.Kind)
+
+		echoed := next(t, conn)
+		assert.False(t, echoed.Outgoing)
+		assert.Equal(t, tt.want.Kind, echoed.Kind)
+		assert.Equal(t, tt.want.Data, echoed.Data)
+	}
+
+	assert.Error(t, conn.Send(FrameJSON, "{not json"))
+	assert.Error(t, conn.Send(FrameBinary, "xyz"))
+
+	assert.NoError(t, conn.Ping())
+	assert.Equal(t, FramePing, next(t, conn).Kind)
+	assert.Equal(t, FramePong, next(t, conn).Kind)
+
+	// the server closes, we answer and the transcript ends
+	assert.NoError(t, conn.Send(FrameText, "bye"))
+	next(t, conn)
+	closed := next(t, conn)
+	assert.Equal(t, FrameClose, closed.Kind)
+	assert.Equal(t, 4000, closed.Code)
+	assert.Equal(t, "see you", closed.Data)
+	reply := next(t, conn)
+	assert.Equal(t, FrameClose, reply.Kind)
+	assert.True(t, reply.Outgoing)
+
+	_, ok := <-conn.Frames()
+	assert.False(t, ok)
+	assert.Error(t, conn.Send(FrameText, "too late"))
+}
+
+func TestWSConn_Close(t *testing.T) {
+	server := echoServer(t)
+	conn, _, err := InitClient(time.Second, false).DialWebSocket(context.Background(), &Request{Method: WS, URL: server.URL})
+	if !assert.NoError(t, err) {
+		return
+	}
+	next(t, conn) // ping
+	next(t, conn) // pong
+
+	assert.NoError(t, conn.Close(websocket.CloseNormalClosure, "done"))
+	sent := next(t, conn)
+	assert.Equal(t, FrameClose, sent.Kind)
+	assert.Equal(t, 1000, sent.Code)
+
+	// the server's answer ends the connection
+	var frames []WSFrame
+	for frame := range conn.Frames() {
+		frames = append(frames, frame)
+	}
+	if assert.Len(t, frames, 1) {
+		assert.Equal(t, FrameClose, frames[0].Kind)
+		assert.Equal(t, 1000, frames[0].Code)
+	}
+}
+
+func TestClient_DialWebSocketRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no websockets here", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	_, res, err := InitClient(time.Second, false).DialWebSocket(context.Background(), &Request{Method: WS, URL: server.URL})
+	assert.Error(t, err)
+	if assert.NotNil(t, res) {
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	}
+}
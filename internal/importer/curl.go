@@ -0,0 +1,459 @@
+package importer
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/owenHochwald/volt/internal/http"
+)
+
+// formBoundary separates the parts of -F bodies, fixed so that the same
+// command always gives the same request
+const formBoundary = "VoltFormBoundary7MA4YWxkTrZu0gW"
+
+// curlShortOptions are the short names of the options, by letter
+var curlShortOptions = map[byte]string{
+	'X': "request", 'H': "header", 'd': "data", 'u': "user", 'F': "form", 'k': "insecure",
+	'A': "user-agent", 'b': "cookie", 'e': "referer", 'G': "get", 'I': "head", 'L': "location",
+	'x': "proxy", 'E': "cert", 'm': "max-time", 'o': "output", 's': "silent", 'S': "show-error",
+	'v': "verbose", 'i': "include", 'f': "fail", 'g': "globoff", 'O': "remote-name", 'N': "no-buffer",
+	'#': "progress-bar", 'c': "cookie-jar", 'D': "dump-header", 'w': "write-out",
+}
+
+// curlValueOptions are the options that take a value
+var curlValueOptions = map[string]bool{
+	"request": true, "header": true, "data": true, "data-raw": true, "data-binary": true, "data-ascii": true,
+	"data-urlencode": true, "json": true, "user": true, "form": true, "form-string": true, "user-agent": true,
+	"cookie": true, "referer": true, "url": true, "proxy": true, "cacert": true, "cert": true, "key": true,
+	"max-time": true, "connect-timeout": true, "retry": true, "max-redirs": true, "output": true,
+	"cookie-jar": true, "dump-header": true, "write-out": true, "proxy-user": true, "resolve": true,
+	"connect-to": true, "interface": true, "limit-rate": true, "range": true, "oauth2-bearer": true,
+}
+
+// curlIgnoredOptions don't change the request, only how curl shows its response
+var curlIgnoredOptions = map[string]bool{
+	"location": true, "silent": true, "show-error": true, "verbose": true, "include": true, "fail": true,
+	"globoff": true, "output": true, "remote-name": true, "no-buffer": true, "progress-bar": true,
+	"no-progress-meter": true, "write-out": true, "dump-header": true, "fail-with-body": true, "path-as-is": true,
+}
+
+// IsCurl reports whether text looks like a curl command rather than a URL or file path
+func IsCurl(text string) bool {
+	fields := strings.Fields(text)
+	return len(fields) > 1 && path.Base(fields[0]) == "curl"
+}
+
+// ParseCurl converts a curl command, such as one copied from a browser's
+// developer tools, into a request. Options that can't be converted, like
+// files to upload, are returned as warnings.
+func ParseCurl(command string) (*http.Request, []string, error) {
+	words, err := shellWords(command)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(words) == 0 || path.Base(words[0]) != "curl" {
+		return nil, nil, fmt.Errorf("not a curl command")
+	}
+
+	result := &Result{}
+	request := &http.Request{}
+	var (
+		method string
+		data   []string
+		form   []string
+		get    bool
+	)
+	options := make(map[string]string)
+
+	for i := 1; i < len(words); i++ {
+		word := words[i]
+		var names []string
+		var attached string
+		switch {
+		case word == "--":
+			continue
+		case strings.HasPrefix(word, "--"):
+			names = []string{strings.TrimPrefix(word, "--")}
+		case strings.HasPrefix(word, "-") && len(word) > 1:
+			// -sSL sets each option, -XPOST gives -X its value
+			for j := 1; j < len(word); j++ {
+				name, ok := curlShortOptions[word[j]]
+				if !ok {
+					name = "-" + string(word[j])
+				}
+				names = append(names, name)
+				if curlValueOptions[name] {
+					attached = word[j+1:]
+					break
+				}
+			}
+		default:
+			if request.URL != "" {
+				result.warn("only the first URL is imported, %s left out", word)
+				continue
+			}
+			request.URL = word
+			continue
+		}
+
+		for _, name := range names {
+			var value string
+			if curlValueOptions[name] {
+				switch {
+				case attached != "":
+					value = attached
+				case i+1 < len(words):
+					i++
+					value = words[i]
+				default:
+					return nil, nil, fmt.Errorf("option %s needs a value", word)
+				}
+			}
+
+			switch name {
+			case "request":
+				method = strings.ToUpper(value)
+			case "header":
+				key, headerValue, found := strings.Cut(value, ":")
+				key = strings.TrimSpace(key)
+				if !found || key == "" {
+					result.warn("header %q left out, expected Name: value", value)
+					continue
+				}
+				if headerValue = strings.TrimSpace(headerValue); headerValue == "" {
+					continue // curl removes the header
+				}
+				addHeader(request, key, headerValue)
+			case "data", "data-ascii", "data-binary":
+				if strings.HasPrefix(value, "@") {
+					result.warn("%s left out, files aren't read", value)
+					continue
+				}
+				if name != "data-binary" {
+					value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+				}
+				data = append(data, value)
+			case "data-raw":
+				data = append(data, value)
+			case "data-urlencode":
+				encoded, ok := urlencodeData(value)
+				if !ok {
+					result.warn("%s left out, files aren't read", value)
+					continue
+				}
+				data = append(data, encoded)
+			case "json":
+				data = append(data, value)
+				setDefaultHeader(request, "Content-Type", "application/json")
+				setDefaultHeader(request, "Accept", "application/json")
+			case "form", "form-string":
+				field, fieldValue, _ := strings.Cut(value, "=")
+				if name == "form" && (strings.HasPrefix(fieldValue, "@") || strings.HasPrefix(fieldValue, "<")) {
+					result.warn("form field %s left out, files aren't read", field)
+					continue
+				}
+				form = append(form, field, fieldValue)
+			case "user":
+				username, password, _ := strings.Cut(value, ":")
+				if key, header, ok := basicAuthHeader(result, "user", username, password); ok {
+					addHeader(request, key, header)
+				}
+			case "oauth2-bearer":
+				addHeader(request, "Authorization", "Bearer "+value)
+			case "user-agent":
+				addHeader(request, "User-Agent", value)
+			case "referer":
+				addHeader(request, "Referer", value)
+			case "cookie":
+				if !strings.Contains(value, "=") {
+					result.warn("cookie file %s left out, files aren't read", value)
+					continue
+				}
+				addHeader(request, "Cookie", value)
+			case "url":
+				request.URL = value
+			case "get":
+				get = true
+			case "head":
+				result.warn("HEAD requests aren't supported, sent as GET")
+				method = http.GET
+			case "compressed":
+				options[http.OptAcceptEncoding] = "all"
+			case "insecure":
+				options[http.OptTLSInsecure] = "true"
+			case "proxy":
+				options[http.OptProxy] = value
+			case "cacert":
+				options[http.OptTLSCA] = value
+			case "cert":
+				options[http.OptTLSCert] = value
+			case "key":
+				options[http.OptTLSKey] = value
+			case "max-time":
+				options[http.OptTimeout] = value + "s"
+			case "connect-timeout":
+				options[http.OptTimeoutConnect] = value + "s"
+			case "retry":
+				options[http.OptRetries] = value
+			case "max-redirs":
+				options[http.OptRedirects] = value
+			case "http1.1":
+				options[http.OptProtocol] = "http/1.1"
+			case "http2":
+				options[http.OptProtocol] = "h2"
+			case "http2-prior-knowledge":
+				options[http.OptProtocol] = "h2c"
+			default:
+				if !curlIgnoredOptions[name] {
+					result.warn("unsupported option %s%s left out", dashes(name), name)
+				}
+			}
+		}
+	}
+
+	if request.URL == "" {
+		return nil, nil, fmt.Errorf("the curl command has no URL")
+	}
+	if !strings.Contains(request.URL, "://") {
+		request.URL = "http://" + request.URL
+	}
+
+	body := strings.Join(data, "&")
+	switch {
+	case get && body != "":
+		separator := "?"
+		if strings.Contains(request.URL, "?") {
+			separator = "&"
+		}
+		request.URL += separator + body
+		body = ""
+	case len(form) > 0:
+		if body != "" {
+			result.warn("-d data left out, it can't be sent with -F fields")
+		}
+		body = multipartBody(form)
+		setDefaultHeader(request, "Content-Type", "multipart/form-data; boundary="+formBoundary)
+	case body != "":
+		setDefaultHeader(request, "Content-Type", "application/x-www-form-urlencoded")
+	}
+	request.Body = body
+
+	if method == "" {
+		method = http.GET
+		if body != "" {
+			method = http.POST
+		}
+	}
+	supported, ok := supportedMethod(method)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s requests aren't supported", supported)
+	}
+	request.Method = supported
+	if len(options) > 0 {
+		request.Options = options
+	}
+	if target, err := url.Parse(request.URL); err == nil {
+		request.Name = truncateName(request.Method + " " + target.Host + target.Path)
+	}
+
+	return request, result.Warnings, nil
+}
+
+// parseCurlFile converts a file holding a curl command into a request in a
+// collection named after the file
+func parseCurlFile(data []byte, name string, result *Result) error {
+	request, warnings, err := ParseCurl(string(data))
+	if err != nil {
+		return err
+	}
+	result.Warnings = append(result.Warnings, warnings...)
+	result.Collections = append(result.Collections, &Folder{
+		Collection: http.Collection{Name: truncateName(cmp.Or(name, "curl"))},
+		Requests:   []http.Request{*request},
+	})
+	return nil
+}
+
+// dashes returns the dashes an option was given with, its name being the
+// long one or - and the letter
+func dashes(name string) string {
+	if strings.HasPrefix(name, "-") {
+		return ""
+	}
+	return "--"
+}
+
+// urlencodeData encodes a --data-urlencode value, which is content,
+// =content or name=content. It is false for files, @file and name@file.
+func urlencodeData(value string) (string, bool) {
+	if i := strings.IndexAny(value, "=@"); i >= 0 {
+		name, content := value[:i], value[i+1:]
+		if value[i] == '@' {
+			return "", false
+		}
+		if name == "" {
+			return url.QueryEscape(content), true
+		}
+		return name + "=" + url.QueryEscape(content), true
+	}
+	return url.QueryEscape(value), true
+}
+
+// multipartBody returns the multipart/form-data body of name and value pairs
+func multipartBody(fields []string) string {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	writer.SetBoundary(formBoundary)
+	for i := 0; i+1 < len(fields); i += 2 {
+		writer.WriteField(fields[i], fields[i+1])
+	}
+	writer.Close()
+	return body.String()
+}
+
+// shellWords splits a command into words the way a POSIX shell does,
+// handling single, double and $'...' quotes, backslashes and line
+// continuations
+func shellWords(command string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		escaped bool
+	)
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case escaped:
+			escaped = false
+			// a continuation, or one whose newline was pasted as a space
+			if c != '\n' && (inWord || (c != ' ' && c != '\t')) {
+				word.WriteByte(c)
+				inWord = true
+			}
+		case c == '\\':
+			// Windows line endings after a continuation
+			if strings.HasPrefix(command[i+1:], "\r\n") {
+				i += 2
+				continue
+			}
+			escaped = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case c == '\'':
+			end := strings.IndexByte(command[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated ' quote")
+			}
+			word.WriteString(command[i+1 : i+1+end])
+			inWord = true
+			i += end + 1
+		case c == '"':
+			end, err := doubleQuoted(command[i+1:], &word)
+			if err != nil {
+				return nil, err
+			}
+			inWord = true
+			i += end + 1
+		case c == '$' && strings.HasPrefix(command[i+1:], "'"):
+			end, err := ansiQuoted(command[i+2:], &word)
+			if err != nil {
+				return nil, err
+			}
+			inWord = true
+			i += end + 2
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("the command ends with a backslash")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// doubleQuoted writes the content of a "..." string, text starting after the
+// opening quote, and returns the index of the closing one. A backslash only
+// escapes $, `, ", \ and newlines.
+func doubleQuoted(text string, word *strings.Builder) (int, error) {
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '"':
+			return i, nil
+		case c == '\\' && i+1 < len(text) && strings.IndexByte("$`\"\\\n", text[i+1]) >= 0:
+			if text[i+1] != '\n' {
+				word.WriteByte(text[i+1])
+			}
+			i++
+		default:
+			word.WriteByte(c)
+		}
+	}
+	return 0, fmt.Errorf(`unterminated " quote`)
+}
+
+// ansiQuoted writes the content of a $'...' string, as written by browsers
+// for bodies with quotes or newlines, text starting after the opening quote,
+// and returns the index of the closing one
+func ansiQuoted(text string, word *strings.Builder) (int, error) {
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c == '\'' {
+			return i, nil
+		}
+		if c != '\\' || i+1 == len(text) {
+			word.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch text[i] {
+		case 'n':
+			word.WriteByte('\n')
+		case 't':
+			word.WriteByte('\t')
+		case 'r':
+			word.WriteByte('\r')
+		case 'x', 'u', 'U':
+			digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[text[i]]
+			end := i + 1
+			for end < len(text) && end <= i+digits && isHex(text[end]) {
+				end++
+			}
+			code, err := strconv.ParseUint(text[i+1:end], 16, 32)
+			if err != nil {
+				word.WriteByte('\\')
+				word.WriteByte(text[i])
+				continue
+			}
+			if text[i] == 'x' {
+				word.WriteByte(byte(code))
+			} else {
+				word.WriteString(string(rune(code)))
+			}
+			i = end - 1
+		default:
+			// \\, \' and \" stand for the character, as do unknown escapes
+			word.WriteByte(text[i])
+		}
+	}
+	return 0, fmt.Errorf("unterminated $' quote")
+}
+
+func isHex(c byte) bool {
+	return strings.IndexByte("0123456789abcdefABCDEF", c) >= 0
+}
@@ -0,0 +1,90 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCurl_DevTools(t *testing.T) {
+	// as copied from a browser, with a $'...' body and line continuations
+	command := `curl 'https://shop.example/api/cart?id=7' \
+  -H 'accept: application/json' \
+  -H 'content-type: application/json' \
+  -b 'session=abc' \
+  --data-raw $'{"note":"it\'s\\nfine"}' \
+  --compressed`
+
+	request, warnings, err := ParseCurl(command)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, http.POST, request.Method)
+	assert.Equal(t, "https://shop.example/api/cart?id=7", request.URL)
+	assert.Equal(t, "POST shop.example/api/cart", request.Name)
+	assert.Equal(t, `{"note":"it's\nfine"}`, request.Body)
+	assert.Equal(t, map[string]string{"accept": "application/json", "content-type": "application/json", "Cookie": "session=abc"}, request.Headers)
+	assert.Equal(t, []string{"Cookie"}, request.Secrets)
+	assert.Equal(t, map[string]string{http.OptAcceptEncoding: "all"}, request.Options)
+}
+
+func TestParseCurl_Options(t *testing.T) {
+	request, warnings, err := ParseCurl(`curl -sSLk -XPUT -u admin:secret "https://api.example/users/\"7\"" -d name=volt -d "tag=a b" --limit-rate 1K -Z`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"unsupported option --limit-rate left out", "unsupported option -Z left out"}, warnings)
+	assert.Equal(t, http.PUT, request.Method)
+	assert.Equal(t, `https://api.example/users/"7"`, request.URL)
+	assert.Equal(t, "name=volt&tag=a b", request.Body)
+	assert.Equal(t, "Basic YWRtaW46c2VjcmV0", request.Headers["Authorization"])
+	assert.Equal(t, "application/x-www-form-urlencoded", request.Headers["Content-Type"])
+	assert.Equal(t, "true", request.Options[http.OptTLSInsecure])
+
+	request, _, err = ParseCurl(`curl -G api.example/search --data-urlencode "q=a&b"`)
+	require.NoError(t, err)
+	assert.Equal(t, http.GET, request.Method)
+	assert.Equal(t, "http://api.example/search?q=a%26b", request.URL)
+	assert.Empty(t, request.Body)
+}
+
+func TestParseCurl_Form(t *testing.T) {
+	request, warnings, err := ParseCurl(`curl -F name=volt -F avatar=@me.png https://api.example/profile`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"form field avatar left out, files aren't read"}, warnings)
+	assert.Equal(t, http.POST, request.Method)
+	assert.Equal(t, "multipart/form-data; boundary="+formBoundary, request.Headers["Content-Type"])
+	assert.Contains(t, request.Body, "Content-Disposition: form-data; name=\"name\"\r\n\r\nvolt\r\n")
+	assert.NotContains(t, request.Body, "avatar")
+}
+
+func TestParseCurl_Invalid(t *testing.T) {
+	for _, command := range []string{
+		`curl 'https://api.example`,
+		`curl -H`,
+		`curl -s`,
+		`curl -X HEAD https://api.example`,
+		`wget https://api.example`,
+	} {
+		_, _, err := ParseCurl(command)
+		assert.Error(t, err, command)
+	}
+}
+
+func TestParse_Curl(t *testing.T) {
+	result, err := Parse([]byte("curl https://api.example/health\n"), "health")
+	require.NoError(t, err)
+	assert.Equal(t, Curl, result.Format)
+	require.Len(t, result.Collections, 1)
+	assert.Equal(t, "health", result.Collections[0].Collection.Name)
+	require.Len(t, result.Collections[0].Requests, 1)
+	assert.Equal(t, "https://api.example/health", result.Collections[0].Requests[0].URL)
+}
+
+func TestParseCurl_FlattenedContinuations(t *testing.T) {
+	// single line inputs replace the newlines of a pasted command with spaces
+	request, warnings, err := ParseCurl(`curl https://api.example/my\ file \  -H 'Accept: text/plain' \  --compressed`)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, "https://api.example/my file", request.URL)
+	assert.Equal(t, "text/plain", request.Headers["Accept"])
+}
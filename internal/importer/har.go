@@ -0,0 +1,90 @@
+package importer
+
+import (
+	"cmp"
+	"encoding/json"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/owenHochwald/volt/internal/http"
+)
+
+type harLog struct {
+	Log struct {
+		Pages []struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		} `json:"pages"`
+		Entries []struct {
+			PageRef string `json:"pageref"`
+			Request struct {
+				Method  string `json:"method"`
+				URL     string `json:"url"`
+				Headers []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"headers"`
+				PostData *struct {
+					MimeType string `json:"mimeType"`
+					Text     string `json:"text"`
+				} `json:"postData"`
+			} `json:"request"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// harSkippedHeaders are set by the client sending a request, or by HTTP/2 as pseudo headers
+var harSkippedHeaders = []string{"host", "content-length", "connection", "accept-encoding", "transfer-encoding"}
+
+// parseHAR converts the entries of an archive into requests, in order, of a
+// collection named after the file
+func parseHAR(data []byte, name string, result *Result) error {
+	var archive harLog
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return err
+	}
+
+	root := &Folder{Collection: http.Collection{Name: truncateName(cmp.Or(name, "HAR"))}}
+	if len(archive.Log.Pages) == 1 && archive.Log.Pages[0].Title != "" && name == "" {
+		root.Collection.Name = truncateName(archive.Log.Pages[0].Title)
+	}
+	result.Collections = append(result.Collections, root)
+
+	skipped := make(map[string]int)
+	for _, entry := range archive.Log.Entries {
+		source := entry.Request
+		target, err := url.Parse(source.URL)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+			skipped["URLs that aren't http or https"]++
+			continue
+		}
+		method, ok := supportedMethod(source.Method)
+		if !ok {
+			skipped[method+" requests"]++
+			continue
+		}
+
+		request := http.Request{Name: truncateName(method + " " + target.Host + target.Path), Method: method, URL: source.URL}
+		for _, header := range source.Headers {
+			key := strings.ToLower(header.Name)
+			if strings.HasPrefix(key, ":") || slices.Contains(harSkippedHeaders, key) {
+				continue
+			}
+			addHeader(&request, header.Name, header.Value)
+		}
+		if postData := source.PostData; postData != nil {
+			request.Body = postData.Text
+			if postData.MimeType != "" && postData.Text != "" {
+				setDefaultHeader(&request, "Content-Type", postData.MimeType)
+			}
+		}
+		root.Requests = append(root.Requests, request)
+	}
+
+	for _, reason := range slices.Sorted(maps.Keys(skipped)) {
+		result.warn("skipped %d %s", skipped[reason], reason)
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+// Package importer converts the exports of other API clients, OpenAPI
+// descriptions, HAR files and curl commands into Volt requests, collections and
+// environment variables.
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/secrets"
+	"github.com/owenHochwald/volt/internal/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a kind of file that can be imported
+type Format string
+
+const (
+	Postman            Format = "postman"             // Postman collection v2.0 or v2.1
+	PostmanEnvironment Format = "postman-environment" // Postman environment
+	Insomnia           Format = "insomnia"            // Insomnia export v4
+	OpenAPI            Format = "openapi"             // OpenAPI 3
+	HAR                Format = "har"                 // HTTP Archive, e.g. saved from a browser
+	Curl               Format = "curl"                // a curl command
+)
+
+// nameLimit is the longest name of a request or collection
+const nameLimit = 40
+
+// Folder is a collection to create, with the requests and folders inside
+type Folder struct {
+	Collection http.Collection
+	Requests   []http.Request
+	Folders    []*Folder
+}
+
+// Result is an export converted to Volt's requests and variables
+type Result struct {
+	Format      Format
+	Collections []*Folder
+
+	// Variables are added to the environment imported into
+	Variables map[string]string
+
+	// Environments are the named environments of the export, by name
+	Environments map[string]map[string]string
+
+	// Secrets names the variables whose values are secret, such as passwords
+	Secrets []string
+
+	// Warnings lists what couldn't be converted, such as scripts
+	Warnings []string
+}
+
+// Summary counts what an import created
+type Summary struct {
+	Collections int
+	Requests    int
+	Variables   int
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("Imported %s, %s and %s", count(s.Collections, "collection"), count(s.Requests, "request"),
+		count(s.Variables, "variable"))
+}
+
+func count(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func (r *Result) warn(format string, args ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// secret marks a variable as secret
+func (r *Result) secret(key string) {
+	if !slices.Contains(r.Secrets, key) {
+		r.Secrets = append(r.Secrets, key)
+	}
+}
+
+// Parse detects the format of an export, JSON or YAML, or a curl command,
+// and converts it.
+// name is the name of the collection when the export has none, e.g. the
+// name of the file.
+func Parse(data []byte, name string) (*Result, error) {
+	if IsCurl(string(data)) {
+		result := &Result{Format: Curl}
+		if err := parseCurlFile(data, name, result); err != nil {
+			return nil, fmt.Errorf("invalid curl command: %w", err)
+		}
+		return result, nil
+	}
+
+	data, err := toJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	format, err := Detect(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Format: format}
+	switch format {
+	case Postman:
+		err = parsePostman(data, result)
+	case PostmanEnvironment:
+		err = parsePostmanEnvironment(data, result)
+	case Insomnia:
+		err = parseInsomnia(data, result)
+	case OpenAPI:
+		err = parseOpenAPI(data, name, result)
+	case HAR:
+		err = parseHAR(data, name, result)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s file: %w", format, err)
+	}
+	return result, nil
+}
+
+// ImportFile parses a file and saves what it converts to, see Result.Save.
+// Collections without a name of their own are named after the file.
+func ImportFile(store storage.Storage, path, environment string) (*Result, Summary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, Summary{}, err
+	}
+	name, _, _ := strings.Cut(filepath.Base(path), ".")
+	result, err := Parse(data, name)
+	if err != nil {
+		return nil, Summary{}, err
+	}
+	summary, err := result.Save(store, environment)
+	return result, summary, err
+}
+
+// Detect returns the format of an export given as JSON
+func Detect(data []byte) (Format, error) {
+	var doc struct {
+		Info *struct {
+			PostmanID string `json:"_postman_id"`
+			Schema    string `json:"schema"`
+		} `json:"info"`
+		Scope        string          `json:"_postman_variable_scope"`
+		Type         string          `json:"_type"`
+		ExportFormat int             `json:"__export_format"`
+		OpenAPI      string          `json:"openapi"`
+		Swagger      string          `json:"swagger"`
+		Log          json.RawMessage `json:"log"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("unrecognised file: %w", err)
+	}
+
+	switch {
+	case doc.Info != nil && (doc.Info.PostmanID != "" || strings.Contains(doc.Info.Schema, "getpostman.com")):
+		return Postman, nil
+	case doc.Scope == "environment" || doc.Scope == "globals":
+		return PostmanEnvironment, nil
+	case doc.Type == "export":
+		if doc.ExportFormat != 4 {
+			return "", fmt.Errorf("insomnia export format %d isn't supported, export as v4", doc.ExportFormat)
+		}
+		return Insomnia, nil
+	case strings.HasPrefix(doc.OpenAPI, "3."):
+		return OpenAPI, nil
+	case doc.OpenAPI != "" || doc.Swagger != "":
+		return "", fmt.Errorf("OpenAPI %s isn't supported, only OpenAPI 3", doc.OpenAPI+doc.Swagger)
+	case doc.Log != nil:
+		return HAR, nil
+	}
+	return "", fmt.Errorf("unrecognised file, expected a Postman or Insomnia export, OpenAPI 3, HAR or a curl command")
+}
+
+// toJSON converts a YAML document to JSON, leaving JSON as it is
+func toJSON(data []byte) ([]byte, error) {
+	if json.Valid(data) {
+		return data, nil
+	}
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("unrecognised file, neither JSON nor YAML: %w", err)
+	}
+	converted, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported YAML: %w", err)
+	}
+	return converted, nil
+}
+
+// Save creates the collections and requests of the import and adds its
+// variables to environment. Credentials and secret variables that can't be
+// encrypted because no secret key is configured are left out and reported as
+// warnings.
+func (r *Result) Save(store storage.Storage, environment string) (Summary, error) {
+	var summary Summary
+	for _, folder := range r.Collections {
+		if err := r.saveFolder(store, folder, 0, &summary); err != nil {
+			return summary, err
+		}
+	}
+
+	environments := maps.Clone(r.Environments)
+	if len(r.Variables) > 0 {
+		if environments == nil {
+			environments = make(map[string]map[string]string)
+		}
+		merged := maps.Clone(environments[environment])
+		if merged == nil {
+			merged = make(map[string]string)
+		}
+		maps.Copy(merged, r.Variables)
+		environments[environment] = merged
+	}
+	for _, name := range slices.Sorted(maps.Keys(environments)) {
+		if err := r.sealVariables(store, name, environments); err != nil {
+			return summary, err
+		}
+		variables, err := store.LoadEnvironmentVariables(name)
+		if err != nil {
+			return summary, err
+		}
+		if variables == nil {
+			variables = make(map[string]string)
+		}
+		maps.Copy(variables, environments[name])
+		if err := store.SaveEnvironmentVariables(name, variables); err != nil {
+			return summary, err
+		}
+		summary.Variables += len(environments[name])
+	}
+	return summary, nil
+}
+
+// sealVariables encrypts the secret variables of an environment, leaving them
+// out when there's no secret key
+func (r *Result) sealVariables(store storage.Storage, name string, environments map[string]map[string]string) error {
+	sealed := maps.Clone(environments[name]) // the result's own map is left alone
+	for _, key := range slices.Sorted(maps.Keys(sealed)) {
+		if !slices.Contains(r.Secrets, key) {
+			continue
+		}
+		value, err := store.SealSecret(sealed[key])
+		if errors.Is(err, secrets.ErrNoKey) {
+			r.warn("%s: variable %s left out, secret variables need a secret key", name, key)
+			delete(sealed, key)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("variable %s: %w", key, err)
+		}
+		sealed[key] = value
+	}
+	environments[name] = sealed
+	return nil
+}
+
+// saveFolder creates a collection inside parentID and everything in it
+func (r *Result) saveFolder(store storage.Storage, folder *Folder, parentID int64, summary *Summary) error {
+	collection := folder.Collection
+	collection.ParentID = parentID
+	err := store.CreateCollection(&collection)
+	if errors.Is(err, secrets.ErrNoKey) {
+		r.warn("%s: auth left out, it needs a secret key", collection.Name)
+		collection.Auth = ""
+		err = store.CreateCollection(&collection)
+	}
+	if err != nil {
+		return fmt.Errorf("collection %s: %w", collection.Name, err)
+	}
+	summary.Collections++
+
+	for _, request := range folder.Requests {
+		request.CollectionID = collection.ID
+		request.Headers = maps.Clone(request.Headers) // secret ones may be left out below
+		err := store.Save(&request)
+		if errors.Is(err, secrets.ErrNoKey) {
+			r.warn("%s: %s left out, secret headers need a secret key", request.Name, strings.Join(request.Secrets, ", "))
+			for _, key := range request.Secrets {
+				delete(request.Headers, key)
+			}
+			request.Secrets = nil
+			err = store.Save(&request)
+		}
+		if err != nil {
+			return fmt.Errorf("request %s: %w", request.Name, err)
+		}
+		summary.Requests++
+	}
+
+	for _, inner := range folder.Folders {
+		if err := r.saveFolder(store, inner, collection.ID, summary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// truncateName shortens a name to the longest one Volt accepts
+func truncateName(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+	for len(name) > nameLimit {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return strings.TrimSpace(name)
+}
+
+// credentialHeaders are the headers imported as secret
+var credentialHeaders = []string{"authorization", "proxy-authorization", "cookie", "x-api-key", "api-key"}
+
+// addHeader sets a header of a request, marking credentials as secret
+func addHeader(request *http.Request, key, value string) {
+	if request.Headers == nil {
+		request.Headers = make(map[string]string)
+	}
+	request.Headers[key] = value
+	if slices.Contains(credentialHeaders, strings.ToLower(key)) && !request.IsSecret(key) {
+		request.Secrets = append(request.Secrets, key)
+	}
+}
+
+// supportedMethod returns a method Volt can send, upper cased, or false
+func supportedMethod(method string) (string, bool) {
+	method = strings.ToUpper(method)
+	switch method {
+	case http.GET, http.POST, http.PUT, http.PATCH, http.DELETE:
+		return method, true
+	}
+	return method, false
+}
@@ -0,0 +1,365 @@
+package importer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/secrets"
+	"github.com/owenHochwald/volt/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const postmanExport = `{
+  "info": {"_postman_id": "1", "name": "Shop API", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+  "auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]},
+  "variable": [{"key": "base_url", "value": "https://shop.example"}, {"key": "page", "value": 2}],
+  "item": [
+    {
+      "name": "Users",
+      "item": [
+        {
+          "name": "List users",
+          "event": [{"listen": "test", "script": {"exec": ["pm.test('ok', () => {})"]}}],
+          "request": {
+            "method": "GET",
+            "header": [{"key": "Accept", "value": "application/json"}, {"key": "X-Debug", "value": "1", "disabled": true}],
+            "url": {"raw": "{{base_url}}/users?page={{page}}", "host": ["{{base_url}}"], "path": ["users"]}
+          }
+        },
+        {
+          "name": "Login",
+          "request": {
+            "method": "POST",
+            "url": "{{base_url}}/login",
+            "body": {"mode": "urlencoded", "urlencoded": [{"key": "user", "value": "a b"}, {"key": "password", "value": "{{password}}"}]},
+            "auth": {"type": "basic", "basic": [{"key": "username", "value": "admin"}, {"key": "password", "value": "secret"}]}
+          }
+        }
+      ]
+    },
+    {"name": "Search", "request": {"method": "POST", "url": "{{base_url}}/graphql", "body": {"mode": "graphql", "graphql": {"query": "{ users { id } }", "variables": ""}}}},
+    {"name": "Ping", "request": {"method": "HEAD", "url": "{{base_url}}/ping"}},
+    {"name": "Raw", "request": {"method": "PUT", "url": "{{base_url}}/raw", "body": {"mode": "raw", "raw": "{}", "options": {"raw": {"language": "json"}}}}},
+    {"name": "Digest", "request": {"method": "GET", "url": "{{base_url}}/digest", "auth": {"type": "digest"}}}
+  ]
+}`
+
+func TestParse_Postman(t *testing.T) {
+	result, err := Parse([]byte(postmanExport), "export")
+	require.NoError(t, err)
+	assert.Equal(t, Postman, result.Format)
+	assert.Equal(t, map[string]string{"base_url": "https://shop.example", "page": "2"}, result.Variables)
+
+	require.Len(t, result.Collections, 1)
+	root := result.Collections[0]
+	assert.Equal(t, "Shop API", root.Collection.Name)
+	assert.Equal(t, "Bearer {{token}}", root.Collection.Auth)
+	require.Len(t, root.Folders, 1)
+
+	users := root.Folders[0]
+	assert.Equal(t, "Users", users.Collection.Name)
+	require.Len(t, users.Requests, 2)
+	list := users.Requests[0]
+	assert.Equal(t, "{{base_url}}/users?page={{page}}", list.URL)
+	assert.Equal(t, map[string]string{"Accept": "application/json"}, list.Headers)
+
+	login := users.Requests[1]
+	assert.Equal(t, "user=a+b&password={{password}}", login.Body)
+	assert.Equal(t, "application/x-www-form-urlencoded", login.Headers["Content-Type"])
+	assert.Equal(t, "Basic YWRtaW46c2VjcmV0", login.Headers["Authorization"])
+	assert.Equal(t, []string{"Authorization"}, login.Secrets)
+
+	require.Len(t, root.Requests, 3, "the HEAD request is skipped")
+	assert.Equal(t, http.GRAPHQL, root.Requests[0].Method)
+	assert.JSONEq(t, `{"query": "{ users { id } }"}`, root.Requests[0].Body)
+	assert.Equal(t, "application/json", root.Requests[1].Headers["Content-Type"])
+
+	assert.Contains(t, result.Warnings, "List users: test script not imported")
+	assert.Contains(t, result.Warnings, "Ping: HEAD requests aren't supported, skipped")
+	assert.Contains(t, result.Warnings, "Digest: digest auth isn't supported, auth left out")
+}
+
+func TestParse_PostmanEnvironment(t *testing.T) {
+	export := `{"name": "Staging", "_postman_variable_scope": "environment",
+		"values": [{"key": "base_url", "value": "https://staging.example", "enabled": true}, {"key": "old", "value": "x", "enabled": false}]}`
+	result, err := Parse([]byte(export), "staging")
+	require.NoError(t, err)
+	assert.Equal(t, PostmanEnvironment, result.Format)
+	assert.Equal(t, map[string]string{"base_url": "https://staging.example"}, result.Variables)
+	assert.Empty(t, result.Collections)
+}
+
+func TestParse_Insomnia(t *testing.T) {
+	export := `{
+	  "_type": "export", "__export_format": 4,
+	  "resources": [
+	    {"_id": "req_2", "_type": "request", "parentId": "fld_1", "name": "Create user", "metaSortKey": 2, "method": "POST",
+	     "url": "{{ _.base_url }}/users", "body": {"mimeType": "application/json", "text": "{\"name\": \"{{ _.user.name }}\"}"},
+	     "headers": [{"name": "X-Request-Id", "value": "{% uuid 'v4' %}"}],
+	     "authentication": {"type": "bearer", "token": "{{ _.token }}"}},
+	    {"_id": "req_1", "_type": "request", "parentId": "fld_1", "name": "List users", "metaSortKey": 1, "method": "GET",
+	     "url": "{{ _.base_url }}/users", "parameters": [{"name": "page", "value": "1"}, {"name": "debug", "value": "1", "disabled": true}]},
+	    {"_id": "fld_1", "_type": "request_group", "parentId": "wrk_1", "name": "Users"},
+	    {"_id": "wrk_1", "_type": "workspace", "name": "Accounts"},
+	    {"_id": "ws_1", "_type": "websocket_request", "parentId": "wrk_1", "name": "Events", "url": "wss://accounts.example/events"},
+	    {"_id": "env_1", "_type": "environment", "parentId": "wrk_1", "name": "Base Environment", "data": {"base_url": "https://accounts.example", "user": {"name": "ada"}}},
+	    {"_id": "env_2", "_type": "environment", "parentId": "env_1", "name": "Staging", "data": {"base_url": "https://staging.accounts.example", "retries": 3}}
+	  ]
+	}`
+	result, err := Parse([]byte(export), "export")
+	require.NoError(t, err)
+	assert.Equal(t, Insomnia, result.Format)
+
+	require.Len(t, result.Collections, 1)
+	workspace := result.Collections[0]
+	assert.Equal(t, "Accounts", workspace.Collection.Name)
+	require.Len(t, workspace.Requests, 1)
+	assert.Equal(t, http.WS, workspace.Requests[0].Method)
+
+	require.Len(t, workspace.Folders, 1)
+	users := workspace.Folders[0].Requests
+	require.Len(t, users, 2)
+	assert.Equal(t, "List users", users[0].Name, "requests keep their order")
+	assert.Equal(t, "{{base_url}}/users?page=1", users[0].URL)
+	assert.Equal(t, `{"name": "{{user.name}}"}`, users[1].Body)
+	assert.Equal(t, "application/json", users[1].Headers["Content-Type"])
+	assert.Equal(t, "Bearer {{token}}", users[1].Headers["Authorization"])
+
+	assert.Equal(t, map[string]string{"base_url": "https://accounts.example", "user.name": "ada"}, result.Variables)
+	assert.Equal(t, map[string]map[string]string{"Staging": {"base_url": "https://staging.accounts.example", "retries": "3"}}, result.Environments)
+	assert.Contains(t, result.Warnings, "Create user: template tag {% uuid 'v4' %} isn't supported")
+}
+
+func TestParse_OpenAPI(t *testing.T) {
+	spec := `
+openapi: 3.0.3
+info:
+  title: Pet Store
+servers:
+  - url: https://{region}.pets.example/v1
+    variables:
+      region:
+        default: eu
+security:
+  - bearer: []
+paths:
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+    get:
+      summary: Get a pet
+      tags: [pets]
+      parameters:
+        - name: fields
+          in: query
+          required: true
+          schema:
+            type: string
+            example: name
+        - $ref: '#/components/parameters/Tenant'
+  /pets:
+    post:
+      operationId: createPet
+      tags: [pets]
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+    head:
+      summary: Count pets
+  /health:
+    get:
+      summary: Health
+      security:
+        - apiKey: []
+components:
+  parameters:
+    Tenant:
+      name: X-Tenant
+      in: header
+      required: true
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+          example: Rex
+        tags:
+          type: array
+          items:
+            type: string
+        age:
+          type: integer
+  securitySchemes:
+    bearer:
+      type: http
+      scheme: bearer
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-API-Key
+`
+	result, err := Parse([]byte(spec), "pets")
+	require.NoError(t, err)
+	assert.Equal(t, OpenAPI, result.Format)
+
+	require.Len(t, result.Collections, 1)
+	root := result.Collections[0]
+	assert.Equal(t, "Pet Store", root.Collection.Name)
+	assert.Equal(t, "https://eu.pets.example/v1", root.Collection.BaseURL)
+	assert.Equal(t, "Bearer {{token}}", root.Collection.Auth)
+
+	require.Len(t, root.Requests, 1)
+	health := root.Requests[0]
+	assert.Equal(t, "/health", health.URL)
+	assert.Equal(t, "{{api_key}}", health.Headers["X-API-Key"])
+
+	require.Len(t, root.Folders, 1)
+	pets := root.Folders[0].Requests
+	require.Len(t, pets, 2)
+	create := pets[0]
+	assert.Equal(t, "createPet", create.Name)
+	assert.Equal(t, http.POST, create.Method)
+	assert.JSONEq(t, `{"name": "Rex", "tags": [""], "age": 0}`, create.Body)
+	assert.Equal(t, "application/json", create.Headers["Content-Type"])
+
+	get := pets[1]
+	assert.Equal(t, "/pets/{{petId}}?fields=name", get.URL)
+	assert.Equal(t, "{{X-Tenant}}", get.Headers["X-Tenant"])
+
+	assert.Contains(t, result.Warnings, "Count pets: HEAD requests aren't supported, skipped")
+}
+
+func TestParse_HAR(t *testing.T) {
+	archive := `{"log": {"version": "1.2", "entries": [
+	  {"request": {"method": "GET", "url": "https://app.example/api/me", "headers": [
+	    {"name": ":authority", "value": "app.example"}, {"name": "Cookie", "value": "session=abc"}, {"name": "Accept", "value": "*/*"}]}},
+	  {"request": {"method": "POST", "url": "https://app.example/api/events", "headers": [{"name": "Content-Length", "value": "2"}],
+	    "postData": {"mimeType": "application/json", "text": "{}"}}},
+	  {"request": {"method": "GET", "url": "data:image/png;base64,AAAA"}},
+	  {"request": {"method": "OPTIONS", "url": "https://app.example/api/events"}}
+	]}}`
+	result, err := Parse([]byte(archive), "session")
+	require.NoError(t, err)
+	assert.Equal(t, HAR, result.Format)
+
+	require.Len(t, result.Collections, 1)
+	requests := result.Collections[0].Requests
+	require.Len(t, requests, 2)
+	assert.Equal(t, "GET app.example/api/me", requests[0].Name)
+	assert.Equal(t, map[string]string{"Cookie": "session=abc", "Accept": "*/*"}, requests[0].Headers)
+	assert.Equal(t, []string{"Cookie"}, requests[0].Secrets)
+	assert.Equal(t, map[string]string{"Content-Type": "application/json"}, requests[1].Headers)
+	assert.Equal(t, []string{"skipped 1 OPTIONS requests", "skipped 1 URLs that aren't http or https"}, result.Warnings)
+}
+
+func TestParse_Unrecognised(t *testing.T) {
+	for _, data := range []string{`{"hello": "world"}`, `swagger: "2.0"`, `{"_type": "export", "__export_format": 3}`, "\t: not yaml"} {
+		_, err := Parse([]byte(data), "file")
+		assert.Error(t, err, data)
+	}
+}
+
+func TestResult_Save(t *testing.T) {
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "volt.db"))
+	require.NoError(t, err)
+	defer store.Close()
+	require.NoError(t, store.SaveEnvironmentVariables("staging", map[string]string{"token": "kept", "page": "1"}))
+
+	result, err := Parse([]byte(postmanExport), "export")
+	require.NoError(t, err)
+	summary, err := result.Save(store, "staging")
+	require.NoError(t, err)
+	assert.Equal(t, Summary{Collections: 2, Requests: 5, Variables: 2}, summary)
+	assert.Equal(t, "Imported 2 collections, 5 requests and 2 variables", summary.String())
+
+	// without a secret key, credentials are left out
+	assert.Contains(t, result.Warnings, "Shop API: auth left out, it needs a secret key")
+	assert.Contains(t, result.Warnings, "Login: Authorization left out, secret headers need a secret key")
+
+	collections, err := store.LoadCollections()
+	require.NoError(t, err)
+	require.Len(t, collections, 2)
+	requests, err := store.Load()
+	require.NoError(t, err)
+	assert.Len(t, requests, 5)
+	for _, request := range requests {
+		assert.NotZero(t, request.CollectionID)
+	}
+
+	variables, err := store.LoadEnvironmentVariables("staging")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"token": "kept", "page": "2", "base_url": "https://shop.example"}, variables)
+
+	// with one, they are encrypted
+	vault, err := secrets.NewVault([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	require.NoError(t, store.SetVault(vault))
+	result, err = Parse([]byte(postmanExport), "export")
+	require.NoError(t, err)
+	_, err = result.Save(store, "staging")
+	require.NoError(t, err)
+	assert.NotContains(t, result.Warnings, "Shop API: auth left out, it needs a secret key")
+	assert.Equal(t, "Basic YWRtaW46c2VjcmV0", result.Collections[0].Folders[0].Requests[1].Headers["Authorization"],
+		"saving doesn't change the import")
+}
+
+func TestResult_SaveSecretVariables(t *testing.T) {
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "volt.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	export := `{"name": "Staging", "_postman_variable_scope": "environment",
+		"values": [{"key": "base_url", "value": "https://staging.example"}, {"key": "password", "value": "hunter2", "type": "secret"}]}`
+	result, err := Parse([]byte(export), "staging")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"password"}, result.Secrets)
+
+	// without a secret key, secret variables are left out
+	summary, err := result.Save(store, "staging")
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Variables)
+	assert.Contains(t, result.Warnings, "staging: variable password left out, secret variables need a secret key")
+	variables, err := store.LoadEnvironmentVariables("staging")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"base_url": "https://staging.example"}, variables)
+
+	// with one, they are encrypted
+	vault, err := secrets.NewVault([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	require.NoError(t, store.SetVault(vault))
+	result, err = Parse([]byte(export), "staging")
+	require.NoError(t, err)
+	_, err = result.Save(store, "staging")
+	require.NoError(t, err)
+	assert.Empty(t, result.Warnings)
+	assert.Equal(t, "hunter2", result.Variables["password"], "saving doesn't change the import")
+
+	variables, err = store.LoadEnvironmentVariables("staging")
+	require.NoError(t, err)
+	assert.True(t, secrets.IsSealed(variables["password"]))
+	password, err := vault.Open(variables["password"])
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", password)
+}
+
+func TestParse_InsomniaPrivateEnvironment(t *testing.T) {
+	export := `{
+	  "_type": "export", "__export_format": 4,
+	  "resources": [
+	    {"_id": "wrk_1", "_type": "workspace", "name": "Accounts"},
+	    {"_id": "env_1", "_type": "environment", "parentId": "wrk_1", "name": "Base Environment", "data": {"base_url": "https://accounts.example"}},
+	    {"_id": "env_2", "_type": "environment", "parentId": "env_1", "name": "Mine", "isPrivate": true, "data": {"token": "abc"}}
+	  ]
+	}`
+	result, err := Parse([]byte(export), "export")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{"Mine": {"token": "abc"}}, result.Environments)
+	assert.Equal(t, []string{"token"}, result.Secrets)
+}
@@ -0,0 +1,277 @@
+package importer
+
+import (
+	"cmp"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/owenHochwald/volt/internal/http"
+)
+
+// insomniaResource is any resource of an Insomnia export, told apart by its type
+type insomniaResource struct {
+	ID          string  `json:"_id"`
+	Type        string  `json:"_type"`
+	ParentID    string  `json:"parentId"`
+	Name        string  `json:"name"`
+	MetaSortKey float64 `json:"metaSortKey"`
+
+	// requests
+	Method     string `json:"method"`
+	URL        string `json:"url"`
+	Parameters []struct {
+		Name     string `json:"name"`
+		Value    string `json:"value"`
+		Disabled bool   `json:"disabled"`
+	} `json:"parameters"`
+	Headers []struct {
+		Name     string `json:"name"`
+		Value    string `json:"value"`
+		Disabled bool   `json:"disabled"`
+	} `json:"headers"`
+	Body struct {
+		MimeType string `json:"mimeType"`
+		Text     string `json:"text"`
+		Params   []struct {
+			Name     string `json:"name"`
+			Value    string `json:"value"`
+			Disabled bool   `json:"disabled"`
+		} `json:"params"`
+	} `json:"body"`
+	Authentication      *insomniaAuth `json:"authentication"`
+	PreRequestScript    string        `json:"preRequestScript"`
+	AfterResponseScript string        `json:"afterResponseScript"`
+
+	// environments, and the variables of folders
+	Data        map[string]any `json:"data"`
+	Environment map[string]any `json:"environment"`
+	IsPrivate   bool           `json:"isPrivate"` // private environments hold secrets
+}
+
+type insomniaAuth struct {
+	Type     string `json:"type"`
+	Disabled bool   `json:"disabled"`
+	Token    string `json:"token"`
+	Prefix   string `json:"prefix"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	AddTo    string `json:"addTo"`
+}
+
+var (
+	// insomniaVariable matches Insomnia's {{ _.name }} references
+	insomniaVariable = regexp.MustCompile(`\{\{\s*_\.([\w.-]+)\s*\}\}`)
+
+	// insomniaTag matches template tags such as {% response ... %}, which Volt can't run
+	insomniaTag = regexp.MustCompile(`\{%.*?%\}`)
+)
+
+func parseInsomnia(data []byte, result *Result) error {
+	var export struct {
+		Resources []insomniaResource `json:"resources"`
+	}
+	if err := json.Unmarshal(data, &export); err != nil {
+		return err
+	}
+
+	resources := export.Resources
+	slices.SortStableFunc(resources, func(a, b insomniaResource) int { return cmp.Compare(a.MetaSortKey, b.MetaSortKey) })
+
+	// Workspaces and folders, by ID
+	folders := make(map[string]*Folder)
+	for _, resource := range resources {
+		if resource.Type == "workspace" || resource.Type == "request_group" {
+			folders[resource.ID] = &Folder{Collection: http.Collection{Name: truncateName(cmp.Or(resource.Name, "Folder"))}}
+		}
+	}
+	var orphans *Folder // for exports of folders without their workspace
+	parentOf := func(resource insomniaResource) *Folder {
+		if folder, ok := folders[resource.ParentID]; ok {
+			return folder
+		}
+		if orphans == nil {
+			orphans = &Folder{Collection: http.Collection{Name: "Insomnia"}}
+			result.Collections = append(result.Collections, orphans)
+		}
+		return orphans
+	}
+
+	for _, resource := range resources {
+		name := truncateName(resource.Name)
+		switch resource.Type {
+		case "workspace":
+			result.Collections = append(result.Collections, folders[resource.ID])
+
+		case "request_group":
+			folder := folders[resource.ID]
+			parent := parentOf(resource)
+			parent.Folders = append(parent.Folders, folder)
+			if len(resource.Environment) > 0 {
+				result.warn("%s: folder environments aren't supported, variables left out", name)
+			}
+			if key, value, ok := insomniaAuthHeader(result, name, resource.Authentication); ok {
+				value = convertInsomniaTemplate(result, name, value)
+				if strings.EqualFold(key, "Authorization") {
+					folder.Collection.Auth = value
+				} else {
+					folder.Collection.Headers = map[string]string{key: value}
+				}
+			}
+
+		case "request", "websocket_request":
+			if request, ok := insomniaRequest(result, resource); ok {
+				parent := parentOf(resource)
+				parent.Requests = append(parent.Requests, request)
+			}
+
+		case "environment":
+			variables := make(map[string]string)
+			flattenVariables(variables, "", resource.Data)
+			for key, value := range variables {
+				variables[key] = convertInsomniaTemplate(result, name, value)
+				if resource.IsPrivate {
+					result.secret(key)
+				}
+			}
+			if _, ok := folders[resource.ParentID]; ok || resource.ParentID == "" {
+				// the base environment of a workspace
+				if result.Variables == nil {
+					result.Variables = make(map[string]string)
+				}
+				for key, value := range variables {
+					result.Variables[key] = value
+				}
+			} else if len(variables) > 0 {
+				if result.Environments == nil {
+					result.Environments = make(map[string]map[string]string)
+				}
+				result.Environments[resource.Name] = variables
+			}
+
+		case "grpc_request":
+			result.warn("%s: gRPC requests aren't supported, skipped", name)
+		}
+	}
+	return nil
+}
+
+// insomniaRequest converts a request or a WebSocket request
+func insomniaRequest(result *Result, resource insomniaResource) (http.Request, bool) {
+	name := truncateName(resource.Name)
+	request := http.Request{Name: name, Method: http.WS}
+	if resource.Type == "request" {
+		method, ok := supportedMethod(cmp.Or(resource.Method, http.GET))
+		if !ok {
+			result.warn("%s: %s requests aren't supported, skipped", name, method)
+			return request, false
+		}
+		request.Method = method
+	}
+	if resource.PreRequestScript != "" || resource.AfterResponseScript != "" {
+		result.warn("%s: scripts not imported", name)
+	}
+
+	request.URL = convertInsomniaTemplate(result, name, resource.URL)
+	var query []string
+	for _, param := range resource.Parameters {
+		if !param.Disabled && param.Name != "" {
+			query = append(query, formEscape(param.Name)+"="+formEscape(param.Value))
+		}
+	}
+	if len(query) > 0 {
+		separator := "?"
+		if strings.Contains(request.URL, "?") {
+			separator = "&"
+		}
+		request.URL += separator + convertInsomniaTemplate(result, name, strings.Join(query, "&"))
+	}
+
+	for _, header := range resource.Headers {
+		if !header.Disabled && header.Name != "" {
+			addHeader(&request, header.Name, convertInsomniaTemplate(result, name, header.Value))
+		}
+	}
+
+	body := resource.Body
+	switch body.MimeType {
+	case "application/x-www-form-urlencoded":
+		var form []string
+		for _, param := range body.Params {
+			if !param.Disabled {
+				form = append(form, formEscape(param.Name)+"="+formEscape(param.Value))
+			}
+		}
+		request.Body = convertInsomniaTemplate(result, name, strings.Join(form, "&"))
+	case "multipart/form-data":
+		result.warn("%s: multipart bodies aren't supported, body left out", name)
+	case "application/graphql":
+		// the text is already a query and its variables as JSON
+		request.Method = http.GRAPHQL
+		request.Body = convertInsomniaTemplate(result, name, body.Text)
+	default:
+		request.Body = convertInsomniaTemplate(result, name, body.Text)
+	}
+	if body.MimeType != "" && body.MimeType != "application/graphql" && request.Body != "" {
+		setDefaultHeader(&request, "Content-Type", body.MimeType)
+	}
+
+	if key, value, ok := insomniaAuthHeader(result, name, resource.Authentication); ok {
+		addHeader(&request, key, convertInsomniaTemplate(result, name, value))
+	}
+	return request, true
+}
+
+// insomniaAuthHeader returns the header an auth sets, warning about the ones
+// that can't be converted
+func insomniaAuthHeader(result *Result, name string, auth *insomniaAuth) (string, string, bool) {
+	if auth == nil || auth.Disabled {
+		return "", "", false
+	}
+	switch auth.Type {
+	case "", "none":
+		return "", "", false
+	case "bearer":
+		return "Authorization", cmp.Or(auth.Prefix, "Bearer") + " " + auth.Token, true
+	case "basic":
+		username := convertInsomniaTemplate(result, name, auth.Username)
+		password := convertInsomniaTemplate(result, name, auth.Password)
+		return basicAuthHeader(result, name, username, password)
+	case "apikey":
+		if auth.AddTo != "" && auth.AddTo != "header" {
+			result.warn("%s: API keys in the %s aren't supported, add %s yourself", name, auth.AddTo, auth.Key)
+			return "", "", false
+		}
+		return auth.Key, auth.Value, auth.Key != ""
+	}
+	result.warn("%s: %s auth isn't supported, auth left out", name, auth.Type)
+	return "", "", false
+}
+
+// convertInsomniaTemplate turns {{ _.name }} into {{name}}, warning about
+// template tags which are left as they are
+func convertInsomniaTemplate(result *Result, name, text string) string {
+	if insomniaTag.MatchString(text) {
+		result.warn("%s: template tag %s isn't supported", name, insomniaTag.FindString(text))
+	}
+	return insomniaVariable.ReplaceAllString(text, "{{$1}}")
+}
+
+// flattenVariables adds the values of nested objects as prefix.key
+func flattenVariables(variables map[string]string, prefix string, data map[string]any) {
+	for key, value := range data {
+		switch value := value.(type) {
+		case map[string]any:
+			flattenVariables(variables, prefix+key+".", value)
+		case string:
+			variables[prefix+key] = value
+		case nil:
+		default:
+			encoded, _ := json.Marshal(value)
+			variables[prefix+key] = string(encoded)
+		}
+	}
+}
@@ -0,0 +1,409 @@
+package importer
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/owenHochwald/volt/internal/http"
+)
+
+type openAPIDocument struct {
+	Info struct {
+		Title string `json:"title"`
+	} `json:"info"`
+	Servers    []openAPIServer                       `json:"servers"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Security   []map[string][]string                 `json:"security"`
+	Components struct {
+		Schemas         map[string]*openAPISchema         `json:"schemas"`
+		Parameters      map[string]*openAPIParameter      `json:"parameters"`
+		RequestBodies   map[string]*openAPIRequestBody    `json:"requestBodies"`
+		SecuritySchemes map[string]*openAPISecurityScheme `json:"securitySchemes"`
+	} `json:"components"`
+}
+
+type openAPIServer struct {
+	URL       string `json:"url"`
+	Variables map[string]struct {
+		Default string `json:"default"`
+	} `json:"variables"`
+}
+
+type openAPIOperation struct {
+	Summary     string                 `json:"summary"`
+	OperationID string                 `json:"operationId"`
+	Tags        []string               `json:"tags"`
+	Parameters  []*openAPIParameter    `json:"parameters"`
+	RequestBody *openAPIRequestBody    `json:"requestBody"`
+	Security    *[]map[string][]string `json:"security"`
+}
+
+type openAPIParameter struct {
+	Ref      string         `json:"$ref"`
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required"`
+	Example  any            `json:"example"`
+	Schema   *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Ref     string `json:"$ref"`
+	Content map[string]struct {
+		Example  any `json:"example"`
+		Examples map[string]struct {
+			Value any `json:"value"`
+		} `json:"examples"`
+		Schema *openAPISchema `json:"schema"`
+	} `json:"content"`
+}
+
+type openAPISchema struct {
+	Ref        string                    `json:"$ref"`
+	Type       any                       `json:"type"` // a name, or a list of them in 3.1
+	Properties map[string]*openAPISchema `json:"properties"`
+	Items      *openAPISchema            `json:"items"`
+	AllOf      []*openAPISchema          `json:"allOf"`
+	OneOf      []*openAPISchema          `json:"oneOf"`
+	AnyOf      []*openAPISchema          `json:"anyOf"`
+	Example    any                       `json:"example"`
+	Default    any                       `json:"default"`
+	Enum       []any                     `json:"enum"`
+}
+
+type openAPISecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+	In     string `json:"in"`
+	Name   string `json:"name"`
+}
+
+// openAPIMethods are the operations of a path, in the order they are imported
+var openAPIMethods = []string{"get", "post", "put", "patch", "delete", "head", "options", "trace"}
+
+// pathParameter matches a {name} parameter of a path or server URL
+var pathParameter = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// sampleDepth limits how deep example bodies are built from schemas, which may be recursive
+const sampleDepth = 8
+
+// parseOpenAPI converts the operations of an API into requests, in folders
+// by tag, of a collection whose base URL is the API's first server
+func parseOpenAPI(data []byte, name string, result *Result) error {
+	var doc openAPIDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	root := &Folder{Collection: http.Collection{Name: truncateName(cmp.Or(doc.Info.Title, name, "OpenAPI"))}}
+	result.Collections = append(result.Collections, root)
+	root.Collection.BaseURL = doc.baseURL(result)
+	if len(doc.Security) > 0 {
+		for key, value := range doc.securityHeaders(result, doc.Security[0]) {
+			if strings.EqualFold(key, "Authorization") {
+				root.Collection.Auth = value
+				continue
+			}
+			if root.Collection.Headers == nil {
+				root.Collection.Headers = make(map[string]string)
+			}
+			root.Collection.Headers[key] = value
+		}
+	}
+
+	tags := make(map[string]*Folder)
+	for _, path := range slices.Sorted(maps.Keys(doc.Paths)) {
+		item := doc.Paths[path]
+		var shared []*openAPIParameter
+		if raw, ok := item["parameters"]; ok {
+			if err := json.Unmarshal(raw, &shared); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+		}
+
+		for _, method := range openAPIMethods {
+			raw, ok := item[method]
+			if !ok {
+				continue
+			}
+			var operation openAPIOperation
+			if err := json.Unmarshal(raw, &operation); err != nil {
+				return fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+			request, ok := doc.request(result, method, path, shared, &operation)
+			if !ok {
+				continue
+			}
+
+			folder := root
+			if len(operation.Tags) > 0 {
+				tag := truncateName(operation.Tags[0])
+				if tags[tag] == nil {
+					tags[tag] = &Folder{Collection: http.Collection{Name: tag}}
+					root.Folders = append(root.Folders, tags[tag])
+				}
+				folder = tags[tag]
+			}
+			folder.Requests = append(folder.Requests, request)
+		}
+	}
+	return nil
+}
+
+// baseURL is the URL of the first server, with its variables set to their defaults
+func (d *openAPIDocument) baseURL(result *Result) string {
+	if len(d.Servers) == 0 {
+		result.warn("no servers listed, set the base URL with volt collection set")
+		return ""
+	}
+	server := d.Servers[0]
+	base := pathParameter.ReplaceAllStringFunc(server.URL, func(match string) string {
+		return server.Variables[match[1:len(match)-1]].Default
+	})
+	base = strings.TrimSuffix(base, "/")
+	if !strings.Contains(base, "://") {
+		result.warn("server URL %q is relative, set the base URL with volt collection set", server.URL)
+		if !strings.HasPrefix(base, "/") {
+			return ""
+		}
+	}
+	return base
+}
+
+// request converts an operation, which is skipped when its method isn't supported
+func (d *openAPIDocument) request(result *Result, method, path string, shared []*openAPIParameter, operation *openAPIOperation) (http.Request, bool) {
+	name := truncateName(cmp.Or(operation.Summary, operation.OperationID, strings.ToUpper(method)+" "+path))
+	method, ok := supportedMethod(method)
+	if !ok {
+		result.warn("%s: %s requests aren't supported, skipped", name, method)
+		return http.Request{}, false
+	}
+
+	// path parameters become variables
+	request := http.Request{Name: name, Method: method, URL: pathParameter.ReplaceAllString(path, "{{$1}}")}
+
+	// parameters of the operation override the path's ones
+	parameters := make(map[string]*openAPIParameter)
+	var order []string
+	for _, parameter := range slices.Concat(shared, operation.Parameters) {
+		parameter = d.parameter(parameter)
+		if parameter == nil || parameter.Name == "" {
+			continue
+		}
+		key := parameter.In + ":" + parameter.Name
+		if _, ok := parameters[key]; !ok {
+			order = append(order, key)
+		}
+		parameters[key] = parameter
+	}
+	var query []string
+	for _, key := range order {
+		parameter := parameters[key]
+		if !parameter.Required {
+			continue
+		}
+		value := d.parameterValue(parameter)
+		switch parameter.In {
+		case "query":
+			query = append(query, formEscape(parameter.Name)+"="+formEscape(value))
+		case "header":
+			addHeader(&request, parameter.Name, value)
+		case "cookie":
+			result.warn("%s: cookie parameter %s isn't supported, left out", name, parameter.Name)
+		}
+	}
+	if len(query) > 0 {
+		request.URL += "?" + strings.Join(query, "&")
+	}
+
+	if body := d.requestBody(operation.RequestBody); body != nil && len(body.Content) > 0 {
+		contentType := slices.Sorted(maps.Keys(body.Content))[0]
+		if _, ok := body.Content["application/json"]; ok {
+			contentType = "application/json"
+		}
+		content := body.Content[contentType]
+		example := content.Example
+		if example == nil {
+			for _, key := range slices.Sorted(maps.Keys(content.Examples)) {
+				example = content.Examples[key].Value
+				break
+			}
+		}
+		if example == nil {
+			example = d.sample(content.Schema, 0)
+		}
+		switch example := example.(type) {
+		case nil:
+		case string:
+			request.Body = example
+		default:
+			encoded, err := json.MarshalIndent(example, "", "  ")
+			if err == nil {
+				request.Body = string(encoded)
+			}
+		}
+		setDefaultHeader(&request, "Content-Type", contentType)
+	}
+
+	// the collection has the API's security, operations can only add to it
+	if operation.Security != nil && len(*operation.Security) > 0 {
+		requirement := (*operation.Security)[0]
+		if len(d.Security) == 0 || !slices.Equal(slices.Sorted(maps.Keys(requirement)), slices.Sorted(maps.Keys(d.Security[0]))) {
+			for key, value := range d.securityHeaders(result, requirement) {
+				addHeader(&request, key, value)
+			}
+		}
+	}
+	return request, true
+}
+
+// parameter resolves a reference to a parameter of the components
+func (d *openAPIDocument) parameter(parameter *openAPIParameter) *openAPIParameter {
+	for depth := 0; parameter != nil && parameter.Ref != "" && depth < sampleDepth; depth++ {
+		name, _ := strings.CutPrefix(parameter.Ref, "#/components/parameters/")
+		parameter = d.Components.Parameters[name]
+	}
+	return parameter
+}
+
+// requestBody resolves a reference to a request body of the components
+func (d *openAPIDocument) requestBody(body *openAPIRequestBody) *openAPIRequestBody {
+	for depth := 0; body != nil && body.Ref != "" && depth < sampleDepth; depth++ {
+		name, _ := strings.CutPrefix(body.Ref, "#/components/requestBodies/")
+		body = d.Components.RequestBodies[name]
+	}
+	return body
+}
+
+// parameterValue is the example of a parameter, or a variable named after it
+func (d *openAPIDocument) parameterValue(parameter *openAPIParameter) string {
+	example := parameter.Example
+	if example == nil && parameter.Schema != nil {
+		schema := d.schema(parameter.Schema, 0)
+		example = schema.Example
+		if example == nil {
+			example = schema.Default
+		}
+	}
+	if example == nil {
+		return "{{" + parameter.Name + "}}"
+	}
+	if text, ok := example.(string); ok {
+		return text
+	}
+	encoded, _ := json.Marshal(example)
+	return string(encoded)
+}
+
+// schema resolves a reference to a schema of the components
+func (d *openAPIDocument) schema(schema *openAPISchema, depth int) *openAPISchema {
+	for ; schema != nil && schema.Ref != "" && depth < sampleDepth; depth++ {
+		name, _ := strings.CutPrefix(schema.Ref, "#/components/schemas/")
+		schema = d.Components.Schemas[name]
+	}
+	if schema == nil {
+		return &openAPISchema{}
+	}
+	return schema
+}
+
+// sample builds an example value of a schema, from its examples and defaults
+// or else the zero value of its type
+func (d *openAPIDocument) sample(schema *openAPISchema, depth int) any {
+	if schema == nil || depth > sampleDepth {
+		return nil
+	}
+	schema = d.schema(schema, depth)
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case len(schema.AllOf) > 0:
+		merged := make(map[string]any)
+		for _, part := range schema.AllOf {
+			if object, ok := d.sample(part, depth+1).(map[string]any); ok {
+				maps.Copy(merged, object)
+			}
+		}
+		return merged
+	case len(schema.OneOf) > 0:
+		return d.sample(schema.OneOf[0], depth+1)
+	case len(schema.AnyOf) > 0:
+		return d.sample(schema.AnyOf[0], depth+1)
+	}
+
+	switch schema.typeName() {
+	case "array":
+		if item := d.sample(schema.Items, depth+1); item != nil {
+			return []any{item}
+		}
+		return []any{}
+	case "string":
+		return ""
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	case "object", "":
+		if schema.Properties == nil && schema.typeName() == "" {
+			return nil
+		}
+		object := make(map[string]any)
+		for key, property := range schema.Properties {
+			object[key] = d.sample(property, depth+1)
+		}
+		return object
+	}
+	return nil
+}
+
+// typeName is the type of a schema, the first that isn't null when it lists several
+func (s *openAPISchema) typeName() string {
+	switch kind := s.Type.(type) {
+	case string:
+		return kind
+	case []any:
+		for _, name := range kind {
+			if name, ok := name.(string); ok && name != "null" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// securityHeaders returns the headers of a security requirement, referencing
+// variables for the credentials
+func (d *openAPIDocument) securityHeaders(result *Result, requirement map[string][]string) map[string]string {
+	headers := make(map[string]string)
+	for _, name := range slices.Sorted(maps.Keys(requirement)) {
+		scheme := d.Components.SecuritySchemes[name]
+		if scheme == nil {
+			continue
+		}
+		switch {
+		case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer"),
+			scheme.Type == "oauth2", scheme.Type == "openIdConnect":
+			headers["Authorization"] = "Bearer {{token}}"
+		case scheme.Type == "apiKey" && scheme.In == "header":
+			headers[scheme.Name] = "{{" + variableName(scheme.Name) + "}}"
+		default:
+			result.warn("%s: %s security isn't supported, set it up yourself", name, cmp.Or(scheme.Scheme, scheme.In, scheme.Type))
+		}
+	}
+	return headers
+}
+
+// variableName turns a header name such as X-API-Key into a variable name, api_key
+func variableName(header string) string {
+	name := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(header, "X-"), "x-"))
+	return strings.ReplaceAll(name, "-", "_")
+}
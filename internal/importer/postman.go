@@ -0,0 +1,414 @@
+package importer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/owenHochwald/volt/internal/http"
+)
+
+type postmanCollection struct {
+	Info struct {
+		Name string `json:"name"`
+	} `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Auth     *postmanAuth      `json:"auth"`
+	Event    []postmanEvent    `json:"event"`
+	Variable []postmanVariable `json:"variable"`
+}
+
+// postmanItem is a request, or a folder when it has no request
+type postmanItem struct {
+	Name    string          `json:"name"`
+	Item    []postmanItem   `json:"item"`
+	Request *postmanRequest `json:"request"`
+	Auth    *postmanAuth    `json:"auth"`
+	Event   []postmanEvent  `json:"event"`
+}
+
+type postmanRequest struct {
+	Method string            `json:"method"`
+	Header []postmanVariable `json:"header"`
+	URL    postmanURL        `json:"url"`
+	Body   *postmanBody      `json:"body"`
+	Auth   *postmanAuth      `json:"auth"`
+}
+
+// UnmarshalJSON also accepts a request given as just its URL
+func (r *postmanRequest) UnmarshalJSON(data []byte) error {
+	var raw string
+	if json.Unmarshal(data, &raw) == nil {
+		*r = postmanRequest{Method: http.GET, URL: postmanURL(raw)}
+		return nil
+	}
+	type request postmanRequest
+	return json.Unmarshal(data, (*request)(r))
+}
+
+// postmanURL is the raw URL of a request, given as a string or an object
+type postmanURL string
+
+func (u *postmanURL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if json.Unmarshal(data, &raw) == nil {
+		*u = postmanURL(raw)
+		return nil
+	}
+	var parts struct {
+		Raw      string            `json:"raw"`
+		Protocol string            `json:"protocol"`
+		Host     []string          `json:"host"`
+		Path     []string          `json:"path"`
+		Query    []postmanVariable `json:"query"`
+	}
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return err
+	}
+	if parts.Raw != "" {
+		*u = postmanURL(parts.Raw)
+		return nil
+	}
+
+	built := strings.Join(parts.Host, ".")
+	if parts.Protocol != "" {
+		built = parts.Protocol + "://" + built
+	}
+	if len(parts.Path) > 0 {
+		built += "/" + strings.Join(parts.Path, "/")
+	}
+	var query []string
+	for _, param := range parts.Query {
+		if !param.Disabled {
+			query = append(query, param.Key+"="+param.Value)
+		}
+	}
+	if len(query) > 0 {
+		built += "?" + strings.Join(query, "&")
+	}
+	*u = postmanURL(built)
+	return nil
+}
+
+type postmanBody struct {
+	Mode       string            `json:"mode"`
+	Raw        string            `json:"raw"`
+	URLEncoded []postmanVariable `json:"urlencoded"`
+	GraphQL    *struct {
+		Query     string `json:"query"`
+		Variables string `json:"variables"`
+	} `json:"graphql"`
+	Options struct {
+		Raw struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+}
+
+// postmanAuth holds the parameters of its type, e.g. Bearer for bearer
+type postmanAuth struct {
+	Type   string          `json:"type"`
+	Bearer json.RawMessage `json:"bearer"`
+	Basic  json.RawMessage `json:"basic"`
+	APIKey json.RawMessage `json:"apikey"`
+}
+
+// params returns the parameters of the auth's type, listed as key and value
+// pairs in v2.1 and as an object in v2.0
+func (a *postmanAuth) params() map[string]string {
+	var raw json.RawMessage
+	switch a.Type {
+	case "bearer":
+		raw = a.Bearer
+	case "basic":
+		raw = a.Basic
+	case "apikey":
+		raw = a.APIKey
+	}
+	params := make(map[string]string)
+	var list []postmanVariable
+	if json.Unmarshal(raw, &list) == nil {
+		for _, param := range list {
+			params[param.Key] = param.Value
+		}
+		return params
+	}
+	json.Unmarshal(raw, &params)
+	return params
+}
+
+type postmanEvent struct {
+	Listen string `json:"listen"`
+	Script struct {
+		Exec json.RawMessage `json:"exec"` // lines, or the whole script
+	} `json:"script"`
+}
+
+// postmanVariable is a key and value pair, used for variables, headers and parameters
+type postmanVariable struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+	Enabled  *bool  `json:"enabled"` // environments disable with enabled: false
+	Type     string `json:"type"`    // "secret" for variables hidden in Postman
+}
+
+// UnmarshalJSON keeps values that aren't strings, such as numbers, as their JSON
+func (v *postmanVariable) UnmarshalJSON(data []byte) error {
+	type variable postmanVariable
+	var parsed struct {
+		variable
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+	*v = postmanVariable(parsed.variable)
+	if json.Unmarshal(parsed.Value, &v.Value) != nil {
+		v.Value = string(parsed.Value)
+	}
+	return nil
+}
+
+func parsePostman(data []byte, result *Result) error {
+	var export postmanCollection
+	if err := json.Unmarshal(data, &export); err != nil {
+		return err
+	}
+
+	root := &Folder{Collection: http.Collection{Name: truncateName(export.Info.Name)}}
+	if root.Collection.Name == "" {
+		root.Collection.Name = "Postman"
+	}
+	applyPostmanAuth(result, root, export.Auth)
+	warnScripts(result, root.Collection.Name, export.Event)
+	for _, item := range export.Item {
+		addPostmanItem(result, root, item)
+	}
+	result.Collections = append(result.Collections, root)
+
+	for _, variable := range export.Variable {
+		if variable.Disabled || variable.Key == "" {
+			continue
+		}
+		if result.Variables == nil {
+			result.Variables = make(map[string]string)
+		}
+		result.Variables[variable.Key] = variable.Value
+		if variable.Type == "secret" {
+			result.secret(variable.Key)
+		}
+	}
+	return nil
+}
+
+// addPostmanItem adds a request or a folder to parent
+func addPostmanItem(result *Result, parent *Folder, item postmanItem) {
+	name := truncateName(item.Name)
+	warnScripts(result, name, item.Event)
+
+	if item.Request == nil {
+		folder := &Folder{Collection: http.Collection{Name: name}}
+		if folder.Collection.Name == "" {
+			folder.Collection.Name = "Folder"
+		}
+		applyPostmanAuth(result, folder, item.Auth)
+		for _, inner := range item.Item {
+			addPostmanItem(result, folder, inner)
+		}
+		parent.Folders = append(parent.Folders, folder)
+		return
+	}
+
+	source := item.Request
+	method, ok := supportedMethod(source.Method)
+	if source.Method == "" {
+		method, ok = http.GET, true
+	}
+	if !ok {
+		result.warn("%s: %s requests aren't supported, skipped", name, method)
+		return
+	}
+	request := http.Request{Name: name, Method: method, URL: string(source.URL)}
+	for _, header := range source.Header {
+		if !header.Disabled && header.Key != "" {
+			addHeader(&request, header.Key, header.Value)
+		}
+	}
+
+	if body := source.Body; body != nil {
+		switch body.Mode {
+		case "", "raw":
+			request.Body = body.Raw
+			if contentType := rawContentType(body.Options.Raw.Language); contentType != "" && body.Raw != "" {
+				setDefaultHeader(&request, "Content-Type", contentType)
+			}
+		case "urlencoded":
+			var form []string
+			for _, param := range body.URLEncoded {
+				if !param.Disabled {
+					form = append(form, formEscape(param.Key)+"="+formEscape(param.Value))
+				}
+			}
+			request.Body = strings.Join(form, "&")
+			setDefaultHeader(&request, "Content-Type", "application/x-www-form-urlencoded")
+		case "graphql":
+			if body.GraphQL == nil {
+				break
+			}
+			graphQLBody, err := http.NewGraphQLBody(body.GraphQL.Query, body.GraphQL.Variables, "")
+			if err != nil {
+				result.warn("%s: %v, variables left out", name, err)
+				graphQLBody, _ = http.NewGraphQLBody(body.GraphQL.Query, "", "")
+			}
+			request.Method, request.Body = http.GRAPHQL, graphQLBody
+		default:
+			result.warn("%s: %s bodies aren't supported, body left out", name, body.Mode)
+		}
+	}
+
+	if source.Auth != nil {
+		if key, value, ok := postmanAuthHeader(result, name, source.Auth); ok {
+			addHeader(&request, key, value)
+		}
+	}
+	parent.Requests = append(parent.Requests, request)
+}
+
+// applyPostmanAuth makes the auth of a collection or folder a default of the folder
+func applyPostmanAuth(result *Result, folder *Folder, auth *postmanAuth) {
+	if auth == nil {
+		return
+	}
+	key, value, ok := postmanAuthHeader(result, folder.Collection.Name, auth)
+	if !ok {
+		return
+	}
+	if strings.EqualFold(key, "Authorization") {
+		folder.Collection.Auth = value
+		return
+	}
+	if folder.Collection.Headers == nil {
+		folder.Collection.Headers = make(map[string]string)
+	}
+	folder.Collection.Headers[key] = value
+}
+
+// postmanAuthHeader returns the header an auth sets, warning about the ones
+// that can't be converted
+func postmanAuthHeader(result *Result, name string, auth *postmanAuth) (string, string, bool) {
+	params := auth.params()
+	switch auth.Type {
+	case "noauth", "inherit", "":
+		return "", "", false
+	case "bearer":
+		return "Authorization", "Bearer " + params["token"], true
+	case "basic":
+		return basicAuthHeader(result, name, params["username"], params["password"])
+	case "apikey":
+		if params["in"] == "query" {
+			result.warn("%s: API keys in the query aren't supported, add %s to the URL", name, params["key"])
+			return "", "", false
+		}
+		return params["key"], params["value"], params["key"] != ""
+	}
+	result.warn("%s: %s auth isn't supported, auth left out", name, auth.Type)
+	return "", "", false
+}
+
+// basicAuthHeader encodes a username and password, which can't be variables
+// since they are sent encoded
+func basicAuthHeader(result *Result, name, username, password string) (string, string, bool) {
+	if strings.Contains(username+password, "{{") {
+		result.warn("%s: basic auth with variables isn't supported, auth left out", name)
+		return "", "", false
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return "Authorization", "Basic " + credentials, true
+}
+
+// warnScripts reports the scripts of an item, which aren't run by Volt
+func warnScripts(result *Result, name string, events []postmanEvent) {
+	for _, event := range events {
+		var lines []string
+		if json.Unmarshal(event.Script.Exec, &lines) != nil {
+			var script string
+			json.Unmarshal(event.Script.Exec, &script)
+			lines = []string{script}
+		}
+		if strings.TrimSpace(strings.Join(lines, "")) == "" {
+			continue
+		}
+		kind := "test"
+		if event.Listen == "prerequest" {
+			kind = "pre-request"
+		}
+		result.warn("%s: %s script not imported", name, kind)
+	}
+}
+
+// variableReference matches a {{name}} reference, including Postman's dynamic ones such as {{$guid}}
+var variableReference = regexp.MustCompile(`\{\{[^{}]*\}\}`)
+
+// formEscape escapes a form field, leaving its variable references as they are
+func formEscape(value string) string {
+	var escaped strings.Builder
+	last := 0
+	for _, match := range variableReference.FindAllStringIndex(value, -1) {
+		escaped.WriteString(url.QueryEscape(value[last:match[0]]))
+		escaped.WriteString(value[match[0]:match[1]])
+		last = match[1]
+	}
+	escaped.WriteString(url.QueryEscape(value[last:]))
+	return escaped.String()
+}
+
+// rawContentType is the content type of a raw body in the given language
+func rawContentType(language string) string {
+	switch language {
+	case "json":
+		return "application/json"
+	case "xml":
+		return "application/xml"
+	case "html":
+		return "text/html"
+	case "javascript":
+		return "application/javascript"
+	}
+	return ""
+}
+
+// setDefaultHeader sets a header unless the request has it, whatever its case
+func setDefaultHeader(request *http.Request, key, value string) {
+	for existing := range request.Headers {
+		if strings.EqualFold(existing, key) {
+			return
+		}
+	}
+	addHeader(request, key, value)
+}
+
+// parsePostmanEnvironment reads the variables of a Postman environment,
+// which go to the environment imported into whatever its name
+func parsePostmanEnvironment(data []byte, result *Result) error {
+	var export struct {
+		Values []postmanVariable `json:"values"`
+	}
+	if err := json.Unmarshal(data, &export); err != nil {
+		return err
+	}
+
+	result.Variables = make(map[string]string)
+	for _, value := range export.Values {
+		if value.Key == "" || value.Disabled || (value.Enabled != nil && !*value.Enabled) {
+			continue
+		}
+		result.Variables[value.Key] = value.Value
+		if value.Type == "secret" {
+			result.secret(value.Key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Environment variables used to configure the vault key, in order of precedence
+const (
+	EnvKey        = "VOLT_SECRET_KEY"        // base64 or hex encoded 32 byte key
+	EnvKeyFile    = "VOLT_SECRET_KEY_FILE"   // path to a file holding the key
+	EnvPassphrase = "VOLT_SECRET_PASSPHRASE" // passphrase the key is derived from
+)
+
+// SaltFunc returns the persistent salt used to derive a key from a passphrase
+type SaltFunc func() ([]byte, error)
+
+// LoadVaultFromEnv builds a vault from the environment. It returns a nil
+// vault and no error when no key source is configured.
+func LoadVaultFromEnv(salt SaltFunc) (*Vault, error) {
+	if encoded := os.Getenv(EnvKey); encoded != "" {
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", EnvKey, err)
+		}
+		return NewVault(key)
+	}
+
+	if path := os.Getenv(EnvKeyFile); path != "" {
+		key, err := ReadKeyFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", EnvKeyFile, err)
+		}
+		return NewVault(key)
+	}
+
+	if passphrase := os.Getenv(EnvPassphrase); passphrase != "" {
+		s, err := salt()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load salt: %w", err)
+		}
+		key, err := DeriveKey(passphrase, s)
+		if err != nil {
+			return nil, err
+		}
+		return NewVault(key)
+	}
+
+	return nil, nil
+}
+
+// ReadKeyFile reads a key file holding either 32 raw bytes or an encoded key
+func ReadKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == KeySize {
+		return data, nil
+	}
+	return decodeKey(string(data))
+}
+
+// decodeKey accepts a base64 or hex encoded 32 byte key
+func decodeKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+
+	if key, err := hex.DecodeString(encoded); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if key, err := enc.DecodeString(encoded); err == nil && len(key) == KeySize {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("key must be %d bytes encoded as base64 or hex", KeySize)
+}
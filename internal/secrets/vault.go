@@ -0,0 +1,129 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// KeySize is the length in bytes of a vault key (AES-256)
+	KeySize = 32
+
+	// SaltSize is the length in bytes of the salt used for passphrase derivation
+	SaltSize = 16
+
+	// Mask is displayed in place of secret values
+	Mask = "••••••••"
+
+	// sealedPrefix marks a value that was encrypted by a Vault
+	sealedPrefix = "enc:v1:"
+
+	// pbkdf2Iterations follows the OWASP recommendation for PBKDF2-HMAC-SHA256
+	pbkdf2Iterations = 600_000
+)
+
+var (
+	// ErrNoKey is returned when a secret has to be sealed or opened without a key
+	ErrNoKey = errors.New("no secret key configured (set VOLT_SECRET_KEY, VOLT_SECRET_KEY_FILE or VOLT_SECRET_PASSPHRASE)")
+
+	// ErrWrongKey is returned when a sealed value can't be decrypted with the vault key
+	ErrWrongKey = errors.New("secret key does not match the one used to encrypt this value")
+)
+
+// Vault encrypts and decrypts secret values with AES-256-GCM
+type Vault struct {
+	aead cipher.AEAD
+}
+
+// NewVault creates a vault from a raw 32 byte key
+func NewVault(key []byte) (*Vault, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("secret key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Vault{aead: aead}, nil
+}
+
+// DeriveKey derives a vault key from a passphrase and salt using PBKDF2
+func DeriveKey(passphrase string, salt []byte) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase is empty")
+	}
+	if len(salt) < SaltSize {
+		return nil, fmt.Errorf("salt must be at least %d bytes", SaltSize)
+	}
+	return pbkdf2.Key(sha256.New, passphrase, salt, pbkdf2Iterations, KeySize)
+}
+
+// NewSalt returns a random salt for passphrase derivation
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// IsSealed reports whether value was produced by Vault.Seal
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
+
+// Seal encrypts plaintext. Already sealed values are returned unchanged.
+func (v *Vault) Seal(plaintext string) (string, error) {
+	if v == nil {
+		return "", ErrNoKey
+	}
+	if IsSealed(plaintext) {
+		return plaintext, nil
+	}
+
+	nonce := make([]byte, v.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := v.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return sealedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a sealed value. Values that aren't sealed are returned unchanged.
+func (v *Vault) Open(value string) (string, error) {
+	if !IsSealed(value) {
+		return value, nil
+	}
+	if v == nil {
+		return "", ErrNoKey
+	}
+
+	data, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, sealedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("malformed secret: %w", err)
+	}
+
+	nonceSize := v.aead.NonceSize()
+	if len(data) < nonceSize {
+		return "", errors.New("malformed secret: too short")
+	}
+
+	plaintext, err := v.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return "", ErrWrongKey
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,126 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testVault(t *testing.T) *Vault {
+	t.Helper()
+	v, err := NewVault(bytes.Repeat([]byte{7}, KeySize))
+	if err != nil {
+		t.Fatalf("failed to create vault: %v", err)
+	}
+	return v
+}
+
+func TestVault_SealOpen(t *testing.T) {
+	v := testVault(t)
+
+	sealed, err := v.Seal("Bearer abc123")
+	assert.NoError(t, err)
+	assert.True(t, IsSealed(sealed))
+	assert.NotContains(t, sealed, "abc123")
+
+	again, err := v.Seal(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, sealed, again, "sealing twice should be a no-op")
+
+	opened, err := v.Open(sealed)
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer abc123", opened)
+}
+
+func TestVault_OpenPlaintext(t *testing.T) {
+	var v *Vault
+
+	opened, err := v.Open("plain")
+	assert.NoError(t, err)
+	assert.Equal(t, "plain", opened)
+}
+
+func TestVault_Errors(t *testing.T) {
+	v := testVault(t)
+	sealed, err := v.Seal("secret")
+	assert.NoError(t, err)
+
+	other, err := NewVault(bytes.Repeat([]byte{9}, KeySize))
+	assert.NoError(t, err)
+
+	_, err = other.Open(sealed)
+	assert.ErrorIs(t, err, ErrWrongKey)
+
+	var nilVault *Vault
+	_, err = nilVault.Open(sealed)
+	assert.ErrorIs(t, err, ErrNoKey)
+
+	_, err = nilVault.Seal("secret")
+	assert.ErrorIs(t, err, ErrNoKey)
+
+	_, err = NewVault([]byte("short"))
+	assert.Error(t, err)
+}
+
+func TestDeriveKey(t *testing.T) {
+	salt := bytes.Repeat([]byte{1}, SaltSize)
+
+	a, err := DeriveKey("hunter2", salt)
+	assert.NoError(t, err)
+	b, err := DeriveKey("hunter2", salt)
+	assert.NoError(t, err)
+	c, err := DeriveKey("hunter3", salt)
+	assert.NoError(t, err)
+
+	assert.Len(t, a, KeySize)
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+
+	_, err = DeriveKey("", salt)
+	assert.Error(t, err)
+}
+
+func TestLoadVaultFromEnv(t *testing.T) {
+	key := bytes.Repeat([]byte{3}, KeySize)
+	noSalt := func() ([]byte, error) { return bytes.Repeat([]byte{2}, SaltSize), nil }
+
+	t.Run("no key configured", func(t *testing.T) {
+		t.Setenv(EnvKey, "")
+		t.Setenv(EnvKeyFile, "")
+		t.Setenv(EnvPassphrase, "")
+
+		v, err := LoadVaultFromEnv(noSalt)
+		assert.NoError(t, err)
+		assert.Nil(t, v)
+	})
+
+	t.Run("base64 key", func(t *testing.T) {
+		t.Setenv(EnvKey, base64.StdEncoding.EncodeToString(key))
+
+		v, err := LoadVaultFromEnv(noSalt)
+		assert.NoError(t, err)
+		assert.NotNil(t, v)
+	})
+
+	t.Run("key file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "volt.key")
+		assert.NoError(t, os.WriteFile(path, key, 0600))
+		t.Setenv(EnvKey, "")
+		t.Setenv(EnvKeyFile, path)
+
+		v, err := LoadVaultFromEnv(noSalt)
+		assert.NoError(t, err)
+		assert.NotNil(t, v)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		t.Setenv(EnvKey, "not-a-key")
+
+		_, err := LoadVaultFromEnv(noSalt)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/secrets"
+)
+
+// nullID stores 0, meaning no collection, as NULL
+func nullID(id int64) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// sealAuth encrypts the Authorization default of a collection
+func (s *SQLiteStorage) sealAuth(auth string) (string, error) {
+	return sealAuth(s.vault, auth)
+}
+
+// sealAuth encrypts the Authorization default of a collection with vault
+func sealAuth(vault *secrets.Vault, auth string) (string, error) {
+	if auth == "" || secrets.IsSealed(auth) {
+		return auth, nil
+	}
+	sealed, err := vault.Seal(auth)
+	if err != nil {
+		return "", fmt.Errorf("auth: %w", err)
+	}
+	return sealed, nil
+}
+
+// collectionExists returns an error unless the collection exists
+func (s *SQLiteStorage) collectionExists(id int64) error {
+	var found int64
+	err := s.db.QueryRow(`SELECT id FROM collections WHERE id = ?`, id).Scan(&found)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("collection not found: %d", id)
+	}
+	return err
+}
+
+// CreateCollection saves a new collection, or a folder when it has a parent
+func (s *SQLiteStorage) CreateCollection(collection *http.Collection) error {
+	collection.Name = strings.TrimSpace(collection.Name)
+	if err := collection.Validate(); err != nil {
+		return err
+	}
+	if collection.ParentID != 0 {
+		if err := s.collectionExists(collection.ParentID); err != nil {
+			return err
+		}
+	}
+	auth, err := s.sealAuth(collection.Auth)
+	if err != nil {
+		return err
+	}
+	headerString, err := serializeOptions(collection.Headers)
+	if err != nil {
+		return err
+	}
+
+	q := `INSERT INTO collections (parent_id, name, base_url, headers, auth) VALUES (?, ?, ?, ?, ?)`
+	res, err := s.db.Exec(q, nullID(collection.ParentID), collection.Name, collection.BaseURL, headerString, auth)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	collection.ID = id
+	collection.Auth = auth
+	return nil
+}
+
+// LoadCollections returns every collection and folder, sorted by name
+func (s *SQLiteStorage) LoadCollections() ([]http.Collection, error) {
+	q := `SELECT id, parent_id, name, base_url, headers, auth FROM collections ORDER BY name COLLATE NOCASE, id`
+	rows, err := s.db.Query(q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var collections []http.Collection
+	for rows.Next() {
+		var (
+			collection http.Collection
+			parentID   sql.NullInt64
+			headers    string
+		)
+		if err := rows.Scan(&collection.ID, &parentID, &collection.Name, &collection.BaseURL, &headers, &collection.Auth); err != nil {
+			return nil, err
+		}
+		collection.ParentID = parentID.Int64
+		if collection.Headers, err = deserializeOptions(headers); err != nil {
+			return nil, err
+		}
+		collections = append(collections, collection)
+	}
+	return collections, rows.Err()
+}
+
+// RenameCollection changes the name of a collection
+func (s *SQLiteStorage) RenameCollection(id int64, name string) error {
+	collection := http.Collection{ID: id, Name: strings.TrimSpace(name)}
+	if err := collection.Validate(); err != nil {
+		return err
+	}
+	return s.updateCollection(`UPDATE collections SET name = ? WHERE id = ?`, collection.Name, id)
+}
+
+// SetCollectionDefaults replaces the base URL, headers and auth inherited by
+// the requests inside a collection
+func (s *SQLiteStorage) SetCollectionDefaults(collection *http.Collection) error {
+	if err := collection.Validate(); err != nil {
+		return err
+	}
+	auth, err := s.sealAuth(collection.Auth)
+	if err != nil {
+		return err
+	}
+	headerString, err := serializeOptions(collection.Headers)
+	if err != nil {
+		return err
+	}
+	if err := s.updateCollection(`UPDATE collections SET base_url = ?, headers = ?, auth = ? WHERE id = ?`,
+		collection.BaseURL, headerString, auth, collection.ID); err != nil {
+		return err
+	}
+	collection.Auth = auth
+	return nil
+}
+
+// MoveCollection moves a collection into another one, or to the top level
+// when parentID is 0. A collection can't be moved into one of its own folders.
+func (s *SQLiteStorage) MoveCollection(id, parentID int64) error {
+	for ancestor := parentID; ancestor != 0; {
+		if ancestor == id {
+			return fmt.Errorf("a collection can't be moved into itself")
+		}
+		var next sql.NullInt64
+		err := s.db.QueryRow(`SELECT parent_id FROM collections WHERE id = ?`, ancestor).Scan(&next)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("collection not found: %d", ancestor)
+		}
+		if err != nil {
+			return err
+		}
+		ancestor = next.Int64
+	}
+	return s.updateCollection(`UPDATE collections SET parent_id = ? WHERE id = ?`, nullID(parentID), id)
+}
+
+// DeleteCollection deletes a collection along with its folders and every
+// request inside them
+func (s *SQLiteStorage) DeleteCollection(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	tree := `WITH RECURSIVE tree (id) AS (
+			SELECT id FROM collections WHERE id = ?
+			UNION ALL
+			SELECT collections.id FROM collections JOIN tree ON collections.parent_id = tree.id
+		)`
+	loadTests := ` DELETE FROM load_tests WHERE request IN (
+			SELECT CAST(id AS TEXT) FROM requests WHERE collection_id IN (SELECT id FROM tree))`
+	if _, err := tx.Exec(tree+loadTests, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(tree+` DELETE FROM requests WHERE collection_id IN (SELECT id FROM tree)`, id); err != nil {
+		return err
+	}
+	res, err := tx.Exec(tree+` DELETE FROM collections WHERE id IN (SELECT id FROM tree)`, id)
+	if err != nil {
+		return err
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return err
+	} else if rows == 0 {
+		return fmt.Errorf("collection not found: %d", id)
+	}
+	return tx.Commit()
+}
+
+// MoveRequest moves a saved request into a collection, or out of any when collectionID is 0
+func (s *SQLiteStorage) MoveRequest(id, collectionID int64) error {
+	if collectionID != 0 {
+		if err := s.collectionExists(collectionID); err != nil {
+			return err
+		}
+	}
+	res, err := s.db.Exec(`UPDATE requests SET collection_id = ? WHERE id = ?`, nullID(collectionID), id)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("request not found: %d", id)
+	}
+	return nil
+}
+
+// updateCollection runs an update of one collection, the last argument being its ID
+func (s *SQLiteStorage) updateCollection(q string, args ...any) error {
+	res, err := s.db.Exec(q, args...)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("collection not found: %d", args[len(args)-1])
+	}
+	return nil
+}
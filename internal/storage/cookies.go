@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/owenHochwald/volt/internal/http"
+)
+
+// SaveCookies replaces the stored cookies of an environment. Values are
+// encrypted when a secret key is configured.
+func (s *SQLiteStorage) SaveCookies(environment string, cookies []http.Cookie) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM cookies WHERE environment = ?`, environment); err != nil {
+		return err
+	}
+
+	q := `INSERT INTO cookies (environment, domain, path, name, value, expires, secure, http_only, host_only, same_site)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	for _, c := range cookies {
+		value := c.Value
+		if s.vault != nil {
+			if value, err = s.vault.Seal(c.Value); err != nil {
+				return err
+			}
+		}
+
+		var expires sql.NullString
+		if !c.Expires.IsZero() {
+			expires = sql.NullString{String: c.Expires.UTC().Format(time.RFC3339), Valid: true}
+		}
+
+		if _, err := tx.Exec(q, environment, c.Domain, c.Path, c.Name, value, expires,
+			c.Secure, c.HttpOnly, c.HostOnly, c.SameSite); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LoadCookies returns the unexpired cookies of an environment
+func (s *SQLiteStorage) LoadCookies(environment string) ([]http.Cookie, error) {
+	q := `SELECT domain, path, name, value, expires, secure, http_only, host_only, same_site
+		FROM cookies WHERE environment = ? ORDER BY domain, path, name`
+	rows, err := s.db.Query(q, environment)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	var cookies []http.Cookie
+	for rows.Next() {
+		var (
+			c        http.Cookie
+			expires  sql.NullString
+			sameSite sql.NullString
+		)
+		if err := rows.Scan(&c.Domain, &c.Path, &c.Name, &c.Value, &expires,
+			&c.Secure, &c.HttpOnly, &c.HostOnly, &sameSite); err != nil {
+			return nil, err
+		}
+
+		if expires.Valid {
+			if c.Expires, err = time.Parse(time.RFC3339, expires.String); err != nil {
+				return nil, err
+			}
+		}
+		if c.Expired(now) {
+			continue
+		}
+		c.SameSite = sameSite.String
+
+		// Cookies sealed with a key we no longer have can't be used, so drop them
+		if c.Value, err = s.vault.Open(c.Value); err != nil {
+			continue
+		}
+		cookies = append(cookies, c)
+	}
+	return cookies, rows.Err()
+}
+
+// ClearCookies removes every stored cookie of an environment
+func (s *SQLiteStorage) ClearCookies(environment string) error {
+	_, err := s.db.Exec(`DELETE FROM cookies WHERE environment = ?`, environment)
+	return err
+}
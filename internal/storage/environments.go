@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"database/sql"
+)
+
+// DefaultEnvironment is used when no environment is selected
+const DefaultEnvironment = "default"
+
+// LoadEnvironmentOptions returns the options of an environment, which requests
+// inherit unless they override them
+func (s *SQLiteStorage) LoadEnvironmentOptions(environment string) (map[string]string, error) {
+	var optionList string
+	err := s.db.QueryRow(`SELECT options FROM environments WHERE name = ?`, environment).Scan(&optionList)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return deserializeOptions(optionList)
+}
+
+// SaveEnvironmentOptions replaces the options of an environment, creating it if needed
+func (s *SQLiteStorage) SaveEnvironmentOptions(environment string, options map[string]string) error {
+	optionString, err := serializeOptions(options)
+	if err != nil {
+		return err
+	}
+	q := `INSERT INTO environments (name, options) VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET options = excluded.options`
+	_, err = s.db.Exec(q, environment, optionString)
+	return err
+}
+
+// LoadEnvironmentVariables returns the variables of an environment, which
+// requests reference as {{name}}
+func (s *SQLiteStorage) LoadEnvironmentVariables(environment string) (map[string]string, error) {
+	var variableList string
+	err := s.db.QueryRow(`SELECT variables FROM environments WHERE name = ?`, environment).Scan(&variableList)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return deserializeOptions(variableList)
+}
+
+// SaveEnvironmentVariables replaces the variables of an environment, creating it if needed
+func (s *SQLiteStorage) SaveEnvironmentVariables(environment string, variables map[string]string) error {
+	variableString, err := serializeOptions(variables)
+	if err != nil {
+		return err
+	}
+	q := `INSERT INTO environments (name, variables) VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET variables = excluded.variables`
+	_, err = s.db.Exec(q, environment, variableString)
+	return err
+}
+
+// SealSecret encrypts a secret value, such as a variable, to be saved where
+// plaintext would be. It fails with secrets.ErrNoKey when no key is configured.
+func (s *SQLiteStorage) SealSecret(value string) (string, error) {
+	return s.vault.Seal(value)
+}
+
+// ListEnvironments returns the names of all configured environments
+func (s *SQLiteStorage) ListEnvironments() ([]string, error) {
+	rows, err := s.db.Query(`SELECT name FROM environments ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
@@ -0,0 +1,1003 @@
+package storage
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/secrets"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// collectionFile holds the name and defaults of the collection of its directory
+	collectionFile = "_collection.yaml"
+
+	// environmentsDir holds a file of options and variables per environment
+	environmentsDir = "environments"
+
+	// localDir holds what isn't shared: history, cookies and secret values
+	localDir = ".local"
+
+	// pollInterval is how often a watched workspace is checked for changes
+	pollInterval = time.Second
+)
+
+// unsafeFileChars are replaced in the names of files and directories
+var unsafeFileChars = strings.NewReplacer("/", "-", `\`, "-", ":", "-", "*", "-", "?", "-", `"`, "-", "<", "-", ">", "-", "|", "-", "#", "-")
+
+// FileStorage keeps requests, collections and environments in a directory
+// of YAML files, so that they can be reviewed and shared with git:
+//
+//	api/
+//	  Health.yaml            a request outside any collection
+//	  Users/
+//	    _collection.yaml     the name and defaults of the collection
+//	    List users.yaml
+//	  environments/
+//	    staging.yaml         options and variables
+//	  .local/                history, cookies and secret values, ignored by git
+//
+// Secret header values and collection auth aren't written to the files but
+// kept encrypted in .local. IDs are given to files and directories as they
+// are found, and last as long as the FileStorage.
+type FileStorage struct {
+	root  string
+	local *SQLiteStorage
+
+	mu      sync.Mutex
+	entries map[string]*fileEntry // by path relative to root, with slashes
+	nextID  int64
+
+	// fingerprint of the files as last written or seen, to tell changes made by others
+	fingerprint uint64
+
+	watch   sync.Once
+	changes chan struct{}
+	done    chan struct{}
+}
+
+// fileEntry is a file or directory found in the workspace
+type fileEntry struct {
+	id      int64
+	version int64
+	hash    uint64
+}
+
+// requestFile is the content of a request file
+type requestFile struct {
+	Name    string            `yaml:"name"`
+	Method  string            `yaml:"method"`
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Secrets []string          `yaml:"secrets,omitempty"` // headers whose values are kept in .local
+	Body    string            `yaml:"body,omitempty"`
+	Options map[string]string `yaml:"options,omitempty"`
+}
+
+// collectionDefaults is the content of a _collection.yaml file
+type collectionDefaults struct {
+	Name    string            `yaml:"name"`
+	BaseURL string            `yaml:"base_url,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+}
+
+// environmentFile is the content of a file in environments
+type environmentFile struct {
+	Options   map[string]string `yaml:"options,omitempty"`
+	Variables map[string]string `yaml:"variables,omitempty"`
+}
+
+// workspace is what a scan of the files found
+type workspace struct {
+	requests        []http.Request
+	requestPaths    map[int64]string
+	collections     []http.Collection
+	collectionPaths map[int64]string
+}
+
+// NewFileStorage opens the workspace in dir, creating it if needed
+func NewFileStorage(dir string) (*FileStorage, error) {
+	local := filepath.Join(dir, localDir)
+	if err := os.MkdirAll(local, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	ignore := filepath.Join(local, ".gitignore")
+	if _, err := os.Stat(ignore); errors.Is(err, fs.ErrNotExist) {
+		if err := os.WriteFile(ignore, []byte("*\n"), 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := NewSQLiteStorage(filepath.Join(local, "volt.db"))
+	if err != nil {
+		return nil, err
+	}
+	s := &FileStorage{root: dir, local: db, entries: make(map[string]*fileEntry)}
+	s.fingerprint = s.fingerprintFiles()
+	return s, nil
+}
+
+// Root returns the directory of the workspace
+func (s *FileStorage) Root() string {
+	return s.root
+}
+
+func (s *FileStorage) Close() error {
+	if s.done != nil {
+		close(s.done)
+	}
+	return s.local.Close()
+}
+
+// Changes returns a channel that receives when files of the workspace are
+// changed by something else than the FileStorage, e.g. a git checkout
+func (s *FileStorage) Changes() <-chan struct{} {
+	s.watch.Do(func() {
+		s.changes = make(chan struct{}, 1)
+		s.done = make(chan struct{})
+		go s.poll()
+	})
+	return s.changes
+}
+
+// poll compares the files to the last fingerprint until the storage is closed
+func (s *FileStorage) poll() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		fingerprint := s.fingerprintFiles()
+		s.mu.Lock()
+		changed := fingerprint != s.fingerprint
+		s.fingerprint = fingerprint
+		s.mu.Unlock()
+		if changed {
+			select {
+			case s.changes <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// fingerprintFiles hashes the names, sizes and modification times of the
+// shared files
+func (s *FileStorage) fingerprintFiles() uint64 {
+	hash := fnv.New64a()
+	filepath.WalkDir(s.root, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() && entry.Name() == localDir {
+			return filepath.SkipDir
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		fmt.Fprintf(hash, "%s %d %d\n", file, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	return hash.Sum64()
+}
+
+// written records that the FileStorage changed the files itself
+func (s *FileStorage) written() {
+	s.fingerprint = s.fingerprintFiles()
+}
+
+func (s *FileStorage) abs(rel string) string {
+	return filepath.Join(s.root, filepath.FromSlash(rel))
+}
+
+// entry returns the entry of a path, giving it an ID when it is new. The
+// version goes up when the content hashes differently than last time.
+func (s *FileStorage) entry(rel string, data []byte) *fileEntry {
+	hash := fnv.New64a()
+	hash.Write(data)
+	sum := hash.Sum64()
+
+	entry, ok := s.entries[rel]
+	if !ok {
+		s.nextID++
+		entry = &fileEntry{id: s.nextID, version: 1, hash: sum}
+		s.entries[rel] = entry
+	} else if entry.hash != sum {
+		entry.version++
+		entry.hash = sum
+	}
+	return entry
+}
+
+// rename moves the entries of a file or directory, and those inside, to a new path
+func (s *FileStorage) rename(oldPath, newPath string) error {
+	if err := os.Rename(s.abs(oldPath), s.abs(newPath)); err != nil {
+		return err
+	}
+	for rel, entry := range s.entries {
+		if rel == oldPath || strings.HasPrefix(rel, oldPath+"/") {
+			delete(s.entries, rel)
+			s.entries[newPath+strings.TrimPrefix(rel, oldPath)] = entry
+		}
+	}
+	if err := s.local.moveWorkspaceSecrets(oldPath+"/", newPath+"/"); err != nil {
+		return err
+	}
+	if err := s.local.moveWorkspaceSecrets(oldPath+"#", newPath+"#"); err != nil {
+		return err
+	}
+	return s.local.moveLoadTests(oldPath, newPath)
+}
+
+// scan reads every collection and request of the workspace
+func (s *FileStorage) scan() (*workspace, error) {
+	w := &workspace{requestPaths: make(map[int64]string), collectionPaths: make(map[int64]string)}
+	seen := make(map[string]bool)
+	if err := s.scanDir("", 0, w, seen); err != nil {
+		return nil, err
+	}
+	for rel := range s.entries {
+		if !seen[rel] {
+			delete(s.entries, rel)
+		}
+	}
+
+	slices.SortFunc(w.requests, func(a, b http.Request) int { return cmp.Compare(a.ID, b.ID) })
+	slices.SortFunc(w.collections, func(a, b http.Collection) int {
+		return cmp.Or(cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)), cmp.Compare(a.ID, b.ID))
+	})
+	return w, nil
+}
+
+// scanDir reads the collections and requests in a directory, whose collection is parentID
+func (s *FileStorage) scanDir(dir string, parentID int64, w *workspace, seen map[string]bool) error {
+	items, err := os.ReadDir(s.abs(dir))
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		name := item.Name()
+		rel := path.Join(dir, name)
+		if strings.HasPrefix(name, ".") || (dir == "" && name == environmentsDir) {
+			continue
+		}
+
+		if item.IsDir() {
+			defaults, err := s.readCollection(rel)
+			if err != nil {
+				return err
+			}
+			seen[rel] = true
+			collection := http.Collection{
+				ID:       s.entry(rel, nil).id,
+				ParentID: parentID,
+				Name:     defaults.Name,
+				BaseURL:  defaults.BaseURL,
+				Headers:  defaults.Headers,
+			}
+			auth, _, err := s.local.workspaceSecret(rel + "/#auth")
+			if err != nil {
+				return err
+			}
+			collection.Auth = auth
+			w.collections = append(w.collections, collection)
+			w.collectionPaths[collection.ID] = rel
+			if err := s.scanDir(rel, collection.ID, w, seen); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if name == collectionFile || path.Ext(name) != ".yaml" {
+			continue
+		}
+		request, err := s.readRequest(rel)
+		if err != nil {
+			return err
+		}
+		seen[rel] = true
+		request.CollectionID = parentID
+		w.requests = append(w.requests, *request)
+		w.requestPaths[request.ID] = rel
+	}
+	return nil
+}
+
+// readCollection reads the _collection.yaml file of a directory, which is
+// named after the directory when it has none
+func (s *FileStorage) readCollection(dir string) (*collectionDefaults, error) {
+	defaults := &collectionDefaults{}
+	data, err := os.ReadFile(s.abs(path.Join(dir, collectionFile)))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, defaults); err != nil {
+		return nil, fmt.Errorf("%s: %w", path.Join(dir, collectionFile), err)
+	}
+	defaults.Name = cmp.Or(strings.TrimSpace(defaults.Name), path.Base(dir))
+	if len(defaults.Headers) == 0 {
+		defaults.Headers = nil
+	}
+	return defaults, nil
+}
+
+// readRequest reads a request file, with its secret headers from .local
+func (s *FileStorage) readRequest(rel string) (*http.Request, error) {
+	file := s.abs(rel)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	var content requestFile
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("%s: %w", rel, err)
+	}
+
+	entry := s.entry(rel, data)
+	request := &http.Request{
+		ID:        entry.id,
+		Name:      cmp.Or(content.Name, strings.TrimSuffix(path.Base(rel), ".yaml")),
+		Method:    cmp.Or(strings.ToUpper(content.Method), http.GET),
+		URL:       content.URL,
+		Headers:   content.Headers,
+		Body:      content.Body,
+		Options:   content.Options,
+		Version:   entry.version,
+		UpdatedAt: info.ModTime().UTC(),
+	}
+	if request.Headers == nil {
+		request.Headers = make(map[string]string)
+	}
+	if len(content.Secrets) > 0 {
+		request.Secrets = content.Secrets
+	}
+	if len(request.Options) == 0 {
+		request.Options = nil
+	}
+	for _, key := range request.Secrets {
+		value, ok, err := s.local.workspaceSecret(rel + "#" + key)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			request.Headers[key] = value
+		}
+	}
+	return request, nil
+}
+
+// writeRequest writes a request file, keeping its secret header values in .local
+func (s *FileStorage) writeRequest(rel string, request *http.Request) error {
+	content := requestFile{
+		Name:    request.Name,
+		Method:  request.Method,
+		URL:     request.URL,
+		Headers: make(map[string]string),
+		Secrets: request.Secrets,
+		Body:    request.Body,
+		Options: request.Options,
+	}
+	if err := s.local.deleteWorkspaceSecrets(rel + "#"); err != nil {
+		return err
+	}
+	for key, value := range request.Headers {
+		if !request.IsSecret(key) {
+			content.Headers[key] = value
+			continue
+		}
+		if err := s.local.setWorkspaceSecret(rel+"#"+key, value); err != nil {
+			return err
+		}
+	}
+
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.abs(rel), data, 0644); err != nil {
+		return err
+	}
+	s.entry(rel, data)
+	return nil
+}
+
+// writeCollection writes the _collection.yaml file of a directory, keeping its auth in .local
+func (s *FileStorage) writeCollection(dir string, collection *http.Collection) error {
+	defaults := collectionDefaults{Name: collection.Name, BaseURL: collection.BaseURL, Headers: collection.Headers}
+	data, err := yaml.Marshal(defaults)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.abs(path.Join(dir, collectionFile)), data, 0644); err != nil {
+		return err
+	}
+	if collection.Auth == "" {
+		return s.local.deleteWorkspaceSecrets(dir + "/#auth")
+	}
+	return s.local.setWorkspaceSecret(dir+"/#auth", collection.Auth)
+}
+
+// fileName returns a name that can be used for a file or directory
+func fileName(name string) string {
+	return cmp.Or(strings.Trim(unsafeFileChars.Replace(strings.TrimSpace(name)), " ."), "untitled")
+}
+
+// freePath returns a path in dir for a file or directory called name, with a
+// number after the name when it is taken. current is the path of the file
+// being renamed, which counts as free.
+func (s *FileStorage) freePath(dir, name, ext, current string) string {
+	for i := 1; ; i++ {
+		candidate := name
+		if i > 1 {
+			candidate = fmt.Sprintf("%s %d", name, i)
+		}
+		rel := path.Join(dir, candidate+ext)
+		if strings.EqualFold(rel, current) {
+			return current
+		}
+		base := path.Base(rel)
+		if base == collectionFile || (dir == "" && strings.EqualFold(base, environmentsDir)) {
+			continue
+		}
+		if _, err := os.Stat(s.abs(rel)); errors.Is(err, fs.ErrNotExist) {
+			return rel
+		}
+	}
+}
+
+// Save writes a new request file, or the file of the request with the same
+// ID. An update fails with ErrConflict when the file was changed since the
+// request was loaded, unless its Version is 0.
+func (s *FileStorage) Save(request *http.Request) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.written()
+
+	w, err := s.scan()
+	if err != nil {
+		return err
+	}
+	if err := s.local.sealHeaders(request); err != nil {
+		return err
+	}
+
+	if request.ID == 0 {
+		dir := ""
+		if request.CollectionID != 0 {
+			var ok bool
+			if dir, ok = w.collectionPaths[request.CollectionID]; !ok {
+				return fmt.Errorf("collection not found: %d", request.CollectionID)
+			}
+		}
+		rel := s.freePath(dir, fileName(request.Name), ".yaml", "")
+		if err := s.writeRequest(rel, request); err != nil {
+			return err
+		}
+		entry := s.entries[rel]
+		request.ID, request.Version, request.UpdatedAt = entry.id, entry.version, time.Now().UTC()
+		return nil
+	}
+
+	rel, ok := w.requestPaths[request.ID]
+	if !ok {
+		return fmt.Errorf("request not found: %d", request.ID)
+	}
+	entry := s.entries[rel]
+	if request.Version != 0 && request.Version != entry.version {
+		return ErrConflict
+	}
+	// the file follows the name of the request
+	if renamed := s.freePath(path.Dir(rel), fileName(request.Name), ".yaml", rel); renamed != rel {
+		if err := s.rename(rel, renamed); err != nil {
+			return err
+		}
+		rel = renamed
+	}
+	// every save moves the version on, like in SQLite, even when the file stays the same
+	saved := entry.version
+	if err := s.writeRequest(rel, request); err != nil {
+		return err
+	}
+	if entry.version == saved {
+		entry.version++
+	}
+	request.Version, request.UpdatedAt = entry.version, time.Now().UTC()
+	return nil
+}
+
+// SaveAs saves the request as a new file, leaving the one it was loaded from as it was
+func (s *FileStorage) SaveAs(request *http.Request) error {
+	request.ID, request.Version = 0, 0
+	return s.Save(request)
+}
+
+func (s *FileStorage) Load() ([]http.Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, err := s.scan()
+	if err != nil {
+		return nil, err
+	}
+	return w.requests, nil
+}
+
+func (s *FileStorage) Delete(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.written()
+
+	w, err := s.scan()
+	if err != nil {
+		return err
+	}
+	rel, ok := w.requestPaths[id]
+	if !ok {
+		return fmt.Errorf("request not found: %d", id)
+	}
+	if err := os.Remove(s.abs(rel)); err != nil {
+		return err
+	}
+	delete(s.entries, rel)
+	if err := s.local.deleteWorkspaceSecrets(rel + "#"); err != nil {
+		return err
+	}
+	return s.local.deleteLoadTests(rel)
+}
+
+func (s *FileStorage) GetAllURLs() ([]string, error) {
+	requests, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	var urls []string
+	for _, request := range requests {
+		if !slices.Contains(urls, request.URL) {
+			urls = append(urls, request.URL)
+		}
+	}
+	return urls, nil
+}
+
+// CreateCollection creates the directory of a new collection, or of a folder when it has a parent
+func (s *FileStorage) CreateCollection(collection *http.Collection) error {
+	collection.Name = strings.TrimSpace(collection.Name)
+	if err := collection.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.written()
+
+	w, err := s.scan()
+	if err != nil {
+		return err
+	}
+	parent := ""
+	if collection.ParentID != 0 {
+		var ok bool
+		if parent, ok = w.collectionPaths[collection.ParentID]; !ok {
+			return fmt.Errorf("collection not found: %d", collection.ParentID)
+		}
+	}
+	auth, err := s.local.sealAuth(collection.Auth)
+	if err != nil {
+		return err
+	}
+	collection.Auth = auth
+
+	dir := s.freePath(parent, fileName(collection.Name), "", "")
+	if err := os.Mkdir(s.abs(dir), 0755); err != nil {
+		return err
+	}
+	if err := s.writeCollection(dir, collection); err != nil {
+		return err
+	}
+	collection.ID = s.entry(dir, nil).id
+	return nil
+}
+
+// LoadCollections returns every collection and folder, sorted by name
+func (s *FileStorage) LoadCollections() ([]http.Collection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, err := s.scan()
+	if err != nil {
+		return nil, err
+	}
+	return w.collections, nil
+}
+
+// collection returns a collection found by a scan and the path of its directory
+func (w *workspace) collection(id int64) (*http.Collection, string, error) {
+	dir, ok := w.collectionPaths[id]
+	if !ok {
+		return nil, "", fmt.Errorf("collection not found: %d", id)
+	}
+	i := slices.IndexFunc(w.collections, func(c http.Collection) bool { return c.ID == id })
+	return &w.collections[i], dir, nil
+}
+
+// RenameCollection changes the name of a collection and of its directory
+func (s *FileStorage) RenameCollection(id int64, name string) error {
+	renamed := http.Collection{ID: id, Name: strings.TrimSpace(name)}
+	if err := renamed.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.written()
+
+	w, err := s.scan()
+	if err != nil {
+		return err
+	}
+	collection, dir, err := w.collection(id)
+	if err != nil {
+		return err
+	}
+	if target := s.freePath(path.Dir(dir), fileName(renamed.Name), "", dir); target != dir {
+		if err := s.rename(dir, target); err != nil {
+			return err
+		}
+		dir = target
+	}
+	collection.Name = renamed.Name
+	return s.writeCollection(dir, collection)
+}
+
+// SetCollectionDefaults replaces the base URL, headers and auth inherited by
+// the requests inside a collection
+func (s *FileStorage) SetCollectionDefaults(collection *http.Collection) error {
+	if err := collection.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.written()
+
+	w, err := s.scan()
+	if err != nil {
+		return err
+	}
+	current, dir, err := w.collection(collection.ID)
+	if err != nil {
+		return err
+	}
+	auth, err := s.local.sealAuth(collection.Auth)
+	if err != nil {
+		return err
+	}
+	collection.Auth = auth
+	updated := *current
+	updated.BaseURL, updated.Headers, updated.Auth = collection.BaseURL, collection.Headers, auth
+	return s.writeCollection(dir, &updated)
+}
+
+// MoveCollection moves the directory of a collection into another one, or to
+// the top level when parentID is 0. A collection can't be moved into one of
+// its own folders.
+func (s *FileStorage) MoveCollection(id, parentID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.written()
+
+	w, err := s.scan()
+	if err != nil {
+		return err
+	}
+	_, dir, err := w.collection(id)
+	if err != nil {
+		return err
+	}
+	parent := ""
+	if parentID != 0 {
+		if _, parent, err = w.collection(parentID); err != nil {
+			return err
+		}
+	}
+	if parent == dir || strings.HasPrefix(parent, dir+"/") {
+		return fmt.Errorf("a collection can't be moved into itself")
+	}
+	if path.Dir(dir) == cmp.Or(parent, ".") {
+		return nil
+	}
+	return s.rename(dir, s.freePath(parent, path.Base(dir), "", ""))
+}
+
+// DeleteCollection deletes the directory of a collection along with its
+// folders and every request inside them
+func (s *FileStorage) DeleteCollection(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.written()
+
+	w, err := s.scan()
+	if err != nil {
+		return err
+	}
+	_, dir, err := w.collection(id)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(s.abs(dir)); err != nil {
+		return err
+	}
+	for rel := range s.entries {
+		if rel == dir || strings.HasPrefix(rel, dir+"/") {
+			delete(s.entries, rel)
+		}
+	}
+	if err := s.local.deleteWorkspaceSecrets(dir + "/"); err != nil {
+		return err
+	}
+	return s.local.deleteLoadTests(dir)
+}
+
+// MoveRequest moves a request file into the directory of a collection, or
+// to the top level when collectionID is 0
+func (s *FileStorage) MoveRequest(id, collectionID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.written()
+
+	w, err := s.scan()
+	if err != nil {
+		return err
+	}
+	rel, ok := w.requestPaths[id]
+	if !ok {
+		return fmt.Errorf("request not found: %d", id)
+	}
+	dir := ""
+	if collectionID != 0 {
+		if _, dir, err = w.collection(collectionID); err != nil {
+			return err
+		}
+	}
+	if path.Dir(rel) == cmp.Or(dir, ".") {
+		return nil
+	}
+	return s.rename(rel, s.freePath(dir, strings.TrimSuffix(path.Base(rel), ".yaml"), ".yaml", ""))
+}
+
+// environmentPath returns the path of the file of an environment
+func environmentPath(environment string) string {
+	return path.Join(environmentsDir, fileName(environment)+".yaml")
+}
+
+// readEnvironment reads the file of an environment, which is empty when there is none
+func (s *FileStorage) readEnvironment(environment string) (*environmentFile, error) {
+	content := &environmentFile{}
+	data, err := os.ReadFile(s.abs(environmentPath(environment)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return content, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, content); err != nil {
+		return nil, fmt.Errorf("%s: %w", environmentPath(environment), err)
+	}
+	return content, nil
+}
+
+// updateEnvironment changes the file of an environment, creating it if needed
+func (s *FileStorage) updateEnvironment(environment string, update func(*environmentFile)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	defer s.written()
+
+	content, err := s.readEnvironment(environment)
+	if err != nil {
+		return err
+	}
+	update(content)
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.abs(environmentsDir), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.abs(environmentPath(environment)), data, 0644)
+}
+
+// LoadEnvironmentOptions returns the options of an environment, which requests
+// inherit unless they override them
+func (s *FileStorage) LoadEnvironmentOptions(environment string) (map[string]string, error) {
+	content, err := s.readEnvironment(environment)
+	if err != nil || len(content.Options) == 0 {
+		return nil, err
+	}
+	return content.Options, nil
+}
+
+// SaveEnvironmentOptions replaces the options of an environment, creating it if needed
+func (s *FileStorage) SaveEnvironmentOptions(environment string, options map[string]string) error {
+	return s.updateEnvironment(environment, func(content *environmentFile) { content.Options = options })
+}
+
+// LoadEnvironmentVariables returns the variables of an environment, which
+// requests reference as {{name}}
+func (s *FileStorage) LoadEnvironmentVariables(environment string) (map[string]string, error) {
+	content, err := s.readEnvironment(environment)
+	if err != nil || len(content.Variables) == 0 {
+		return nil, err
+	}
+	return content.Variables, nil
+}
+
+// SaveEnvironmentVariables replaces the variables of an environment, creating it if needed
+func (s *FileStorage) SaveEnvironmentVariables(environment string, variables map[string]string) error {
+	return s.updateEnvironment(environment, func(content *environmentFile) { content.Variables = variables })
+}
+
+// ListEnvironments returns the names of the environment files
+func (s *FileStorage) ListEnvironments() ([]string, error) {
+	items, err := os.ReadDir(s.abs(environmentsDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, item := range items {
+		if name, ok := strings.CutSuffix(item.Name(), ".yaml"); ok && !item.IsDir() {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// The rest isn't shared, and is kept in .local
+
+func (s *FileStorage) SecretSalt() ([]byte, error) {
+	return s.local.SecretSalt()
+}
+
+func (s *FileStorage) SetVault(vault *secrets.Vault) error {
+	return s.local.SetVault(vault)
+}
+
+func (s *FileStorage) SealSecret(value string) (string, error) {
+	return s.local.SealSecret(value)
+}
+
+func (s *FileStorage) SaveCookies(environment string, cookies []http.Cookie) error {
+	return s.local.SaveCookies(environment, cookies)
+}
+
+func (s *FileStorage) LoadCookies(environment string) ([]http.Cookie, error) {
+	return s.local.LoadCookies(environment)
+}
+
+func (s *FileStorage) ClearCookies(environment string) error {
+	return s.local.ClearCookies(environment)
+}
+
+func (s *FileStorage) SaveGraphQLSchema(url string, schema *http.GraphQLSchema) error {
+	return s.local.SaveGraphQLSchema(url, schema)
+}
+
+func (s *FileStorage) LoadGraphQLSchema(url string) (*http.GraphQLSchema, time.Time, error) {
+	return s.local.LoadGraphQLSchema(url)
+}
+
+func (s *FileStorage) AddHistory(entry *HistoryEntry) error {
+	return s.local.AddHistory(entry)
+}
+
+func (s *FileStorage) LoadHistory(filter HistoryFilter) ([]HistoryEntry, error) {
+	return s.local.LoadHistory(filter)
+}
+
+func (s *FileStorage) GetHistory(id int64) (*HistoryEntry, error) {
+	return s.local.GetHistory(id)
+}
+
+func (s *FileStorage) ClearHistory() error {
+	return s.local.ClearHistory()
+}
+
+func (s *FileStorage) PruneHistory(retention HistoryRetention) error {
+	return s.local.PruneHistory(retention)
+}
+
+func (s *FileStorage) HistoryRetention() (HistoryRetention, error) {
+	return s.local.HistoryRetention()
+}
+
+func (s *FileStorage) SetHistoryRetention(retention HistoryRetention) error {
+	return s.local.SetHistoryRetention(retention)
+}
+
+// AddLoadTest keeps a finished load test of a request file in the local
+// database, under the path of the file
+func (s *FileStorage) AddLoadTest(run *LoadTestRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, err := s.scan()
+	if err != nil {
+		return err
+	}
+	rel, ok := w.requestPaths[run.RequestID]
+	if !ok {
+		return fmt.Errorf("request not found: %d", run.RequestID)
+	}
+	return s.local.addLoadTest(rel, run)
+}
+
+func (s *FileStorage) LoadLoadTests(requestID int64) ([]LoadTestRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, err := s.scan()
+	if err != nil {
+		return nil, err
+	}
+	rel, ok := w.requestPaths[requestID]
+	if !ok {
+		return nil, fmt.Errorf("request not found: %d", requestID)
+	}
+	runs, err := s.local.loadLoadTests(rel)
+	for i := range runs {
+		runs[i].RequestID = requestID
+	}
+	return runs, err
+}
+
+func (s *FileStorage) GetLoadTest(id int64) (*LoadTestRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, rel, err := s.local.getLoadTest(id)
+	if err != nil {
+		return nil, err
+	}
+	// the file may be gone, leaving the run without a request
+	if entry, ok := s.entries[rel]; ok {
+		run.RequestID = entry.id
+	}
+	return run, nil
+}
+
+// Search indexes the request files again in the local database, then looks
+// for requests and history entries like SQLiteStorage.Search
+func (s *FileStorage) Search(query SearchQuery) ([]SearchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, err := s.scan()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.local.indexWorkspace(w.requests); err != nil {
+		return nil, err
+	}
+	return s.local.search("workspace_requests_fts", query)
+}
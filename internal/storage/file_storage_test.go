@@ -0,0 +1,242 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/secrets"
+)
+
+func setupTestWorkspace(t *testing.T) (*FileStorage, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	store, err := NewFileStorage(dir)
+	if err != nil {
+		t.Fatalf("failed to create file storage: %v", err)
+	}
+
+	t.Cleanup(func() {
+		store.Close()
+	})
+
+	return store, dir
+}
+
+func readFile(t *testing.T, file string) string {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func TestFileStorage_SaveLoadDelete(t *testing.T) {
+	store, dir := setupTestWorkspace(t)
+
+	req := &http.Request{
+		Name:    "List users",
+		Method:  http.GET,
+		URL:     "https://api.example/users",
+		Headers: map[string]string{"Accept": "application/json"},
+		Body:    "{}",
+		Options: map[string]string{"timeout": "5s"},
+	}
+	assert.NoError(t, store.Save(req))
+	assert.NotEqual(t, int64(0), req.ID)
+	assert.Equal(t, int64(1), req.Version)
+	assert.Contains(t, readFile(t, filepath.Join(dir, "List users.yaml")), "url: https://api.example/users")
+
+	requests, err := store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(requests))
+	assert.Equal(t, req.ID, requests[0].ID)
+	assert.Equal(t, req.Headers, requests[0].Headers)
+	assert.Equal(t, req.Options, requests[0].Options)
+	assert.Equal(t, "{}", requests[0].Body)
+
+	// the file follows the name
+	req.Name = "All users"
+	assert.NoError(t, store.Save(req))
+	assert.Equal(t, int64(2), req.Version)
+	_, err = os.Stat(filepath.Join(dir, "List users.yaml"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dir, "All users.yaml"))
+	assert.NoError(t, err)
+
+	// another request with the same name gets its own file
+	copied := *req
+	assert.NoError(t, store.SaveAs(&copied))
+	assert.NotEqual(t, req.ID, copied.ID)
+	_, err = os.Stat(filepath.Join(dir, "All users 2.yaml"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Delete(req.ID))
+	requests, err = store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(requests))
+	assert.Equal(t, copied.ID, requests[0].ID)
+	assert.Error(t, store.Delete(req.ID))
+}
+
+func TestFileStorage_Conflict(t *testing.T) {
+	store, dir := setupTestWorkspace(t)
+
+	req := &http.Request{Name: "health", Method: http.GET, URL: "https://api.example/health"}
+	assert.NoError(t, store.Save(req))
+
+	// edited by hand since it was loaded
+	file := filepath.Join(dir, "health.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte("name: health\nmethod: HEAD\nurl: https://api.example/health\n"), 0644))
+
+	req.URL = "https://api.example/status"
+	assert.IsError(t, store.Save(req), ErrConflict)
+
+	requests, err := store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.example/health", requests[0].URL)
+	assert.Equal(t, req.Version+1, requests[0].Version)
+
+	req.Version = 0
+	assert.NoError(t, store.Save(req))
+	assert.Contains(t, readFile(t, file), "https://api.example/status")
+}
+
+func TestFileStorage_SecretsStayLocal(t *testing.T) {
+	store, dir := setupTestWorkspace(t)
+
+	req := &http.Request{
+		Name:    "me",
+		Method:  http.GET,
+		URL:     "https://api.example/me",
+		Headers: map[string]string{"Authorization": "Bearer token"},
+		Secrets: []string{"Authorization"},
+	}
+	assert.IsError(t, store.Save(req), secrets.ErrNoKey)
+
+	vault := testVault(t, 1)
+	assert.NoError(t, store.SetVault(vault))
+	assert.NoError(t, store.Save(req))
+
+	content := readFile(t, filepath.Join(dir, "me.yaml"))
+	assert.NotContains(t, content, "Bearer")
+	assert.Contains(t, content, "Authorization")
+
+	collection := &http.Collection{Name: "API", Auth: "Bearer collection"}
+	assert.NoError(t, store.CreateCollection(collection))
+	assert.NotContains(t, readFile(t, filepath.Join(dir, "API", collectionFile)), "Bearer")
+
+	// secrets follow their files
+	assert.NoError(t, store.MoveRequest(req.ID, collection.ID))
+	assert.NoError(t, store.RenameCollection(collection.ID, "Users API"))
+
+	requests, err := store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, collection.ID, requests[0].CollectionID)
+	unsealed, err := requests[0].Unseal(vault)
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer token", unsealed.Headers["Authorization"])
+
+	collections, err := store.LoadCollections()
+	assert.NoError(t, err)
+	assert.Equal(t, "Users API", collections[0].Name)
+	auth, err := vault.Open(collections[0].Auth)
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer collection", auth)
+
+	ignore := readFile(t, filepath.Join(dir, localDir, ".gitignore"))
+	assert.Equal(t, "*\n", ignore)
+}
+
+func TestFileStorage_Collections(t *testing.T) {
+	store, dir := setupTestWorkspace(t)
+
+	api := &http.Collection{Name: "API", BaseURL: "https://api.example", Headers: map[string]string{"Accept": "application/json"}}
+	assert.NoError(t, store.CreateCollection(api))
+	users := &http.Collection{Name: "users", ParentID: api.ID}
+	assert.NoError(t, store.CreateCollection(users))
+	req := &http.Request{Name: "list", Method: http.GET, URL: "/users", CollectionID: users.ID}
+	assert.NoError(t, store.Save(req))
+	_, err := os.Stat(filepath.Join(dir, "API", "users", "list.yaml"))
+	assert.NoError(t, err)
+
+	collections, err := store.LoadCollections()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(collections))
+	assert.Equal(t, "https://api.example", collections[0].BaseURL)
+	assert.Equal(t, api.Headers, collections[0].Headers)
+	assert.Equal(t, api.ID, collections[1].ParentID)
+
+	assert.Error(t, store.MoveCollection(api.ID, users.ID))
+	assert.NoError(t, store.MoveCollection(users.ID, 0))
+	_, err = os.Stat(filepath.Join(dir, "users", "list.yaml"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.DeleteCollection(users.ID))
+	requests, err := store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(requests))
+
+	// directories made by hand are collections named after them
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "Billing"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "Billing", "invoices.yaml"), []byte("method: get\nurl: /invoices\n"), 0644))
+	collections, err = store.LoadCollections()
+	assert.NoError(t, err)
+	assert.Equal(t, "Billing", collections[1].Name)
+	requests, err = store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "invoices", requests[0].Name)
+	assert.Equal(t, http.GET, requests[0].Method)
+	assert.Equal(t, collections[1].ID, requests[0].CollectionID)
+}
+
+func TestFileStorage_Environments(t *testing.T) {
+	store, dir := setupTestWorkspace(t)
+
+	assert.NoError(t, store.SaveEnvironmentVariables("staging", map[string]string{"host": "staging.example"}))
+	assert.NoError(t, store.SaveEnvironmentOptions("staging", map[string]string{"timeout": "5s"}))
+
+	variables, err := store.LoadEnvironmentVariables("staging")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "staging.example"}, variables)
+	options, err := store.LoadEnvironmentOptions("staging")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"timeout": "5s"}, options)
+
+	names, err := store.ListEnvironments()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"staging"}, names)
+	assert.Contains(t, readFile(t, filepath.Join(dir, environmentsDir, "staging.yaml")), "host: staging.example")
+
+	// the environments directory isn't a collection
+	collections, err := store.LoadCollections()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(collections))
+
+	variables, err = store.LoadEnvironmentVariables("production")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(variables))
+}
+
+func TestFileStorage_Changes(t *testing.T) {
+	store, dir := setupTestWorkspace(t)
+	changes := store.Changes()
+
+	// saving doesn't count as a change
+	assert.NoError(t, store.Save(&http.Request{Name: "health", Method: http.GET, URL: "https://api.example/health"}))
+	select {
+	case <-changes:
+		t.Fatal("unexpected change")
+	case <-time.After(2 * pollInterval):
+	}
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "status.yaml"), []byte("url: https://api.example/status\n"), 0644))
+	select {
+	case <-changes:
+	case <-time.After(3 * pollInterval):
+		t.Fatal("change not noticed")
+	}
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/owenHochwald/volt/internal/http"
+)
+
+// SaveGraphQLSchema caches the introspected schema of a GraphQL endpoint, replacing any previous one
+func (s *SQLiteStorage) SaveGraphQLSchema(url string, schema *http.GraphQLSchema) error {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return err
+	}
+	q := `INSERT INTO graphql_schemas (url, schema, fetched_at) VALUES (?, ?, ?)
+		ON CONFLICT (url) DO UPDATE SET schema = excluded.schema, fetched_at = excluded.fetched_at`
+	_, err = s.db.Exec(q, url, string(data), time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
+// LoadGraphQLSchema returns the cached schema of a GraphQL endpoint and when
+// it was fetched, or a nil schema when there is none
+func (s *SQLiteStorage) LoadGraphQLSchema(url string) (*http.GraphQLSchema, time.Time, error) {
+	var data, fetchedAt string
+	err := s.db.QueryRow(`SELECT schema, fetched_at FROM graphql_schemas WHERE url = ?`, url).Scan(&data, &fetchedAt)
+	if err == sql.ErrNoRows {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var schema http.GraphQLSchema
+	if err := json.Unmarshal([]byte(data), &schema); err != nil {
+		return nil, time.Time{}, err
+	}
+	fetched, err := time.Parse(time.RFC3339, fetchedAt)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return &schema, fetched, nil
+}
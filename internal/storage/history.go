@@ -0,0 +1,422 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/secrets"
+)
+
+// historyBodyLimit is the most of a response body kept in the history
+const historyBodyLimit = 64 << 10
+
+const settingHistoryRetention = "history_retention"
+
+// historyTimeFormat stores times in UTC with fixed width, so that they sort as strings
+const historyTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// HistoryEntry is a sent request and the response it got
+type HistoryEntry struct {
+	ID      int64        `json:"id"`
+	SentAt  time.Time    `json:"sent_at"`
+	Request http.Request `json:"request"`
+
+	StatusCode int                 `json:"status_code"`
+	Status     string              `json:"status,omitempty"`
+	Headers    map[string][]string `json:"headers,omitempty"`
+	Body       string              `json:"body,omitempty"`      // the start of the body, up to 64KB
+	BodySize   int64               `json:"body_size,omitempty"` // size of the whole body
+	Duration   time.Duration       `json:"duration"`
+	Timing     *http.Timing        `json:"timing,omitempty"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// Truncated reports whether only the start of the body was kept
+func (e *HistoryEntry) Truncated() bool {
+	return e.BodySize > int64(len(e.Body))
+}
+
+// NewHistoryEntry records a request sent at sentAt and its response
+func NewHistoryEntry(request *http.Request, response *http.Response, sentAt time.Time) *HistoryEntry {
+	entry := &HistoryEntry{
+		SentAt:     sentAt.UTC(),
+		Request:    *request,
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Headers:    response.Headers,
+		Body:       response.Body[:min(len(response.Body), historyBodyLimit)],
+		BodySize:   response.BodySize(),
+		Duration:   response.Duration,
+		Timing:     response.Timing,
+		Error:      response.Error,
+	}
+	entry.Request.ID, entry.Request.Version, entry.Request.UpdatedAt = 0, 0, time.Time{}
+	return entry
+}
+
+// HistoryRetention limits how much history is kept, the oldest entries
+// going first. Zero means no limit.
+type HistoryRetention struct {
+	MaxEntries int           `json:"max_entries"`
+	MaxAge     time.Duration `json:"max_age"`
+	MaxSize    int64         `json:"max_size"` // bytes of request and response bodies and headers
+}
+
+// DefaultHistoryRetention is used until other limits are set
+var DefaultHistoryRetention = HistoryRetention{
+	MaxEntries: 1000,
+	MaxAge:     30 * 24 * time.Hour,
+	MaxSize:    50 << 20,
+}
+
+// HistoryFilter selects history entries. Zero fields match everything.
+type HistoryFilter struct {
+	Query  string // part of the URL or name
+	Method string
+	Status string // a code such as 404, a class such as 4xx, or "error" for requests that got no response
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+// ParseHistoryQuery reads a filter typed as words, e.g.
+// "method:post status:5xx since:2024-05-01 until:2024-05-31 users". The
+// day given by date: sets both since and until, and words that aren't
+// fields match the URL or name.
+func ParseHistoryQuery(query string) (HistoryFilter, error) {
+	var filter HistoryFilter
+	var words []string
+	for _, word := range strings.Fields(query) {
+		key, value, found := strings.Cut(word, ":")
+		key = strings.ToLower(key)
+		if !found || value == "" {
+			words = append(words, word)
+			continue
+		}
+		switch key {
+		case "method":
+			filter.Method = strings.ToUpper(value)
+		case "status":
+			if _, _, err := statusRange(value); err != nil {
+				return filter, err
+			}
+			filter.Status = value
+		case "since", "until", "date":
+			day, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+			if err != nil {
+				return filter, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+			}
+			if key != "until" {
+				filter.Since = day
+			}
+			if key != "since" {
+				filter.Until = day.AddDate(0, 0, 1)
+			}
+		default:
+			// e.g. a URL with a scheme
+			words = append(words, word)
+		}
+	}
+	filter.Query = strings.Join(words, " ")
+	return filter, nil
+}
+
+// statusRange returns the status codes matched by a status filter, 0 to 0 for "error"
+func statusRange(status string) (int, int, error) {
+	status = strings.ToLower(status)
+	if status == "error" {
+		return 0, 0, nil
+	}
+	if class, ok := strings.CutSuffix(status, "xx"); ok && len(class) == 1 && class[0] >= '1' && class[0] <= '5' {
+		low := int(class[0]-'0') * 100
+		return low, low + 99, nil
+	}
+	code, err := strconv.Atoi(status)
+	if err != nil || code < 100 || code > 599 {
+		return 0, 0, fmt.Errorf("invalid status %q, expected a code such as 404, a class such as 4xx or error", status)
+	}
+	return code, code, nil
+}
+
+// historySize is what an entry counts towards the size limit: its request
+// and response headers, as JSON, and bodies
+func historySize(headers, body, responseHeaders, responseBody string) int64 {
+	return int64(len(headers) + len(body) + len(responseHeaders) + len(responseBody))
+}
+
+// historyRequest returns a copy of a sent request whose secret header values
+// are encrypted with vault, or masked when it is nil
+func historyRequest(vault *secrets.Vault, sent *http.Request) http.Request {
+	request := *sent
+	request.Headers = make(map[string]string, len(sent.Headers))
+	for key, value := range sent.Headers {
+		if sent.IsSecret(key) && !secrets.IsSealed(value) {
+			sealed, err := vault.Seal(value)
+			if err != nil {
+				// without a key the value isn't kept
+				sealed = secrets.Mask
+			}
+			value = sealed
+		}
+		request.Headers[key] = value
+	}
+	return request
+}
+
+// credentialResponseHeaders are the response headers whose values are
+// masked in the history, as they hand out or echo credentials
+var credentialResponseHeaders = []string{"set-cookie", "set-cookie2", "authorization", "proxy-authorization",
+	"x-api-key", "api-key", "x-auth-token", "x-access-token", "x-refresh-token"}
+
+// historyResponseHeaders returns a copy of response headers with the values
+// of credentials masked. They are only shown, never sent again, so they
+// aren't worth encrypting.
+func historyResponseHeaders(headers map[string][]string) map[string][]string {
+	if headers == nil {
+		return nil
+	}
+	masked := make(map[string][]string, len(headers))
+	for key, values := range headers {
+		if slices.Contains(credentialResponseHeaders, strings.ToLower(key)) {
+			values = slices.Repeat([]string{secrets.Mask}, len(values))
+		}
+		masked[key] = values
+	}
+	return masked
+}
+
+// AddHistory records a sent request, then drops the entries past the
+// retention limits. Secret header values are encrypted, or masked when no
+// secret key is configured, and credentials in the response are masked.
+func (s *SQLiteStorage) AddHistory(entry *HistoryEntry) error {
+	request := historyRequest(s.vault, &entry.Request)
+	entry.Headers = historyResponseHeaders(entry.Headers)
+	headerString, err := serializeHeaders(request.Headers)
+	if err != nil {
+		return err
+	}
+	secretString, err := serializeSecrets(request.Secrets)
+	if err != nil {
+		return err
+	}
+	optionString, err := serializeOptions(request.Options)
+	if err != nil {
+		return err
+	}
+	responseHeaders, err := json.Marshal(entry.Headers)
+	if err != nil {
+		return err
+	}
+	var timing sql.NullString
+	if entry.Timing != nil {
+		data, err := json.Marshal(entry.Timing)
+		if err != nil {
+			return err
+		}
+		timing = sql.NullString{String: string(data), Valid: true}
+	}
+	size := historySize(headerString, request.Body, string(responseHeaders), entry.Body)
+
+	q := `INSERT INTO history (sent_at, name, method, url, headers, body, secrets, options,
+			status_code, status, response_headers, response_body, body_size, duration, timing, error, size)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := s.db.Exec(q, entry.SentAt.UTC().Format(historyTimeFormat), request.Name, request.Method, request.URL,
+		headerString, request.Body, secretString, optionString,
+		entry.StatusCode, entry.Status, string(responseHeaders), entry.Body, entry.BodySize, entry.Duration, timing, entry.Error, size)
+	if err != nil {
+		return err
+	}
+	if entry.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+	entry.Request = request
+
+	retention, err := s.HistoryRetention()
+	if err != nil {
+		return err
+	}
+	return s.PruneHistory(retention)
+}
+
+// LoadHistory returns the entries matching a filter, newest first
+func (s *SQLiteStorage) LoadHistory(filter HistoryFilter) ([]HistoryEntry, error) {
+	var where []string
+	var args []any
+	if filter.Query != "" {
+		where = append(where, `(url LIKE ? OR name LIKE ?)`)
+		pattern := "%" + filter.Query + "%"
+		args = append(args, pattern, pattern)
+	}
+	if filter.Method != "" {
+		where = append(where, `method = ?`)
+		args = append(args, strings.ToUpper(filter.Method))
+	}
+	if filter.Status != "" {
+		low, high, err := statusRange(filter.Status)
+		if err != nil {
+			return nil, err
+		}
+		where = append(where, `status_code BETWEEN ? AND ?`)
+		args = append(args, low, high)
+	}
+	if !filter.Since.IsZero() {
+		where = append(where, `sent_at >= ?`)
+		args = append(args, filter.Since.UTC().Format(historyTimeFormat))
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, `sent_at < ?`)
+		args = append(args, filter.Until.UTC().Format(historyTimeFormat))
+	}
+
+	q := `SELECT id, sent_at, name, method, url, headers, body, secrets, options,
+			status_code, status, response_headers, response_body, body_size, duration, timing, error
+		FROM history`
+	if len(where) > 0 {
+		q += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	q += ` ORDER BY id DESC`
+	if filter.Limit > 0 {
+		q += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []HistoryEntry
+	for rows.Next() {
+		entry, err := scanHistoryEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, rows.Err()
+}
+
+// GetHistory returns one history entry
+func (s *SQLiteStorage) GetHistory(id int64) (*HistoryEntry, error) {
+	q := `SELECT id, sent_at, name, method, url, headers, body, secrets, options,
+			status_code, status, response_headers, response_body, body_size, duration, timing, error
+		FROM history WHERE id = ?`
+	entry, err := scanHistoryEntry(s.db.QueryRow(q, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("history entry not found: %d", id)
+	}
+	return entry, err
+}
+
+// scanHistoryEntry reads a history entry from a row
+func scanHistoryEntry(row interface{ Scan(...any) error }) (*HistoryEntry, error) {
+	var (
+		entry           HistoryEntry
+		sentAt          string
+		headers         string
+		secretList      string
+		optionList      string
+		responseHeaders string
+		timing          sql.NullString
+	)
+	err := row.Scan(&entry.ID, &sentAt, &entry.Request.Name, &entry.Request.Method, &entry.Request.URL,
+		&headers, &entry.Request.Body, &secretList, &optionList,
+		&entry.StatusCode, &entry.Status, &responseHeaders, &entry.Body, &entry.BodySize, &entry.Duration, &timing, &entry.Error)
+	if err != nil {
+		return nil, err
+	}
+	if entry.SentAt, err = time.Parse(historyTimeFormat, sentAt); err != nil {
+		return nil, err
+	}
+	if entry.Request.Headers, err = deserializeHeaders(headers); err != nil {
+		return nil, err
+	}
+	if entry.Request.Secrets, err = deserializeSecrets(secretList); err != nil {
+		return nil, err
+	}
+	if entry.Request.Options, err = deserializeOptions(optionList); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(responseHeaders), &entry.Headers); err != nil {
+		return nil, err
+	}
+	if timing.Valid {
+		entry.Timing = &http.Timing{}
+		if err := json.Unmarshal([]byte(timing.String), entry.Timing); err != nil {
+			return nil, err
+		}
+	}
+	return &entry, nil
+}
+
+// ClearHistory deletes every history entry
+func (s *SQLiteStorage) ClearHistory() error {
+	_, err := s.db.Exec(`DELETE FROM history`)
+	return err
+}
+
+// PruneHistory deletes the oldest entries past the retention limits
+func (s *SQLiteStorage) PruneHistory(retention HistoryRetention) error {
+	if retention.MaxAge > 0 {
+		cutoff := time.Now().Add(-retention.MaxAge).UTC().Format(historyTimeFormat)
+		if _, err := s.db.Exec(`DELETE FROM history WHERE sent_at < ?`, cutoff); err != nil {
+			return err
+		}
+	}
+	if retention.MaxEntries > 0 {
+		q := `DELETE FROM history WHERE id NOT IN (SELECT id FROM history ORDER BY id DESC LIMIT ?)`
+		if _, err := s.db.Exec(q, retention.MaxEntries); err != nil {
+			return err
+		}
+	}
+	if retention.MaxSize > 0 {
+		q := `DELETE FROM history WHERE id IN (
+			SELECT id FROM (SELECT id, SUM(size) OVER (ORDER BY id DESC) AS total FROM history) WHERE total > ?
+		)`
+		if _, err := s.db.Exec(q, retention.MaxSize); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HistoryRetention returns the configured retention limits
+func (s *SQLiteStorage) HistoryRetention() (HistoryRetention, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT value FROM settings WHERE key = ?`, settingHistoryRetention).Scan(&data)
+	if err == sql.ErrNoRows {
+		return DefaultHistoryRetention, nil
+	}
+	if err != nil {
+		return HistoryRetention{}, err
+	}
+	var retention HistoryRetention
+	if err := json.Unmarshal(data, &retention); err != nil {
+		return HistoryRetention{}, err
+	}
+	return retention, nil
+}
+
+// SetHistoryRetention changes the retention limits and applies them
+func (s *SQLiteStorage) SetHistoryRetention(retention HistoryRetention) error {
+	if retention.MaxEntries < 0 || retention.MaxAge < 0 || retention.MaxSize < 0 {
+		return fmt.Errorf("retention limits can't be negative")
+	}
+	data, err := json.Marshal(retention)
+	if err != nil {
+		return err
+	}
+	q := `INSERT INTO settings (key, value) VALUES (?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value`
+	if _, err := s.db.Exec(q, settingHistoryRetention, data); err != nil {
+		return err
+	}
+	return s.PruneHistory(retention)
+}
@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/owenHochwald/volt/internal/http"
+)
+
+// LoadTestRun is a finished load test of a saved request
+type LoadTestRun struct {
+	ID        int64               `json:"id"`
+	RequestID int64               `json:"request_id"`
+	RanAt     time.Time           `json:"ran_at"`
+	Label     string              `json:"label,omitempty"`
+	GitSHA    string              `json:"git_sha,omitempty"` // commit checked out where volt ran
+	Config    LoadTestConfig      `json:"config"`
+	Stats     *http.LoadTestStats `json:"stats"`
+}
+
+// LoadTestConfig is what a load test was run with
+type LoadTestConfig struct {
+	Method        string        `json:"method"`
+	URL           string        `json:"url"`
+	Concurrency   int           `json:"concurrency"`
+	TotalRequests int           `json:"total_requests"`
+	QPS           float64       `json:"qps,omitempty"`
+	Timeout       time.Duration `json:"timeout"`
+	Protocol      string        `json:"protocol,omitempty"`
+}
+
+// NewLoadTestRun records a load test of a saved request once it finished
+func NewLoadTestRun(requestID int64, config *http.JobConfig, stats *http.LoadTestStats, label, gitSHA string) *LoadTestRun {
+	return &LoadTestRun{
+		RequestID: requestID,
+		RanAt:     stats.StartTime.UTC(),
+		Label:     label,
+		GitSHA:    gitSHA,
+		Config: LoadTestConfig{
+			Method:        config.Request.Method,
+			URL:           config.Request.URL,
+			Concurrency:   config.Concurrency,
+			TotalRequests: config.TotalRequests,
+			QPS:           config.QPS,
+			Timeout:       config.Timeout,
+			Protocol:      config.Protocol,
+		},
+		Stats: stats,
+	}
+}
+
+// Name tells the run apart from the others of its request
+func (r *LoadTestRun) Name() string {
+	name := r.RanAt.Local().Format(time.DateTime)
+	if r.Label != "" {
+		name += " " + r.Label
+	}
+	if r.GitSHA != "" {
+		name += " @" + r.GitSHA
+	}
+	return name
+}
+
+// AddLoadTest keeps a finished load test of a saved request
+func (s *SQLiteStorage) AddLoadTest(run *LoadTestRun) error {
+	return s.addLoadTest(strconv.FormatInt(run.RequestID, 10), run)
+}
+
+// LoadLoadTests returns the load tests of a saved request, newest first
+func (s *SQLiteStorage) LoadLoadTests(requestID int64) ([]LoadTestRun, error) {
+	runs, err := s.loadLoadTests(strconv.FormatInt(requestID, 10))
+	for i := range runs {
+		runs[i].RequestID = requestID
+	}
+	return runs, err
+}
+
+// GetLoadTest returns a single load test
+func (s *SQLiteStorage) GetLoadTest(id int64) (*LoadTestRun, error) {
+	run, request, err := s.getLoadTest(id)
+	if err != nil {
+		return nil, err
+	}
+	run.RequestID, err = strconv.ParseInt(request, 10, 64)
+	return run, err
+}
+
+// addLoadTest keeps a load test of the request with the given ID or path
+func (s *SQLiteStorage) addLoadTest(request string, run *LoadTestRun) error {
+	config, err := json.Marshal(run.Config)
+	if err != nil {
+		return err
+	}
+	stats, err := json.Marshal(run.Stats)
+	if err != nil {
+		return err
+	}
+	q := `INSERT INTO load_tests (request, ran_at, label, git_sha, config, stats) VALUES (?, ?, ?, ?, ?, ?)`
+	res, err := s.db.Exec(q, request, run.RanAt.UTC().Format(historyTimeFormat), run.Label, run.GitSHA, string(config), string(stats))
+	if err != nil {
+		return err
+	}
+	run.ID, err = res.LastInsertId()
+	return err
+}
+
+func (s *SQLiteStorage) loadLoadTests(request string) ([]LoadTestRun, error) {
+	q := `SELECT id, request, ran_at, label, git_sha, config, stats FROM load_tests
+		WHERE request = ? ORDER BY ran_at DESC, id DESC`
+	rows, err := s.db.Query(q, request)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []LoadTestRun
+	for rows.Next() {
+		run, _, err := scanLoadTest(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	return runs, rows.Err()
+}
+
+// getLoadTest returns a load test and the ID or path of its request
+func (s *SQLiteStorage) getLoadTest(id int64) (*LoadTestRun, string, error) {
+	q := `SELECT id, request, ran_at, label, git_sha, config, stats FROM load_tests WHERE id = ?`
+	run, request, err := scanLoadTest(s.db.QueryRow(q, id))
+	if err == sql.ErrNoRows {
+		return nil, "", fmt.Errorf("load test not found: %d", id)
+	}
+	return run, request, err
+}
+
+// moveLoadTests moves the load tests of a renamed request file, or of the
+// request files inside a renamed directory
+func (s *SQLiteStorage) moveLoadTests(oldPath, newPath string) error {
+	q := `UPDATE load_tests SET request = ?1 || substr(request, length(?2) + 1)
+		WHERE request = ?2 OR substr(request, 1, length(?2) + 1) = ?2 || '/'`
+	_, err := s.db.Exec(q, newPath, oldPath)
+	return err
+}
+
+// deleteLoadTests deletes the load tests of a request file, or of the request
+// files inside a directory
+func (s *SQLiteStorage) deleteLoadTests(rel string) error {
+	q := `DELETE FROM load_tests WHERE request = ?1 OR substr(request, 1, length(?1) + 1) = ?1 || '/'`
+	_, err := s.db.Exec(q, rel)
+	return err
+}
+
+// scanLoadTest reads a load test and the ID or path of its request from a row
+func scanLoadTest(row interface{ Scan(...any) error }) (*LoadTestRun, string, error) {
+	var (
+		run     LoadTestRun
+		request string
+		ranAt   string
+		config  string
+		stats   string
+	)
+	err := row.Scan(&run.ID, &request, &ranAt, &run.Label, &run.GitSHA, &config, &stats)
+	if err != nil {
+		return nil, "", err
+	}
+	if run.RanAt, err = time.Parse(historyTimeFormat, ranAt); err != nil {
+		return nil, "", err
+	}
+	if err := json.Unmarshal([]byte(config), &run.Config); err != nil {
+		return nil, "", err
+	}
+	if err := json.Unmarshal([]byte(stats), &run.Stats); err != nil {
+		return nil, "", err
+	}
+	return &run, request, nil
+}
@@ -0,0 +1,793 @@
+package storage
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/secrets"
+)
+
+// MemoryStorage keeps everything in memory, and forgets it when the program
+// exits. It serves tests and throwaway sessions (volt --db :memory:).
+type MemoryStorage struct {
+	mu    sync.Mutex
+	vault *secrets.Vault
+
+	requests    map[int64]http.Request
+	collections map[int64]http.Collection
+	options     map[string]map[string]string // by environment
+	variables   map[string]map[string]string // by environment
+	cookies     map[string][]http.Cookie     // by environment
+	schemas     map[string]memorySchema      // by URL
+	history     []memoryHistoryEntry         // oldest first
+	loadTests   []LoadTestRun                // oldest first
+	retention   *HistoryRetention
+	salt        []byte
+	check       string // secretCheckValue sealed with the first key
+	nextID      int64
+}
+
+type memorySchema struct {
+	schema    *http.GraphQLSchema
+	fetchedAt time.Time
+}
+
+type memoryHistoryEntry struct {
+	entry HistoryEntry
+	size  int64
+}
+
+// NewMemoryStorage returns an empty MemoryStorage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		requests:    make(map[int64]http.Request),
+		collections: make(map[int64]http.Collection),
+		options:     make(map[string]map[string]string),
+		variables:   make(map[string]map[string]string),
+		cookies:     make(map[string][]http.Cookie),
+		schemas:     make(map[string]memorySchema),
+	}
+}
+
+// newID returns an ID unused by requests, collections, history entries and
+// load tests
+func (s *MemoryStorage) newID() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+// copyRequest returns a request that shares no maps or slices with r
+func copyRequest(r http.Request) http.Request {
+	r.Headers = maps.Clone(r.Headers)
+	r.Secrets = slices.Clone(r.Secrets)
+	r.Options = maps.Clone(r.Options)
+	return r
+}
+
+func (s *MemoryStorage) Close() error {
+	return nil
+}
+
+// SecretSalt returns the salt used to derive a key from a passphrase, creating it on first use
+func (s *MemoryStorage) SecretSalt() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.salt == nil {
+		salt, err := secrets.NewSalt()
+		if err != nil {
+			return nil, err
+		}
+		s.salt = salt
+	}
+	return s.salt, nil
+}
+
+// SetVault configures the key used to encrypt secrets. The key is checked
+// against the first one configured, and any secret values still stored as
+// plaintext are encrypted.
+func (s *MemoryStorage) SetVault(vault *secrets.Vault) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if vault == nil {
+		s.vault = nil
+		return nil
+	}
+
+	if s.check == "" {
+		sealed, err := vault.Seal(secretCheckValue)
+		if err != nil {
+			return err
+		}
+		s.check = sealed
+	} else if opened, err := vault.Open(s.check); err != nil || opened != secretCheckValue {
+		return secrets.ErrWrongKey
+	}
+
+	s.vault = vault
+	for id, request := range s.requests {
+		if err := sealHeaders(vault, &request); err != nil {
+			return err
+		}
+		s.requests[id] = request
+	}
+	return nil
+}
+
+// SealSecret encrypts a secret value, such as a variable, to be saved where
+// plaintext would be
+func (s *MemoryStorage) SealSecret(value string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.vault.Seal(value)
+}
+
+// Save inserts a new request, or updates the saved request with the same ID.
+// An update fails with ErrConflict when the request was saved elsewhere
+// since it was loaded, unless its Version is 0.
+func (s *MemoryStorage) Save(request *http.Request) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := sealHeaders(s.vault, request); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+
+	if request.ID == 0 {
+		if request.CollectionID != 0 {
+			if _, ok := s.collections[request.CollectionID]; !ok {
+				return fmt.Errorf("collection not found: %d", request.CollectionID)
+			}
+		}
+		request.ID, request.Version, request.UpdatedAt = s.newID(), 1, now
+		s.requests[request.ID] = copyRequest(*request)
+		return nil
+	}
+
+	saved, ok := s.requests[request.ID]
+	if !ok {
+		return fmt.Errorf("request not found: %d", request.ID)
+	}
+	if request.Version != 0 && request.Version != saved.Version {
+		return ErrConflict
+	}
+	// The collection is left alone, requests are moved with MoveRequest
+	updated := copyRequest(*request)
+	updated.CollectionID, updated.Version, updated.UpdatedAt = saved.CollectionID, saved.Version+1, now
+	s.requests[request.ID] = updated
+	request.Version, request.UpdatedAt = updated.Version, now
+	return nil
+}
+
+// SaveAs saves the request as a new one, leaving the request it was loaded from as it was
+func (s *MemoryStorage) SaveAs(request *http.Request) error {
+	request.ID, request.Version = 0, 0
+	return s.Save(request)
+}
+
+func (s *MemoryStorage) Load() ([]http.Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var requests []http.Request
+	for _, id := range slices.Sorted(maps.Keys(s.requests)) {
+		requests = append(requests, copyRequest(s.requests[id]))
+	}
+	return requests, nil
+}
+
+func (s *MemoryStorage) Delete(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.requests[id]; !ok {
+		return fmt.Errorf("request not found: %d", id)
+	}
+	delete(s.requests, id)
+	s.loadTests = slices.DeleteFunc(s.loadTests, func(run LoadTestRun) bool { return run.RequestID == id })
+	return nil
+}
+
+func (s *MemoryStorage) GetAllURLs() ([]string, error) {
+	requests, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	var urls []string
+	for _, request := range requests {
+		if !slices.Contains(urls, request.URL) {
+			urls = append(urls, request.URL)
+		}
+	}
+	return urls, nil
+}
+
+// CreateCollection saves a new collection, or a folder when it has a parent
+func (s *MemoryStorage) CreateCollection(collection *http.Collection) error {
+	collection.Name = strings.TrimSpace(collection.Name)
+	if err := collection.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if collection.ParentID != 0 {
+		if _, ok := s.collections[collection.ParentID]; !ok {
+			return fmt.Errorf("collection not found: %d", collection.ParentID)
+		}
+	}
+	auth, err := sealAuth(s.vault, collection.Auth)
+	if err != nil {
+		return err
+	}
+	collection.ID, collection.Auth = s.newID(), auth
+	saved := *collection
+	saved.Headers = maps.Clone(collection.Headers)
+	s.collections[collection.ID] = saved
+	return nil
+}
+
+// LoadCollections returns every collection and folder, sorted by name
+func (s *MemoryStorage) LoadCollections() ([]http.Collection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var collections []http.Collection
+	for _, collection := range s.collections {
+		collection.Headers = maps.Clone(collection.Headers)
+		collections = append(collections, collection)
+	}
+	slices.SortFunc(collections, func(a, b http.Collection) int {
+		return cmp.Or(cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)), cmp.Compare(a.ID, b.ID))
+	})
+	return collections, nil
+}
+
+// updateCollection changes one collection in place
+func (s *MemoryStorage) updateCollection(id int64, update func(*http.Collection)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	collection, ok := s.collections[id]
+	if !ok {
+		return fmt.Errorf("collection not found: %d", id)
+	}
+	update(&collection)
+	s.collections[id] = collection
+	return nil
+}
+
+// RenameCollection changes the name of a collection
+func (s *MemoryStorage) RenameCollection(id int64, name string) error {
+	renamed := http.Collection{ID: id, Name: strings.TrimSpace(name)}
+	if err := renamed.Validate(); err != nil {
+		return err
+	}
+	return s.updateCollection(id, func(collection *http.Collection) { collection.Name = renamed.Name })
+}
+
+// SetCollectionDefaults replaces the base URL, headers and auth inherited by
+// the requests inside a collection
+func (s *MemoryStorage) SetCollectionDefaults(collection *http.Collection) error {
+	if err := collection.Validate(); err != nil {
+		return err
+	}
+	auth, err := sealAuth(s.vault, collection.Auth)
+	if err != nil {
+		return err
+	}
+	if err := s.updateCollection(collection.ID, func(saved *http.Collection) {
+		saved.BaseURL, saved.Headers, saved.Auth = collection.BaseURL, maps.Clone(collection.Headers), auth
+	}); err != nil {
+		return err
+	}
+	collection.Auth = auth
+	return nil
+}
+
+// MoveCollection moves a collection into another one, or to the top level
+// when parentID is 0. A collection can't be moved into one of its own folders.
+func (s *MemoryStorage) MoveCollection(id, parentID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ancestor := parentID; ancestor != 0; {
+		if ancestor == id {
+			return fmt.Errorf("a collection can't be moved into itself")
+		}
+		parent, ok := s.collections[ancestor]
+		if !ok {
+			return fmt.Errorf("collection not found: %d", ancestor)
+		}
+		ancestor = parent.ParentID
+	}
+	collection, ok := s.collections[id]
+	if !ok {
+		return fmt.Errorf("collection not found: %d", id)
+	}
+	collection.ParentID = parentID
+	s.collections[id] = collection
+	return nil
+}
+
+// DeleteCollection deletes a collection along with its folders and every
+// request inside them
+func (s *MemoryStorage) DeleteCollection(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.collections[id]; !ok {
+		return fmt.Errorf("collection not found: %d", id)
+	}
+
+	tree := map[int64]bool{id: true}
+	for grown := true; grown; {
+		grown = false
+		for _, collection := range s.collections {
+			if tree[collection.ParentID] && !tree[collection.ID] {
+				tree[collection.ID], grown = true, true
+			}
+		}
+	}
+	maps.DeleteFunc(s.collections, func(id int64, _ http.Collection) bool { return tree[id] })
+	maps.DeleteFunc(s.requests, func(_ int64, request http.Request) bool { return tree[request.CollectionID] })
+	s.loadTests = slices.DeleteFunc(s.loadTests, func(run LoadTestRun) bool {
+		_, ok := s.requests[run.RequestID]
+		return !ok
+	})
+	return nil
+}
+
+// MoveRequest moves a saved request into a collection, or out of any when collectionID is 0
+func (s *MemoryStorage) MoveRequest(id, collectionID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if collectionID != 0 {
+		if _, ok := s.collections[collectionID]; !ok {
+			return fmt.Errorf("collection not found: %d", collectionID)
+		}
+	}
+	request, ok := s.requests[id]
+	if !ok {
+		return fmt.Errorf("request not found: %d", id)
+	}
+	request.CollectionID = collectionID
+	s.requests[id] = request
+	return nil
+}
+
+// LoadEnvironmentOptions returns the options of an environment, which requests
+// inherit unless they override them
+func (s *MemoryStorage) LoadEnvironmentOptions(environment string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.options[environment]), nil
+}
+
+// SaveEnvironmentOptions replaces the options of an environment, creating it if needed
+func (s *MemoryStorage) SaveEnvironmentOptions(environment string, options map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.options[environment] = maps.Clone(options)
+	if _, ok := s.variables[environment]; !ok {
+		s.variables[environment] = nil
+	}
+	return nil
+}
+
+// LoadEnvironmentVariables returns the variables of an environment, which
+// requests reference as {{name}}
+func (s *MemoryStorage) LoadEnvironmentVariables(environment string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.variables[environment]), nil
+}
+
+// SaveEnvironmentVariables replaces the variables of an environment, creating it if needed
+func (s *MemoryStorage) SaveEnvironmentVariables(environment string, variables map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.variables[environment] = maps.Clone(variables)
+	if _, ok := s.options[environment]; !ok {
+		s.options[environment] = nil
+	}
+	return nil
+}
+
+// ListEnvironments returns the names of all configured environments
+func (s *MemoryStorage) ListEnvironments() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := slices.Sorted(maps.Keys(s.options))
+	if len(names) == 0 {
+		return nil, nil
+	}
+	return names, nil
+}
+
+// SaveCookies replaces the stored cookies of an environment
+func (s *MemoryStorage) SaveCookies(environment string, cookies []http.Cookie) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cookies[environment] = slices.Clone(cookies)
+	return nil
+}
+
+// LoadCookies returns the unexpired cookies of an environment
+func (s *MemoryStorage) LoadCookies(environment string) ([]http.Cookie, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var cookies []http.Cookie
+	for _, c := range s.cookies[environment] {
+		if !c.Expired(now) {
+			cookies = append(cookies, c)
+		}
+	}
+	slices.SortFunc(cookies, func(a, b http.Cookie) int {
+		return cmp.Or(cmp.Compare(a.Domain, b.Domain), cmp.Compare(a.Path, b.Path), cmp.Compare(a.Name, b.Name))
+	})
+	return cookies, nil
+}
+
+// ClearCookies removes every stored cookie of an environment
+func (s *MemoryStorage) ClearCookies(environment string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cookies, environment)
+	return nil
+}
+
+// SaveGraphQLSchema caches the introspected schema of a GraphQL endpoint, replacing any previous one
+func (s *MemoryStorage) SaveGraphQLSchema(url string, schema *http.GraphQLSchema) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schemas[url] = memorySchema{schema: schema, fetchedAt: time.Now().UTC().Truncate(time.Second)}
+	return nil
+}
+
+// LoadGraphQLSchema returns the cached schema of a GraphQL endpoint and when
+// it was fetched, or a nil schema when there is none
+func (s *MemoryStorage) LoadGraphQLSchema(url string) (*http.GraphQLSchema, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cached := s.schemas[url]
+	return cached.schema, cached.fetchedAt, nil
+}
+
+// AddHistory records a sent request, then drops the entries past the
+// retention limits. Secret header values are encrypted, or masked when no
+// secret key is configured, and credentials in the response are masked.
+func (s *MemoryStorage) AddHistory(entry *HistoryEntry) error {
+	if err := s.addHistory(entry); err != nil {
+		return err
+	}
+	retention, err := s.HistoryRetention()
+	if err != nil {
+		return err
+	}
+	return s.PruneHistory(retention)
+}
+
+// addHistory records a sent request
+func (s *MemoryStorage) addHistory(entry *HistoryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	request := historyRequest(s.vault, &entry.Request)
+	entry.Headers = historyResponseHeaders(entry.Headers)
+	headers, err := serializeHeaders(request.Headers)
+	if err != nil {
+		return err
+	}
+	responseHeaders, err := json.Marshal(entry.Headers)
+	if err != nil {
+		return err
+	}
+
+	entry.ID, entry.Request = s.newID(), request
+	recorded := *entry
+	recorded.SentAt = entry.SentAt.UTC()
+	recorded.Request = copyRequest(request)
+	s.history = append(s.history, memoryHistoryEntry{
+		entry: recorded,
+		size:  historySize(headers, request.Body, string(responseHeaders), entry.Body),
+	})
+	return nil
+}
+
+// LoadHistory returns the entries matching a filter, newest first
+func (s *MemoryStorage) LoadHistory(filter HistoryFilter) ([]HistoryEntry, error) {
+	low, high := 0, 0
+	if filter.Status != "" {
+		var err error
+		if low, high, err = statusRange(filter.Status); err != nil {
+			return nil, err
+		}
+	}
+	query := strings.ToLower(filter.Query)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var entries []HistoryEntry
+	for _, recorded := range slices.Backward(s.history) {
+		entry := recorded.entry
+		switch {
+		case query != "" && !strings.Contains(strings.ToLower(entry.Request.URL), query) &&
+			!strings.Contains(strings.ToLower(entry.Request.Name), query):
+			continue
+		case filter.Method != "" && entry.Request.Method != strings.ToUpper(filter.Method):
+			continue
+		case filter.Status != "" && (entry.StatusCode < low || entry.StatusCode > high):
+			continue
+		case !filter.Since.IsZero() && entry.SentAt.Before(filter.Since):
+			continue
+		case !filter.Until.IsZero() && !entry.SentAt.Before(filter.Until):
+			continue
+		}
+		entry.Request = copyRequest(entry.Request)
+		entries = append(entries, entry)
+		if filter.Limit > 0 && len(entries) == filter.Limit {
+			break
+		}
+	}
+	return entries, nil
+}
+
+// GetHistory returns one history entry
+func (s *MemoryStorage) GetHistory(id int64) (*HistoryEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, recorded := range s.history {
+		if recorded.entry.ID == id {
+			entry := recorded.entry
+			entry.Request = copyRequest(entry.Request)
+			return &entry, nil
+		}
+	}
+	return nil, fmt.Errorf("history entry not found: %d", id)
+}
+
+// ClearHistory deletes every history entry
+func (s *MemoryStorage) ClearHistory() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history = nil
+	return nil
+}
+
+// PruneHistory deletes the oldest entries past the retention limits
+func (s *MemoryStorage) PruneHistory(retention HistoryRetention) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if retention.MaxAge > 0 {
+		cutoff := time.Now().Add(-retention.MaxAge)
+		s.history = slices.DeleteFunc(s.history, func(recorded memoryHistoryEntry) bool {
+			return recorded.entry.SentAt.Before(cutoff)
+		})
+	}
+	if retention.MaxEntries > 0 && len(s.history) > retention.MaxEntries {
+		s.history = s.history[len(s.history)-retention.MaxEntries:]
+	}
+	if retention.MaxSize > 0 {
+		var total int64
+		for i, recorded := range slices.Backward(s.history) {
+			if total += recorded.size; total > retention.MaxSize {
+				s.history = s.history[i+1:]
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// HistoryRetention returns the configured retention limits
+func (s *MemoryStorage) HistoryRetention() (HistoryRetention, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.retention == nil {
+		return DefaultHistoryRetention, nil
+	}
+	return *s.retention, nil
+}
+
+// SetHistoryRetention changes the retention limits and applies them
+func (s *MemoryStorage) SetHistoryRetention(retention HistoryRetention) error {
+	if retention.MaxEntries < 0 || retention.MaxAge < 0 || retention.MaxSize < 0 {
+		return fmt.Errorf("retention limits can't be negative")
+	}
+	s.mu.Lock()
+	s.retention = &retention
+	s.mu.Unlock()
+	return s.PruneHistory(retention)
+}
+
+// AddLoadTest keeps a finished load test of a saved request
+func (s *MemoryStorage) AddLoadTest(run *LoadTestRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run.ID = s.newID()
+	s.loadTests = append(s.loadTests, *run)
+	return nil
+}
+
+// LoadLoadTests returns the load tests of a saved request, newest first
+func (s *MemoryStorage) LoadLoadTests(requestID int64) ([]LoadTestRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var runs []LoadTestRun
+	for _, run := range slices.Backward(s.loadTests) {
+		if run.RequestID == requestID {
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+// GetLoadTest returns a single load test
+func (s *MemoryStorage) GetLoadTest(id int64) (*LoadTestRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, run := range s.loadTests {
+		if run.ID == id {
+			return &run, nil
+		}
+	}
+	return nil, fmt.Errorf("load test not found: %d", id)
+}
+
+// Search looks for requests, and history entries when asked, containing
+// every word typed regardless of case. Results rank by where the words were
+// found, the name counting most, like the weights of SQLiteStorage.Search.
+func (s *MemoryStorage) Search(query SearchQuery) ([]SearchResult, error) {
+	words := strings.Fields(query.Text)
+	if len(words) == 0 {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var results []SearchResult
+	for _, id := range slices.Sorted(maps.Keys(s.requests)) {
+		request := s.requests[id]
+		fields := []string{request.Name, request.URL, searchableHeaders(&request), request.Body}
+		if result, ok := matchFields(words, fields); ok {
+			result.Kind, result.ID, result.Method = SearchRequest, id, request.Method
+			results = append(results, result)
+		}
+	}
+	if query.History {
+		for _, recorded := range slices.Backward(s.history) {
+			entry := recorded.entry
+			var responseHeaders strings.Builder
+			for key, values := range entry.Headers {
+				fmt.Fprintf(&responseHeaders, "%s: %s\n", key, strings.Join(values, ", "))
+			}
+			fields := []string{entry.Request.Name, entry.Request.URL, searchableHeaders(&entry.Request),
+				entry.Request.Body, responseHeaders.String(), entry.Body}
+			if result, ok := matchFields(words, fields); ok {
+				result.Kind, result.ID, result.Method = SearchHistory, entry.ID, entry.Request.Method
+				result.SentAt, result.StatusCode = entry.SentAt, entry.StatusCode
+				results = append(results, result)
+			}
+		}
+	}
+	return sortResults(results, query.Limit), nil
+}
+
+// searchableHeaders lists headers one per line, leaving out secret values
+func searchableHeaders(request *http.Request) string {
+	var b strings.Builder
+	for key, value := range request.Headers {
+		if request.IsSecret(key) {
+			value = ""
+		}
+		fmt.Fprintf(&b, "%s: %s\n", key, value)
+	}
+	return b.String()
+}
+
+// searchWeights are how much a word found in the name, the URL, and the
+// other fields of a request counts
+var searchWeights = []float64{10, 5}
+
+// matchFields returns a result with the name and URL (the first two fields)
+// highlighted and a snippet of the first other field that matched, when
+// every word is in one of the fields
+func matchFields(words, fields []string) (SearchResult, bool) {
+	var result SearchResult
+	for _, word := range words {
+		found := false
+		for i, field := range fields {
+			if indexFold(field, word) < 0 {
+				continue
+			}
+			found = true
+			weight := 1.0
+			if i < len(searchWeights) {
+				weight = searchWeights[i]
+			}
+			result.Rank -= weight
+		}
+		if !found {
+			return SearchResult{}, false
+		}
+	}
+	result.Name = markWords(fields[0], words)
+	result.URL = markWords(fields[1], words)
+	for _, field := range fields[2:] {
+		if snippet := markWords(snippetAround(field, words), words); strings.Contains(snippet, MatchStart) {
+			result.Snippet = snippet
+			break
+		}
+	}
+	return result, true
+}
+
+// indexFold returns where word first is in text regardless of case, or -1
+func indexFold(text, word string) int {
+	for i := range text {
+		if len(text)-i < len(word) {
+			break
+		}
+		if strings.EqualFold(text[i:i+len(word)], word) {
+			return i
+		}
+	}
+	return -1
+}
+
+// markWords wraps the words of text the words searched for start in MatchStart
+// and MatchEnd
+func markWords(text string, words []string) string {
+	var b strings.Builder
+	for text != "" {
+		next, length := -1, 0
+		for _, word := range words {
+			if i := indexFold(text, word); i >= 0 && (next < 0 || i < next) {
+				next, length = i, len(word)
+			}
+		}
+		if next < 0 {
+			b.WriteString(text)
+			break
+		}
+		// like the index, the whole word a match starts is marked
+		for next+length < len(text) {
+			r, size := utf8.DecodeRuneInString(text[next+length:])
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				break
+			}
+			length += size
+		}
+		b.WriteString(text[:next] + MatchStart + text[next:next+length] + MatchEnd)
+		text = text[next+length:]
+	}
+	return b.String()
+}
+
+// snippetAround returns the part of text around the first word found in it
+func snippetAround(text string, words []string) string {
+	const context = 40
+	first := -1
+	for _, word := range words {
+		if i := indexFold(text, word); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+	runes := []rune(text)
+	at := len([]rune(text[:first]))
+	start, end := max(at-context, 0), min(at+context, len(runes))
+	snippet := string(runes[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
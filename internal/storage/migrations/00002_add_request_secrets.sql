@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE requests ADD COLUMN secrets TEXT NOT NULL DEFAULT '[]';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS settings (
+    key TEXT PRIMARY KEY,
+    value BLOB NOT NULL
+);
+-- +goose StatementEnd
+
+-- Existing rows: well known credential headers are flagged as secret, and
+-- encrypted, the first time Volt starts with a secret key configured. Until
+-- then the rows are saved as before, see SQLiteStorage.SetVault.
+-- +goose StatementBegin
+INSERT INTO settings (key, value)
+SELECT 'legacy_secrets', 'pending' WHERE EXISTS (SELECT 1 FROM requests);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS settings;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE requests DROP COLUMN secrets;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS cookies (
+    environment TEXT NOT NULL DEFAULT 'default',
+    domain TEXT NOT NULL,
+    path TEXT NOT NULL DEFAULT '/',
+    name TEXT NOT NULL,
+    value TEXT NOT NULL,
+    expires TEXT,
+    secure INTEGER NOT NULL DEFAULT 0,
+    http_only INTEGER NOT NULL DEFAULT 0,
+    host_only INTEGER NOT NULL DEFAULT 0,
+    same_site TEXT,
+    PRIMARY KEY (environment, domain, path, name)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS cookies;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE requests ADD COLUMN options TEXT NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS environments (
+    name TEXT PRIMARY KEY,
+    options TEXT NOT NULL DEFAULT '{}'
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS environments;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE requests DROP COLUMN options;
+-- +goose StatementEnd
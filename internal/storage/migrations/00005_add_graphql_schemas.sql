@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS graphql_schemas (
+    url TEXT PRIMARY KEY,
+    schema TEXT NOT NULL,
+    fetched_at TEXT NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS graphql_schemas;
+-- +goose StatementEnd
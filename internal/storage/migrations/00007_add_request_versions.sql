@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE requests ADD COLUMN updated_at TEXT;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE requests ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE requests SET updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE requests DROP COLUMN version;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE requests DROP COLUMN updated_at;
+-- +goose StatementEnd
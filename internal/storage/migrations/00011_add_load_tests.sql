@@ -0,0 +1,27 @@
+-- +goose Up
+-- The request is the ID of a saved request, or the path of its file in a
+-- workspace.
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS load_tests (
+    id INTEGER PRIMARY KEY,
+    request TEXT NOT NULL,
+    ran_at TEXT NOT NULL,
+    label TEXT NOT NULL DEFAULT '',
+    git_sha TEXT NOT NULL DEFAULT '',
+    config TEXT NOT NULL DEFAULT '{}',
+    stats TEXT NOT NULL DEFAULT '{}'
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS load_tests_request ON load_tests (request, ran_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS load_tests_request;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS load_tests;
+-- +goose StatementEnd
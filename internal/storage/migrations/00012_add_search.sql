@@ -0,0 +1,135 @@
+-- +goose Up
+-- Full-text indexes of saved requests and history, kept up to date by
+-- triggers. They copy rows from views leaving out the values of secret
+-- headers, encrypted or not yet, as FTS5 can't read such views as external
+-- content.
+-- Requests of a workspace live in files, and are indexed again in
+-- workspace_requests_fts before each search.
+-- +goose StatementBegin
+CREATE VIEW IF NOT EXISTS requests_search AS
+SELECT id, name, url, body, method,
+    CASE WHEN json_valid(headers) THEN (
+        SELECT json_group_object(key, CASE
+            WHEN value LIKE 'enc:v1:%' THEN ''
+            WHEN json_valid(requests.secrets) AND lower(key) IN (SELECT lower(value) FROM json_each(requests.secrets)) THEN ''
+            ELSE value END)
+        FROM json_each(requests.headers)
+    ) END AS headers
+FROM requests;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE VIRTUAL TABLE IF NOT EXISTS requests_fts USING fts5 (
+    name, url, headers, body, method UNINDEXED
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO requests_fts (rowid, name, url, headers, body, method)
+SELECT id, name, url, headers, body, method FROM requests_search;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS requests_fts_insert AFTER INSERT ON requests BEGIN
+    INSERT INTO requests_fts (rowid, name, url, headers, body, method)
+    SELECT id, name, url, headers, body, method FROM requests_search WHERE id = new.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS requests_fts_delete AFTER DELETE ON requests BEGIN
+    DELETE FROM requests_fts WHERE rowid = old.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS requests_fts_update AFTER UPDATE ON requests BEGIN
+    DELETE FROM requests_fts WHERE rowid = old.id;
+    INSERT INTO requests_fts (rowid, name, url, headers, body, method)
+    SELECT id, name, url, headers, body, method FROM requests_search WHERE id = new.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE VIEW IF NOT EXISTS history_search AS
+SELECT id, name, url, body, response_headers, response_body, method,
+    CASE WHEN json_valid(headers) THEN (
+        SELECT json_group_object(key, CASE
+            WHEN value LIKE 'enc:v1:%' THEN ''
+            WHEN json_valid(history.secrets) AND lower(key) IN (SELECT lower(value) FROM json_each(history.secrets)) THEN ''
+            ELSE value END)
+        FROM json_each(history.headers)
+    ) END AS headers
+FROM history;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE VIRTUAL TABLE IF NOT EXISTS history_fts USING fts5 (
+    name, url, headers, body, response_headers, response_body, method UNINDEXED
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+INSERT INTO history_fts (rowid, name, url, headers, body, response_headers, response_body, method)
+SELECT id, name, url, headers, body, response_headers, response_body, method FROM history_search;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS history_fts_insert AFTER INSERT ON history BEGIN
+    INSERT INTO history_fts (rowid, name, url, headers, body, response_headers, response_body, method)
+    SELECT id, name, url, headers, body, response_headers, response_body, method FROM history_search WHERE id = new.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS history_fts_delete AFTER DELETE ON history BEGIN
+    DELETE FROM history_fts WHERE rowid = old.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE VIRTUAL TABLE IF NOT EXISTS workspace_requests_fts USING fts5 (
+    name, url, headers, body, method UNINDEXED
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS workspace_requests_fts;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS history_fts_delete;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS history_fts_insert;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS history_fts;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP VIEW IF EXISTS history_search;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS requests_fts_update;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS requests_fts_delete;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS requests_fts_insert;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS requests_fts;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP VIEW IF EXISTS requests_search;
+-- +goose StatementEnd
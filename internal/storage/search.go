@@ -0,0 +1,228 @@
+package storage
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/owenHochwald/volt/internal/http"
+)
+
+// Matches in search results are wrapped in MatchStart and MatchEnd, see Highlight
+const (
+	MatchStart = "\x02"
+	MatchEnd   = "\x03"
+)
+
+// searchSnippetTokens is about how many words of a header or body are shown around a match
+const searchSnippetTokens = 12
+
+// SearchKind is what a search result was found in
+type SearchKind string
+
+const (
+	SearchRequest SearchKind = "request"
+	SearchHistory SearchKind = "history"
+)
+
+// SearchQuery looks for saved requests, and optionally sent ones with their
+// responses, containing every word of Text. Words match the start of words.
+type SearchQuery struct {
+	Text    string
+	History bool
+	Limit   int
+}
+
+// SearchResult is a saved request or history entry that matched a search,
+// with its matches wrapped in MatchStart and MatchEnd
+type SearchResult struct {
+	Kind    SearchKind `json:"kind"`
+	ID      int64      `json:"id"` // of the saved request or the history entry
+	Method  string     `json:"method"`
+	Name    string     `json:"name"`
+	URL     string     `json:"url"`
+	Snippet string     `json:"snippet,omitempty"` // the part of the headers or bodies that matched
+	Rank    float64    `json:"rank"`              // lower ranks first
+
+	// history entries only
+	SentAt     time.Time `json:"sent_at,omitzero"`
+	StatusCode int       `json:"status_code,omitempty"`
+}
+
+// Highlight replaces the matches marked in text with what mark makes of them
+func Highlight(text string, mark func(match string) string) string {
+	var b strings.Builder
+	for {
+		before, rest, found := strings.Cut(text, MatchStart)
+		b.WriteString(before)
+		if !found {
+			return b.String()
+		}
+		match, after, _ := strings.Cut(rest, MatchEnd)
+		b.WriteString(mark(match))
+		text = after
+	}
+}
+
+// ftsQuery turns the words typed into an FTS5 query matching every one of
+// them as a prefix, so that punctuation in URLs isn't read as syntax
+func ftsQuery(text string) string {
+	words := strings.Fields(text)
+	for i, word := range words {
+		words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"*`
+	}
+	return strings.Join(words, " ")
+}
+
+// sortResults ranks results found in different places together, keeping limit of them
+func sortResults(results []SearchResult, limit int) []SearchResult {
+	slices.SortStableFunc(results, func(a, b SearchResult) int {
+		return cmp.Compare(a.Rank, b.Rank)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// firstMatch returns the first snippet with a match in it
+func firstMatch(snippets ...string) string {
+	for _, snippet := range snippets {
+		if strings.Contains(snippet, MatchStart) {
+			return snippet
+		}
+	}
+	return ""
+}
+
+// Search looks for saved requests, and history entries when asked, by
+// their name, URL, headers and bodies, the best matches first
+func (s *SQLiteStorage) Search(query SearchQuery) ([]SearchResult, error) {
+	return s.search("requests_fts", query)
+}
+
+// search searches an index of saved requests, then the history when asked
+func (s *SQLiteStorage) search(table string, query SearchQuery) ([]SearchResult, error) {
+	results, err := s.searchRequests(table, query)
+	if err != nil || !query.History {
+		return results, err
+	}
+	history, err := s.searchHistory(query)
+	if err != nil {
+		return nil, err
+	}
+	return sortResults(append(results, history...), query.Limit), nil
+}
+
+// searchRequests searches one of the indexes of saved requests
+func (s *SQLiteStorage) searchRequests(table string, query SearchQuery) ([]SearchResult, error) {
+	match := ftsQuery(query.Text)
+	if match == "" {
+		return nil, nil
+	}
+	q := fmt.Sprintf(`SELECT rowid, method,
+			coalesce(highlight(%[1]s, 0, ?1, ?2), ''), highlight(%[1]s, 1, ?1, ?2),
+			coalesce(snippet(%[1]s, 2, ?1, ?2, '…', ?3), ''), coalesce(snippet(%[1]s, 3, ?1, ?2, '…', ?3), ''),
+			bm25(%[1]s, 10.0, 5.0, 1.0, 1.0)
+		FROM %[1]s WHERE %[1]s MATCH ?4 ORDER BY 7`, table)
+	args := []any{MatchStart, MatchEnd, searchSnippetTokens, match}
+	if query.Limit > 0 {
+		q += ` LIMIT ?5`
+		args = append(args, query.Limit)
+	}
+
+	rows, err := s.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		result := SearchResult{Kind: SearchRequest}
+		var headers, body string
+		if err := rows.Scan(&result.ID, &result.Method, &result.Name, &result.URL, &headers, &body, &result.Rank); err != nil {
+			return nil, err
+		}
+		result.Snippet = firstMatch(headers, body)
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+// searchHistory searches the requests sent and the responses they got
+func (s *SQLiteStorage) searchHistory(query SearchQuery) ([]SearchResult, error) {
+	match := ftsQuery(query.Text)
+	if match == "" {
+		return nil, nil
+	}
+	q := `SELECT history_fts.rowid, history_fts.method,
+			highlight(history_fts, 0, ?1, ?2), highlight(history_fts, 1, ?1, ?2),
+			coalesce(snippet(history_fts, 2, ?1, ?2, '…', ?3), ''), snippet(history_fts, 3, ?1, ?2, '…', ?3),
+			snippet(history_fts, 4, ?1, ?2, '…', ?3), snippet(history_fts, 5, ?1, ?2, '…', ?3),
+			bm25(history_fts, 10.0, 5.0, 1.0, 1.0, 1.0, 1.0), history.sent_at, history.status_code
+		FROM history_fts JOIN history ON history.id = history_fts.rowid
+		WHERE history_fts MATCH ?4 ORDER BY 9`
+	args := []any{MatchStart, MatchEnd, searchSnippetTokens, match}
+	if query.Limit > 0 {
+		q += ` LIMIT ?5`
+		args = append(args, query.Limit)
+	}
+
+	rows, err := s.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		result := SearchResult{Kind: SearchHistory}
+		var headers, body, responseHeaders, responseBody, sentAt string
+		err := rows.Scan(&result.ID, &result.Method, &result.Name, &result.URL,
+			&headers, &body, &responseHeaders, &responseBody, &result.Rank, &sentAt, &result.StatusCode)
+		if err != nil {
+			return nil, err
+		}
+		if result.SentAt, err = time.Parse(historyTimeFormat, sentAt); err != nil {
+			return nil, err
+		}
+		result.Snippet = firstMatch(headers, body, responseHeaders, responseBody)
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+// indexWorkspace replaces the index of the requests of a workspace, secret
+// header values left out
+func (s *SQLiteStorage) indexWorkspace(requests []http.Request) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM workspace_requests_fts`); err != nil {
+		return err
+	}
+	q := `INSERT INTO workspace_requests_fts (rowid, name, url, headers, body, method) VALUES (?, ?, ?, ?, ?, ?)`
+	for _, request := range requests {
+		headers := make(map[string]string, len(request.Headers))
+		for key, value := range request.Headers {
+			if request.IsSecret(key) {
+				value = ""
+			}
+			headers[key] = value
+		}
+		serialized, err := serializeHeaders(headers)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(q, request.ID, request.Name, request.URL, serialized, request.Body, request.Method); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -4,11 +4,18 @@ import (
 	"database/sql"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/secrets"
 	"github.com/pressly/goose/v3"
 	_ "modernc.org/sqlite"
 )
@@ -16,8 +23,22 @@ import (
 //go:embed migrations/*.sql
 var embedMigrations embed.FS
 
+const (
+	settingSecretSalt    = "secret_salt"
+	settingSecretCheck   = "secret_check"
+	settingLegacySecrets = "legacy_secrets" // set by the secrets migration for the requests saved before it
+
+	// secretCheckValue is sealed once to detect a wrong key on later runs
+	secretCheckValue = "volt"
+)
+
+// ErrConflict is returned when saving a request that was saved elsewhere,
+// e.g. by another instance of volt, since it was loaded
+var ErrConflict = errors.New("the request was changed elsewhere since it was loaded")
+
 type SQLiteStorage struct {
-	db *sql.DB
+	db    *sql.DB
+	vault *secrets.Vault
 }
 
 func serializeHeaders(headers map[string]string) (string, error) {
@@ -36,6 +57,67 @@ func deserializeHeaders(jsonStr string) (map[string]string, error) {
 	return headers, nil
 }
 
+func serializeSecrets(keys []string) (string, error) {
+	if keys == nil {
+		keys = []string{}
+	}
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func deserializeSecrets(jsonStr string) ([]string, error) {
+	var keys []string
+	if err := json.Unmarshal([]byte(jsonStr), &keys); err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	return keys, nil
+}
+
+func serializeOptions(options map[string]string) (string, error) {
+	if options == nil {
+		return "{}", nil
+	}
+	return serializeHeaders(options)
+}
+
+func deserializeOptions(jsonStr string) (map[string]string, error) {
+	options, err := deserializeHeaders(jsonStr)
+	if err != nil || len(options) == 0 {
+		return nil, err
+	}
+	return options, nil
+}
+
+// sealHeaders encrypts the values of secret headers, see sealHeaders
+func (s *SQLiteStorage) sealHeaders(request *http.Request) error {
+	return sealHeaders(s.vault, request)
+}
+
+// sealHeaders replaces the headers of the request with a copy holding the
+// values of secret headers encrypted with vault. The map the request had is
+// left alone, as the request pane may still be reading it.
+func sealHeaders(vault *secrets.Vault, request *http.Request) error {
+	headers := maps.Clone(request.Headers)
+	for key, value := range headers {
+		if !request.IsSecret(key) || secrets.IsSealed(value) {
+			continue
+		}
+		sealed, err := vault.Seal(value)
+		if err != nil {
+			return fmt.Errorf("header %s: %w", key, err)
+		}
+		headers[key] = sealed
+	}
+	request.Headers = headers
+	return nil
+}
+
 func runMigrations(db *sql.DB) error {
 	// Set the embedded filesystem for goose
 	goose.SetBaseFS(embedMigrations)
@@ -77,27 +159,218 @@ func (s *SQLiteStorage) Close() error {
 	return s.db.Close()
 }
 
+// SecretSalt returns the salt used to derive a key from a passphrase, creating it on first use
+func (s *SQLiteStorage) SecretSalt() ([]byte, error) {
+	var salt []byte
+	err := s.db.QueryRow(`SELECT value FROM settings WHERE key = ?`, settingSecretSalt).Scan(&salt)
+	if err == nil {
+		return salt, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	salt, err = secrets.NewSalt()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.db.Exec(`INSERT INTO settings (key, value) VALUES (?, ?)`, settingSecretSalt, salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// SetVault configures the key used to encrypt secrets. The key is checked
+// against previously stored secrets, and any secret values still stored as
+// plaintext are encrypted, credential headers of requests saved before
+// secrets existed included.
+func (s *SQLiteStorage) SetVault(vault *secrets.Vault) error {
+	if vault == nil {
+		s.vault = nil
+		return nil
+	}
+
+	var check string
+	err := s.db.QueryRow(`SELECT value FROM settings WHERE key = ?`, settingSecretCheck).Scan(&check)
+	switch {
+	case err == sql.ErrNoRows:
+		sealed, err := vault.Seal(secretCheckValue)
+		if err != nil {
+			return err
+		}
+		if _, err := s.db.Exec(`INSERT INTO settings (key, value) VALUES (?, ?)`, settingSecretCheck, sealed); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		if opened, err := vault.Open(check); err != nil || opened != secretCheckValue {
+			return secrets.ErrWrongKey
+		}
+	}
+
+	s.vault = vault
+	if err := s.flagLegacySecrets(); err != nil {
+		return err
+	}
+	return s.sealPlaintextSecrets()
+}
+
+// legacyCredentialHeaders are flagged as secret in requests saved before
+// secrets existed
+var legacyCredentialHeaders = []string{"authorization", "proxy-authorization", "cookie", "x-api-key", "api-key"}
+
+// flagLegacySecrets marks the credential headers of requests saved before
+// secrets existed as secret, once a key can encrypt them. Without one, those
+// requests keep being saved in plaintext as they were.
+func (s *SQLiteStorage) flagLegacySecrets() error {
+	var pending string
+	err := s.db.QueryRow(`SELECT value FROM settings WHERE key = ?`, settingLegacySecrets).Scan(&pending)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	requests, err := s.Load()
+	if err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, request := range requests {
+		flagged := false
+		for key := range request.Headers {
+			if slices.Contains(legacyCredentialHeaders, strings.ToLower(key)) && !request.IsSecret(key) {
+				request.Secrets = append(request.Secrets, key)
+				flagged = true
+			}
+		}
+		if !flagged {
+			continue
+		}
+		secretString, err := serializeSecrets(request.Secrets)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE requests SET secrets = ? WHERE id = ?`, secretString, request.ID); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM settings WHERE key = ?`, settingLegacySecrets); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// sealPlaintextSecrets encrypts secret values stored before a key was configured
+func (s *SQLiteStorage) sealPlaintextSecrets() error {
+	requests, err := s.Load()
+	if err != nil {
+		return err
+	}
+
+	for _, request := range requests {
+		if len(request.Secrets) == 0 {
+			continue
+		}
+		pending := false
+		for key, value := range request.Headers {
+			if request.IsSecret(key) && !secrets.IsSealed(value) {
+				pending = true
+			}
+		}
+		if !pending {
+			continue
+		}
+
+		if err := s.sealHeaders(&request); err != nil {
+			return err
+		}
+		headerString, err := serializeHeaders(request.Headers)
+		if err != nil {
+			return err
+		}
+		if _, err := s.db.Exec(`UPDATE requests SET headers = ? WHERE id = ?`, headerString, request.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save inserts a new request, or updates the saved request with the same ID.
+// An update fails with ErrConflict when the request was saved elsewhere
+// since it was loaded, unless its Version is 0.
 func (s *SQLiteStorage) Save(request *http.Request) error {
+	if err := s.sealHeaders(request); err != nil {
+		return err
+	}
 	headerString, err := serializeHeaders(request.Headers)
 	if err != nil {
 		return err
 	}
-	q := `INSERT INTO requests (name, method, url, headers, body) VALUES (?, ?, ?, ?, ?)`
-
-	res, err := s.db.Exec(q, request.Name, request.Method, request.URL, headerString, request.Body)
+	secretString, err := serializeSecrets(request.Secrets)
+	if err != nil {
+		return err
+	}
+	optionString, err := serializeOptions(request.Options)
 	if err != nil {
 		return err
 	}
-	id, err := res.LastInsertId()
+	now := time.Now().UTC()
+	updatedAt := now.Format(time.RFC3339Nano)
+
+	if request.ID == 0 {
+		q := `INSERT INTO requests (name, method, url, headers, body, secrets, options, collection_id, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+		res, err := s.db.Exec(q, request.Name, request.Method, request.URL, headerString, request.Body, secretString, optionString,
+			nullID(request.CollectionID), updatedAt)
+		if err != nil {
+			return err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		request.ID, request.Version, request.UpdatedAt = id, 1, now
+		return nil
+	}
+
+	// The collection is left alone, requests are moved with MoveRequest
+	q := `UPDATE requests SET name = ?, method = ?, url = ?, headers = ?, body = ?, secrets = ?, options = ?,
+			updated_at = ?, version = version + 1
+		WHERE id = ? AND (? = 0 OR version = ?)
+		RETURNING version`
+	var version int64
+	err = s.db.QueryRow(q, request.Name, request.Method, request.URL, headerString, request.Body, secretString, optionString,
+		updatedAt, request.ID, request.Version, request.Version).Scan(&version)
+	if err == sql.ErrNoRows {
+		var found int64
+		if err := s.db.QueryRow(`SELECT id FROM requests WHERE id = ?`, request.ID).Scan(&found); err == sql.ErrNoRows {
+			return fmt.Errorf("request not found: %d", request.ID)
+		}
+		return ErrConflict
+	}
 	if err != nil {
 		return err
 	}
-	request.ID = id
+	request.Version, request.UpdatedAt = version, now
 	return nil
 }
 
+// SaveAs saves the request as a new one, leaving the request it was loaded from as it was
+func (s *SQLiteStorage) SaveAs(request *http.Request) error {
+	request.ID, request.Version = 0, 0
+	return s.Save(request)
+}
+
 func (s *SQLiteStorage) Load() ([]http.Request, error) {
-	q := `SELECT id, name, method, url, headers, body FROM requests`
+	q := `SELECT id, name, method, url, headers, body, secrets, options, collection_id, version, updated_at FROM requests`
 	rows, err := s.db.Query(q)
 	if err != nil {
 		return nil, err
@@ -108,15 +381,20 @@ func (s *SQLiteStorage) Load() ([]http.Request, error) {
 
 	for rows.Next() {
 		var (
-			id      int64
-			name    string
-			method  string
-			url     string
-			headers string
-			body    string
+			id         int64
+			name       string
+			method     string
+			url        string
+			headers    string
+			body       string
+			secretList string
+			optionList string
+			collection sql.NullInt64
+			version    int64
+			updated    sql.NullString
 		)
 
-		if err := rows.Scan(&id, &name, &method, &url, &headers, &body); err != nil {
+		if err := rows.Scan(&id, &name, &method, &url, &headers, &body, &secretList, &optionList, &collection, &version, &updated); err != nil {
 			return nil, err
 
 		}
@@ -124,6 +402,20 @@ func (s *SQLiteStorage) Load() ([]http.Request, error) {
 		if err != nil {
 			return nil, err
 		}
+		secretKeys, err := deserializeSecrets(secretList)
+		if err != nil {
+			return nil, err
+		}
+		options, err := deserializeOptions(optionList)
+		if err != nil {
+			return nil, err
+		}
+		var updatedAt time.Time
+		if updated.Valid {
+			if updatedAt, err = time.Parse(time.RFC3339Nano, updated.String); err != nil {
+				return nil, err
+			}
+		}
 		request := http.Request{
 			ID:      id,
 			Name:    name,
@@ -131,6 +423,12 @@ func (s *SQLiteStorage) Load() ([]http.Request, error) {
 			URL:     url,
 			Headers: headersMap,
 			Body:    body,
+			Secrets: secretKeys,
+			Options: options,
+
+			CollectionID: collection.Int64,
+			Version:      version,
+			UpdatedAt:    updatedAt,
 		}
 		requests = append(requests, request)
 	}
@@ -138,8 +436,14 @@ func (s *SQLiteStorage) Load() ([]http.Request, error) {
 }
 
 func (s *SQLiteStorage) Delete(id int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	q := `DELETE FROM requests WHERE id = ?`
-	res, err := s.db.Exec(q, id)
+	res, err := tx.Exec(q, id)
 	if err != nil {
 		return err
 	}
@@ -150,7 +454,11 @@ func (s *SQLiteStorage) Delete(id int64) error {
 	if rows == 0 {
 		return fmt.Errorf("request not found: %d", id)
 	}
-	return nil
+	// its load test runs go with it
+	if _, err := tx.Exec(`DELETE FROM load_tests WHERE request = ?`, strconv.FormatInt(id, 10)); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (s *SQLiteStorage) GetAllURLs() ([]string, error) {
@@ -1,12 +1,19 @@
 package storage
 
 import (
+	"bytes"
+	"database/sql"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/pressly/goose/v3"
 	_ "modernc.org/sqlite"
 
 	"github.com/alecthomas/assert/v2"
 	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/secrets"
 )
 
 func setupTestDB(t *testing.T) *SQLiteStorage {
@@ -199,3 +206,567 @@ func TestSQLiteStorage_GetAllURLs_NoURLs(t *testing.T) {
 	assert.Equal(t, len(requests), len(urls))
 	assert.NoError(t, err)
 }
+
+func testVault(t *testing.T, b byte) *secrets.Vault {
+	t.Helper()
+	vault, err := secrets.NewVault(bytes.Repeat([]byte{b}, secrets.KeySize))
+	if err != nil {
+		t.Fatalf("failed to create vault: %v", err)
+	}
+	return vault
+}
+
+func TestSQLiteStorage_SaveSecretWithoutKey(t *testing.T) {
+	db := setupTestDB(t)
+
+	req := &http.Request{
+		Name:    "secret",
+		Method:  "GET",
+		URL:     "http://localhost:8080",
+		Headers: map[string]string{"Authorization": "Bearer token"},
+		Secrets: []string{"Authorization"},
+	}
+
+	err := db.Save(req)
+	assert.IsError(t, err, secrets.ErrNoKey)
+}
+
+func TestSQLiteStorage_SaveSecretEncrypted(t *testing.T) {
+	db := setupTestDB(t)
+	vault := testVault(t, 1)
+	assert.NoError(t, db.SetVault(vault))
+
+	req := &http.Request{
+		Name:   "secret",
+		Method: "GET",
+		URL:    "http://localhost:8080",
+		Headers: map[string]string{
+			"Authorization": "Bearer token",
+			"Accept":        "application/json",
+		},
+		Secrets: []string{"Authorization"},
+	}
+	assert.NoError(t, db.Save(req))
+
+	var raw string
+	assert.NoError(t, db.db.QueryRow(`SELECT headers FROM requests WHERE id = ?`, req.ID).Scan(&raw))
+	assert.NotContains(t, raw, "Bearer token")
+	assert.Contains(t, raw, "application/json")
+
+	requests, err := db.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(requests))
+	assert.Equal(t, []string{"Authorization"}, requests[0].Secrets)
+	assert.True(t, secrets.IsSealed(requests[0].Headers["Authorization"]))
+
+	unsealed, err := requests[0].Unseal(vault)
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer token", unsealed.Headers["Authorization"])
+}
+
+func TestSQLiteStorage_SetVaultWrongKey(t *testing.T) {
+	db := setupTestDB(t)
+
+	assert.NoError(t, db.SetVault(testVault(t, 1)))
+	assert.IsError(t, db.SetVault(testVault(t, 2)), secrets.ErrWrongKey)
+}
+
+func TestSQLiteStorage_SecretSaltIsStable(t *testing.T) {
+	db := setupTestDB(t)
+
+	first, err := db.SecretSalt()
+	assert.NoError(t, err)
+	second, err := db.SecretSalt()
+	assert.NoError(t, err)
+
+	assert.Equal(t, secrets.SaltSize, len(first))
+	assert.Equal(t, first, second)
+}
+
+func TestSQLiteStorage_SecretsMigration(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "volt.db")
+
+	// Simulate a database created before secrets existed
+	raw, err := sql.Open("sqlite", dbPath)
+	assert.NoError(t, err)
+	goose.SetBaseFS(embedMigrations)
+	assert.NoError(t, goose.SetDialect("sqlite3"))
+	assert.NoError(t, goose.UpTo(raw, "migrations", 1))
+	_, err = raw.Exec(
+		`INSERT INTO requests (name, method, url, headers, body) VALUES (?, ?, ?, ?, ?)`,
+		"legacy", "GET", "http://localhost", `{"Authorization":"Bearer old","Accept":"*/*"}`, "",
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, raw.Close())
+
+	store, err := NewSQLiteStorage(dbPath)
+	assert.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	// Without a key, the request is saved as it was
+	requests, err := store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(requests))
+	assert.Equal(t, 0, len(requests[0].Secrets))
+	assert.Equal(t, "Bearer old", requests[0].Headers["Authorization"])
+	requests[0].Name = "renamed"
+	assert.NoError(t, store.Save(&requests[0]))
+
+	// Configuring a key flags the credential header and encrypts it
+	assert.NoError(t, store.SetVault(testVault(t, 1)))
+	requests, err = store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Authorization"}, requests[0].Secrets)
+	assert.True(t, secrets.IsSealed(requests[0].Headers["Authorization"]))
+	assert.Equal(t, "*/*", requests[0].Headers["Accept"])
+
+	// only once: a header made public again stays so
+	requests[0].Secrets = nil
+	requests[0].Headers["Authorization"] = "Bearer public"
+	assert.NoError(t, store.Save(&requests[0]))
+	assert.NoError(t, store.SetVault(testVault(t, 1)))
+	requests, err = store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(requests[0].Secrets))
+	assert.Equal(t, "Bearer public", requests[0].Headers["Authorization"])
+}
+
+func TestSQLiteStorage_SearchLeavesOutPlaintextSecrets(t *testing.T) {
+	store := setupTestDB(t)
+
+	// a secret value not encrypted yet
+	_, err := store.db.Exec(
+		`INSERT INTO requests (name, method, url, headers, body, secrets) VALUES (?, ?, ?, ?, ?, ?)`,
+		"legacy", "GET", "http://localhost", `{"Authorization":"Bearer hunter2","Accept":"*/*"}`, "", `["Authorization"]`,
+	)
+	assert.NoError(t, err)
+
+	search := func(text string) int {
+		t.Helper()
+		results, err := store.Search(SearchQuery{Text: text})
+		assert.NoError(t, err)
+		return len(results)
+	}
+	assert.Equal(t, 1, search("legacy"))
+	assert.Equal(t, 0, search("hunter2"))
+
+	// nor when the row is indexed again
+	_, err = store.db.Exec(`UPDATE requests SET name = 'renamed'`)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, search("renamed"))
+	assert.Equal(t, 0, search("hunter2"))
+	assert.Equal(t, 1, search("accept"))
+
+	// secret keys match headers whatever their case
+	_, err = store.db.Exec(`UPDATE requests SET secrets = '["authorization"]'`)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, search("hunter2"))
+	assert.Equal(t, 1, search("accept"))
+
+	// and so do those of the requests of a workspace
+	assert.NoError(t, store.indexWorkspace([]http.Request{{
+		ID: 1, Name: "workspace", Method: http.GET, URL: "http://localhost",
+		Headers: map[string]string{"Authorization": "Bearer hunter2"}, Secrets: []string{"AUTHORIZATION"},
+	}}))
+	results, err := store.search("workspace_requests_fts", SearchQuery{Text: "hunter2"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(results))
+	results, err = store.search("workspace_requests_fts", SearchQuery{Text: "workspace"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(results))
+}
+
+func TestSQLiteStorage_Cookies(t *testing.T) {
+	db := setupTestDB(t)
+
+	cookies := []http.Cookie{
+		{Name: "session", Value: "abc", Domain: "example.com", Path: "/", HttpOnly: true, HostOnly: true},
+		{Name: "theme", Value: "dark", Domain: "example.com", Path: "/", Expires: time.Now().Add(time.Hour).UTC().Truncate(time.Second), SameSite: "Lax"},
+		{Name: "stale", Value: "x", Domain: "example.com", Path: "/", Expires: time.Now().Add(-time.Hour).UTC().Truncate(time.Second)},
+	}
+
+	assert.NoError(t, db.SaveCookies(DefaultEnvironment, cookies))
+	assert.NoError(t, db.SaveCookies("staging", cookies[:1]))
+
+	loaded, err := db.LoadCookies(DefaultEnvironment)
+	assert.NoError(t, err)
+	assert.Equal(t, cookies[:2], loaded)
+
+	staging, err := db.LoadCookies("staging")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(staging))
+
+	assert.NoError(t, db.ClearCookies(DefaultEnvironment))
+	loaded, err = db.LoadCookies(DefaultEnvironment)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(loaded))
+}
+
+func TestSQLiteStorage_CookiesEncrypted(t *testing.T) {
+	db := setupTestDB(t)
+	assert.NoError(t, db.SetVault(testVault(t, 1)))
+
+	cookies := []http.Cookie{{Name: "session", Value: "abc", Domain: "example.com", Path: "/"}}
+	assert.NoError(t, db.SaveCookies(DefaultEnvironment, cookies))
+
+	var raw string
+	assert.NoError(t, db.db.QueryRow(`SELECT value FROM cookies`).Scan(&raw))
+	assert.True(t, secrets.IsSealed(raw))
+
+	loaded, err := db.LoadCookies(DefaultEnvironment)
+	assert.NoError(t, err)
+	assert.Equal(t, cookies, loaded)
+}
+
+func TestSQLiteStorage_RequestOptions(t *testing.T) {
+	db := setupTestDB(t)
+
+	req := &http.Request{
+		Name:    "internal",
+		Method:  "GET",
+		URL:     "https://internal.example",
+		Headers: map[string]string{},
+		Body:    "{}",
+		Options: map[string]string{http.OptTLSCA: "/etc/ca.pem", http.OptTLSMin: "1.2"},
+	}
+	assert.NoError(t, db.Save(req))
+
+	requests, err := db.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, req.Options, requests[0].Options)
+}
+
+func TestSQLiteStorage_WebSocketRequest(t *testing.T) {
+	db := setupTestDB(t)
+
+	req := &http.Request{
+		Name:    "ticker",
+		Method:  http.WS,
+		URL:     "wss://stream.example/ticker",
+		Headers: map[string]string{"Origin": "https://example.com"},
+		Body:    `{"subscribe": "BTC"}`, // the message draft
+		Options: map[string]string{http.OptWSSubprotocols: "graphql-ws"},
+	}
+	assert.NoError(t, db.Save(req))
+
+	requests, err := db.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, *req, requests[0])
+}
+
+func TestSQLiteStorage_EnvironmentOptions(t *testing.T) {
+	db := setupTestDB(t)
+
+	options, err := db.LoadEnvironmentOptions("staging")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(options))
+
+	assert.NoError(t, db.SaveEnvironmentOptions("staging", map[string]string{http.OptTLSInsecure: "true"}))
+	assert.NoError(t, db.SaveEnvironmentOptions("staging", map[string]string{http.OptTLSCA: "/etc/ca.pem"}))
+	assert.NoError(t, db.SaveEnvironmentOptions(DefaultEnvironment, nil))
+
+	options, err = db.LoadEnvironmentOptions("staging")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{http.OptTLSCA: "/etc/ca.pem"}, options)
+
+	names, err := db.ListEnvironments()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{DefaultEnvironment, "staging"}, names)
+}
+
+func TestSQLiteStorage_EnvironmentVariables(t *testing.T) {
+	db := setupTestDB(t)
+
+	variables, err := db.LoadEnvironmentVariables("staging")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(variables))
+
+	assert.NoError(t, db.SaveEnvironmentOptions("staging", map[string]string{http.OptTLSInsecure: "true"}))
+	assert.NoError(t, db.SaveEnvironmentVariables("staging", map[string]string{"base_url": "https://staging.example"}))
+
+	variables, err = db.LoadEnvironmentVariables("staging")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"base_url": "https://staging.example"}, variables)
+	options, err := db.LoadEnvironmentOptions("staging")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{http.OptTLSInsecure: "true"}, options, "options are kept")
+}
+
+func TestSQLiteStorage_GraphQLSchema(t *testing.T) {
+	db := setupTestDB(t)
+
+	schema, _, err := db.LoadGraphQLSchema("https://api.example/graphql")
+	assert.NoError(t, err)
+	assert.Zero(t, schema)
+
+	saved := &http.GraphQLSchema{
+		QueryType: "Query",
+		Types: []http.GraphQLType{{
+			Kind:   "OBJECT",
+			Name:   "Query",
+			Fields: []http.GraphQLField{{Name: "user", Args: []string{"id"}, Type: "User"}},
+		}},
+	}
+	assert.NoError(t, db.SaveGraphQLSchema("https://api.example/graphql", saved))
+
+	// fetching again replaces the cached schema
+	saved.MutationType = "Mutation"
+	assert.NoError(t, db.SaveGraphQLSchema("https://api.example/graphql", saved))
+
+	schema, fetchedAt, err := db.LoadGraphQLSchema("https://api.example/graphql")
+	assert.NoError(t, err)
+	assert.Equal(t, saved, schema)
+	assert.True(t, time.Since(fetchedAt) < time.Minute)
+
+	schema, _, err = db.LoadGraphQLSchema("https://other.example/graphql")
+	assert.NoError(t, err)
+	assert.Zero(t, schema)
+}
+
+func TestSQLiteStorage_Collections(t *testing.T) {
+	db := setupTestDB(t)
+
+	api := &http.Collection{Name: "API", BaseURL: "https://api.example", Headers: map[string]string{"Accept": "application/json"}}
+	assert.NoError(t, db.CreateCollection(api))
+	assert.NotEqual(t, 0, api.ID)
+	users := &http.Collection{Name: "users", ParentID: api.ID, BaseURL: "/users"}
+	assert.NoError(t, db.CreateCollection(users))
+	other := &http.Collection{Name: "other"}
+	assert.NoError(t, db.CreateCollection(other))
+
+	assert.Error(t, db.CreateCollection(&http.Collection{Name: "orphan", ParentID: 999}))
+	assert.Error(t, db.CreateCollection(&http.Collection{Name: " "}))
+	assert.Error(t, db.CreateCollection(&http.Collection{Name: "secret", Auth: "Bearer abc"}), "auth is encrypted, which needs a key")
+
+	req := &http.Request{Name: "list", Method: "GET", URL: "?page=1", Headers: map[string]string{}, CollectionID: users.ID}
+	assert.NoError(t, db.Save(req))
+	loose := &http.Request{Name: "loose", Method: "GET", URL: "http://localhost", Headers: map[string]string{}}
+	assert.NoError(t, db.Save(loose))
+
+	requests, err := db.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, users.ID, requests[0].CollectionID)
+	assert.Equal(t, int64(0), requests[1].CollectionID)
+
+	collections, err := db.LoadCollections()
+	assert.NoError(t, err)
+	assert.Equal(t, []http.Collection{*api, *other, *users}, collections)
+
+	assert.NoError(t, db.RenameCollection(other.ID, "Other"))
+	assert.Error(t, db.RenameCollection(999, "missing"))
+
+	// A collection can't be moved into its own folders
+	assert.Error(t, db.MoveCollection(api.ID, users.ID))
+	assert.Error(t, db.MoveCollection(api.ID, api.ID))
+	assert.NoError(t, db.MoveCollection(other.ID, users.ID))
+
+	assert.NoError(t, db.MoveRequest(loose.ID, other.ID))
+	assert.Error(t, db.MoveRequest(loose.ID, 999))
+	assert.Error(t, db.MoveRequest(999, other.ID))
+
+	// Deleting a collection deletes its folders and the requests inside
+	assert.NoError(t, db.DeleteCollection(api.ID))
+	collections, err = db.LoadCollections()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(collections))
+	requests, err = db.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(requests))
+	assert.Error(t, db.DeleteCollection(api.ID))
+}
+
+func TestSQLiteStorage_CollectionDefaults(t *testing.T) {
+	db := setupTestDB(t)
+	assert.NoError(t, db.SetVault(testVault(t, 1)))
+
+	collection := &http.Collection{Name: "API"}
+	assert.NoError(t, db.CreateCollection(collection))
+
+	collection.BaseURL = "https://api.example"
+	collection.Headers = map[string]string{"Accept": "application/json"}
+	collection.Auth = "Bearer abc"
+	assert.NoError(t, db.SetCollectionDefaults(collection))
+	assert.True(t, secrets.IsSealed(collection.Auth))
+
+	collections, err := db.LoadCollections()
+	assert.NoError(t, err)
+	assert.Equal(t, []http.Collection{*collection}, collections)
+
+	collection.BaseURL = "api.example"
+	assert.Error(t, db.SetCollectionDefaults(collection))
+}
+
+func TestSQLiteStorage_SaveUpdatesInPlace(t *testing.T) {
+	db := setupTestDB(t)
+
+	req := &http.Request{Name: "users", Method: "GET", URL: "http://localhost/users", Headers: map[string]string{}}
+	assert.NoError(t, db.Save(req))
+	id := req.ID
+	assert.Equal(t, int64(1), req.Version)
+	assert.False(t, req.UpdatedAt.IsZero())
+
+	req.URL = "http://localhost/users?page=2"
+	assert.NoError(t, db.Save(req))
+	assert.Equal(t, id, req.ID, "saving a loaded request keeps its ID")
+	assert.Equal(t, int64(2), req.Version)
+
+	requests, err := db.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, []http.Request{*req}, requests)
+
+	// Another instance saving the same version loses
+	stale := requests[0]
+	stale.Headers = map[string]string{}
+	req.Name = "all users"
+	assert.NoError(t, db.Save(req))
+	stale.Name = "users, page 2"
+	assert.IsError(t, db.Save(&stale), ErrConflict)
+
+	// unless it doesn't check
+	stale.Version = 0
+	assert.NoError(t, db.Save(&stale))
+	assert.Equal(t, int64(4), stale.Version)
+
+	// Saving as new leaves the original alone
+	copied := stale
+	assert.NoError(t, db.SaveAs(&copied))
+	assert.NotEqual(t, id, copied.ID)
+	assert.Equal(t, int64(1), copied.Version)
+	requests, err = db.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(requests))
+
+	assert.NoError(t, db.Delete(id))
+	assert.Error(t, db.Save(req), "the request was deleted")
+}
+
+func TestSQLiteStorage_History(t *testing.T) {
+	db := setupTestDB(t)
+	assert.NoError(t, db.SetHistoryRetention(HistoryRetention{}))
+
+	sent := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	add := func(method, url string, status int, sentAt time.Time) *HistoryEntry {
+		t.Helper()
+		request := &http.Request{
+			Method:  method,
+			URL:     url,
+			Headers: map[string]string{"Authorization": "Bearer abc", "Accept": "*/*"},
+			Secrets: []string{"Authorization"},
+		}
+		response := &http.Response{StatusCode: status, Headers: map[string][]string{"Content-Type": {"text/plain"}}, Body: "ok", Duration: time.Second}
+		entry := NewHistoryEntry(request, response, sentAt)
+		assert.NoError(t, db.AddHistory(entry))
+		return entry
+	}
+	first := add("GET", "http://localhost/users", 200, sent)
+	add("POST", "http://localhost/users", 201, sent.Add(time.Hour))
+	add("GET", "http://localhost/orders", 404, sent.AddDate(0, 0, 1))
+	add("GET", "http://down.example", 0, sent.AddDate(0, 0, 2))
+
+	entries, err := db.LoadHistory(HistoryFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(entries))
+	assert.Equal(t, "http://down.example", entries[0].Request.URL, "newest first")
+
+	loaded, err := db.GetHistory(first.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, first, loaded)
+	assert.Equal(t, secrets.Mask, loaded.Request.Headers["Authorization"], "secrets aren't kept without a key")
+
+	for query, want := range map[string]int{
+		"users":                        2,
+		"method:get":                   3,
+		"status:2xx":                   2,
+		"status:404 orders":            1,
+		"status:error":                 1,
+		"date:2024-05-01":              2,
+		"since:2024-05-02":             2,
+		"until:2024-05-01 method:post": 1,
+	} {
+		filter, err := ParseHistoryQuery(query)
+		assert.NoError(t, err, query)
+		entries, err := db.LoadHistory(filter)
+		assert.NoError(t, err, query)
+		assert.Equal(t, want, len(entries), query)
+	}
+	_, err = ParseHistoryQuery("status:7xx")
+	assert.Error(t, err)
+	_, err = ParseHistoryQuery("date:yesterday")
+	assert.Error(t, err)
+
+	assert.NoError(t, db.ClearHistory())
+	entries, err = db.LoadHistory(HistoryFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(entries))
+	_, err = db.GetHistory(first.ID)
+	assert.Error(t, err)
+}
+
+func TestSQLiteStorage_HistoryRetention(t *testing.T) {
+	db := setupTestDB(t)
+	assert.NoError(t, db.SetVault(testVault(t, 1)))
+
+	retention, err := db.HistoryRetention()
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultHistoryRetention, retention)
+
+	add := func(body string, sentAt time.Time) {
+		t.Helper()
+		request := &http.Request{Method: "GET", URL: "http://localhost", Headers: map[string]string{"Authorization": "Bearer abc"}, Secrets: []string{"Authorization"}}
+		entry := NewHistoryEntry(request, &http.Response{StatusCode: 200, Body: body}, sentAt)
+		assert.NoError(t, db.AddHistory(entry))
+		assert.True(t, secrets.IsSealed(entry.Request.Headers["Authorization"]))
+	}
+	count := func() int {
+		t.Helper()
+		entries, err := db.LoadHistory(HistoryFilter{})
+		assert.NoError(t, err)
+		return len(entries)
+	}
+
+	add("stale", time.Now().AddDate(0, -2, 0))
+	assert.Equal(t, 0, count(), "older than 30 days")
+
+	assert.NoError(t, db.SetHistoryRetention(HistoryRetention{MaxEntries: 3}))
+	for range 5 {
+		add("x", time.Now())
+	}
+	assert.Equal(t, 3, count())
+
+	// Big bodies push out old entries
+	assert.NoError(t, db.SetHistoryRetention(HistoryRetention{MaxSize: 100 << 10}))
+	add(strings.Repeat("x", 60<<10), time.Now())
+	add(strings.Repeat("y", 60<<10), time.Now())
+	entries, err := db.LoadHistory(HistoryFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.True(t, strings.HasPrefix(entries[0].Body, "y"))
+
+	retention, err = db.HistoryRetention()
+	assert.NoError(t, err)
+	assert.Equal(t, HistoryRetention{MaxSize: 100 << 10}, retention)
+	assert.Error(t, db.SetHistoryRetention(HistoryRetention{MaxEntries: -1}))
+}
+
+func TestSQLiteStorage_DeleteKeepsRequestWhenRunsFail(t *testing.T) {
+	store := setupTestDB(t)
+	req := &http.Request{Name: "health", Method: http.GET, URL: "https://api.example/health"}
+	assert.NoError(t, store.Save(req))
+	config := &http.JobConfig{Request: req, Concurrency: 1, TotalRequests: 1}
+	assert.NoError(t, store.AddLoadTest(NewLoadTestRun(req.ID, config, http.NewLoadTestStats(1), "", "")))
+
+	// the runs can't be deleted, so neither is the request
+	_, err := store.db.Exec(`CREATE TRIGGER keep_runs BEFORE DELETE ON load_tests BEGIN SELECT RAISE(ABORT, 'kept'); END`)
+	assert.NoError(t, err)
+	assert.Error(t, store.Delete(req.ID))
+	requests, err := store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(requests))
+
+	_, err = store.db.Exec(`DROP TRIGGER keep_runs`)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Delete(req.ID))
+	runs, err := store.LoadLoadTests(req.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(runs))
+}
@@ -1,12 +1,69 @@
 package storage
 
 import (
+	"time"
+
 	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/secrets"
 )
 
 type Storage interface {
 	Save(requests *http.Request) error
+	SaveAs(request *http.Request) error
 	Load() ([]http.Request, error)
 	Delete(id int64) error
 	GetAllURLs() ([]string, error)
+
+	CreateCollection(collection *http.Collection) error
+	LoadCollections() ([]http.Collection, error)
+	RenameCollection(id int64, name string) error
+	SetCollectionDefaults(collection *http.Collection) error
+	MoveCollection(id, parentID int64) error
+	DeleteCollection(id int64) error
+	MoveRequest(id, collectionID int64) error
+
+	LoadEnvironmentOptions(environment string) (map[string]string, error)
+	SaveEnvironmentOptions(environment string, options map[string]string) error
+	LoadEnvironmentVariables(environment string) (map[string]string, error)
+	SaveEnvironmentVariables(environment string, variables map[string]string) error
+	ListEnvironments() ([]string, error)
+
+	SaveCookies(environment string, cookies []http.Cookie) error
+	LoadCookies(environment string) ([]http.Cookie, error)
+	ClearCookies(environment string) error
+
+	SaveGraphQLSchema(url string, schema *http.GraphQLSchema) error
+	LoadGraphQLSchema(url string) (*http.GraphQLSchema, time.Time, error)
+
+	AddHistory(entry *HistoryEntry) error
+	LoadHistory(filter HistoryFilter) ([]HistoryEntry, error)
+	GetHistory(id int64) (*HistoryEntry, error)
+	ClearHistory() error
+	PruneHistory(retention HistoryRetention) error
+	HistoryRetention() (HistoryRetention, error)
+	SetHistoryRetention(retention HistoryRetention) error
+
+	AddLoadTest(run *LoadTestRun) error
+	LoadLoadTests(requestID int64) ([]LoadTestRun, error)
+	GetLoadTest(id int64) (*LoadTestRun, error)
+
+	Search(query SearchQuery) ([]SearchResult, error)
+
+	SecretSalt() ([]byte, error)
+	SetVault(vault *secrets.Vault) error
+	SealSecret(value string) (string, error)
+	Close() error
+}
+
+// Watcher is implemented by storages whose content can be changed by other
+// programs, such as a workspace edited in a text editor or by git
+type Watcher interface {
+	Changes() <-chan struct{}
 }
+
+var (
+	_ Storage = (*SQLiteStorage)(nil)
+	_ Storage = (*FileStorage)(nil)
+	_ Storage = (*MemoryStorage)(nil)
+	_ Watcher = (*FileStorage)(nil)
+)
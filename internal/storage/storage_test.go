@@ -0,0 +1,463 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/secrets"
+)
+
+// backends opens an empty storage of every kind, which must all behave the
+// same way for the contract tests below
+var backends = map[string]func(t *testing.T) Storage{
+	"sqlite": func(t *testing.T) Storage {
+		return setupTestDB(t)
+	},
+	"file": func(t *testing.T) Storage {
+		store, _ := setupTestWorkspace(t)
+		return store
+	},
+	"memory": func(t *testing.T) Storage {
+		return NewMemoryStorage()
+	},
+}
+
+// forEachBackend runs a contract test against every backend
+func forEachBackend(t *testing.T, test func(t *testing.T, store Storage)) {
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			test(t, open(t))
+		})
+	}
+}
+
+func TestStorage_Requests(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Storage) {
+		req := &http.Request{
+			Name:    "users",
+			Method:  http.GET,
+			URL:     "https://api.example/users",
+			Headers: map[string]string{"Accept": "application/json"},
+			Body:    "{}",
+			Options: map[string]string{"timeout": "5s"},
+		}
+		assert.NoError(t, store.Save(req))
+		assert.NotEqual(t, int64(0), req.ID)
+		assert.Equal(t, int64(1), req.Version)
+		assert.False(t, req.UpdatedAt.IsZero())
+
+		// changes to the saved request don't reach the storage
+		req.Headers["Accept"] = "text/plain"
+		requests, err := store.Load()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(requests))
+		assert.Equal(t, "application/json", requests[0].Headers["Accept"])
+		assert.Equal(t, req.Options, requests[0].Options)
+		assert.Equal(t, int64(1), requests[0].Version)
+
+		// a save with the same content is a save all the same
+		loaded := requests[0]
+		assert.NoError(t, store.Save(&loaded))
+		assert.Equal(t, int64(2), loaded.Version)
+		assert.IsError(t, store.Save(req), ErrConflict)
+		req.Version = 0
+		assert.NoError(t, store.Save(req))
+		assert.Equal(t, int64(3), req.Version)
+
+		copied := *req
+		assert.NoError(t, store.SaveAs(&copied))
+		assert.NotEqual(t, req.ID, copied.ID)
+		assert.Equal(t, int64(1), copied.Version)
+
+		urls, err := store.GetAllURLs()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"https://api.example/users"}, urls)
+
+		assert.NoError(t, store.Delete(req.ID))
+		assert.Error(t, store.Delete(req.ID))
+		assert.Error(t, store.Save(req))
+		requests, err = store.Load()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(requests))
+		assert.Equal(t, copied.ID, requests[0].ID)
+	})
+}
+
+func TestStorage_Collections(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Storage) {
+		api := &http.Collection{Name: " api ", BaseURL: "https://api.example"}
+		assert.NoError(t, store.CreateCollection(api))
+		assert.Equal(t, "api", api.Name)
+		users := &http.Collection{Name: "Users", ParentID: api.ID}
+		assert.NoError(t, store.CreateCollection(users))
+		billing := &http.Collection{Name: "Billing"}
+		assert.NoError(t, store.CreateCollection(billing))
+		assert.Error(t, store.CreateCollection(&http.Collection{Name: " "}))
+		assert.Error(t, store.CreateCollection(&http.Collection{Name: "orphan", ParentID: 999}))
+
+		collections, err := store.LoadCollections()
+		assert.NoError(t, err)
+		var names []string
+		for _, collection := range collections {
+			names = append(names, collection.Name)
+		}
+		assert.Equal(t, []string{"api", "Billing", "Users"}, names, "sorted by name, ignoring case")
+		assert.Equal(t, api.ID, collections[2].ParentID)
+
+		assert.NoError(t, store.RenameCollection(billing.ID, "Payments"))
+		assert.Error(t, store.RenameCollection(billing.ID, ""))
+		assert.NoError(t, store.SetCollectionDefaults(&http.Collection{
+			ID: users.ID, Name: "Users", BaseURL: "/users", Headers: map[string]string{"Accept": "application/json"},
+		}))
+		collections, err = store.LoadCollections()
+		assert.NoError(t, err)
+		assert.Equal(t, "Payments", collections[1].Name)
+		assert.Equal(t, "/users", collections[2].BaseURL)
+		assert.Equal(t, map[string]string{"Accept": "application/json"}, collections[2].Headers)
+
+		req := &http.Request{Name: "list", Method: http.GET, URL: "/", CollectionID: users.ID}
+		assert.NoError(t, store.Save(req))
+		other := &http.Request{Name: "health", Method: http.GET, URL: "https://api.example/health"}
+		assert.NoError(t, store.Save(other))
+		assert.NoError(t, store.MoveRequest(other.ID, billing.ID))
+		assert.Error(t, store.MoveRequest(other.ID, 999))
+		assert.Error(t, store.MoveRequest(999, billing.ID))
+
+		assert.Error(t, store.MoveCollection(api.ID, users.ID))
+		assert.NoError(t, store.MoveCollection(billing.ID, api.ID))
+		collections, err = store.LoadCollections()
+		assert.NoError(t, err)
+		assert.Equal(t, api.ID, collections[1].ParentID)
+
+		// the request moved with its collection
+		requests, err := store.Load()
+		assert.NoError(t, err)
+		assert.Equal(t, billing.ID, requests[1].CollectionID)
+
+		assert.NoError(t, store.DeleteCollection(api.ID))
+		assert.Error(t, store.DeleteCollection(api.ID))
+		collections, err = store.LoadCollections()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(collections))
+		requests, err = store.Load()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(requests))
+	})
+}
+
+func TestStorage_Environments(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Storage) {
+		options, err := store.LoadEnvironmentOptions("staging")
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(options))
+		names, err := store.ListEnvironments()
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(names))
+
+		assert.NoError(t, store.SaveEnvironmentOptions("staging", map[string]string{"timeout": "5s"}))
+		assert.NoError(t, store.SaveEnvironmentVariables("staging", map[string]string{"host": "staging.example"}))
+		assert.NoError(t, store.SaveEnvironmentVariables("production", map[string]string{"host": "api.example"}))
+
+		options, err = store.LoadEnvironmentOptions("staging")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"timeout": "5s"}, options)
+		variables, err := store.LoadEnvironmentVariables("staging")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"host": "staging.example"}, variables)
+		options, err = store.LoadEnvironmentOptions("production")
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(options))
+
+		names, err = store.ListEnvironments()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"production", "staging"}, names)
+	})
+}
+
+func TestStorage_Secrets(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Storage) {
+		req := &http.Request{
+			Name:    "me",
+			Method:  http.GET,
+			URL:     "https://api.example/me",
+			Headers: map[string]string{"Authorization": "Bearer token", "Accept": "*/*"},
+			Secrets: []string{"Authorization"},
+		}
+		assert.IsError(t, store.Save(req), secrets.ErrNoKey)
+		assert.IsError(t, store.CreateCollection(&http.Collection{Name: "api", Auth: "Bearer token"}), secrets.ErrNoKey)
+
+		salt, err := store.SecretSalt()
+		assert.NoError(t, err)
+		again, err := store.SecretSalt()
+		assert.NoError(t, err)
+		assert.Equal(t, salt, again)
+
+		vault := testVault(t, 1)
+		assert.NoError(t, store.SetVault(vault))
+		assert.IsError(t, store.SetVault(testVault(t, 2)), secrets.ErrWrongKey)
+		headers := req.Headers
+		assert.NoError(t, store.Save(req))
+		// the caller's map is left alone, the request gets a sealed copy
+		assert.Equal(t, "Bearer token", headers["Authorization"])
+		assert.True(t, secrets.IsSealed(req.Headers["Authorization"]))
+		collection := &http.Collection{Name: "api", Auth: "Bearer token"}
+		assert.NoError(t, store.CreateCollection(collection))
+
+		requests, err := store.Load()
+		assert.NoError(t, err)
+		assert.True(t, secrets.IsSealed(requests[0].Headers["Authorization"]))
+		assert.Equal(t, "*/*", requests[0].Headers["Accept"])
+		unsealed, err := requests[0].Unseal(vault)
+		assert.NoError(t, err)
+		assert.Equal(t, "Bearer token", unsealed.Headers["Authorization"])
+
+		collections, err := store.LoadCollections()
+		assert.NoError(t, err)
+		auth, err := vault.Open(collections[0].Auth)
+		assert.NoError(t, err)
+		assert.Equal(t, "Bearer token", auth)
+	})
+}
+
+func TestStorage_History(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Storage) {
+		assert.NoError(t, store.SetHistoryRetention(HistoryRetention{}))
+
+		sent := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+		add := func(method, url string, status int, sentAt time.Time) *HistoryEntry {
+			t.Helper()
+			request := &http.Request{
+				Method:  method,
+				URL:     url,
+				Headers: map[string]string{"Authorization": "Bearer abc"},
+				Secrets: []string{"Authorization"},
+			}
+			response := &http.Response{StatusCode: status, Headers: map[string][]string{"Content-Type": {"text/plain"}}, Body: "ok"}
+			entry := NewHistoryEntry(request, response, sentAt)
+			assert.NoError(t, store.AddHistory(entry))
+			return entry
+		}
+		first := add("GET", "http://localhost/users", 200, sent)
+		add("POST", "http://localhost/Users", 201, sent.Add(time.Hour))
+		add("GET", "http://localhost/orders", 404, sent.AddDate(0, 0, 1))
+
+		loaded, err := store.GetHistory(first.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, first, loaded)
+		assert.Equal(t, secrets.Mask, loaded.Request.Headers["Authorization"])
+
+		for filter, want := range map[HistoryFilter]int{
+			{}:                           3,
+			{Query: "users"}:             2,
+			{Method: "get"}:              2,
+			{Status: "4xx"}:              1,
+			{Since: sent.Add(time.Hour)}: 2,
+			{Until: sent.Add(time.Hour)}: 1,
+			{Limit: 2}:                   2,
+		} {
+			entries, err := store.LoadHistory(filter)
+			assert.NoError(t, err)
+			assert.Equal(t, want, len(entries), "%+v", filter)
+		}
+		entries, err := store.LoadHistory(HistoryFilter{})
+		assert.NoError(t, err)
+		assert.Equal(t, "http://localhost/orders", entries[0].Request.URL, "newest first")
+
+		assert.NoError(t, store.SetHistoryRetention(HistoryRetention{MaxEntries: 2}))
+		retention, err := store.HistoryRetention()
+		assert.NoError(t, err)
+		assert.Equal(t, HistoryRetention{MaxEntries: 2}, retention)
+		_, err = store.GetHistory(first.ID)
+		assert.Error(t, err)
+
+		// big bodies push out old entries
+		assert.NoError(t, store.SetHistoryRetention(HistoryRetention{MaxSize: 100 << 10}))
+		for _, body := range []string{"x", "y"} {
+			entry := NewHistoryEntry(&http.Request{Method: "GET", URL: "http://localhost/big"}, &http.Response{Body: strings.Repeat(body, 60<<10)}, time.Now())
+			assert.NoError(t, store.AddHistory(entry))
+		}
+		entries, err = store.LoadHistory(HistoryFilter{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(entries))
+		assert.True(t, strings.HasPrefix(entries[0].Body, "y"))
+		assert.NoError(t, store.PruneHistory(HistoryRetention{MaxSize: 50 << 10}))
+		entries, err = store.LoadHistory(HistoryFilter{})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(entries))
+
+		add("GET", "http://localhost", 200, time.Now())
+		assert.NoError(t, store.ClearHistory())
+		entries, err = store.LoadHistory(HistoryFilter{})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(entries))
+	})
+}
+
+func TestStorage_Cookies(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Storage) {
+		cookies := []http.Cookie{
+			{Name: "theme", Value: "dark", Domain: "example.com", Path: "/", Expires: time.Now().Add(time.Hour).UTC().Truncate(time.Second)},
+			{Name: "session", Value: "abc", Domain: "example.com", Path: "/", HttpOnly: true, HostOnly: true},
+			{Name: "stale", Value: "x", Domain: "example.com", Path: "/", Expires: time.Now().Add(-time.Hour).UTC().Truncate(time.Second)},
+		}
+		assert.NoError(t, store.SaveCookies(DefaultEnvironment, cookies))
+
+		loaded, err := store.LoadCookies(DefaultEnvironment)
+		assert.NoError(t, err)
+		assert.Equal(t, []http.Cookie{cookies[1], cookies[0]}, loaded)
+		loaded, err = store.LoadCookies("staging")
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(loaded))
+
+		assert.NoError(t, store.ClearCookies(DefaultEnvironment))
+		loaded, err = store.LoadCookies(DefaultEnvironment)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(loaded))
+	})
+}
+
+func TestStorage_GraphQLSchema(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Storage) {
+		schema, _, err := store.LoadGraphQLSchema("https://api.example/graphql")
+		assert.NoError(t, err)
+		assert.Zero(t, schema)
+
+		saved := &http.GraphQLSchema{QueryType: "Query", Types: []http.GraphQLType{{Name: "Query"}}}
+		assert.NoError(t, store.SaveGraphQLSchema("https://api.example/graphql", saved))
+		schema, fetchedAt, err := store.LoadGraphQLSchema("https://api.example/graphql")
+		assert.NoError(t, err)
+		assert.Equal(t, saved, schema)
+		assert.True(t, time.Since(fetchedAt) < time.Minute)
+	})
+}
+
+func TestStorage_LoadTests(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Storage) {
+		req := &http.Request{Name: "health", Method: http.GET, URL: "https://api.example/health"}
+		assert.NoError(t, store.Save(req))
+		config := &http.JobConfig{Request: req, Concurrency: 10, TotalRequests: 100, Timeout: 5 * time.Second}
+
+		var runs []*LoadTestRun
+		for i, label := range []string{"before", "after"} {
+			stats := http.NewLoadTestStats(100)
+			stats.StartTime = time.Date(2024, 5, 1, 12, i, 0, 0, time.UTC)
+			stats.CompletedRequests = 100
+			stats.Errors["503"] = int64(i)
+			run := NewLoadTestRun(req.ID, config, stats, label, "abc1234")
+			assert.NoError(t, store.AddLoadTest(run))
+			assert.NotEqual(t, int64(0), run.ID)
+			runs = append(runs, run)
+		}
+
+		loaded, err := store.LoadLoadTests(req.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(loaded))
+		assert.Equal(t, "after", loaded[0].Label)
+		assert.Equal(t, req.ID, loaded[0].RequestID)
+		assert.Equal(t, "abc1234", loaded[0].GitSHA)
+		assert.Equal(t, LoadTestConfig{Method: http.GET, URL: "https://api.example/health", Concurrency: 10, TotalRequests: 100, Timeout: 5 * time.Second}, loaded[0].Config)
+		assert.Equal(t, 100, loaded[0].Stats.CompletedRequests)
+		assert.Equal(t, int64(1), loaded[0].Stats.Errors["503"])
+
+		// runs follow their request into a collection
+		collection := &http.Collection{Name: "API"}
+		assert.NoError(t, store.CreateCollection(collection))
+		assert.NoError(t, store.MoveRequest(req.ID, collection.ID))
+		run, err := store.GetLoadTest(runs[0].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "before", run.Label)
+		assert.Equal(t, req.ID, run.RequestID)
+		assert.True(t, runs[0].RanAt.Equal(run.RanAt))
+
+		assert.NoError(t, store.DeleteCollection(collection.ID))
+		_, err = store.GetLoadTest(runs[0].ID)
+		assert.Error(t, err)
+	})
+}
+
+func TestStorage_HistoryMasksResponseCredentials(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Storage) {
+		assert.NoError(t, store.SetVault(testVault(t, 1)))
+
+		request := &http.Request{Method: http.POST, URL: "https://api.example/login"}
+		response := &http.Response{StatusCode: 200, Headers: map[string][]string{
+			"Content-Type": {"application/json"},
+			"Set-Cookie":   {"session=hunter2; HttpOnly", "theme=dark"},
+			"X-Auth-Token": {"hunter3"},
+		}}
+		entry := NewHistoryEntry(request, response, time.Now())
+		assert.NoError(t, store.AddHistory(entry))
+		assert.Equal(t, []string{"session=hunter2; HttpOnly", "theme=dark"}, response.Headers["Set-Cookie"], "the response isn't changed")
+
+		loaded, err := store.GetHistory(entry.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{secrets.Mask, secrets.Mask}, loaded.Headers["Set-Cookie"])
+		assert.Equal(t, []string{secrets.Mask}, loaded.Headers["X-Auth-Token"])
+		assert.Equal(t, []string{"application/json"}, loaded.Headers["Content-Type"])
+
+		for _, text := range []string{"hunter2", "hunter3"} {
+			results, err := store.Search(SearchQuery{Text: text, History: true})
+			assert.NoError(t, err)
+			assert.Equal(t, 0, len(results), text)
+		}
+	})
+}
+
+func TestStorage_Search(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, store Storage) {
+		assert.NoError(t, store.SetVault(testVault(t, 1)))
+
+		users := &http.Request{
+			Name:    "List users",
+			Method:  http.GET,
+			URL:     "https://api.example/users?page=2",
+			Headers: map[string]string{"Accept": "application/json", "Authorization": "Bearer hunter2"},
+			Secrets: []string{"Authorization"},
+		}
+		orders := &http.Request{Name: "Create order", Method: http.POST, URL: "https://api.example/orders", Body: `{"sku": "widget", "user": 7}`}
+		assert.NoError(t, store.Save(users))
+		assert.NoError(t, store.Save(orders))
+
+		search := func(text string, history bool) []SearchResult {
+			t.Helper()
+			results, err := store.Search(SearchQuery{Text: text, History: history})
+			assert.NoError(t, err)
+			return results
+		}
+
+		// the name counts more than the body
+		results := search("user", false)
+		assert.Equal(t, 2, len(results))
+		assert.Equal(t, users.ID, results[0].ID)
+		assert.Equal(t, SearchRequest, results[0].Kind)
+		assert.Equal(t, http.GET, results[0].Method)
+		assert.Equal(t, "List "+MatchStart+"users"+MatchEnd, results[0].Name)
+		assert.Contains(t, results[1].Snippet, MatchStart)
+
+		// every word has to match, punctuation included
+		assert.Equal(t, 1, len(search("api.example/orders widget", false)))
+		assert.Equal(t, 0, len(search("orders gadget", false)))
+		assert.Equal(t, 1, len(search("application/json", false)))
+		assert.Equal(t, 0, len(search("hunter2", false)))
+		assert.Equal(t, 0, len(search("  ", false)))
+
+		// saving and deleting keep the index up to date
+		orders.Name = "Place order"
+		assert.NoError(t, store.Save(orders))
+		assert.Equal(t, 1, len(search("place", false)))
+		assert.NoError(t, store.Delete(orders.ID))
+		assert.Equal(t, 0, len(search("widget", false)))
+
+		response := &http.Response{StatusCode: 200, Headers: map[string][]string{"Content-Type": {"application/json"}}, Body: `{"name": "Ada Lovelace"}`}
+		assert.NoError(t, store.AddHistory(NewHistoryEntry(users, response, time.Now())))
+		assert.Equal(t, 0, len(search("lovelace", false)))
+		results = search("lovelace", true)
+		assert.Equal(t, 1, len(results))
+		assert.Equal(t, SearchHistory, results[0].Kind)
+		assert.Equal(t, 200, results[0].StatusCode)
+		assert.Equal(t, `{"name": "Ada `+MatchStart+"Lovelace"+MatchEnd+`"}`, results[0].Snippet)
+	})
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"database/sql"
+)
+
+// workspaceSecret returns the value kept for a workspace file, sealed, or
+// false when there is none
+func (s *SQLiteStorage) workspaceSecret(key string) (string, bool, error) {
+	var value string
+	err := s.db.QueryRow(`SELECT value FROM workspace_secrets WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// setWorkspaceSecret keeps a sealed value for a workspace file
+func (s *SQLiteStorage) setWorkspaceSecret(key, value string) error {
+	q := `INSERT INTO workspace_secrets (key, value) VALUES (?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value`
+	_, err := s.db.Exec(q, key, value)
+	return err
+}
+
+// moveWorkspaceSecrets changes the start of the keys of the secrets of a
+// file or directory that was renamed or moved
+func (s *SQLiteStorage) moveWorkspaceSecrets(oldPrefix, newPrefix string) error {
+	q := `UPDATE workspace_secrets SET key = ?1 || substr(key, length(?2) + 1) WHERE substr(key, 1, length(?2)) = ?2`
+	_, err := s.db.Exec(q, newPrefix, oldPrefix)
+	return err
+}
+
+// deleteWorkspaceSecrets deletes the secrets whose keys start with prefix
+func (s *SQLiteStorage) deleteWorkspaceSecrets(prefix string) error {
+	_, err := s.db.Exec(`DELETE FROM workspace_secrets WHERE substr(key, 1, length(?1)) = ?1`, prefix)
+	return err
+}
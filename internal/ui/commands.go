@@ -1,19 +1,44 @@
 package ui
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/importer"
 	"github.com/owenHochwald/volt/internal/storage"
 )
 
 type RequestsLoadingMsg struct {
-	Requests []http.Request
-	Err      error
+	Requests    []http.Request
+	Collections []http.Collection
+	Err         error
 }
 
+// RequestSavedMsg is sent once a request was saved. The save works on a copy,
+// so the request pane's Request is left alone until the app applies the
+// result of a successful save.
 type RequestSavedMsg struct {
 	Request *http.Request
-	Err     error
+
+	// ID, Version and UpdatedAt are the request's once saved, Headers its
+	// headers with the values of secret headers sealed
+	ID        int64
+	Version   int64
+	UpdatedAt time.Time
+	Headers   map[string]string
+
+	AsNew bool // saved as a new request rather than over the one it was loaded from
+	Err   error
 }
 
 type RequestDeletedMsg struct {
@@ -21,19 +46,103 @@ type RequestDeletedMsg struct {
 	Err error
 }
 
+// CollectionsChangedMsg is sent once a collection was created, changed or
+// deleted, or a request moved between collections
+type CollectionsChangedMsg struct {
+	Err error
+}
+
+// HistoryLoadedMsg carries the history entries matching the sidebar's filter
+type HistoryLoadedMsg struct {
+	Entries []storage.HistoryEntry
+	Err     error
+}
+
+// HistoryRecordedMsg is sent once a sent request was added to the history
+type HistoryRecordedMsg struct {
+	Err error
+}
+
+// HistoryEntryLoadedMsg carries a history entry opened from the search palette
+type HistoryEntryLoadedMsg struct {
+	Entry *storage.HistoryEntry
+	Err   error
+}
+
+// SearchResultsMsg carries the results of a search typed in the search palette
+type SearchResultsMsg struct {
+	Query   storage.SearchQuery
+	Results []storage.SearchResult
+	Err     error
+}
+
+// LoadTestSavedMsg is sent once a finished load test was added to its request's runs
+type LoadTestSavedMsg struct {
+	Run *storage.LoadTestRun
+	Err error
+}
+
+// LoadTestsLoadedMsg carries the load tests of a saved request, Show asking
+// for them to be listed in the response pane
+type LoadTestsLoadedMsg struct {
+	RequestID int64
+	Runs      []storage.LoadTestRun
+	Show      bool
+	Err       error
+}
+
+// ImportFileMsg asks for a file typed in the sidebar to be imported
+type ImportFileMsg struct {
+	Path string
+}
+
+// ImportCurlMsg asks for a curl command typed in the sidebar to be opened in the request pane
+type ImportCurlMsg struct {
+	Command string
+}
+
+// ImportedMsg is sent once a file was imported, with what couldn't be converted
+type ImportedMsg struct {
+	Summary  importer.Summary
+	Warnings []string
+	Err      error
+}
+
+type CookiesLoadedMsg struct {
+	Cookies []http.Cookie
+	Err     error
+}
+
+type CookiesSavedMsg struct {
+	Err error
+}
+
+type EnvironmentLoadedMsg struct {
+	Options   map[string]string
+	Variables map[string]string
+	Err       error
+}
+
+// StorageChangedMsg tells that saved requests were changed outside of volt
+type StorageChangedMsg struct{}
+
 type SetRequestPaneRequestMsg struct {
 	Request *http.Request
+
+	// Collections are the ones the request is in, outermost first
+	Collections []http.Collection
 }
 
-func SetRequestPaneRequestCmd(request *http.Request) tea.Cmd {
+func SetRequestPaneRequestCmd(request *http.Request, collections []http.Collection) tea.Cmd {
 	return func() tea.Msg {
 		return SetRequestPaneRequestMsg{
-			Request: request,
+			Request:     request,
+			Collections: collections,
 		}
 	}
 }
 
-func DeleteRequestCmd(db *storage.SQLiteStorage, id int64) tea.Cmd {
+func DeleteRequestCmd(db storage.Storage, id int64) tea.Cmd {
 	return func() tea.Msg {
 		err := db.Delete(id)
 		return RequestDeletedMsg{
@@ -43,29 +152,236 @@ func DeleteRequestCmd(db *storage.SQLiteStorage, id int64) tea.Cmd {
 	}
 }
 
-func SaveRequestCmd(db *storage.SQLiteStorage, request *http.Request) tea.Cmd {
+// SaveRequestCmd saves a copy of the request, which the request pane keeps editing meanwhile
+func SaveRequestCmd(db storage.Storage, request *http.Request) tea.Cmd {
+	saved := *request
 	return func() tea.Msg {
-		err := db.Save(request)
-		return RequestSavedMsg{
-			Request: request,
-			Err:     err,
-		}
+		return savedMsg(request, &saved, false, db.Save(&saved))
+	}
+}
+
+// SaveRequestAsCmd saves the request as a new one, leaving the one it was loaded from as it was
+func SaveRequestAsCmd(db storage.Storage, request *http.Request) tea.Cmd {
+	saved := *request
+	return func() tea.Msg {
+		return savedMsg(request, &saved, true, db.SaveAs(&saved))
+	}
+}
+
+// savedMsg reports the save of a copy of request
+func savedMsg(request, saved *http.Request, asNew bool, err error) RequestSavedMsg {
+	return RequestSavedMsg{
+		Request:   request,
+		ID:        saved.ID,
+		Version:   saved.Version,
+		UpdatedAt: saved.UpdatedAt,
+		Headers:   saved.Headers,
+		AsNew:     asNew,
+		Err:       err,
 	}
 }
 
-func LoadRequestsCmd(db *storage.SQLiteStorage) tea.Cmd {
+func LoadRequestsCmd(db storage.Storage) tea.Cmd {
 	return func() tea.Msg {
 		requests, err := db.Load()
+		if err != nil {
+			return RequestsLoadingMsg{Err: err}
+		}
+		collections, err := db.LoadCollections()
 		return RequestsLoadingMsg{
-			Requests: requests,
-			Err:      err,
+			Requests:    requests,
+			Collections: collections,
+			Err:         err,
+		}
+	}
+}
+
+func CreateCollectionCmd(db storage.Storage, collection *http.Collection) tea.Cmd {
+	return func() tea.Msg {
+		return CollectionsChangedMsg{Err: db.CreateCollection(collection)}
+	}
+}
+
+func RenameCollectionCmd(db storage.Storage, id int64, name string) tea.Cmd {
+	return func() tea.Msg {
+		return CollectionsChangedMsg{Err: db.RenameCollection(id, name)}
+	}
+}
+
+func MoveCollectionCmd(db storage.Storage, id, parentID int64) tea.Cmd {
+	return func() tea.Msg {
+		return CollectionsChangedMsg{Err: db.MoveCollection(id, parentID)}
+	}
+}
+
+func DeleteCollectionCmd(db storage.Storage, id int64) tea.Cmd {
+	return func() tea.Msg {
+		return CollectionsChangedMsg{Err: db.DeleteCollection(id)}
+	}
+}
+
+func MoveRequestCmd(db storage.Storage, id, collectionID int64) tea.Cmd {
+	return func() tea.Msg {
+		return CollectionsChangedMsg{Err: db.MoveRequest(id, collectionID)}
+	}
+}
+
+// historyListLimit is the most history entries shown in the sidebar
+const historyListLimit = 500
+
+// LoadHistoryCmd loads the history entries matching a query, see storage.ParseHistoryQuery
+func LoadHistoryCmd(db storage.Storage, query string) tea.Cmd {
+	return func() tea.Msg {
+		filter, err := storage.ParseHistoryQuery(query)
+		if err != nil {
+			return HistoryLoadedMsg{Err: err}
+		}
+		filter.Limit = historyListLimit
+		entries, err := db.LoadHistory(filter)
+		return HistoryLoadedMsg{
+			Entries: entries,
+			Err:     err,
 		}
 	}
 }
 
-func StartLoadTestCmd(config *http.JobConfig) tea.Cmd {
+// LoadHistoryEntryCmd loads a single history entry
+func LoadHistoryEntryCmd(db storage.Storage, id int64) tea.Cmd {
 	return func() tea.Msg {
-		return http.LoadTestStartMsg{Config: config}
+		entry, err := db.GetHistory(id)
+		return HistoryEntryLoadedMsg{Entry: entry, Err: err}
+	}
+}
+
+// SearchCmd searches saved requests, and the history when asked
+func SearchCmd(db storage.Storage, query storage.SearchQuery) tea.Cmd {
+	return func() tea.Msg {
+		results, err := db.Search(query)
+		return SearchResultsMsg{
+			Query:   query,
+			Results: results,
+			Err:     err,
+		}
+	}
+}
+
+// RecordHistoryCmd adds a sent request and its response to the history
+func RecordHistoryCmd(db storage.Storage, entry *storage.HistoryEntry) tea.Cmd {
+	return func() tea.Msg {
+		return HistoryRecordedMsg{Err: db.AddHistory(entry)}
+	}
+}
+
+// SaveLoadTestCmd adds a finished load test to its request's runs, along with
+// the git commit checked out in the current directory
+func SaveLoadTestCmd(db storage.Storage, run *storage.LoadTestRun) tea.Cmd {
+	return func() tea.Msg {
+		if run.GitSHA == "" {
+			run.GitSHA = gitSHA()
+		}
+		return LoadTestSavedMsg{Run: run, Err: db.AddLoadTest(run)}
+	}
+}
+
+// gitSHA returns the short hash of the commit checked out in the current
+// directory, or nothing outside of a git repository
+func gitSHA() string {
+	out, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// LoadLoadTestsCmd loads the load tests of a saved request, newest first
+func LoadLoadTestsCmd(db storage.Storage, requestID int64, show bool) tea.Cmd {
+	return func() tea.Msg {
+		runs, err := db.LoadLoadTests(requestID)
+		return LoadTestsLoadedMsg{
+			RequestID: requestID,
+			Runs:      runs,
+			Show:      show,
+			Err:       err,
+		}
+	}
+}
+
+// ImportCmd imports a file into the saved requests, adding its variables to environment
+func ImportCmd(db storage.Storage, path, environment string) tea.Cmd {
+	return func() tea.Msg {
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, rest)
+			}
+		}
+		result, summary, err := importer.ImportFile(db, path, environment)
+		msg := ImportedMsg{Summary: summary, Err: err}
+		if result != nil {
+			msg.Warnings = result.Warnings
+		}
+		return msg
+	}
+}
+
+func LoadCookiesCmd(db storage.Storage, environment string) tea.Cmd {
+	return func() tea.Msg {
+		cookies, err := db.LoadCookies(environment)
+		return CookiesLoadedMsg{
+			Cookies: cookies,
+			Err:     err,
+		}
+	}
+}
+
+func SaveCookiesCmd(db storage.Storage, environment string, cookies []http.Cookie) tea.Cmd {
+	return func() tea.Msg {
+		err := db.SaveCookies(environment, cookies)
+		return CookiesSavedMsg{
+			Err: err,
+		}
+	}
+}
+
+func LoadEnvironmentCmd(db storage.Storage, environment string) tea.Cmd {
+	return func() tea.Msg {
+		options, err := db.LoadEnvironmentOptions(environment)
+		if err != nil {
+			return EnvironmentLoadedMsg{Err: err}
+		}
+		variables, err := db.LoadEnvironmentVariables(environment)
+		return EnvironmentLoadedMsg{
+			Options:   options,
+			Variables: variables,
+			Err:       err,
+		}
+	}
+}
+
+// WatchStorageCmd waits for the next change made to the storage by other programs
+func WatchStorageCmd(watcher storage.Watcher) tea.Cmd {
+	return func() tea.Msg {
+		<-watcher.Changes()
+		return StorageChangedMsg{}
+	}
+}
+
+func StartLoadTestCmd(config *http.JobConfig, requestID int64, label string) tea.Cmd {
+	return func() tea.Msg {
+		return http.LoadTestStartMsg{Config: config, RequestID: requestID, Label: label}
+	}
+}
+
+// WaitForStreamCmd waits for the next update of a request, ending with its ResultMsg
+func WaitForStreamCmd(updates <-chan http.StreamUpdate) tea.Cmd {
+	return func() tea.Msg {
+		update, ok := <-updates
+		if !ok {
+			return nil
+		}
+		if update.Done {
+			return http.ResultMsg{Response: update.Response}
+		}
+		return http.StreamMsg{Update: update}
 	}
 }
 
@@ -98,3 +414,135 @@ func WaitForLoadTestUpdatesCmd(updates <-chan *http.LoadTestStats, totalRequests
 		}
 	}
 }
+
+// WSSentMsg reports a frame that couldn't be written
+type WSSentMsg struct {
+	Err error
+}
+
+// ConnectWebSocketCmd opens a WebSocket connection for the request
+func ConnectWebSocketCmd(client *http.Client, request *http.Request) tea.Cmd {
+	return func() tea.Msg {
+		conn, response, err := client.DialWebSocket(context.Background(), request)
+		return http.WSConnectedMsg{
+			Conn:     conn,
+			Response: response,
+			Err:      err,
+		}
+	}
+}
+
+// WaitForFrameCmd waits for the next frame of the connection, ending with WSClosedMsg
+func WaitForFrameCmd(conn *http.WSConn) tea.Cmd {
+	return func() tea.Msg {
+		frame, ok := <-conn.Frames()
+		if !ok {
+			return http.WSClosedMsg{}
+		}
+		return http.WSFrameMsg{Frame: frame}
+	}
+}
+
+// SendFrameCmd writes a frame; the sent frame itself shows up through WaitForFrameCmd
+func SendFrameCmd(conn *http.WSConn, kind, payload string) tea.Cmd {
+	return func() tea.Msg {
+		return WSSentMsg{Err: conn.Send(kind, payload)}
+	}
+}
+
+// PingCmd sends a ping
+func PingCmd(conn *http.WSConn) tea.Cmd {
+	return func() tea.Msg {
+		return WSSentMsg{Err: conn.Ping()}
+	}
+}
+
+// CloseWebSocketCmd starts the closing handshake with a normal closure
+func CloseWebSocketCmd(conn *http.WSConn) tea.Cmd {
+	return func() tea.Msg {
+		return WSSentMsg{Err: conn.Close(http.CloseNormal, "")}
+	}
+}
+
+// GRPCMethodsMsg carries the methods of a gRPC server
+type GRPCMethodsMsg struct {
+	URL     string
+	Methods []http.GRPCMethod
+	Err     error
+}
+
+// ListGRPCMethodsCmd lists the methods of the server at the request's address
+func ListGRPCMethodsCmd(client *http.Client, request *http.Request) tea.Cmd {
+	req := *request
+	return func() tea.Msg {
+		methods, err := client.ListGRPCMethods(context.Background(), &req)
+		return GRPCMethodsMsg{URL: req.URL, Methods: methods, Err: err}
+	}
+}
+
+// GraphQLSchemaMsg carries a freshly introspected schema
+type GraphQLSchemaMsg struct {
+	URL       string
+	Schema    *http.GraphQLSchema
+	FetchedAt time.Time
+	Err       error
+}
+
+// IntrospectCmd fetches the schema of a GraphQL endpoint and caches it
+func IntrospectCmd(client *http.Client, db storage.Storage, request *http.Request) tea.Cmd {
+	req := *request
+	return func() tea.Msg {
+		schema, err := client.Introspect(&req)
+		if err == nil {
+			err = db.SaveGraphQLSchema(req.URL, schema)
+		}
+		return GraphQLSchemaMsg{
+			URL:       req.URL,
+			Schema:    schema,
+			FetchedAt: time.Now(),
+			Err:       err,
+		}
+	}
+}
+
+// BodySavedMsg reports where a response body was saved
+type BodySavedMsg struct {
+	Path string
+	Err  error
+}
+
+// SaveBodyCmd saves the whole body of a response to the current directory,
+// under the name the response suggests, never overwriting a file
+func SaveBodyCmd(response *http.Response) tea.Cmd {
+	return func() tea.Msg {
+		path := availablePath(response.Filename())
+		return BodySavedMsg{Path: path, Err: response.SaveBody(path)}
+	}
+}
+
+// availablePath returns name, or name with a number before its extension
+// when a file by that name already exists
+func availablePath(name string) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	path := name
+	for i := 1; ; i++ {
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			return path
+		}
+		path = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+}
+
+// CopiedMsg reports what was copied to the clipboard
+type CopiedMsg struct {
+	What string
+	Err  error
+}
+
+// CopyCmd copies content to the system clipboard, what naming it in the status
+func CopyCmd(content, what string) tea.Cmd {
+	return func() tea.Msg {
+		return CopiedMsg{What: what, Err: clipboard.WriteAll(content)}
+	}
+}
@@ -0,0 +1,52 @@
+package cookiepane
+
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/owenHochwald/volt/internal/http"
+)
+
+// CookiePane is the modal listing the cookies held by the cookie jar
+type CookiePane struct {
+	jar     *http.CookieJar
+	cookies []http.Cookie
+	cursor  int
+
+	editing   bool
+	editInput textinput.Model
+
+	height, width int
+
+	Focused bool
+}
+
+func (m CookiePane) Init() tea.Cmd {
+	return nil
+}
+
+func (m *CookiePane) SetFocused(focused bool) {
+	m.Focused = focused
+}
+
+func (m *CookiePane) SetHeight(height int) {
+	m.height = height
+}
+
+func (m *CookiePane) SetWidth(width int) {
+	m.width = width
+}
+
+// Refresh reloads the cookie list from the jar
+func (m *CookiePane) Refresh() {
+	m.cookies = m.jar.All()
+	m.editing = false
+	m.cursor = min(m.cursor, max(len(m.cookies)-1, 0))
+}
+
+// selected returns the cookie under the cursor
+func (m CookiePane) selected() (http.Cookie, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.cookies) {
+		return http.Cookie{}, false
+	}
+	return m.cookies[m.cursor], true
+}
@@ -0,0 +1,21 @@
+package cookiepane
+
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/owenHochwald/volt/internal/http"
+)
+
+// SetupCookiePane sets up the cookie pane for the given jar
+func SetupCookiePane(jar *http.CookieJar) CookiePane {
+	input := textinput.New()
+	input.Placeholder = "cookie value"
+	input.CharLimit = 4096
+	input.Width = 40
+
+	return CookiePane{
+		jar:       jar,
+		height:    25,
+		width:     60,
+		editInput: input,
+	}
+}
@@ -0,0 +1,85 @@
+package cookiepane
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// CloseCookieModalMsg signals the app to close the cookie modal
+type CloseCookieModalMsg struct{}
+
+// CookiesChangedMsg signals the app that the jar was edited and should be persisted
+type CookiesChangedMsg struct{}
+
+func cookiesChanged() tea.Msg {
+	return CookiesChangedMsg{}
+}
+
+func (m CookiePane) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if m.editing {
+		return m.updateEditing(keyMsg)
+	}
+
+	switch keyMsg.String() {
+	case "j", tea.KeyDown.String():
+		if m.cursor < len(m.cookies)-1 {
+			m.cursor++
+		}
+	case "k", tea.KeyUp.String():
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "e", tea.KeyEnter.String():
+		if cookie, ok := m.selected(); ok {
+			m.editing = true
+			m.editInput.SetValue(cookie.Value)
+			m.editInput.CursorEnd()
+			return m, m.editInput.Focus()
+		}
+	case "d":
+		if cookie, ok := m.selected(); ok {
+			m.jar.Delete(cookie)
+			m.Refresh()
+			return m, cookiesChanged
+		}
+	case "C":
+		m.jar.Clear()
+		m.Refresh()
+		return m, cookiesChanged
+	case "q", "alt+c", tea.KeyEscape.String():
+		return m, func() tea.Msg {
+			return CloseCookieModalMsg{}
+		}
+	}
+
+	return m, nil
+}
+
+// updateEditing handles input while a cookie value is being edited
+func (m CookiePane) updateEditing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case tea.KeyEnter.String():
+		cookie, ok := m.selected()
+		m.editing = false
+		m.editInput.Blur()
+		if !ok {
+			return m, nil
+		}
+		cookie.Value = m.editInput.Value()
+		m.jar.Set(cookie)
+		m.Refresh()
+		return m, cookiesChanged
+	case tea.KeyEscape.String():
+		m.editing = false
+		m.editInput.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.editInput, cmd = m.editInput.Update(msg)
+	return m, cmd
+}
@@ -0,0 +1,112 @@
+package cookiepane
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/owenHochwald/volt/internal/http"
+)
+
+var (
+	accentColor = lipgloss.Color("205")
+
+	keyStyle      = lipgloss.NewStyle().Foreground(accentColor).Bold(true)
+	valueStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("255"))
+	attrStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	selectedStyle = lipgloss.NewStyle().Background(lipgloss.Color("98")).Foreground(lipgloss.Color("255"))
+)
+
+func (m CookiePane) View() string {
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(accentColor).
+		Padding(1, 2).
+		Width(m.width).
+		Height(m.height)
+
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(accentColor).
+		Render(fmt.Sprintf("Cookie Jar (%d)", len(m.cookies)))
+
+	footerText := "j/k: move • e: edit • d: delete • C: clear all • esc: close"
+	if m.editing {
+		footerText = "enter: save • esc: cancel"
+	}
+	footer := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Render(footerText)
+
+	return modalStyle.Render(lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		m.renderCookieList(),
+		"",
+		footer,
+	))
+}
+
+// renderCookieList renders one line per cookie plus its attributes
+func (m CookiePane) renderCookieList() string {
+	if len(m.cookies) == 0 {
+		return attrStyle.Render("No cookies stored yet. Responses with Set-Cookie fill the jar.")
+	}
+
+	// Keep the cursor visible when the list is taller than the modal
+	visible := max(m.height-8, 1)
+	start := max(0, m.cursor-visible+1)
+	end := min(len(m.cookies), start+visible)
+
+	var lines []string
+	for i := start; i < end; i++ {
+		cookie := m.cookies[i]
+
+		value := cookie.Value
+		if m.editing && i == m.cursor {
+			value = m.editInput.View()
+		} else {
+			value = truncate(value, 30)
+		}
+
+		line := lipgloss.JoinHorizontal(lipgloss.Left,
+			keyStyle.Render(cookie.Name), " = ", valueStyle.Render(value), "  ",
+			attrStyle.Render(describe(cookie)),
+		)
+		if i == m.cursor && !m.editing {
+			line = selectedStyle.Render(cookie.Name+" = "+value) + "  " + attrStyle.Render(describe(cookie))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// describe summarises where a cookie is sent and when it expires
+func describe(cookie http.Cookie) string {
+	parts := []string{cookie.Domain + cookie.Path}
+	if cookie.Expires.IsZero() {
+		parts = append(parts, "session")
+	} else {
+		parts = append(parts, "expires "+cookie.Expires.Local().Format(time.DateTime))
+	}
+	if cookie.Secure {
+		parts = append(parts, "secure")
+	}
+	if cookie.HttpOnly {
+		parts = append(parts, "httponly")
+	}
+	if cookie.SameSite != "" {
+		parts = append(parts, "samesite="+cookie.SameSite)
+	}
+	return strings.Join(parts, " • ")
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
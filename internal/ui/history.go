@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/owenHochwald/volt/internal/storage"
+)
+
+// HistoryItem is a sent request in the sidebar's history
+type HistoryItem struct {
+	Entry storage.HistoryEntry
+}
+
+func (i HistoryItem) Title() string {
+	status := "ERR"
+	if i.Entry.StatusCode != 0 {
+		status = fmt.Sprint(i.Entry.StatusCode)
+	}
+	target := i.Entry.Request.Name
+	if target == "" {
+		target = i.Entry.Request.URL
+		if u, err := url.Parse(target); err == nil && u.Host != "" {
+			target = u.Host + u.RequestURI()
+		}
+	}
+	return fmt.Sprintf("%s %s %s", i.Entry.Request.Method, status, target)
+}
+
+func (i HistoryItem) Description() string {
+	return fmt.Sprintf("%s • %s", i.Entry.SentAt.Local().Format("2006-01-02 15:04"), i.Entry.Duration.Round(time.Millisecond))
+}
+
+func (i HistoryItem) FilterValue() string { return i.Entry.Request.URL }
+
+// newHistoryList creates the list of the history view, filtered by a query
+// rather than the list's own filter
+func newHistoryList() list.Model {
+	history := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	history.Title = "History"
+	history.SetShowHelp(false)
+	history.SetFilteringEnabled(false)
+	return history
+}
+
+// updateHistory handles keys in the history view
+func (s *SidebarPane) updateHistory(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "H":
+		s.showHistory = false
+		return s, nil
+	case "/":
+		return s, s.startPrompt(promptHistoryFilter, 0, s.historyQuery)
+	case tea.KeyUp.String(), "k":
+		if s.historyList.Index() == 0 {
+			s.historyList.Select(len(s.historyList.Items()) - 1)
+		} else {
+			s.historyList.CursorUp()
+		}
+		return s, nil
+	case tea.KeyDown.String(), "j":
+		if s.historyList.Index() == len(s.historyList.Items())-1 {
+			s.historyList.Select(0)
+		} else {
+			s.historyList.CursorDown()
+		}
+		return s, nil
+	}
+
+	var cmd tea.Cmd
+	s.historyList, cmd = s.historyList.Update(msg)
+	return s, cmd
+}
+
+// setHistory shows the loaded history entries
+func (s *SidebarPane) setHistory(entries []storage.HistoryEntry) {
+	items := make([]list.Item, len(entries))
+	for i, entry := range entries {
+		items[i] = HistoryItem{Entry: entry}
+	}
+	s.historyList.SetItems(items)
+	s.historyList.Title = fmt.Sprintf("History (%d)", len(items))
+	if s.historyQuery != "" {
+		s.historyList.Title += " " + s.historyQuery
+	}
+}
+
+// SelectedHistory returns the history entry under the cursor, when the history is shown
+func (s *SidebarPane) SelectedHistory() (*storage.HistoryEntry, bool) {
+	if !s.showHistory {
+		return nil, false
+	}
+	if item, ok := s.historyList.SelectedItem().(HistoryItem); ok {
+		return &item.Entry, true
+	}
+	return nil, false
+}
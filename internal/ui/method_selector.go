@@ -19,6 +19,9 @@ var (
 	putMethodStyle    = methodStyleBase.Foreground(lipgloss.Color("117")) // Blue
 	patchMethodStyle  = methodStyleBase.Foreground(lipgloss.Color("141")) // Purple
 	deleteMethodStyle = methodStyleBase.Foreground(lipgloss.Color("196")) // Red
+	graphQLStyle      = methodStyleBase.Foreground(lipgloss.Color("205")) // Pink
+	wsMethodStyle     = methodStyleBase.Foreground(lipgloss.Color("51"))  // Cyan
+	grpcMethodStyle   = methodStyleBase.Foreground(lipgloss.Color("184")) // Yellow
 )
 
 type MethodSelector struct {
@@ -68,6 +71,12 @@ func (m *MethodSelector) GetStyle() lipgloss.Style {
 		methodStyle = patchMethodStyle
 	case http.DELETE:
 		methodStyle = deleteMethodStyle
+	case http.GRAPHQL:
+		methodStyle = graphQLStyle
+	case http.WS:
+		methodStyle = wsMethodStyle
+	case http.GRPC:
+		methodStyle = grpcMethodStyle
 	default:
 		methodStyle = methodStyleBase
 	}
@@ -100,6 +109,9 @@ func NewMethodSelector() *MethodSelector {
 		http.PUT,
 		http.PATCH,
 		http.DELETE,
+		http.GRAPHQL,
+		http.WS,
+		http.GRPC,
 	}
 
 	return &MethodSelector{
@@ -108,3 +120,10 @@ func NewMethodSelector() *MethodSelector {
 		focused:       false,
 	}
 }
+
+// NewFrameKindSelector creates a selector for the kind of WebSocket frame to send
+func NewFrameKindSelector() *MethodSelector {
+	return &MethodSelector{
+		methods: http.FrameKinds,
+	}
+}
@@ -38,7 +38,7 @@ func NewConfiguredTextInput(config TextInputConfig) textinput.Model {
 }
 
 // NewURLInput creates a pre-configured URL input field
-func NewURLInput(db *storage.SQLiteStorage) textinput.Model {
+func NewURLInput(db storage.Storage) textinput.Model {
 	urls := []string{"http://", "https://"}
 
 	dbUrls, err := db.GetAllURLs()
@@ -103,7 +103,15 @@ func NewLoadTestInput(placeholder string, charLimit, width int) textinput.Model
 // NewHeadersTextArea creates a pre-configured headers textarea
 func NewHeadersTextArea() textarea.Model {
 	ta := textarea.New()
-	ta.Placeholder = "Content-Type = multipart/form-data,\nAuthorization= Bearer ...,"
+	ta.Placeholder = "Content-Type = multipart/form-data,\n!Authorization = Bearer ... (! marks a secret),"
+	return ta
+}
+
+// NewOptionsTextArea creates a pre-configured textarea for transport options
+func NewOptionsTextArea() textarea.Model {
+	ta := textarea.New()
+	ta.Placeholder = "tls.ca = ~/certs/ca.pem,\nproxy = http://localhost:8080,"
+	ta.SetHeight(3)
 	return ta
 }
 
@@ -113,3 +121,35 @@ func NewBodyTextArea() textarea.Model {
 	ta.Placeholder = "key = value,\nname = volt,\nversion=1.0"
 	return ta
 }
+
+// NewWSMessageTextArea creates a pre-configured textarea for WebSocket messages
+func NewWSMessageTextArea() textarea.Model {
+	ta := textarea.New()
+	ta.Placeholder = "{\"type\": \"subscribe\"}"
+	return ta
+}
+
+// NewGraphQLQueryTextArea creates a pre-configured textarea for GraphQL queries
+func NewGraphQLQueryTextArea() textarea.Model {
+	ta := textarea.New()
+	ta.Placeholder = "query {\n  user(id: 1) { name }\n}"
+	ta.ShowLineNumbers = true
+	return ta
+}
+
+// NewGraphQLVariablesTextArea creates a pre-configured textarea for GraphQL variables
+func NewGraphQLVariablesTextArea() textarea.Model {
+	ta := textarea.New()
+	ta.Placeholder = "{\"id\": 1}"
+	ta.SetHeight(3)
+	return ta
+}
+
+// NewOperationNameInput creates a pre-configured input for the GraphQL operation name
+func NewOperationNameInput() textinput.Model {
+	return NewConfiguredTextInput(TextInputConfig{
+		Placeholder: "optional, picks the operation to run",
+		CharLimit:   100,
+		Width:       60,
+	})
+}
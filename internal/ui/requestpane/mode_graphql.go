@@ -0,0 +1,187 @@
+package requestpane
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/ui"
+)
+
+// GraphQLMode implements the mode for GraphQL requests, whose body is
+// edited as a query, its variables and an operation name
+type GraphQLMode struct{}
+
+// HandleInput handles keyboard input in GraphQL mode
+func (gm *GraphQLMode) HandleInput(m *RequestPane, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	// Alt + Enter submits the request from any focus point
+	case "alt+enter":
+		return gm.handleSubmit(m, msg)
+	case "alt+i":
+		m.syncRequest()
+		m.SetStatus("Fetching schema...")
+		return m, ui.IntrospectCmd(m.Client, m.DB, m.outgoing())
+	}
+
+	switch FieldIndex(m.FocusManager.CurrentIndex()) {
+	case FieldMethodSelector:
+		switch msg.String() {
+		case tea.KeyRight.String(), "l":
+			m.MethodSelector.Next()
+		case tea.KeyLeft.String(), "h":
+			m.MethodSelector.Prev()
+		}
+		m.syncModeWithMethod()
+	case FieldURL:
+		var cmd tea.Cmd
+		*m.URLInput, cmd = m.URLInput.Update(msg)
+		return m, cmd
+	case FieldName:
+		var cmd tea.Cmd
+		*m.NameInput, cmd = m.NameInput.Update(msg)
+		return m, cmd
+	case FieldHeaders:
+		var cmd tea.Cmd
+		*m.Headers, cmd = m.Headers.Update(msg)
+		return m, cmd
+	case FieldGQLQuery:
+		// ctrl+n/ctrl+p pick a completion and ctrl+y inserts it, as in Vim
+		if len(m.completions) > 0 {
+			switch msg.String() {
+			case "ctrl+n":
+				m.cycleCompletion(1)
+				return m, nil
+			case "ctrl+p":
+				m.cycleCompletion(-1)
+				return m, nil
+			case "ctrl+y":
+				m.acceptCompletion()
+				return m, nil
+			}
+		}
+		var cmd tea.Cmd
+		*m.GQLQuery, cmd = m.GQLQuery.Update(msg)
+		m.updateCompletions()
+		return m, cmd
+	case FieldGQLVariables:
+		var cmd tea.Cmd
+		*m.GQLVariables, cmd = m.GQLVariables.Update(msg)
+		return m, cmd
+	case FieldGQLOperation:
+		var cmd tea.Cmd
+		*m.GQLOperationName, cmd = m.GQLOperationName.Update(msg)
+		return m, cmd
+	case FieldGQLOptions:
+		var cmd tea.Cmd
+		*m.Options, cmd = m.Options.Update(msg)
+		return m, cmd
+	case FieldGQLSubmit:
+		return gm.handleSubmit(m, msg)
+	}
+	return m, nil
+}
+
+// handleSubmit handles the submit button in GraphQL mode
+func (gm *GraphQLMode) handleSubmit(m *RequestPane, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case tea.KeyEnter.String(), "alt+enter":
+		if m.RequestInProgress {
+			return m, nil
+		}
+
+		m.syncRequest()
+		if len(m.ParseErrors) > 0 {
+			m.SetStatus(m.ParseErrors[len(m.ParseErrors)-1])
+			return m, nil
+		}
+		m.RequestInProgress = true
+
+		m.Stopwatch.Reset()
+		stopwatchCmd := m.Stopwatch.Start()
+
+		return m, tea.Batch(stopwatchCmd, sendRequestCmd(m.Client, m.outgoing()))
+	}
+	return m, nil
+}
+
+// GetFocusManager returns the focus manager for GraphQL mode
+func (gm *GraphQLMode) GetFocusManager(m *RequestPane) *ui.FocusManager {
+	return ui.NewFocusManager(gm.components(m))
+}
+
+// GetFocusManagerWithIndex returns the focus manager for GraphQL mode starting at a specific index
+func (gm *GraphQLMode) GetFocusManagerWithIndex(m *RequestPane, index int) *ui.FocusManager {
+	return ui.NewFocusManagerWithIndex(gm.components(m), index)
+}
+
+func (gm *GraphQLMode) components(m *RequestPane) []ui.Focusable {
+	return []ui.Focusable{
+		m.MethodSelector,
+		m.URLInput,
+		m.NameInput,
+		m.Headers,
+		m.GQLQuery,
+		m.GQLVariables,
+		m.GQLOperationName,
+		m.Options,
+		m.SubmitButton,
+	}
+}
+
+// OnEnter is called when entering GraphQL mode
+func (gm *GraphQLMode) OnEnter(m *RequestPane) {
+	m.GraphQLMode = true
+	m.updateCompletions()
+}
+
+// OnExit is called when exiting GraphQL mode
+func (gm *GraphQLMode) OnExit(m *RequestPane) {
+	m.GraphQLMode = false
+	m.completions = nil
+}
+
+// queryBeforeCursor returns the text of the query editor up to the cursor
+func (m *RequestPane) queryBeforeCursor() string {
+	lines := strings.Split(m.GQLQuery.Value(), "\n")
+	row := min(m.GQLQuery.Line(), len(lines)-1)
+	info := m.GQLQuery.LineInfo()
+	line := []rune(lines[row])
+	col := min(info.StartColumn+info.ColumnOffset, len(line))
+
+	before := strings.Join(lines[:row], "\n")
+	if row > 0 {
+		before += "\n"
+	}
+	return before + string(line[:col])
+}
+
+// updateCompletions refreshes the completions for the word before the cursor
+func (m *RequestPane) updateCompletions() {
+	m.completions, m.completion = nil, 0
+
+	// Schemas are cached per URL, a lookup by primary key is cheap enough to do inline
+	draft := &http.Request{URL: m.URLInput.Value()}
+	if url := draft.Inherit(m.Collections).URL; m.schemaURL != url {
+		schema, fetchedAt, err := m.DB.LoadGraphQLSchema(url)
+		if err != nil {
+			schema = nil
+		}
+		m.schemaURL, m.GraphQLSchema, m.schemaFetchedAt = url, schema, fetchedAt
+	}
+	if m.GraphQLSchema == nil {
+		return
+	}
+	m.completionPrefix, m.completions = m.GraphQLSchema.Complete(m.queryBeforeCursor())
+}
+
+// cycleCompletion moves the selected completion by step, wrapping around
+func (m *RequestPane) cycleCompletion(step int) {
+	m.completion = (m.completion + step + len(m.completions)) % len(m.completions)
+}
+
+// acceptCompletion inserts the rest of the selected completion at the cursor
+func (m *RequestPane) acceptCompletion() {
+	m.GQLQuery.InsertString(strings.TrimPrefix(m.completions[m.completion], m.completionPrefix))
+	m.updateCompletions()
+}
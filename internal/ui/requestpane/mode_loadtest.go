@@ -1,7 +1,10 @@
 package requestpane
 
 import (
+	"strings"
+
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/owenHochwald/volt/internal/http"
 	"github.com/owenHochwald/volt/internal/ui"
 )
 
@@ -22,8 +25,14 @@ func (ltm *LoadTestMode) HandleInput(m *RequestPane, msg tea.KeyMsg) (tea.Model,
 		switch msg.String() {
 		case tea.KeyRight.String(), "l":
 			m.MethodSelector.Next()
+			for !loadTestable(m.MethodSelector.Current()) {
+				m.MethodSelector.Next()
+			}
 		case tea.KeyLeft.String(), "h":
 			m.MethodSelector.Prev()
+			for !loadTestable(m.MethodSelector.Current()) {
+				m.MethodSelector.Prev()
+			}
 		}
 	case FieldURL:
 		var cmd tea.Cmd
@@ -41,6 +50,10 @@ func (ltm *LoadTestMode) HandleInput(m *RequestPane, msg tea.KeyMsg) (tea.Model,
 		var cmd tea.Cmd
 		*m.Body, cmd = m.Body.Update(msg)
 		cmds = append(cmds, cmd)
+	case FieldOptions:
+		var cmd tea.Cmd
+		*m.Options, cmd = m.Options.Update(msg)
+		cmds = append(cmds, cmd)
 	case FieldLTConcurrency:
 		var cmd tea.Cmd
 		*m.LoadTestConcurrency, cmd = m.LoadTestConcurrency.Update(msg)
@@ -57,6 +70,10 @@ func (ltm *LoadTestMode) HandleInput(m *RequestPane, msg tea.KeyMsg) (tea.Model,
 		var cmd tea.Cmd
 		*m.LoadTestTimeout, cmd = m.LoadTestTimeout.Update(msg)
 		cmds = append(cmds, cmd)
+	case FieldLTLabel:
+		var cmd tea.Cmd
+		*m.LoadTestLabel, cmd = m.LoadTestLabel.Update(msg)
+		cmds = append(cmds, cmd)
 	case FieldLTSubmit:
 		return ltm.handleSubmit(m, msg)
 	}
@@ -64,6 +81,12 @@ func (ltm *LoadTestMode) HandleInput(m *RequestPane, msg tea.KeyMsg) (tea.Model,
 	return m, tea.Batch(cmds...)
 }
 
+// loadTestable reports whether load tests can send requests of the method.
+// They don't open WebSockets nor build GraphQL envelopes.
+func loadTestable(method string) bool {
+	return method != http.WS && method != http.GRAPHQL && method != http.GRPC
+}
+
 // handleSubmit handles the submit button in load test mode
 func (ltm *LoadTestMode) handleSubmit(m *RequestPane, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
@@ -78,10 +101,12 @@ func (ltm *LoadTestMode) handleSubmit(m *RequestPane, msg tea.KeyMsg) (tea.Model
 		config, err := m.buildJobConfig()
 		if err != nil {
 			m.ParseErrors = append(m.ParseErrors, "Load test config error: "+err.Error())
+			m.SetStatus("Load test config error: " + err.Error())
 			m.RequestInProgress = false
 			return m, nil
 		}
-		return m, ui.StartLoadTestCmd(config)
+		// runs of saved requests are kept in their history
+		return m, ui.StartLoadTestCmd(config, m.Request.ID, strings.TrimSpace(m.LoadTestLabel.Value()))
 	}
 	return m, nil
 }
@@ -94,10 +119,12 @@ func (ltm *LoadTestMode) GetFocusManager(m *RequestPane) *ui.FocusManager {
 		m.NameInput,
 		m.Headers,
 		m.Body,
+		m.Options,
 		m.LoadTestConcurrency,
 		m.LoadTestTotalReqs,
 		m.LoadTestQPS,
 		m.LoadTestTimeout,
+		m.LoadTestLabel,
 		m.SubmitButton,
 	}
 	return ui.NewFocusManager(unifiedComponents)
@@ -111,17 +138,23 @@ func (ltm *LoadTestMode) GetFocusManagerWithIndex(m *RequestPane, index int) *ui
 		m.NameInput,
 		m.Headers,
 		m.Body,
+		m.Options,
 		m.LoadTestConcurrency,
 		m.LoadTestTotalReqs,
 		m.LoadTestQPS,
 		m.LoadTestTimeout,
+		m.LoadTestLabel,
 		m.SubmitButton,
 	}
 	return ui.NewFocusManagerWithIndex(unifiedComponents, index)
 }
 
 // OnEnter is called when entering load test mode
-func (ltm *LoadTestMode) OnEnter(m *RequestPane) {}
+func (ltm *LoadTestMode) OnEnter(m *RequestPane) {
+	m.LoadTestMode = true
+}
 
 // OnExit is called when exiting load test mode
-func (ltm *LoadTestMode) OnExit(m *RequestPane) {}
+func (ltm *LoadTestMode) OnExit(m *RequestPane) {
+	m.LoadTestMode = false
+}
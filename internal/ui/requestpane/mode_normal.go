@@ -2,6 +2,7 @@ package requestpane
 
 import (
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/owenHochwald/volt/internal/http"
 	"github.com/owenHochwald/volt/internal/ui"
 )
 
@@ -10,9 +11,16 @@ type NormalMode struct{}
 
 // HandleInput handles keyboard input in normal mode
 func (nm *NormalMode) HandleInput(m *RequestPane, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
 	// Alt + Enter submits the request from any focus point
-	if msg.String() == "alt+enter" {
+	case "alt+enter":
 		return nm.handleSubmit(m, msg)
+	case "alt+g":
+		if m.MethodSelector.Current() == http.GRPC {
+			m.syncRequest()
+			m.SetStatus("Listing methods...")
+			return m, ui.ListGRPCMethodsCmd(m.Client, m.outgoing())
+		}
 	}
 
 	switch FieldIndex(m.FocusManager.CurrentIndex()) {
@@ -23,6 +31,7 @@ func (nm *NormalMode) HandleInput(m *RequestPane, msg tea.KeyMsg) (tea.Model, te
 		case tea.KeyLeft.String(), "h":
 			m.MethodSelector.Prev()
 		}
+		m.syncModeWithMethod()
 	case FieldURL:
 		var cmd tea.Cmd
 		*m.URLInput, cmd = m.URLInput.Update(msg)
@@ -39,6 +48,10 @@ func (nm *NormalMode) HandleInput(m *RequestPane, msg tea.KeyMsg) (tea.Model, te
 		var cmd tea.Cmd
 		*m.Body, cmd = m.Body.Update(msg)
 		return m, cmd
+	case FieldOptions:
+		var cmd tea.Cmd
+		*m.Options, cmd = m.Options.Update(msg)
+		return m, cmd
 	case FieldSubmitButton:
 		return nm.handleSubmit(m, msg)
 	}
@@ -59,7 +72,7 @@ func (nm *NormalMode) handleSubmit(m *RequestPane, msg tea.KeyMsg) (tea.Model, t
 		m.Stopwatch.Reset()
 		stopwatchCmd := m.Stopwatch.Start()
 
-		return m, tea.Batch(stopwatchCmd, sendRequestCmd(m.Client, m.Request))
+		return m, tea.Batch(stopwatchCmd, sendRequestCmd(m.Client, m.outgoing()))
 	}
 	return m, nil
 }
@@ -72,6 +85,7 @@ func (nm *NormalMode) GetFocusManager(m *RequestPane) *ui.FocusManager {
 		m.NameInput,
 		m.Headers,
 		m.Body,
+		m.Options,
 		m.SubmitButton,
 	}
 	return ui.NewFocusManager(components)
@@ -85,6 +99,7 @@ func (nm *NormalMode) GetFocusManagerWithIndex(m *RequestPane, index int) *ui.Fo
 		m.NameInput,
 		m.Headers,
 		m.Body,
+		m.Options,
 		m.SubmitButton,
 	}
 	return ui.NewFocusManagerWithIndex(components, index)
@@ -0,0 +1,137 @@
+package requestpane
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/owenHochwald/volt/internal/ui"
+)
+
+// WebSocketMode implements the mode for WS requests: submitting connects,
+// then sends the message box as a frame until the connection is closed
+type WebSocketMode struct{}
+
+// HandleInput handles keyboard input in WebSocket mode
+func (wsm *WebSocketMode) HandleInput(m *RequestPane, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	// Alt + Enter connects or sends from any focus point
+	case "alt+enter":
+		return wsm.handleSubmit(m, msg)
+	case "alt+p":
+		if m.WebSocket != nil {
+			return m, ui.PingCmd(m.WebSocket)
+		}
+		return m, nil
+	case "alt+d":
+		if m.WebSocket != nil {
+			return m, ui.CloseWebSocketCmd(m.WebSocket)
+		}
+		return m, nil
+	}
+
+	switch FieldIndex(m.FocusManager.CurrentIndex()) {
+	case FieldMethodSelector:
+		// The connection belongs to the WS request, so it stays until closed
+		if m.WebSocket != nil {
+			m.SetStatus("Disconnect (alt+d) before changing the method")
+			return m, nil
+		}
+		switch msg.String() {
+		case tea.KeyRight.String(), "l":
+			m.MethodSelector.Next()
+		case tea.KeyLeft.String(), "h":
+			m.MethodSelector.Prev()
+		}
+		m.syncModeWithMethod()
+	case FieldURL:
+		var cmd tea.Cmd
+		*m.URLInput, cmd = m.URLInput.Update(msg)
+		return m, cmd
+	case FieldName:
+		var cmd tea.Cmd
+		*m.NameInput, cmd = m.NameInput.Update(msg)
+		return m, cmd
+	case FieldHeaders:
+		var cmd tea.Cmd
+		*m.Headers, cmd = m.Headers.Update(msg)
+		return m, cmd
+	case FieldWSOptions:
+		var cmd tea.Cmd
+		*m.Options, cmd = m.Options.Update(msg)
+		return m, cmd
+	case FieldWSFrameKind:
+		switch msg.String() {
+		case tea.KeyRight.String(), "l":
+			m.WSFrameKind.Next()
+		case tea.KeyLeft.String(), "h":
+			m.WSFrameKind.Prev()
+		}
+	case FieldWSMessage:
+		var cmd tea.Cmd
+		*m.WSMessage, cmd = m.WSMessage.Update(msg)
+		return m, cmd
+	case FieldWSSubmit:
+		return wsm.handleSubmit(m, msg)
+	}
+	return m, nil
+}
+
+// handleSubmit connects when disconnected, and sends the message otherwise
+func (wsm *WebSocketMode) handleSubmit(m *RequestPane, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case tea.KeyEnter.String(), "alt+enter":
+		if m.RequestInProgress {
+			return m, nil
+		}
+
+		if m.WebSocket != nil {
+			return m, ui.SendFrameCmd(m.WebSocket, m.WSFrameKind.Current(), m.WSMessage.Value())
+		}
+
+		m.syncRequest()
+		request := m.outgoing()
+		// checked as it will be sent, its variables expanded
+		unsealed, err := m.Client.Unseal(request)
+		if err == nil {
+			err = unsealed.Validate()
+		}
+		if err != nil {
+			m.SetStatus(err.Error())
+			return m, nil
+		}
+		m.RequestInProgress = true
+		return m, connectWebSocketCmd(m.Client, request)
+	}
+	return m, nil
+}
+
+// GetFocusManager returns the focus manager for WebSocket mode
+func (wsm *WebSocketMode) GetFocusManager(m *RequestPane) *ui.FocusManager {
+	return ui.NewFocusManager(wsm.components(m))
+}
+
+// GetFocusManagerWithIndex returns the focus manager for WebSocket mode starting at a specific index
+func (wsm *WebSocketMode) GetFocusManagerWithIndex(m *RequestPane, index int) *ui.FocusManager {
+	return ui.NewFocusManagerWithIndex(wsm.components(m), index)
+}
+
+func (wsm *WebSocketMode) components(m *RequestPane) []ui.Focusable {
+	return []ui.Focusable{
+		m.MethodSelector,
+		m.URLInput,
+		m.NameInput,
+		m.Headers,
+		m.Options,
+		m.WSFrameKind,
+		m.WSMessage,
+		m.SubmitButton,
+	}
+}
+
+// OnEnter is called when entering WebSocket mode
+func (wsm *WebSocketMode) OnEnter(m *RequestPane) {
+	m.WebSocketMode = true
+}
+
+// OnExit is called when exiting WebSocket mode
+func (wsm *WebSocketMode) OnExit(m *RequestPane) {
+	m.WebSocketMode = false
+}
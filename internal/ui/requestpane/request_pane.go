@@ -21,22 +21,44 @@ const (
 	FieldName
 	FieldHeaders
 	FieldBody
+	FieldOptions
 	FieldSubmitButton
 )
 
 // Load test mode field indices (extend from base fields)
 const (
-	FieldLTConcurrency FieldIndex = iota + 5
+	FieldLTConcurrency FieldIndex = iota + 6
 	FieldLTTotalReqs
 	FieldLTQPS
 	FieldLTTimeout
+	FieldLTLabel
 	FieldLTSubmit
 )
 
+// WebSocket mode field indices (the body is replaced by the message box)
+const (
+	FieldWSOptions FieldIndex = iota + 4
+	FieldWSFrameKind
+	FieldWSMessage
+	FieldWSSubmit
+)
+
+// GraphQL mode field indices (the body is replaced by the query editors)
+const (
+	FieldGQLQuery FieldIndex = iota + 4
+	FieldGQLVariables
+	FieldGQLOperation
+	FieldGQLOptions
+	FieldGQLSubmit
+)
+
 // RequestPane is the main component for handling HTTP request input
 type RequestPane struct {
 	Client *http.Client
 
+	// CookieJar is attached to Client while cookies are enabled
+	CookieJar *http.CookieJar
+
 	Stopwatch stopwatch.Model
 	Quitting  bool
 
@@ -49,20 +71,38 @@ type RequestPane struct {
 	NameInput      *textinput.Model
 	Headers        *textarea.Model
 	Body           *textarea.Model
+	Options        *textarea.Model
 	SubmitButton   *ui.SubmitButton
 
 	Request *http.Request
 
+	// saved is the request as last loaded or saved, to tell whether it has unsaved changes
+	saved http.Request
+
+	// loadedBody is the body of the request as loaded, sent as it is while
+	// the body editor still holds loadedBodyValue
+	loadedBody, loadedBodyValue string
+
+	// Collections are the ones the request is in, outermost first, whose
+	// defaults it inherits when sent
+	Collections []http.Collection
+
 	Height int
 
 	ParseErrors []string
 
+	// StatusMessage is shown above the help text, e.g. the outcome of a save
+	StatusMessage string
+
+	// copyingAs is set after alt+x, while the next key picks the snippet format
+	copyingAs bool
+
 	HeadersExpanded bool
 	BodyExpanded    bool
 
 	RequestInProgress bool
 
-	DB *storage.SQLiteStorage
+	DB storage.Storage
 
 	// Load test mode fields
 	LoadTestMode        bool
@@ -71,6 +111,31 @@ type RequestPane struct {
 	LoadTestTotalReqs   *textinput.Model
 	LoadTestQPS         *textinput.Model
 	LoadTestTimeout     *textinput.Model
+	LoadTestLabel       *textinput.Model // names the run in the request's load test history
+
+	// WebSocket mode fields
+	WebSocketMode bool
+	WSFrameKind   *ui.MethodSelector
+	WSMessage     *textarea.Model
+
+	// WebSocket is the open connection, nil when disconnected
+	WebSocket *http.WSConn
+
+	// GraphQL mode fields
+	GraphQLMode      bool
+	GQLQuery         *textarea.Model
+	GQLVariables     *textarea.Model
+	GQLOperationName *textinput.Model
+
+	// GraphQLSchema is the cached schema of the URL in schemaURL, used for completion
+	GraphQLSchema   *http.GraphQLSchema
+	schemaURL       string
+	schemaFetchedAt time.Time
+
+	// Completions for the word before the cursor in the query editor
+	completionPrefix string
+	completions      []string
+	completion       int
 }
 
 // Init initializes the request pane
@@ -93,6 +158,56 @@ func (m *RequestPane) GetCurrentMethod() string {
 	return m.MethodSelector.Current()
 }
 
+// SetStatus sets the status line shown under the request form
+func (m *RequestPane) SetStatus(status string) {
+	m.StatusMessage = status
+}
+
+// ToggleCookies attaches or detaches the cookie jar from the client
+func (m *RequestPane) ToggleCookies() {
+	if m.Client.CookiesEnabled() {
+		m.Client.SetCookieJar(nil)
+		m.SetStatus("Cookie jar disabled")
+		return
+	}
+	m.Client.SetCookieJar(m.CookieJar)
+	m.SetStatus("Cookie jar enabled")
+}
+
+// Dirty reports whether the request has changes that aren't saved
+func (m *RequestPane) Dirty() bool {
+	return !sameRequest(&m.saved, m.Request)
+}
+
+// MarkSaved records that the request was saved, taking the ID, version and
+// sealed headers it was saved with, once its save completes
+func (m *RequestPane) MarkSaved(saved ui.RequestSavedMsg) {
+	if saved.Request != m.Request {
+		return
+	}
+	request := m.Request
+	request.ID, request.Version, request.UpdatedAt = saved.ID, saved.Version, saved.UpdatedAt
+	request.Headers = saved.Headers
+	// secret values are shown masked once saved
+	if len(request.Secrets) > 0 {
+		m.Headers.SetValue(formatHeaders(request))
+	}
+	m.markSaved()
+}
+
+// outgoing returns the request to send, with the defaults of its collections.
+// Its variables are expanded by the client once its secrets are decrypted.
+func (m *RequestPane) outgoing() *http.Request {
+	return m.Request.Inherit(m.Collections)
+}
+
+// Insecure reports whether the current request skips TLS certificate
+// verification, either through its own options or the environment's
+func (m *RequestPane) Insecure() bool {
+	opts, err := http.ParseTLSOptions(http.MergeOptions(m.Client.Defaults, m.outgoing().Options))
+	return err == nil && opts.Insecure
+}
+
 // ResultMsgCleanup resets the stopwatch and request state after a response
 func (m *RequestPane) ResultMsgCleanup() {
 	m.Stopwatch.Stop()
@@ -100,6 +215,18 @@ func (m *RequestPane) ResultMsgCleanup() {
 	m.RequestInProgress = false
 }
 
+// SetWebSocket attaches the open connection, or detaches it when conn is nil
+func (m *RequestPane) SetWebSocket(conn *http.WSConn) {
+	m.WebSocket = conn
+	m.RequestInProgress = false
+}
+
+// SetGraphQLSchema sets the schema used for completion of queries to url
+func (m *RequestPane) SetGraphQLSchema(url string, schema *http.GraphQLSchema, fetchedAt time.Time) {
+	m.schemaURL, m.GraphQLSchema, m.schemaFetchedAt = url, schema, fetchedAt
+	m.updateCompletions()
+}
+
 // ExitLoadTestMode exits load test mode and resets state
 func (m *RequestPane) ExitLoadTestMode() {
 	if m.FocusManager != nil {
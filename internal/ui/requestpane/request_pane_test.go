@@ -0,0 +1,142 @@
+package requestpane
+
+import (
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/secrets"
+	"github.com/owenHochwald/volt/internal/storage"
+)
+
+// newTestPane returns a request pane whose client has the variables, the
+// token one sealed
+func newTestPane(t *testing.T, variables map[string]string, token string) *RequestPane {
+	t.Helper()
+	vault, err := secrets.NewVault([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := vault.Seal(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := SetupRequestPane(storage.NewMemoryStorage(), vault)
+	m.Client.Variables = map[string]string{"token": sealed}
+	for key, value := range variables {
+		m.Client.Variables[key] = value
+	}
+	return &m
+}
+
+func TestRequestPane_SendsSecretVariables(t *testing.T) {
+	var authorization, path string
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		authorization, path = r.Header.Get("Authorization"), r.URL.Path
+	}))
+	defer server.Close()
+
+	m := newTestPane(t, map[string]string{"base_url": server.URL}, "hunter2")
+	m.reinitRequestPane(&http.Request{
+		Method:  http.GET,
+		URL:     "{{base_url}}/users/{{token}}",
+		Headers: map[string]string{"Authorization": "Bearer {{token}}"},
+	})
+
+	result := make(chan *http.Response, 1)
+	m.Client.Send(m.outgoing(), result)
+	if response := <-result; response.Error != "" {
+		t.Fatal(response.Error)
+	}
+	if authorization != "Bearer hunter2" {
+		t.Errorf("Authorization = %q, want the decrypted variable", authorization)
+	}
+	if path != "/users/hunter2" {
+		t.Errorf("path = %q, want the decrypted variable", path)
+	}
+}
+
+func TestRequestPane_LoadTestExpandsVariables(t *testing.T) {
+	m := newTestPane(t, map[string]string{"base_url": "https://api.example"}, "hunter2")
+	m.reinitRequestPane(&http.Request{
+		Method:  http.GET,
+		URL:     "{{base_url}}/users",
+		Headers: map[string]string{"Authorization": "Bearer {{token}}"},
+	})
+
+	config, err := m.buildJobConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Request.URL != "https://api.example/users" {
+		t.Errorf("URL = %q", config.Request.URL)
+	}
+	if got := config.Request.Headers["Authorization"]; got != "Bearer hunter2" {
+		t.Errorf("Authorization = %q, want the decrypted variable", got)
+	}
+}
+
+func TestRequestPane_CopyMasksSecretVariables(t *testing.T) {
+	m := newTestPane(t, nil, "hunter2")
+	m.reinitRequestPane(&http.Request{
+		Method:  http.GET,
+		URL:     "https://api.example/users?key={{token}}",
+		Headers: map[string]string{"X-Token": "{{token}}"},
+	})
+
+	request := m.Client.Redact(m.outgoing())
+	if request.URL != "https://api.example/users?key="+secrets.Mask {
+		t.Errorf("URL = %q, want the variable masked", request.URL)
+	}
+	if got := request.Headers["X-Token"]; got != secrets.Mask {
+		t.Errorf("X-Token = %q, want the variable masked", got)
+	}
+}
+
+func TestRequestPane_LoadKeepsBody(t *testing.T) {
+	for _, body := range []string{"", `{"tags": "a,b", "query": "x=1"}`, "user=a&password=b", "plain text"} {
+		m := newTestPane(t, nil, "hunter2")
+		m.reinitRequestPane(&http.Request{Method: http.POST, URL: "https://api.example", Body: body})
+		m.syncRequest()
+		if m.Request.Body != body {
+			t.Errorf("body %q was loaded as %q", body, m.Request.Body)
+		}
+		if !sameRequest(m.Request, &m.saved) {
+			t.Errorf("body %q: loading left unsaved changes", body)
+		}
+	}
+
+	// once edited, it is made from the editor
+	m := newTestPane(t, nil, "hunter2")
+	m.reinitRequestPane(&http.Request{Method: http.POST, URL: "https://api.example", Body: `{"name": "ada"}`})
+	m.Body.SetValue("name = grace")
+	m.syncRequest()
+	if m.Request.Body != `{"name":"grace"}` {
+		t.Errorf("edited body = %q", m.Request.Body)
+	}
+}
+
+func TestRequestPane_LoadCurlRawBody(t *testing.T) {
+	m := newTestPane(t, nil, "hunter2")
+	m.LoadCurl(`curl -X POST https://api.example/users -H 'Content-Type: application/json' -d '{"a":1,"b":"x=1"}'`)
+	if m.Request.Body != `{"a":1,"b":"x=1"}` {
+		t.Errorf("body = %q, want the -d payload", m.Request.Body)
+	}
+	if len(m.ParseErrors) > 0 {
+		t.Errorf("unexpected warnings: %v", m.ParseErrors)
+	}
+
+	// edited, it stays raw
+	m.Body.SetValue(`{"a":2,"b":"x=1"}`)
+	m.syncRequest()
+	if m.Request.Body != `{"a":2,"b":"x=1"}` {
+		t.Errorf("edited body = %q", m.Request.Body)
+	}
+	m.Body.SetValue("plain, text")
+	m.syncRequest()
+	if m.Request.Body != "plain, text" {
+		t.Errorf("edited body = %q", m.Request.Body)
+	}
+}
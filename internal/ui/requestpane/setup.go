@@ -5,12 +5,13 @@ import (
 
 	"github.com/charmbracelet/bubbles/stopwatch"
 	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/secrets"
 	"github.com/owenHochwald/volt/internal/storage"
 	"github.com/owenHochwald/volt/internal/ui"
 )
 
 // SetupRequestPane creates and initializes a new RequestPane
-func SetupRequestPane(db *storage.SQLiteStorage) RequestPane {
+func SetupRequestPane(db storage.Storage, vault *secrets.Vault) RequestPane {
 	methodSelector := ui.NewMethodSelector()
 
 	// Use factories for text inputs
@@ -20,26 +21,41 @@ func SetupRequestPane(db *storage.SQLiteStorage) RequestPane {
 	// Create text areas
 	headers := NewHeadersTextArea()
 	body := NewBodyTextArea()
+	options := NewOptionsTextArea()
 
 	submitButton := ui.NewSubmitButton()
 
+	// WebSocket inputs
+	wsFrameKind := ui.NewFrameKindSelector()
+	wsMessage := NewWSMessageTextArea()
+
+	// GraphQL inputs
+	gqlQuery := NewGraphQLQueryTextArea()
+	gqlVariables := NewGraphQLVariablesTextArea()
+	gqlOperationName := NewOperationNameInput()
+
 	// Load test inputs using factory
 	ltConcurrency := NewLoadTestInput("100", 5, 15)
 	ltTotalReqs := NewLoadTestInput("10000", 10, 15)
 	ltQPS := NewLoadTestInput("0 (unlimited)", 10, 15)
 	ltTimeout := NewLoadTestInput("30s", 10, 15)
+	ltLabel := NewLoadTestInput("e.g. before caching", 40, 30)
 
 	// Initialize with normal mode
 	normalMode := &NormalMode{}
 
+	client := http.InitClient(0, false)
+	client.Vault = vault
+
 	m := RequestPane{
 		MethodSelector:      methodSelector,
 		URLInput:            &urlInput,
 		NameInput:           &nameInput,
 		Headers:             &headers,
 		Body:                &body,
+		Options:             &options,
 		SubmitButton:        submitButton,
-		Client:              http.InitClient(0, false),
+		Client:              client,
 		Stopwatch:           stopwatch.NewWithInterval(10 * time.Millisecond),
 		Request:             http.NewDefaultRequest(),
 		DB:                  db,
@@ -47,11 +63,19 @@ func SetupRequestPane(db *storage.SQLiteStorage) RequestPane {
 		LoadTestTotalReqs:   &ltTotalReqs,
 		LoadTestQPS:         &ltQPS,
 		LoadTestTimeout:     &ltTimeout,
+		LoadTestLabel:       &ltLabel,
 		LoadTestMode:        false,
+		WSFrameKind:         wsFrameKind,
+		WSMessage:           &wsMessage,
+		GQLQuery:            &gqlQuery,
+		GQLVariables:        &gqlVariables,
+		GQLOperationName:    &gqlOperationName,
 		currentMode:         normalMode,
 	}
 
 	m.FocusManager = normalMode.GetFocusManager(&m)
+	m.syncRequest()
+	m.markSaved()
 
 	return m
 }
@@ -1,15 +1,23 @@
 package requestpane
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/secrets"
 	"github.com/owenHochwald/volt/internal/utils"
 )
 
+// secretKeyPrefix marks a header as secret in the headers textarea, e.g. "!Authorization = Bearer ..."
+const secretKeyPrefix = "!"
+
 // syncRequest synchronizes the UI state with the request model
 func (m *RequestPane) syncRequest() {
 	if m.Request.Headers == nil {
@@ -22,6 +30,63 @@ func (m *RequestPane) syncRequest() {
 
 	headerMap, headerErrors := utils.ParseKeyValuePairs(m.Headers.Value())
 	bodyMap, bodyErrors := utils.ParseKeyValuePairs(m.Body.Value())
+	optionMap, optionErrors := utils.ParseKeyValuePairs(m.Options.Value())
+	headerMap, m.Request.Secrets = m.resolveSecretHeaders(headerMap)
+
+	if len(optionMap) == 0 {
+		optionMap = nil
+	}
+	m.Request.Options = optionMap
+	if err := http.ValidateOptions(optionMap); err != nil {
+		optionErrors = append(optionErrors, err.Error())
+	}
+	headerErrors = append(headerErrors, optionErrors...)
+
+	// The message draft of a WS request is kept as is
+	if m.Request.Method == http.WS {
+		m.Request.Headers = headerMap
+		m.Request.Body = m.WSMessage.Value()
+		m.ParseErrors = headerErrors
+		return
+	}
+
+	// The body of a gRPC request is its message as JSON, kept as is
+	if m.Request.Method == http.GRPC {
+		m.Request.Headers = headerMap
+		m.Request.Body = m.Body.Value()
+		m.ParseErrors = headerErrors
+		return
+	}
+
+	// GraphQL requests are sent as the JSON envelope of the query
+	if m.Request.Method == http.GRAPHQL {
+		m.Request.Headers = headerMap
+		body, err := http.NewGraphQLBody(m.GQLQuery.Value(), m.GQLVariables.Value(), m.GQLOperationName.Value())
+		if err != nil {
+			headerErrors = append(headerErrors, err.Error())
+		} else {
+			m.Request.Body = body
+		}
+		m.ParseErrors = headerErrors
+		return
+	}
+
+	// The body is only made from the editor once edited, so loading a
+	// request doesn't rewrite it
+	if m.Body.Value() == m.loadedBodyValue {
+		m.Request.Headers = headerMap
+		m.Request.Body = m.loadedBody
+		m.ParseErrors = headerErrors
+		return
+	}
+
+	// A body that isn't key = value pairs, such as JSON or a form, is raw
+	if len(bodyErrors) > 0 || json.Valid([]byte(m.Body.Value())) {
+		m.Request.Headers = headerMap
+		m.Request.Body = m.Body.Value()
+		m.ParseErrors = headerErrors
+		return
+	}
 
 	jsonData, err := json.Marshal(bodyMap)
 	if err != nil {
@@ -34,7 +99,96 @@ func (m *RequestPane) syncRequest() {
 
 	m.Request.Headers = headerMap
 	m.Request.Body = string(jsonData)
-	m.ParseErrors = append(headerErrors, bodyErrors...)
+	m.ParseErrors = headerErrors
+}
+
+// markSaved takes a copy of the request to compare later edits with
+func (m *RequestPane) markSaved() {
+	m.saved = *m.Request
+	m.saved.Headers = maps.Clone(m.Request.Headers)
+	m.saved.Options = maps.Clone(m.Request.Options)
+	m.saved.Secrets = slices.Clone(m.Request.Secrets)
+}
+
+// sameRequest reports whether two requests have the same content. The order
+// of secret keys doesn't matter, it follows the headers map.
+func sameRequest(a, b *http.Request) bool {
+	return a.Name == b.Name && a.Method == b.Method && a.URL == b.URL && a.Body == b.Body &&
+		maps.Equal(a.Headers, b.Headers) && maps.Equal(a.Options, b.Options) &&
+		slices.Equal(slices.Sorted(slices.Values(a.Secrets)), slices.Sorted(slices.Values(b.Secrets)))
+}
+
+// setGraphQLEditors fills the query editors from the envelope of a GraphQL request
+func (m *RequestPane) setGraphQLEditors(body string) {
+	query, err := http.ParseGraphQLBody(body)
+	if err != nil {
+		m.ParseErrors = append(m.ParseErrors, err.Error())
+		return
+	}
+	m.GQLQuery.SetValue(query.Query)
+	m.GQLOperationName.SetValue(query.OperationName)
+
+	var variables bytes.Buffer
+	if len(query.Variables) > 0 && json.Indent(&variables, query.Variables, "", "  ") == nil {
+		m.GQLVariables.SetValue(variables.String())
+	}
+}
+
+// resolveSecretHeaders strips the secret marker from header keys and swaps
+// masked values back for the values they stand in for
+func (m *RequestPane) resolveSecretHeaders(headerMap map[string]string) (map[string]string, []string) {
+	resolved := make(map[string]string, len(headerMap))
+	var secretKeys []string
+
+	for key, value := range headerMap {
+		name, isSecret := strings.CutPrefix(key, secretKeyPrefix)
+		name = strings.TrimSpace(name)
+		if !isSecret {
+			resolved[name] = value
+			continue
+		}
+
+		secretKeys = append(secretKeys, name)
+		if value == secrets.Mask {
+			value = m.Request.Headers[name]
+		}
+		resolved[name] = value
+	}
+
+	return resolved, secretKeys
+}
+
+// bodyEditorValue returns a body as the key = value pairs of the body
+// editor, which turns them into a JSON object. It is false when the body
+// isn't such an object and is shown, and sent, as it is.
+func bodyEditorValue(body string) (string, bool) {
+	if body == "" {
+		return "", true
+	}
+	var fields map[string]string
+	if err := json.Unmarshal([]byte(body), &fields); err != nil {
+		return body, false
+	}
+	var value strings.Builder
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		if strings.ContainsAny(key, ",=") || strings.Contains(fields[key], ",") || strings.TrimSpace(fields[key]) == "" {
+			return body, false
+		}
+		value.WriteString(key + " = " + fields[key] + ",\n")
+	}
+	return value.String(), true
+}
+
+// formatHeaders renders request headers for the headers textarea, masking secret values
+func formatHeaders(request *http.Request) string {
+	display := maps.Clone(request.Headers)
+	for key := range request.Headers {
+		if request.IsSecret(key) {
+			delete(display, key)
+			display[secretKeyPrefix+key] = secrets.Mask
+		}
+	}
+	return utils.ParseMapToString(display)
 }
 
 // buildJobConfig builds a load test job configuration from current input
@@ -84,30 +238,72 @@ func (m *RequestPane) buildJobConfig() (*http.JobConfig, error) {
 
 	m.ParseErrors = append(m.ParseErrors, parseErrors...)
 
-	if err := m.Request.Validate(); err != nil {
+	// Secrets are decrypted only once the load test is about to start, and
+	// the request is checked as it will be sent
+	request, err := m.Client.Unseal(m.outgoing())
+	if err != nil {
+		return nil, err
+	}
+	if err := request.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
 
+	options := http.MergeOptions(m.Client.Defaults, request.Options)
+	tlsConfig, err := http.LoadTLSConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	dial, err := http.LoadProxyDialer(options, timeout)
+	if err != nil {
+		return nil, err
+	}
+	redirects, err := http.ParseRedirectPolicy(options)
+	if err != nil {
+		return nil, err
+	}
+	protocol, err := http.ParseProtocol(options)
+	if err != nil {
+		return nil, err
+	}
+	acceptEncoding, err := http.ParseAcceptEncoding(options)
+	if err != nil {
+		return nil, err
+	}
+
 	return &http.JobConfig{
-		Request:       m.Request,
-		Concurrency:   concurrency,
-		TotalRequests: totalRequests,
-		QPS:           qps,
-		Timeout:       timeout,
-		StreamUpdates: true,
+		Request:        request,
+		Concurrency:    concurrency,
+		TotalRequests:  totalRequests,
+		QPS:            qps,
+		Timeout:        timeout,
+		StreamUpdates:  true,
+		Cookies:        m.Client.CookiesEnabled(),
+		TLSConfig:      tlsConfig,
+		Dial:           dial,
+		Redirects:      redirects,
+		Protocol:       protocol,
+		AcceptEncoding: acceptEncoding,
 	}, nil
 }
 
-// sendRequestCmd creates a command to send an HTTP request
+// sendRequestCmd creates a command to send an HTTP request. The app sends
+// it, so that streamed responses can be followed and stopped.
 func sendRequestCmd(client *http.Client, request *http.Request) tea.Cmd {
 	return func() tea.Msg {
-		res := make(chan *http.Response)
-		go client.Send(request, res)
-
-		responseObject := <-res
+		return http.RequestStartMsg{
+			Client:  client,
+			Request: request,
+		}
+	}
+}
 
-		return http.ResultMsg{
-			Response: responseObject,
+// connectWebSocketCmd creates a command to open a WebSocket connection. The
+// app opens it, so that it can follow the frames.
+func connectWebSocketCmd(client *http.Client, request *http.Request) tea.Cmd {
+	return func() tea.Msg {
+		return http.WSConnectMsg{
+			Client:  client,
+			Request: request,
 		}
 	}
 }
@@ -1,8 +1,11 @@
 package requestpane
 
 import (
+	"strings"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/importer"
 	"github.com/owenHochwald/volt/internal/ui"
 	"github.com/owenHochwald/volt/internal/utils"
 )
@@ -15,6 +18,7 @@ func (m RequestPane) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case ui.SetRequestPaneRequestMsg:
 		m.reinitRequestPane(msg.Request)
+		m.Collections = msg.Collections
 		return m, nil
 
 	case tea.KeyMsg:
@@ -22,14 +26,36 @@ func (m RequestPane) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		if m.copyingAs {
+			return m, m.copyAs(msg.String())
+		}
+		if msg.Paste && m.URLInput.Focused() && importer.IsCurl(string(msg.Runes)) {
+			if m.WebSocket != nil {
+				m.SetStatus("Disconnect (alt+d) before pasting a curl command")
+				return m, nil
+			}
+			m.LoadCurl(string(msg.Runes))
+			return m, nil
+		}
+
 		// Global shortcuts
 		switch msg.String() {
+		case "alt+x":
+			m.copyingAs = true
+			m.SetStatus("Copy as: c curl • h HTTPie • g Go • p Python • j JavaScript • esc cancel")
+			return m, nil
 		case "alt+l":
 			m.toggleLoadTestMode()
 			return m, nil
+		case "alt+k":
+			m.ToggleCookies()
+			return m, nil
 		case tea.KeyCtrlS.String(), tea.KeyShiftDown.String():
 			m.syncRequest()
 			return m, ui.SaveRequestCmd(m.DB, m.Request)
+		case "alt+s":
+			m.syncRequest()
+			return m, ui.SaveRequestAsCmd(m.DB, m.Request)
 		case tea.KeyTab.String(), tea.KeyDown.String():
 			m.FocusManager.Next()
 			return m, nil
@@ -41,8 +67,10 @@ func (m RequestPane) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Delegate to current mode strategy
 		model, cmd := m.currentMode.HandleInput(&m, msg)
 		if ptr, ok := model.(*RequestPane); ok {
-			return *ptr, cmd
+			m = *ptr
 		}
+		// keeps the unsaved changes indicator current
+		m.syncRequest()
 		return m, cmd
 	}
 
@@ -50,8 +78,88 @@ func (m RequestPane) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// LoadCurl replaces the request with a new one made from a curl command,
+// listing what couldn't be converted in ParseErrors
+func (m *RequestPane) LoadCurl(command string) {
+	request, warnings, err := importer.ParseCurl(command)
+	if err != nil {
+		m.SetStatus("Invalid curl command: " + err.Error())
+		return
+	}
+	m.reinitRequestPane(request)
+	m.Collections = nil
+	m.ParseErrors = append(m.ParseErrors, warnings...)
+	if len(warnings) > 0 {
+		m.SetStatus("Pasted curl command: " + strings.Join(warnings, "; "))
+		return
+	}
+	m.SetStatus("Pasted curl command, ctrl+s saves it")
+}
+
+// snippetKeys pick the format after alt+x
+var snippetKeys = map[string]struct{ format, name string }{
+	"c": {"curl", "curl"},
+	"h": {"httpie", "HTTPie"},
+	"g": {"go", "Go"},
+	"p": {"python", "Python"},
+	"j": {"js", "JavaScript"},
+}
+
+// copyAs copies the request as a snippet in the format picked by key, with
+// variables resolved and secrets masked
+func (m *RequestPane) copyAs(key string) tea.Cmd {
+	m.copyingAs = false
+	snippet, ok := snippetKeys[key]
+	if !ok {
+		m.SetStatus("")
+		return nil
+	}
+
+	m.syncRequest()
+	request := m.Client.Redact(m.outgoing())
+	request.Options = http.MergeOptions(m.Client.Defaults, request.Options)
+	content, err := request.Snippet(snippet.format)
+	if err != nil {
+		m.SetStatus("Copy failed: " + err.Error())
+		return nil
+	}
+	return ui.CopyCmd(content, "request as "+snippet.name)
+}
+
 // toggleLoadTestMode toggles between normal and load test mode
 func (m *RequestPane) toggleLoadTestMode() {
+	if !loadTestable(m.MethodSelector.Current()) {
+		m.SetStatus("Load tests don't support " + m.MethodSelector.Current() + " requests")
+		return
+	}
+
+	if m.LoadTestMode {
+		m.setMode(&NormalMode{})
+	} else {
+		m.setMode(&LoadTestMode{})
+	}
+}
+
+// syncModeWithMethod switches to the mode editing requests of the selected method
+func (m *RequestPane) syncModeWithMethod() {
+	switch m.MethodSelector.Current() {
+	case http.WS:
+		if !m.WebSocketMode {
+			m.setMode(&WebSocketMode{})
+		}
+	case http.GRAPHQL:
+		if !m.GraphQLMode {
+			m.setMode(&GraphQLMode{})
+		}
+	default:
+		if m.WebSocketMode || m.GraphQLMode {
+			m.setMode(&NormalMode{})
+		}
+	}
+}
+
+// setMode switches to another mode, keeping the focus position where it can
+func (m *RequestPane) setMode(mode ModeStrategy) {
 	if m.FocusManager != nil {
 		m.FocusManager.Current().Blur()
 	}
@@ -62,13 +170,9 @@ func (m *RequestPane) toggleLoadTestMode() {
 		currentIndex = m.FocusManager.CurrentIndex()
 	}
 
-	m.LoadTestMode = !m.LoadTestMode
-
-	if m.LoadTestMode {
-		m.currentMode = &LoadTestMode{}
-	} else {
-		m.currentMode = &NormalMode{}
-	}
+	m.currentMode.OnExit(m)
+	m.currentMode = mode
+	m.currentMode.OnEnter(m)
 
 	// Create new focus manager, preserving index (clamped to valid range)
 	m.FocusManager = m.currentMode.GetFocusManagerWithIndex(m, currentIndex)
@@ -76,11 +180,39 @@ func (m *RequestPane) toggleLoadTestMode() {
 
 // reinitRequestPane reinits the request pane with a new request
 func (m *RequestPane) reinitRequestPane(request *http.Request) {
-	m.Request = request
+	// edits stay in the pane until saved
+	copied := *request
+	m.Request = &copied
 
 	m.MethodSelector.SetCurrentIndex(request.Method)
 	m.URLInput.SetValue(request.URL)
 	m.NameInput.SetValue(request.Name)
-	m.Headers.SetValue(utils.ParseMapToString(request.Headers))
-	m.Body.SetValue(request.Body[1 : len(request.Body)-1])
+	m.Headers.SetValue(formatHeaders(request))
+	m.Options.SetValue(utils.ParseMapToString(request.Options))
+
+	m.Body.SetValue("")
+	m.WSMessage.SetValue("")
+	m.GQLQuery.SetValue("")
+	m.GQLVariables.SetValue("")
+	m.GQLOperationName.SetValue("")
+
+	switch request.Method {
+	case http.WS:
+		// WS requests keep their message draft as the body
+		m.WSMessage.SetValue(request.Body)
+	case http.GRAPHQL:
+		m.setGraphQLEditors(request.Body)
+	case http.GRPC:
+		m.Body.SetValue(request.Body)
+	default:
+		body, _ := bodyEditorValue(request.Body)
+		m.Body.SetValue(body)
+	}
+	m.loadedBody, m.loadedBodyValue = request.Body, m.Body.Value()
+	m.syncModeWithMethod()
+	if m.GraphQLMode {
+		m.updateCompletions()
+	}
+	m.syncRequest()
+	m.markSaved()
 }
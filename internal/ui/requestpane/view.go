@@ -2,6 +2,7 @@ package requestpane
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/owenHochwald/volt/internal/ui"
@@ -15,6 +16,9 @@ func (m RequestPane) View() string {
 
 	nameLabel := ui.LabelStyle.Render("Name ")
 	nameLine := lipgloss.JoinHorizontal(lipgloss.Left, nameLabel, m.NameInput.View())
+	if m.Dirty() {
+		nameLine = lipgloss.JoinHorizontal(lipgloss.Left, nameLine, ui.StatusStyle.Render("● unsaved"))
+	}
 
 	headersLabel := ui.LabelStyle.Render("Headers ")
 	headersLine := lipgloss.JoinHorizontal(lipgloss.Left, headersLabel, m.Headers.View())
@@ -22,12 +26,23 @@ func (m RequestPane) View() string {
 	bodyLabel := ui.LabelStyle.Render("Body    ")
 	bodyLine := lipgloss.JoinHorizontal(lipgloss.Left, bodyLabel, m.Body.View())
 
+	optionsLabel := ui.LabelStyle.Render("Options ")
+	optionsLine := lipgloss.JoinHorizontal(lipgloss.Left, optionsLabel, m.Options.View())
+
+	// Skipping certificate verification should never go unnoticed
+	var insecureWarning string
+	if m.Insecure() {
+		insecureWarning = ui.WarningStyle.Render("⚠ TLS certificate verification is disabled (tls.insecure)")
+	}
+
 	// Render button based on state
 	var button string
 	var stopwatchCount string
 	if m.RequestInProgress {
 		if m.LoadTestMode {
 			button = ui.FocusedButton.Render("Running Load Test...")
+		} else if m.WebSocketMode {
+			button = ui.FocusedButton.Render("Connecting...")
 		} else {
 			button = ui.FocusedButton.Render("Sending...")
 			elapsed := m.Stopwatch.Elapsed()
@@ -37,10 +52,16 @@ func (m RequestPane) View() string {
 				Foreground(lipgloss.Color("241")).
 				Render(fmt.Sprintf("%.3fs", seconds))
 		}
-	} else if m.SubmitButton.IsFocused() {
-		button = ui.FocusedButton.Render("→ Send")
 	} else {
-		button = ui.UnfocusedButton.Render("→ Send")
+		label := "→ Send"
+		if m.WebSocketMode && m.WebSocket == nil {
+			label = "→ Connect"
+		}
+		if m.SubmitButton.IsFocused() {
+			button = ui.FocusedButton.Render(label)
+		} else {
+			button = ui.UnfocusedButton.Render(label)
+		}
 	}
 
 	// Render mode-specific content
@@ -65,6 +86,10 @@ func (m RequestPane) View() string {
 		ltTimeoutLine := lipgloss.JoinHorizontal(lipgloss.Left,
 			ltTimeoutLabel, m.LoadTestTimeout.View())
 
+		ltLabelLabel := ui.LabelStyle.Render("Label:          ")
+		ltLabelLine := lipgloss.JoinHorizontal(lipgloss.Left,
+			ltLabelLabel, m.LoadTestLabel.View())
+
 		mainContent = lipgloss.JoinVertical(
 			lipgloss.Left,
 			"",
@@ -72,17 +97,74 @@ func (m RequestPane) View() string {
 			nameLine,
 			headersLine,
 			bodyLine,
+			optionsLine,
+			insecureWarning,
 			"\n\n",
 			lipgloss.NewStyle().Foreground(lipgloss.Color("226")).Bold(true).Render("Load Test Configuration:"),
 			ltConcurrencyLine,
 			ltTotalLine,
 			ltQPSLine,
 			ltTimeoutLine,
+			ltLabelLine,
 			"",
 			button,
 		)
 
 		helpText = ui.HelpStyle.Render("alt/opt+l: exit load test mode • tab/↑/↓: navigate • enter: start load test")
+	} else if m.GraphQLMode {
+		// GraphQL mode - the body is replaced by the query editors
+		queryLabel := ui.LabelStyle.Render("Query   ")
+		queryLine := lipgloss.JoinHorizontal(lipgloss.Left, queryLabel, m.GQLQuery.View())
+
+		variablesLabel := ui.LabelStyle.Render("Vars    ")
+		variablesLine := lipgloss.JoinHorizontal(lipgloss.Left, variablesLabel, m.GQLVariables.View())
+
+		operationLabel := ui.LabelStyle.Render("Op Name ")
+		operationLine := lipgloss.JoinHorizontal(lipgloss.Left, operationLabel, m.GQLOperationName.View())
+
+		mainContent = lipgloss.JoinVertical(
+			lipgloss.Left,
+			"",
+			primaryLine,
+			nameLine,
+			headersLine,
+			queryLine,
+			m.renderCompletions(),
+			variablesLine,
+			operationLine,
+			optionsLine,
+			insecureWarning,
+			button,
+		)
+
+		helpText = ui.HelpStyle.Render("alt/opt+i: fetch schema • ctrl+n/p: pick field • ctrl+y: complete • alt/opt+enter: send • ctrl+s: save • alt/opt+s: save as new")
+	} else if m.WebSocketMode {
+		// WebSocket mode - the body is replaced by the message box
+		frameKindLabel := ui.LabelStyle.Render("Frame   ")
+		frameKindLine := lipgloss.JoinHorizontal(lipgloss.Left,
+			frameKindLabel, m.WSFrameKind.GetStyle().Render(m.WSFrameKind.Current()))
+
+		messageLabel := ui.LabelStyle.Render("Message ")
+		messageLine := lipgloss.JoinHorizontal(lipgloss.Left, messageLabel, m.WSMessage.View())
+
+		mainContent = lipgloss.JoinVertical(
+			lipgloss.Left,
+			"",
+			primaryLine,
+			nameLine,
+			headersLine,
+			optionsLine,
+			insecureWarning,
+			frameKindLine,
+			messageLine,
+			button,
+		)
+
+		if m.WebSocket != nil {
+			helpText = ui.HelpStyle.Render("alt/opt+enter: send frame • ←/→ or h/l: frame type • alt/opt+p: ping • alt/opt+d: disconnect • ctrl+s: save • alt/opt+s: save as new")
+		} else {
+			helpText = ui.HelpStyle.Render("tab/↑/↓: navigate • ←/→ or h/l: change method • alt/opt+enter: connect • ctrl+s: save • alt/opt+s: save as new")
+		}
 	} else {
 		// Normal mode
 		mainContent = lipgloss.JoinVertical(
@@ -92,29 +174,74 @@ func (m RequestPane) View() string {
 			nameLine,
 			headersLine,
 			bodyLine,
-			"",
+			optionsLine,
+			insecureWarning,
 			button,
 		)
 
-		helpText = ui.HelpStyle.Render("alt/opt+l: load test mode • tab/↑/↓: navigate • ←/→ or h/l: change method • alt/opt+enter: send • enter/→: accept URL • ctrl+s: save")
+		helpText = ui.HelpStyle.Render("alt/opt+l: load test mode • tab/↑/↓: navigate • ←/→ or h/l: change method • alt/opt+enter: send • enter/→: accept URL • ctrl+s: save • alt/opt+s: save as new")
 	}
 
 	var spacing string
 	if m.LoadTestMode {
-		spacing = lipgloss.NewStyle().Height(m.Height - 18).Render("")
+		spacing = lipgloss.NewStyle().Height(m.Height - 22).Render("")
+
+	} else if m.WebSocketMode {
+		spacing = lipgloss.NewStyle().Height(m.Height - 17).Render("")
+
+	} else if m.GraphQLMode {
+		spacing = lipgloss.NewStyle().Height(m.Height - 19).Render("")
 
 	} else {
-		spacing = lipgloss.NewStyle().Height(m.Height - 10).Render("")
+		spacing = lipgloss.NewStyle().Height(m.Height - 14).Render("")
 
 	}
 
+	var status string
+	if m.StatusMessage != "" {
+		status = ui.StatusStyle.Render(m.StatusMessage)
+	}
+
 	finalContent := lipgloss.JoinVertical(
 		lipgloss.Left,
 		mainContent,
 		spacing,
 		stopwatchCount,
+		status,
 		helpText,
 	)
 
 	return finalContent
 }
+
+// renderCompletions renders the fields that can be typed at the cursor of
+// the query editor, or where the schema for completion comes from
+func (m RequestPane) renderCompletions() string {
+	faint := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	if m.GraphQLSchema == nil {
+		return faint.Render("        no schema for this URL, alt/opt+i fetches it")
+	}
+	if len(m.completions) == 0 {
+		return faint.Render(fmt.Sprintf("        schema: %d types, fetched %s",
+			len(m.GraphQLSchema.Types), m.schemaFetchedAt.Local().Format("2006-01-02 15:04")))
+	}
+
+	// Show a window of completions around the selected one
+	const shown = 6
+	start := max(0, min(m.completion-shown/2, len(m.completions)-shown))
+	end := min(len(m.completions), start+shown)
+
+	items := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		if i == m.completion {
+			items = append(items, ui.FocusedButton.Render(m.completions[i]))
+		} else {
+			items = append(items, m.completions[i])
+		}
+	}
+	line := "        " + strings.Join(items, " ")
+	if len(m.completions) > shown {
+		line += faint.Render(fmt.Sprintf("  %d/%d", m.completion+1, len(m.completions)))
+	}
+	return line
+}
@@ -52,8 +52,9 @@ func formatContentByType(body, contentType string) string {
 		formatted := formatJSON(body)
 		return highlightContent(formatted, "json")
 
-	case strings.Contains(contentType, "image/jpeg"), strings.Contains(contentType, "image/"):
-		return fmt.Sprintf("Sorry, we don't support %s yet!", contentType)
+	case strings.Contains(contentType, "image/"):
+		// image bodies are previewed, see renderImage
+		return faintStyle.Render(fmt.Sprintf("Empty %s body", contentType))
 
 	case strings.Contains(contentType, "text/html"):
 		return highlightContent(body, "html")
@@ -64,8 +65,12 @@ func formatContentByType(body, contentType string) string {
 	case strings.Contains(contentType, "application/xml"), strings.Contains(contentType, "text/xml"):
 		return highlightContent(body, "xml")
 
+	case strings.Contains(contentType, "application/graphql-response+json"):
+		formatted := formatJSON(body)
+		return highlightContent(formatted, "json")
+
 	case strings.Contains(contentType, "application/graphql"):
-		return "Sorry, we don't support GraphQL yet!"
+		return highlightContent(body, "graphql")
 
 	case strings.Contains(contentType, "multipart/form-data"):
 		return "Sorry, we don't support multipart/form-data yet!"
@@ -111,16 +111,22 @@ func TestFormatContentByType(t *testing.T) {
 			wantContain: "html",
 		},
 		{
-			name:        "Image type shows unsupported message",
+			name:        "Empty image says so",
 			body:        "",
 			contentType: "image/jpeg",
-			wantContain: "don't support",
+			wantContain: "Empty image/jpeg",
 		},
 		{
-			name:        "GraphQL shows unsupported message",
-			body:        "",
+			name:        "GraphQL is highlighted",
+			body:        "{ users { id } }",
 			contentType: "application/graphql",
-			wantContain: "don't support",
+			wantContain: "users",
+		},
+		{
+			name:        "GraphQL response is formatted as JSON",
+			body:        `{"data":{"id":1}}`,
+			contentType: "application/graphql-response+json",
+			wantContain: "    ",
 		},
 		{
 			name:        "Unknown type shows unhandled message",
@@ -139,3 +145,16 @@ func TestFormatContentByType(t *testing.T) {
 		})
 	}
 }
+
+func TestPreviewOf(t *testing.T) {
+	if got := previewOf("short", 10); got != "short" {
+		t.Errorf("previewOf() = %q, want the whole body", got)
+	}
+	if got := previewOf("abcdef", 3); got != "abc" {
+		t.Errorf("previewOf() = %q, want abc", got)
+	}
+	// "é" is two bytes, the preview stops before it rather than in the middle
+	if got := previewOf("aé", 2); got != "a" {
+		t.Errorf("previewOf() = %q, want a", got)
+	}
+}
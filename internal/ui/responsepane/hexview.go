@@ -0,0 +1,49 @@
+package responsepane
+
+import (
+	"fmt"
+	"strings"
+)
+
+// hexRowWidth is the width of a row of 16 bytes, narrower panes get 8 per row
+const hexRowWidth = 78
+
+// renderHex renders data as a hex viewer: each row has the offset of its
+// first byte, the bytes in hex and the same bytes as ASCII, with dots for
+// bytes that can't be printed
+func renderHex(data []byte, width int) string {
+	perRow := 16
+	if width > 0 && width < hexRowWidth {
+		perRow = 8
+	}
+
+	var b strings.Builder
+	var ascii strings.Builder
+	for offset := 0; offset < len(data); offset += perRow {
+		row := data[offset:min(offset+perRow, len(data))]
+		b.WriteString(faintStyle.Render(fmt.Sprintf("%08x", offset)))
+		b.WriteString("  ")
+
+		ascii.Reset()
+		for i := 0; i < perRow; i++ {
+			if i == 8 {
+				b.WriteString(" ")
+			}
+			if i >= len(row) {
+				b.WriteString("   ")
+				continue
+			}
+			fmt.Fprintf(&b, "%02x ", row[i])
+			if row[i] >= 0x20 && row[i] < 0x7f {
+				ascii.WriteByte(row[i])
+			} else {
+				ascii.WriteByte('.')
+			}
+		}
+		b.WriteString(faintStyle.Render("|"))
+		b.WriteString(responseValueStyle.Render(ascii.String()))
+		b.WriteString(faintStyle.Render("|"))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
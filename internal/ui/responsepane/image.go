@@ -0,0 +1,287 @@
+package responsepane
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	_ "image/jpeg" // registered with image.Decode
+	"image/png"
+	"strings"
+
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/utils"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // registered with image.Decode
+)
+
+// Graphics protocols that images are previewed with
+const (
+	graphicsKitty  = "kitty"
+	graphicsSixel  = "sixel"
+	graphicsBlocks = "blocks" // half-block characters in true color, for any other terminal
+)
+
+// Assumed size of a terminal cell in pixels, for protocols that draw pixels
+const (
+	cellWidth  = 10
+	cellHeight = 20
+)
+
+// kittyChunkSize is the most base64 data sent in one kitty graphics command
+const kittyChunkSize = 4096
+
+// detectGraphics picks the protocol images are drawn with: the one set by
+// VOLT_GRAPHICS, or one the terminal is known to support
+func detectGraphics(getenv func(string) string) string {
+	switch value := strings.ToLower(getenv("VOLT_GRAPHICS")); value {
+	case graphicsKitty, graphicsSixel, graphicsBlocks:
+		return value
+	}
+
+	// tmux and screen swallow graphics unless passthrough is set up
+	if getenv("TMUX") != "" || strings.HasPrefix(getenv("TERM"), "screen") {
+		return graphicsBlocks
+	}
+	term, program := getenv("TERM"), getenv("TERM_PROGRAM")
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "", term == "xterm-kitty", term == "xterm-ghostty",
+		program == "ghostty", program == "WezTerm":
+		return graphicsKitty
+	case strings.Contains(term, "sixel"), term == "foot", strings.HasPrefix(term, "mlterm"),
+		program == "iTerm.app", program == "mintty":
+		return graphicsSixel
+	}
+	return graphicsBlocks
+}
+
+// imagePreview is an image body, decoded once when the response arrives
+type imagePreview struct {
+	img    image.Image
+	format string // png, jpeg, gif or webp
+	frames int    // frames of an animated GIF, the first is shown
+	err    error  // why the body couldn't be decoded
+}
+
+// decodeImage decodes an image body, or returns nil when the body isn't an image
+func decodeImage(res *http.Response) *imagePreview {
+	if !res.Binary() || (!strings.HasPrefix(res.ParseContentType(), "image/") && !strings.HasPrefix(res.DetectedType(), "image/")) {
+		return nil
+	}
+	if res.Truncated() {
+		return &imagePreview{err: fmt.Errorf("only the first %s was kept in memory", utils.FormatSize(len(res.Body)))}
+	}
+
+	data := []byte(res.Body)
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return &imagePreview{err: err}
+	}
+	preview := &imagePreview{img: img, format: format, frames: 1}
+	if format == "gif" {
+		if animation, err := gif.DecodeAll(bytes.NewReader(data)); err == nil {
+			preview.frames = len(animation.Image)
+		}
+	}
+	return preview
+}
+
+// renderImage renders the metadata of an image and a preview fitting in
+// width columns and height rows, drawn with the given graphics protocol
+func renderImage(res *http.Response, preview *imagePreview, graphics string, width, height int) string {
+	var b strings.Builder
+	if preview.err != nil {
+		b.WriteString(faintStyle.Render("No preview: " + preview.err.Error()))
+		b.WriteString("\n\n")
+		b.WriteString(renderBinary(res, width))
+		return b.String()
+	}
+
+	bounds := preview.img.Bounds()
+	b.WriteString(responseKeyStyle.Render(strings.ToUpper(preview.format) + " image"))
+	b.WriteString(responseValueStyle.Render(fmt.Sprintf(" %d×%d", bounds.Dx(), bounds.Dy())))
+	b.WriteString(faintStyle.Render(fmt.Sprintf(", %s, %s", colorModelName(preview.img.ColorModel()), utils.FormatSize(len(res.Body)))))
+	if preview.frames > 1 {
+		b.WriteString(faintStyle.Render(fmt.Sprintf(", %d frames", preview.frames)))
+	}
+	b.WriteString("\n")
+	b.WriteString(faintStyle.Render("x shows it as hex, s saves it"))
+	b.WriteString("\n\n")
+
+	// cells are about twice as tall as they are wide
+	cols, rows := fitImage(bounds.Dx(), bounds.Dy(), max(width, 1), max(height-3, 1))
+	switch graphics {
+	case graphicsKitty:
+		b.WriteString(encodeKitty(scaleImage(preview.img, cols*cellWidth, rows*cellHeight), cols, rows))
+	case graphicsSixel:
+		b.WriteString(encodeSixel(scaleImage(preview.img, cols*cellWidth, rows*cellHeight)))
+	default:
+		b.WriteString(renderBlocks(scaleImage(preview.img, cols, rows*2)))
+		return b.String()
+	}
+	// the image is drawn over the blank rows that follow, which keep the layout in place
+	b.WriteString(strings.Repeat("\n", rows))
+	return b.String()
+}
+
+// fitImage returns the columns and rows an image of w×h pixels takes when
+// scaled down to fit in cols×rows cells, keeping its aspect ratio. Small
+// images get one cell per two pixels across and down.
+func fitImage(w, h, cols, rows int) (int, int) {
+	if w <= 0 || h <= 0 {
+		return 1, 1
+	}
+	scale := min(float64(cols)/float64(w), float64(rows*2)/float64(h), 1)
+	return max(int(float64(w)*scale), 1), max(int(float64(h)*scale/2), 1)
+}
+
+// scaleImage resizes an image to w×h pixels
+func scaleImage(img image.Image, w, h int) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	xdraw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, img.Bounds(), xdraw.Src, nil)
+	return dst
+}
+
+// colorModelName names the color model of an image
+func colorModelName(model color.Model) string {
+	switch model {
+	case color.GrayModel, color.Gray16Model:
+		return "grayscale"
+	case color.RGBAModel, color.RGBA64Model, color.NRGBAModel, color.NRGBA64Model:
+		return "RGBA"
+	case color.YCbCrModel, color.NYCbCrAModel:
+		return "YCbCr"
+	case color.CMYKModel:
+		return "CMYK"
+	}
+	if _, ok := model.(color.Palette); ok {
+		return "paletted"
+	}
+	return "color"
+}
+
+// renderBlocks draws an image with half-block characters, two pixels per
+// cell: the top one as the foreground and the bottom one as the background.
+// Transparent pixels show the terminal's background.
+func renderBlocks(img *image.NRGBA) string {
+	bounds := img.Bounds()
+	var b strings.Builder
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += 2 {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			top := img.NRGBAAt(x, y)
+			bottom := color.NRGBA{}
+			if y+1 < bounds.Max.Y {
+				bottom = img.NRGBAAt(x, y+1)
+			}
+			switch {
+			case top.A < 128 && bottom.A < 128:
+				b.WriteString("\x1b[0m ")
+			case top.A < 128:
+				fmt.Fprintf(&b, "\x1b[0;38;2;%d;%d;%dm▄", bottom.R, bottom.G, bottom.B)
+			case bottom.A < 128:
+				fmt.Fprintf(&b, "\x1b[0;38;2;%d;%d;%dm▀", top.R, top.G, top.B)
+			default:
+				fmt.Fprintf(&b, "\x1b[38;2;%d;%d;%d;48;2;%d;%d;%dm▀", top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+			}
+		}
+		b.WriteString("\x1b[0m\n")
+	}
+	return b.String()
+}
+
+// encodeKitty draws an image with the kitty graphics protocol, scaled to
+// cols×rows cells. Earlier previews are deleted first, and the cursor is
+// left where it was.
+func encodeKitty(img image.Image, cols, rows int) string {
+	var data bytes.Buffer
+	png.Encode(&data, img)
+	encoded := base64.StdEncoding.EncodeToString(data.Bytes())
+
+	var b strings.Builder
+	b.WriteString("\x1b_Ga=d,d=A,q=2\x1b\\")
+	b.WriteString("\x1b7")
+	for i := 0; i < len(encoded); i += kittyChunkSize {
+		chunk := encoded[i:min(i+kittyChunkSize, len(encoded))]
+		more := 0
+		if i+kittyChunkSize < len(encoded) {
+			more = 1
+		}
+		if i == 0 {
+			fmt.Fprintf(&b, "\x1b_Ga=T,f=100,q=2,C=1,c=%d,r=%d,m=%d;%s\x1b\\", cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	b.WriteString("\x1b8")
+	return b.String()
+}
+
+// encodeSixel draws an image as sixels, dithered to a 256 color palette.
+// The cursor is left where it was.
+func encodeSixel(img image.Image) string {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	paletted := image.NewPaletted(image.Rect(0, 0, w, h), palette.Plan9)
+	draw.FloydSteinberg.Draw(paletted, paletted.Bounds(), img, bounds.Min)
+
+	var b strings.Builder
+	b.WriteString("\x1b7\x1bP0;1;0q")
+	fmt.Fprintf(&b, "\"1;1;%d;%d", w, h)
+	for i, c := range paletted.Palette {
+		r, g, bl, _ := c.RGBA()
+		fmt.Fprintf(&b, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, bl*100/0xffff)
+	}
+
+	// Each band is six pixels tall, drawn once per color it uses
+	for y := 0; y < h; y += 6 {
+		var used [256]bool
+		for dy := 0; dy < 6 && y+dy < h; dy++ {
+			for x := 0; x < w; x++ {
+				used[paletted.ColorIndexAt(x, y+dy)] = true
+			}
+		}
+		for index, ok := range used {
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(&b, "#%d", index)
+			writeSixelRow(&b, paletted, uint8(index), y)
+			b.WriteByte('$') // back to the start of the band
+		}
+		b.WriteByte('-') // next band
+	}
+	b.WriteString("\x1b\\\x1b8")
+	return b.String()
+}
+
+// writeSixelRow writes the pixels of one color in the band starting at row
+// y, run-length encoded
+func writeSixelRow(b *strings.Builder, img *image.Paletted, index uint8, y int) {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	sixel := func(x int) byte {
+		var bits byte
+		for dy := 0; dy < 6 && y+dy < h; dy++ {
+			if img.ColorIndexAt(x, y+dy) == index {
+				bits |= 1 << dy
+			}
+		}
+		return 63 + bits
+	}
+
+	for x := 0; x < w; {
+		c, run := sixel(x), 1
+		for x+run < w && sixel(x+run) == c {
+			run++
+		}
+		if run > 3 {
+			fmt.Fprintf(b, "!%d%c", run, c)
+		} else {
+			b.WriteString(strings.Repeat(string(c), run))
+		}
+		x += run
+	}
+}
@@ -0,0 +1,121 @@
+package responsepane
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	nethttp "net/http"
+	"strings"
+	"testing"
+
+	"github.com/owenHochwald/volt/internal/http"
+)
+
+// pngResponse returns a response with a w×h PNG body
+func pngResponse(t *testing.T, w, h int) *http.Response {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x * 40), G: uint8(y * 40), B: 200, A: 255})
+		}
+	}
+	var body bytes.Buffer
+	if err := png.Encode(&body, img); err != nil {
+		t.Fatal(err)
+	}
+	return &http.Response{
+		Headers: nethttp.Header{"Content-Type": {"image/png"}},
+		Body:    body.String(),
+	}
+}
+
+func TestDetectGraphics(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{"TERM": "xterm-256color"}, graphicsBlocks},
+		{map[string]string{"TERM": "xterm-kitty"}, graphicsKitty},
+		{map[string]string{"TERM_PROGRAM": "WezTerm"}, graphicsKitty},
+		{map[string]string{"TERM": "foot"}, graphicsSixel},
+		{map[string]string{"TERM": "xterm-kitty", "TMUX": "/tmp/tmux-1000/default"}, graphicsBlocks},
+		{map[string]string{"TERM": "xterm-kitty", "VOLT_GRAPHICS": "Sixel"}, graphicsSixel},
+	}
+	for _, tt := range tests {
+		if got := detectGraphics(func(key string) string { return tt.env[key] }); got != tt.want {
+			t.Errorf("detectGraphics(%v) = %s, want %s", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestFitImage(t *testing.T) {
+	tests := []struct {
+		w, h, cols, rows int
+		wantCols         int
+		wantRows         int
+	}{
+		{8, 4, 80, 20, 8, 2},       // small images aren't enlarged
+		{800, 400, 80, 40, 80, 20}, // wide images fit the width
+		{400, 800, 80, 20, 20, 20}, // tall images fit the height
+		{1000, 1, 10, 10, 10, 1},   // at least a row
+	}
+	for _, tt := range tests {
+		cols, rows := fitImage(tt.w, tt.h, tt.cols, tt.rows)
+		if cols != tt.wantCols || rows != tt.wantRows {
+			t.Errorf("fitImage(%d, %d, %d, %d) = %d, %d, want %d, %d", tt.w, tt.h, tt.cols, tt.rows, cols, rows, tt.wantCols, tt.wantRows)
+		}
+	}
+}
+
+func TestRenderImage(t *testing.T) {
+	res := pngResponse(t, 4, 2)
+	preview := decodeImage(res)
+	if preview == nil || preview.err != nil {
+		t.Fatalf("decodeImage() = %+v, want a decoded PNG", preview)
+	}
+
+	blocks := renderImage(res, preview, graphicsBlocks, 80, 24)
+	for _, want := range []string{"PNG image", "4×2", "RGBA", "▀", "\x1b[38;2;"} {
+		if !strings.Contains(blocks, want) {
+			t.Errorf("renderImage(blocks) = %q, want to contain %q", blocks, want)
+		}
+	}
+	if got := renderImage(res, preview, graphicsKitty, 80, 24); !strings.Contains(got, "\x1b_Ga=T,f=100") {
+		t.Errorf("renderImage(kitty) has no kitty graphics command")
+	}
+	if got := renderImage(res, preview, graphicsSixel, 80, 24); !strings.Contains(got, "\x1bP0;1;0q") {
+		t.Errorf("renderImage(sixel) has no sixel data")
+	}
+
+	// A broken image falls back to the hex viewer
+	res.Body = res.Body[:20]
+	got := renderImage(res, decodeImage(res), graphicsBlocks, 80, 24)
+	if !strings.Contains(got, "No preview") || !strings.Contains(got, "00000000") {
+		t.Errorf("renderImage() = %q, want the reason and a hex dump", got)
+	}
+
+	if preview := decodeImage(&http.Response{Headers: nethttp.Header{"Content-Type": {"application/json"}}, Body: "{}"}); preview != nil {
+		t.Errorf("decodeImage() = %+v for JSON, want nil", preview)
+	}
+}
+
+func TestRenderHex(t *testing.T) {
+	data := []byte("Hello, volt!\x00\x01\x02\x03 and a few more bytes")
+
+	got := renderHex(data, 120)
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("renderHex() has %d rows, want 3 of 16 bytes", len(lines))
+	}
+	for _, want := range []string{"00000000", "00000010", "48 65 6c 6c", "Hello, volt!....", "00 01 02 03"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("renderHex() = %q, want to contain %q", got, want)
+		}
+	}
+
+	if narrow := renderHex(data, 60); strings.Count(narrow, "\n") != 5 {
+		t.Errorf("renderHex() on a narrow pane has %d rows, want 5 of 8 bytes", strings.Count(narrow, "\n"))
+	}
+}
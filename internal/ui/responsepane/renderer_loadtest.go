@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/utils"
 )
 
 // renderLoadTestOverview renders the overview tab for load test results
@@ -42,20 +45,25 @@ func (m ResponsePane) renderLoadTestOverview() string {
 	b.WriteString(responseValueStyle.Render(fmt.Sprintf("%d (%.1f%%)", stats.FailedRequests, 100-successRate)))
 	b.WriteString("\n\n")
 
-	// Calculate throughput
 	elapsed := time.Since(stats.StartTime)
 	if !stats.EndTime.IsZero() {
 		elapsed = stats.EndTime.Sub(stats.StartTime)
 	}
-	throughput := 0.0
-	if elapsed.Seconds() > 0 {
-		throughput = float64(stats.CompletedRequests) / elapsed.Seconds()
-	}
 
 	// Throughput
 	b.WriteString(responseLabelStyle.Render("Throughput"))
 	b.WriteString(": ")
-	b.WriteString(responseValueStyle.Render(fmt.Sprintf("%.1f req/s", throughput)))
+	b.WriteString(responseValueStyle.Render(fmt.Sprintf("%.1f req/s", throughput(stats))))
+	b.WriteString("\n\n")
+
+	// Bytes received, and their decoded size when responses were compressed
+	received := utils.FormatSize(int(stats.BytesRecv))
+	if stats.BytesDecoded != stats.BytesRecv {
+		received += fmt.Sprintf(" (~%s decoded)", utils.FormatSize(int(stats.BytesDecoded)))
+	}
+	b.WriteString(responseLabelStyle.Render("Received"))
+	b.WriteString(": ")
+	b.WriteString(responseValueStyle.Render(received))
 	b.WriteString("\n\n")
 
 	// Duration
@@ -137,7 +145,7 @@ func (m ResponsePane) renderLoadTestErrors() string {
 
 	// Render each error code with styling
 	for code, count := range stats.Errors {
-		b.WriteString(responseKeyStyle.Render(fmt.Sprintf("HTTP %s", code)))
+		b.WriteString(responseKeyStyle.Render(http.ErrorLabel(code)))
 		b.WriteString(": ")
 		b.WriteString(responseValueStyle.Render(fmt.Sprintf("%d occurrences", count)))
 		b.WriteString("\n\n")
@@ -145,3 +153,213 @@ func (m ResponsePane) renderLoadTestErrors() string {
 
 	return b.String()
 }
+
+// timelineBarWidth is the width of the bar of the busiest second
+const timelineBarWidth = 30
+
+// renderLoadTestTimeline renders the requests and latency of every second of the test
+func (m ResponsePane) renderLoadTestTimeline() string {
+	stats := m.LoadTestStats
+	if stats == nil {
+		return "No timeline data"
+	}
+
+	var b strings.Builder
+	b.WriteString("Timeline\n")
+	b.WriteString(strings.Repeat("─", 60) + "\n\n")
+
+	if len(stats.Timeline) == 0 {
+		b.WriteString(faintStyle.Render("No requests completed yet."))
+		return b.String()
+	}
+
+	peak := 1
+	for _, bucket := range stats.Timeline {
+		peak = max(peak, bucket.Requests)
+	}
+	for _, bucket := range stats.Timeline {
+		bar := strings.Repeat("█", bucket.Requests*timelineBarWidth/peak)
+		b.WriteString(responseLabelStyle.Render(fmt.Sprintf("%4ds", int(bucket.Offset.Seconds()))))
+		b.WriteString(" ")
+		b.WriteString(responseKeyStyle.Render(fmt.Sprintf("%-*s", timelineBarWidth, bar)))
+		b.WriteString(responseValueStyle.Render(fmt.Sprintf(" %6d req", bucket.Requests)))
+		if bucket.Samples > 0 {
+			b.WriteString(responseValueStyle.Render(fmt.Sprintf("  %s", bucket.Latency().Round(time.Millisecond))))
+		}
+		if bucket.Failures > 0 {
+			b.WriteString(worseStyle.Render(fmt.Sprintf("  %d failed", bucket.Failures)))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// runsHeaderLines are the lines above the first run in the Runs tab
+const runsHeaderLines = 3
+
+// renderLoadTestRuns renders the saved load tests of the request
+func (m ResponsePane) renderLoadTestRuns() string {
+	var b strings.Builder
+	b.WriteString("Load Test Runs\n")
+	b.WriteString(strings.Repeat("─", 60) + "\n\n")
+
+	if len(m.runs) == 0 {
+		b.WriteString(faintStyle.Render("No saved runs. Load tests of saved requests are kept here."))
+		return b.String()
+	}
+
+	for i, run := range m.runs {
+		cursor := "  "
+		if i == m.runCursor {
+			cursor = responseKeyStyle.Render("> ")
+		}
+		b.WriteString(cursor)
+		b.WriteString(responseLabelStyle.Render(run.Name()))
+		b.WriteString(responseValueStyle.Render(fmt.Sprintf("  p50 %s  p99 %s  %.1f req/s  %d failed",
+			run.Stats.Percentiles.Percentile(50).Round(time.Millisecond),
+			run.Stats.Percentiles.Percentile(99).Round(time.Millisecond),
+			throughput(run.Stats), run.Stats.FailedRequests)))
+		if m.shownRun != nil && m.shownRun.ID == run.ID {
+			b.WriteString(faintStyle.Render(" [shown]"))
+		}
+		if m.baseline != nil && m.baseline.ID == run.ID {
+			b.WriteString(faintStyle.Render(" [baseline]"))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(faintStyle.Render("enter: open • c: compare the run shown with this one"))
+	return b.String()
+}
+
+// comparedMetric is a row of the Compare tab
+type comparedMetric struct {
+	name          string
+	value         func(stats *http.LoadTestStats) float64
+	format        func(value float64) string
+	lowerIsBetter bool
+}
+
+func formatLatency(value float64) string {
+	return time.Duration(value).Round(time.Millisecond).String()
+}
+
+func percentileMetric(name string, p float64) comparedMetric {
+	return comparedMetric{
+		name:          name,
+		value:         func(stats *http.LoadTestStats) float64 { return float64(stats.Percentiles.Percentile(p)) },
+		format:        formatLatency,
+		lowerIsBetter: true,
+	}
+}
+
+var comparedMetrics = []comparedMetric{
+	{
+		name:   "Requests",
+		value:  func(stats *http.LoadTestStats) float64 { return float64(stats.CompletedRequests) },
+		format: func(value float64) string { return fmt.Sprintf("%.0f", value) },
+	},
+	{
+		name:          "Failed",
+		value:         failureRate,
+		format:        func(value float64) string { return fmt.Sprintf("%.1f%%", value) },
+		lowerIsBetter: true,
+	},
+	{
+		name:   "Throughput",
+		value:  throughput,
+		format: func(value float64) string { return fmt.Sprintf("%.1f req/s", value) },
+	},
+	{
+		name:          "Min",
+		value:         func(stats *http.LoadTestStats) float64 { return float64(stats.MinDuration) },
+		format:        formatLatency,
+		lowerIsBetter: true,
+	},
+	percentileMetric("p50", 50),
+	percentileMetric("p90", 90),
+	percentileMetric("p95", 95),
+	percentileMetric("p99", 99),
+	{
+		name:          "Max",
+		value:         func(stats *http.LoadTestStats) float64 { return float64(stats.MaxDuration) },
+		format:        formatLatency,
+		lowerIsBetter: true,
+	},
+}
+
+// renderLoadTestCompare renders the stats shown side by side with the baseline's
+func (m ResponsePane) renderLoadTestCompare() string {
+	var b strings.Builder
+	b.WriteString("Comparison\n")
+	b.WriteString(strings.Repeat("─", 60) + "\n\n")
+
+	if m.baseline == nil {
+		b.WriteString(faintStyle.Render("Pick a run to compare with in the Runs tab (c)."))
+		return b.String()
+	}
+
+	shown := "this test"
+	if m.shownRun != nil {
+		shown = m.shownRun.Name()
+	}
+	b.WriteString(responseLabelStyle.Render("Baseline"))
+	b.WriteString(": ")
+	b.WriteString(responseValueStyle.Render(m.baseline.Name()))
+	b.WriteString("\n")
+	b.WriteString(responseLabelStyle.Render("Run     "))
+	b.WriteString(": ")
+	b.WriteString(responseValueStyle.Render(shown))
+	b.WriteString("\n\n")
+
+	b.WriteString(faintStyle.Render(fmt.Sprintf("%-12s%16s%16s%12s", "", "Baseline", "Run", "Change")))
+	b.WriteString("\n")
+	for _, metric := range comparedMetrics {
+		before, after := metric.value(m.baseline.Stats), metric.value(m.LoadTestStats)
+		b.WriteString(responseLabelStyle.Render(fmt.Sprintf("%-12s", metric.name)))
+		b.WriteString(responseValueStyle.Render(fmt.Sprintf("%16s%16s", metric.format(before), metric.format(after))))
+		b.WriteString(renderChange(before, after, metric.lowerIsBetter))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// renderChange renders the change from before to after in percent, green
+// when it is for the better and red when it is for the worse
+func renderChange(before, after float64, lowerIsBetter bool) string {
+	if before == after {
+		return faintStyle.Render(fmt.Sprintf("%12s", "="))
+	}
+	if before == 0 {
+		return faintStyle.Render(fmt.Sprintf("%12s", "new"))
+	}
+	change := (after - before) / before * 100
+	text := fmt.Sprintf("%+11.1f%%", change)
+	if (change < 0) == lowerIsBetter {
+		return betterStyle.Render(text)
+	}
+	return worseStyle.Render(text)
+}
+
+// throughput returns the requests completed per second
+func throughput(stats *http.LoadTestStats) float64 {
+	elapsed := time.Since(stats.StartTime)
+	if !stats.EndTime.IsZero() {
+		elapsed = stats.EndTime.Sub(stats.StartTime)
+	}
+	if elapsed.Seconds() <= 0 {
+		return 0
+	}
+	return float64(stats.CompletedRequests) / elapsed.Seconds()
+}
+
+// failureRate returns the percentage of the completed requests that failed
+func failureRate(stats *http.LoadTestStats) float64 {
+	if stats.CompletedRequests == 0 {
+		return 0
+	}
+	return float64(stats.FailedRequests) / float64(stats.CompletedRequests) * 100
+}
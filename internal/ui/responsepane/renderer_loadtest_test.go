@@ -0,0 +1,71 @@
+package responsepane
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/storage"
+)
+
+func testRun(id int64, label string, completed int) storage.LoadTestRun {
+	stats := http.NewLoadTestStats(completed)
+	stats.CompletedRequests = completed
+	stats.EndTime = stats.StartTime.Add(time.Second)
+	return storage.LoadTestRun{ID: id, RequestID: 1, RanAt: stats.StartTime, Label: label, Stats: stats}
+}
+
+func TestLoadTestRuns(t *testing.T) {
+	m := SetupResponsePane()
+	m.ShowLoadTestRuns(1, []storage.LoadTestRun{testRun(2, "after", 200), testRun(1, "before", 100)})
+
+	if TabIndex(m.activeTab) != TabLoadTestRuns {
+		t.Fatalf("activeTab = %d, want the Runs tab", m.activeTab)
+	}
+	if m.LoadTestStats.CompletedRequests != 200 {
+		t.Errorf("shown run has %d requests, want the newest one's 200", m.LoadTestStats.CompletedRequests)
+	}
+
+	// mark the older run as the baseline
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	if TabIndex(m.activeTab) != TabLoadTestCompare || m.baseline == nil || m.baseline.Label != "before" {
+		t.Fatalf("baseline = %v on tab %d, want the run labeled before on the Compare tab", m.baseline, m.activeTab)
+	}
+	compare := m.renderLoadTestCompare()
+	if !strings.Contains(compare, "+100.0%") {
+		t.Errorf("comparison doesn't show the requests doubling:\n%s", compare)
+	}
+
+	// runs of another request drop the baseline
+	m.SetLoadTestRuns(2, nil)
+	if m.baseline != nil {
+		t.Errorf("baseline kept for another request")
+	}
+}
+
+func TestRenderChange(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after float64
+		lowerIsBetter bool
+		want          string
+	}{
+		{"unchanged", 10, 10, true, "="},
+		{"from nothing", 0, 10, true, "new"},
+		{"slower", 100, 150, true, "+50.0%"},
+		{"faster", 100, 50, true, "-50.0%"},
+		{"more throughput", 100, 150, false, "+50.0%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderChange(tt.before, tt.after, tt.lowerIsBetter)
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("renderChange() = %q, want to contain %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,24 @@
 package responsepane
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	nethttp "net/http"
 	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/utils"
+)
+
+// Limits on how much of a body is rendered, the rest can be saved to a file
+const (
+	previewLimit       = 256 << 10 // text bodies
+	hexLimit           = 64 << 10  // the hex view, about four times as long once dumped
+	binaryPreviewLimit = 4 << 10   // the hex viewer under the summary of a binary body
 )
 
 // renderBody renders the response body with appropriate formatting and syntax highlighting
@@ -13,11 +28,200 @@ func (m ResponsePane) renderBody() string {
 	}
 
 	if m.Response.Error != "" {
+		if len(m.Response.Attempts) > 0 {
+			return m.Response.Error + "\n\n" + renderAttempts(m.Response)
+		}
 		return m.Response.Error
 	}
 
+	// The hex view shows the bytes as received, before decompression
+	if m.hexView {
+		raw := m.Response.RawBody()
+		if len(raw) > hexLimit {
+			return renderPreviewNote(hexLimit, int64(len(raw))) + renderHex(raw[:hexLimit], m.viewport.Width)
+		}
+		return renderHex(raw, m.viewport.Width)
+	}
+
+	if m.webSocket {
+		return renderFrames(m.Response.Frames)
+	}
+
+	if len(m.Response.Events) > 0 {
+		return renderEvents(m.Response.Events)
+	}
+
+	// gRPC messages are decoded to JSON, whatever the content type on the wire
+	if m.Response.GRPC {
+		return formatContentByType(m.Response.Body, "application/json")
+	}
+
+	if m.image != nil {
+		return renderImage(m.Response, m.image, m.graphics, m.viewport.Width, m.viewport.Height)
+	}
+	if m.Response.Binary() {
+		return renderBinary(m.Response, m.viewport.Width)
+	}
+
+	// Large bodies are cut short, formatting and highlighting all of it would freeze the pane
+	body, note := m.Response.Body, ""
+	if size := m.Response.BodySize(); size > previewLimit {
+		body = previewOf(body, previewLimit)
+		note = renderPreviewNote(len(body), size)
+	}
+
 	contentType := m.Response.ParseContentType()
-	return formatContentByType(m.Response.Body, contentType)
+	if note == "" && (m.Response.GraphQL || strings.Contains(contentType, "application/graphql-response+json")) {
+		if result, ok := http.ParseGraphQLResult(body); ok {
+			return renderGraphQLResult(result)
+		}
+	}
+	return note + formatContentByType(body, contentType)
+}
+
+// renderPreviewNote explains that only the first shown bytes of a body of size bytes are rendered
+func renderPreviewNote(shown int, size int64) string {
+	return faintStyle.Render(fmt.Sprintf("Showing the first %s of %s, s saves the whole body",
+		utils.FormatSize(shown), utils.FormatSize(int(size)))) + "\n\n"
+}
+
+// previewOf returns at most limit bytes of the start of body, without cutting a rune in half
+func previewOf(body string, limit int) string {
+	if len(body) <= limit {
+		return body
+	}
+	for limit > 0 && !utf8.RuneStart(body[limit]) {
+		limit--
+	}
+	return body[:limit]
+}
+
+// renderBinary summarizes a binary body, followed by its start in the hex viewer
+func renderBinary(res *http.Response, width int) string {
+	var b strings.Builder
+	b.WriteString(responseKeyStyle.Render("Binary body"))
+	b.WriteString(responseValueStyle.Render(" " + utils.FormatSize(int(res.BodySize()))))
+
+	contentType := res.ParseContentType()
+	if contentType == "" {
+		contentType = "no Content-Type"
+	}
+	b.WriteString(faintStyle.Render(", " + contentType))
+	if detected := res.DetectedType(); !strings.HasPrefix(contentType, detected) {
+		b.WriteString(faintStyle.Render(", looks like " + detected))
+	}
+	b.WriteString("\n")
+	b.WriteString(faintStyle.Render("x shows it as hex, s saves it"))
+	b.WriteString("\n\n")
+
+	b.WriteString(renderHex([]byte(res.Body[:min(len(res.Body), binaryPreviewLimit)]), width))
+	if res.BodySize() > binaryPreviewLimit {
+		b.WriteString(faintStyle.Render("..."))
+	}
+	return b.String()
+}
+
+// renderGraphQLResult renders the errors of a GraphQL response above its
+// data, so that partial failures aren't missed
+func renderGraphQLResult(result *http.GraphQLResult) string {
+	var b strings.Builder
+	if len(result.Errors) > 0 {
+		b.WriteString(graphQLErrorStyle.Render(fmt.Sprintf("✗ errors (%d)", len(result.Errors))))
+		b.WriteString("\n")
+		for _, graphQLError := range result.Errors {
+			b.WriteString("  • " + responseValueStyle.Render(graphQLError.Message))
+			if where := graphQLError.Where(); where != "" {
+				b.WriteString(faintStyle.Render("  " + where))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if result.Data == nil {
+		b.WriteString(faintStyle.Render("no data"))
+		return b.String()
+	}
+	b.WriteString(graphQLDataStyle.Render("✓ data"))
+	b.WriteString("\n")
+	b.WriteString(formatContentByType(string(result.Data), "application/json"))
+	return b.String()
+}
+
+// renderEvents renders Server-Sent Events with the time they arrived
+func renderEvents(events []http.StreamEvent) string {
+	var b strings.Builder
+	for i, event := range events {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(faintStyle.Render(event.Time.Format("15:04:05.000")))
+		b.WriteString("  ")
+		b.WriteString(responseKeyStyle.Render(event.Type()))
+		if event.ID != "" {
+			b.WriteString(faintStyle.Render("  id " + event.ID))
+		}
+		b.WriteString("\n")
+		for _, line := range strings.Split(event.Data, "\n") {
+			b.WriteString("  " + responseValueStyle.Render(line) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// renderFrames renders a WebSocket transcript, one frame per entry with
+// the time it was sent (→) or received (←)
+func renderFrames(frames []http.WSFrame) string {
+	if len(frames) == 0 {
+		return faintStyle.Render("Connected, no frames yet")
+	}
+
+	var b strings.Builder
+	for _, frame := range frames {
+		direction := "←"
+		if frame.Outgoing {
+			direction = "→"
+		}
+		b.WriteString(faintStyle.Render(frame.Time.Format("15:04:05.000")))
+		b.WriteString(" " + direction + " ")
+		b.WriteString(responseKeyStyle.Render(frame.Kind))
+
+		switch frame.Kind {
+		case http.FrameClose:
+			b.WriteString("  " + responseValueStyle.Render(http.CloseCodeText(frame.Code)))
+			if frame.Data != "" {
+				b.WriteString(faintStyle.Render("  " + frame.Data))
+			}
+			if frame.Error != "" {
+				b.WriteString("\n  " + frame.Error)
+			}
+			b.WriteString("\n")
+			continue
+		case http.FrameBinary:
+			b.WriteString(faintStyle.Render(fmt.Sprintf("  %d bytes", len(frame.Data))))
+			b.WriteString("\n")
+			for _, line := range strings.Split(strings.TrimSuffix(hex.Dump([]byte(frame.Data)), "\n"), "\n") {
+				b.WriteString("  " + responseValueStyle.Render(line) + "\n")
+			}
+			continue
+		}
+
+		b.WriteString("\n")
+		if frame.Data == "" {
+			continue
+		}
+		// JSON payloads are pretty printed and highlighted
+		if json.Valid([]byte(frame.Data)) {
+			for _, line := range strings.Split(formatContentByType(frame.Data, "application/json"), "\n") {
+				b.WriteString("  " + line + "\n")
+			}
+			continue
+		}
+		for _, line := range strings.Split(frame.Data, "\n") {
+			b.WriteString("  " + responseValueStyle.Render(line) + "\n")
+		}
+	}
+	return b.String()
 }
 
 // renderHeaders renders the response headers in a sorted, styled format
@@ -30,16 +234,30 @@ func (m ResponsePane) renderHeaders() string {
 	b.WriteString("Response Headers:\n")
 	b.WriteString(strings.Repeat("-", len("Response Headers:")+2) + "\n\n")
 
+	writeHeaders(&b, m.Response.Headers)
+
+	// gRPC status and trailers arrive after the messages
+	if len(m.Response.Trailers) > 0 {
+		b.WriteString("\nTrailers:\n")
+		b.WriteString(strings.Repeat("-", len("Trailers:")+2) + "\n\n")
+		writeHeaders(&b, m.Response.Trailers)
+	}
+
+	return b.String()
+}
+
+// writeHeaders renders headers sorted by key, one value per line
+func writeHeaders(b *strings.Builder, headers nethttp.Header) {
 	// Sort keys for consistent display
-	keys := make([]string, 0, len(m.Response.Headers))
-	for k := range m.Response.Headers {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
 
 	// Render each header with styling
 	for _, key := range keys {
-		values := m.Response.Headers[key]
+		values := headers[key]
 		for _, value := range values {
 			b.WriteString(responseKeyStyle.Render(key))
 			b.WriteString(": ")
@@ -47,10 +265,72 @@ func (m ResponsePane) renderHeaders() string {
 			b.WriteString("\n")
 		}
 	}
+}
+
+// renderCookies renders the cookies set by the response, one attribute per line
+func (m ResponsePane) renderCookies() string {
+	if m.Response == nil || len(m.Response.Cookies) == 0 {
+		return "No cookies set by this response"
+	}
+
+	var b strings.Builder
+	b.WriteString("Set-Cookie\n")
+	b.WriteString(strings.Repeat("─", 60) + "\n\n")
+
+	for _, cookie := range m.Response.Cookies {
+		b.WriteString(responseKeyStyle.Render(cookie.Name))
+		b.WriteString(" = ")
+		b.WriteString(responseValueStyle.Render(cookie.Value))
+		b.WriteString("\n")
+
+		for _, attr := range cookieAttributes(cookie) {
+			b.WriteString("  ")
+			b.WriteString(responseLabelStyle.Render(attr[0]))
+			b.WriteString(": ")
+			b.WriteString(responseValueStyle.Render(attr[1]))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
 
 	return b.String()
 }
 
+// cookieAttributes lists the attributes present on a cookie as label/value pairs
+func cookieAttributes(cookie *nethttp.Cookie) [][2]string {
+	var attrs [][2]string
+	if cookie.Domain != "" {
+		attrs = append(attrs, [2]string{"Domain", cookie.Domain})
+	}
+	if cookie.Path != "" {
+		attrs = append(attrs, [2]string{"Path", cookie.Path})
+	}
+	if !cookie.Expires.IsZero() {
+		attrs = append(attrs, [2]string{"Expires", cookie.Expires.Format(time.RFC1123)})
+	}
+	if cookie.MaxAge != 0 {
+		attrs = append(attrs, [2]string{"Max-Age", fmt.Sprintf("%ds", cookie.MaxAge)})
+	}
+	if cookie.Secure {
+		attrs = append(attrs, [2]string{"Secure", "yes"})
+	}
+	if cookie.HttpOnly {
+		attrs = append(attrs, [2]string{"HttpOnly", "yes"})
+	}
+	switch cookie.SameSite {
+	case nethttp.SameSiteLaxMode:
+		attrs = append(attrs, [2]string{"SameSite", "Lax"})
+	case nethttp.SameSiteStrictMode:
+		attrs = append(attrs, [2]string{"SameSite", "Strict"})
+	case nethttp.SameSiteNoneMode:
+		attrs = append(attrs, [2]string{"SameSite", "None"})
+	}
+	if cookie.Partitioned {
+		attrs = append(attrs, [2]string{"Partitioned", "yes"})
+	}
+	return attrs
+}
+
 // renderTiming renders the response timing information
 func (m ResponsePane) renderTiming() string {
 	if m.Response == nil {
@@ -84,9 +364,222 @@ func (m ResponsePane) renderTiming() string {
 	}
 	b.WriteString("\n\n")
 
-	b.WriteString(faintStyle.Render(
-		"Note: Detailed timing breakdown (DNS, TLS, TTFB) coming in a future release!",
-	))
+	if len(m.Response.Attempts) > 0 {
+		b.WriteString(responseLabelStyle.Render("Attempts"))
+		b.WriteString("\n")
+		b.WriteString(renderAttempts(m.Response))
+		b.WriteString("\n")
+	}
+
+	if m.Response.Timing == nil {
+		return b.String()
+	}
+	timing := m.Response.Timing
+
+	b.WriteString(responseLabelStyle.Render("Remote Address"))
+	b.WriteString(": ")
+	b.WriteString(responseValueStyle.Render(valueOr(timing.RemoteAddr, "unknown")))
+	b.WriteString("\n\n")
+
+	b.WriteString(responseLabelStyle.Render("Connection Reused"))
+	b.WriteString(": ")
+	if timing.ConnReused {
+		b.WriteString(responseValueStyle.Render("yes (keep-alive, no DNS/connect/TLS)"))
+	} else {
+		b.WriteString(responseValueStyle.Render("no (new connection)"))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(responseLabelStyle.Render("Waterfall"))
+	b.WriteString("\n")
+	b.WriteString(renderWaterfall(timing, 40))
+
+	return b.String()
+}
+
+// renderAttempts lists the retried attempts of a response, then the response itself
+func renderAttempts(res *http.Response) string {
+	outcome := func(status, err string) string {
+		if err != "" {
+			return errorStyle.Render(err)
+		}
+		return responseValueStyle.Render(status)
+	}
+
+	var b strings.Builder
+	for i, attempt := range res.Attempts {
+		wait := "retried after " + formatPhaseDuration(attempt.Delay)
+		if attempt.Delay == 0 {
+			wait = "retried immediately"
+		}
+		if attempt.RetryAfter {
+			wait += " (Retry-After)"
+		}
+		b.WriteString(fmt.Sprintf("  %d. %s  %s  %s\n", i+1, outcome(attempt.Status, attempt.Error),
+			faintStyle.Render(formatPhaseDuration(attempt.Duration)), faintStyle.Render(wait)))
+	}
+	b.WriteString(fmt.Sprintf("  %d. %s  %s\n", len(res.Attempts)+1, outcome(res.Status, res.Error), faintStyle.Render("final")))
+	return b.String()
+}
+
+// renderTLS renders the negotiated TLS connection and the peer certificate chain
+func (m ResponsePane) renderTLS() string {
+	if m.Response == nil || m.Response.TLS == nil {
+		return "No TLS connection (plain HTTP)"
+	}
+	info := m.Response.TLS
+
+	var b strings.Builder
+	b.WriteString("TLS Connection\n")
+	b.WriteString(strings.Repeat("─", 60) + "\n\n")
+
+	if info.Insecure {
+		b.WriteString(errorStyle.Render("⚠ Certificate verification disabled (tls.insecure)"))
+		b.WriteString("\n\n")
+	}
+
+	fields := [][2]string{
+		{"Version", info.Version},
+		{"Cipher Suite", info.CipherSuite},
+		{"Server Name", valueOr(info.ServerName, "-")},
+		{"ALPN", valueOr(info.ALPN, "-")},
+	}
+	for _, field := range fields {
+		b.WriteString(responseLabelStyle.Render(field[0]))
+		b.WriteString(": ")
+		b.WriteString(responseValueStyle.Render(field[1]))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(responseLabelStyle.Render("Certificate Chain"))
+	b.WriteString("\n")
+
+	now := time.Now()
+	for i, cert := range info.Chain {
+		b.WriteString(fmt.Sprintf("\n  %d. ", i))
+		b.WriteString(responseKeyStyle.Render(cert.Subject))
+		b.WriteString("\n")
+
+		b.WriteString("     Issuer:  " + responseValueStyle.Render(cert.Issuer) + "\n")
+		if len(cert.DNSNames) > 0 {
+			b.WriteString("     Names:   " + responseValueStyle.Render(strings.Join(cert.DNSNames, ", ")) + "\n")
+		}
+		b.WriteString("     Valid:   " + responseValueStyle.Render(
+			cert.NotBefore.Format("2006-01-02")+" → "+cert.NotAfter.Format("2006-01-02")) + "\n")
+		b.WriteString("     Expires: " + formatExpiry(cert.ExpiresIn(now)) + "\n")
+		b.WriteString("     SHA-256: " + faintStyle.Render(cert.SHA256) + "\n")
+	}
+
+	return b.String()
+}
+
+// formatExpiry describes the time left on a certificate, highlighting ones that expire soon
+func formatExpiry(left time.Duration) string {
+	days := int(left.Hours() / 24)
+	switch {
+	case left <= 0:
+		return expiredStyle.Render(fmt.Sprintf("expired %d days ago", -days))
+	case days < 30:
+		return expiringStyle.Render(fmt.Sprintf("in %d days", days))
+	default:
+		return responseValueStyle.Render(fmt.Sprintf("in %d days", days))
+	}
+}
+
+// renderRedirects renders each redirect hop, ending with the final response
+func (m ResponsePane) renderRedirects() string {
+	if m.Response == nil {
+		return "No response data"
+	}
+	if len(m.Response.Redirects) == 0 {
+		return "No redirects followed"
+	}
+
+	var b strings.Builder
+	b.WriteString("Redirect Chain\n")
+	b.WriteString(strings.Repeat("─", 60) + "\n")
+
+	for i, hop := range m.Response.Redirects {
+		b.WriteString(fmt.Sprintf("\n%d. ", i+1))
+		b.WriteString(responseKeyStyle.Render(hop.Status))
+		b.WriteString("  ")
+		b.WriteString(faintStyle.Render(formatPhaseDuration(hop.Duration)))
+		b.WriteString("\n")
+		b.WriteString("   " + responseLabelStyle.Render(hop.Method) + " " + responseValueStyle.Render(hop.URL) + "\n")
+		b.WriteString("   " + responseLabelStyle.Render("→") + " " + responseValueStyle.Render(hop.Location) + "\n")
+
+		keys := make([]string, 0, len(hop.Headers))
+		for key := range hop.Headers {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			b.WriteString("     " + faintStyle.Render(key+": "+strings.Join(hop.Headers[key], ", ")) + "\n")
+		}
+	}
+
+	b.WriteString(fmt.Sprintf("\n%d. ", len(m.Response.Redirects)+1))
+	b.WriteString(responseKeyStyle.Render(valueOr(m.Response.Status, m.Response.Error)))
+	b.WriteString("\n   " + responseValueStyle.Render(m.Response.URL) + "\n")
+
+	// A redirect as the final response means the policy stopped following
+	if m.Response.Headers.Get("Location") != "" {
+		b.WriteString("\n" + faintStyle.Render("Not followed: redirect limit reached (see the redirects option)") + "\n")
+	}
+
+	return b.String()
+}
+
+// renderWaterfall draws one bar per request phase, offset by when the phase started
+func renderWaterfall(timing *http.Timing, barWidth int) string {
+	total := timing.Total
+	if total <= 0 {
+		return faintStyle.Render("No timing data")
+	}
+
+	var b strings.Builder
+	for i, phase := range timing.Phases() {
+		offset := int(float64(phase.Start) / float64(total) * float64(barWidth))
+		length := int(float64(phase.Duration) / float64(total) * float64(barWidth))
+		if phase.Duration > 0 && length == 0 {
+			length = 1 // always show phases that happened
+		}
+		offset = min(offset, barWidth-length)
+
+		bar := strings.Repeat(" ", offset) +
+			waterfallStyles[i%len(waterfallStyles)].Render(strings.Repeat("█", length)) +
+			strings.Repeat(" ", max(barWidth-offset-length, 0))
 
+		b.WriteString(fmt.Sprintf("  %-18s ", phase.Name))
+		b.WriteString(faintStyle.Render("│"))
+		b.WriteString(bar)
+		b.WriteString(faintStyle.Render("│"))
+		b.WriteString(" ")
+		b.WriteString(responseValueStyle.Render(formatPhaseDuration(phase.Duration)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString(fmt.Sprintf("  %-18s  %s", "Total", responseValueStyle.Render(formatPhaseDuration(total))))
+	b.WriteString("\n")
 	return b.String()
 }
+
+// formatPhaseDuration shows durations with a precision that suits their size
+func formatPhaseDuration(d time.Duration) string {
+	switch {
+	case d == 0:
+		return "-"
+	case d < time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	default:
+		return d.Round(10 * time.Microsecond).String()
+	}
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/storage"
 )
 
 // ResponsePane is the component responsible for displaying HTTP responses and load test statistics
@@ -15,6 +16,20 @@ type ResponsePane struct {
 
 	viewport  viewport.Model
 	activeTab int
+	hexView   bool          // show the raw body as a hex dump
+	image     *imagePreview // the decoded body, when it is an image
+	graphics  string        // protocol images are drawn with, see detectGraphics
+	streaming bool          // the response body is still arriving
+	webSocket bool          // the response is a WebSocket transcript
+
+	// runs are the saved load tests of the request, newest first. shownRun is
+	// the one whose stats are shown, nil for a test that just ran, and
+	// baseline the one they are compared with.
+	runs          []storage.LoadTestRun
+	runsRequestID int64
+	runCursor     int
+	shownRun      *storage.LoadTestRun
+	baseline      *storage.LoadTestRun
 }
 
 // Init initializes the response pane
@@ -29,21 +44,81 @@ func (m *ResponsePane) SetFocused(focused bool) {
 
 // SetResponse updates the response pane with a new HTTP response
 func (m *ResponsePane) SetResponse(response *http.Response) {
+	// a body kept on disk is only needed while its response is shown
+	if m.Response != nil && m.Response != response {
+		m.Response.Close()
+	}
 	m.Response = response
 	m.isLoadTest = false
+	m.streaming = false
+	m.webSocket = false
 
+	m.image = nil
 	if m.Response != nil {
-		if m.Response.Error != "" {
-			m.viewport.SetContent(m.Response.Error)
-			return
+		m.image = decodeImage(m.Response)
+		m.viewport.SetContent(m.renderBody())
+		if m.Response.Streamed {
+			m.viewport.GotoBottom()
 		}
+	}
+}
+
+// StartStream shows the headers of a streamed response, whose body follows through AppendStream
+func (m *ResponsePane) StartStream(response *http.Response) {
+	m.SetResponse(response)
+	m.streaming = true
+	m.activeTab = int(TabBody)
+	m.updateViewportForActiveTab()
+}
+
+// AppendStream adds an event or chunk to the streamed response, scrolling
+// along unless the user has scrolled up
+func (m *ResponsePane) AppendStream(update http.StreamUpdate) {
+	if m.Response == nil || !m.streaming {
+		return
+	}
+	if update.Event != nil {
+		m.Response.Events = append(m.Response.Events, *update.Event)
+	}
+	m.Response.Body += update.Chunk
 
-		contentType := m.Response.ParseContentType()
-		content := formatContentByType(m.Response.Body, contentType)
-		m.viewport.SetContent(content)
+	if TabIndex(m.activeTab) == TabBody {
+		follow := m.viewport.AtBottom()
+		m.viewport.SetContent(m.renderBody())
+		if follow {
+			m.viewport.GotoBottom()
+		}
 	}
 }
 
+// StartWebSocket shows the handshake of a connection, whose frames follow through AppendFrame
+func (m *ResponsePane) StartWebSocket(response *http.Response) {
+	m.StartStream(response)
+	m.webSocket = true
+	m.viewport.SetContent(m.renderBody())
+}
+
+// AppendFrame adds a frame to the transcript, scrolling along unless the user has scrolled up
+func (m *ResponsePane) AppendFrame(frame http.WSFrame) {
+	if m.Response == nil || !m.webSocket {
+		return
+	}
+	m.Response.Frames = append(m.Response.Frames, frame)
+
+	if TabIndex(m.activeTab) == TabBody {
+		follow := m.viewport.AtBottom()
+		m.viewport.SetContent(m.renderBody())
+		if follow {
+			m.viewport.GotoBottom()
+		}
+	}
+}
+
+// EndWebSocket marks the connection as closed, keeping the transcript
+func (m *ResponsePane) EndWebSocket() {
+	m.streaming = false
+}
+
 // SetLoadTestStats updates the response pane with load test statistics
 func (m *ResponsePane) SetLoadTestStats(stats *http.LoadTestStats) {
 	m.LoadTestStats = stats
@@ -55,9 +130,49 @@ func (m *ResponsePane) SetLoadTestStats(stats *http.LoadTestStats) {
 // ClearLoadTestStats clears load test data and switches back to normal mode
 func (m *ResponsePane) ClearLoadTestStats() {
 	m.LoadTestStats = nil
+	m.shownRun = nil
 	m.isLoadTest = false
 }
 
+// SetLoadTestRuns sets the saved load tests of a request, keeping the
+// baseline if it is one of its runs
+func (m *ResponsePane) SetLoadTestRuns(requestID int64, runs []storage.LoadTestRun) {
+	if m.baseline != nil && m.baseline.RequestID != requestID {
+		m.baseline = nil
+	}
+	m.runs = runs
+	m.runsRequestID = requestID
+	m.runCursor = min(m.runCursor, max(len(runs)-1, 0))
+	if m.isLoadTest {
+		m.updateViewportForActiveTab()
+	}
+}
+
+// ShowLoadTestRuns lists the saved load tests of a request, showing the
+// newest one unless one of them already is
+func (m *ResponsePane) ShowLoadTestRuns(requestID int64, runs []storage.LoadTestRun) {
+	m.SetLoadTestRuns(requestID, runs)
+	if len(runs) > 0 && (m.shownRun == nil || m.shownRun.RequestID != requestID) {
+		m.showRun(runs[0])
+	}
+	m.isLoadTest = true
+	m.activeTab = int(TabLoadTestRuns)
+	m.updateViewportForActiveTab()
+}
+
+// LoadTestSaved marks the stats shown as those of a run once it was saved
+func (m *ResponsePane) LoadTestSaved(run storage.LoadTestRun) {
+	if m.LoadTestStats == run.Stats {
+		m.shownRun = &run
+	}
+}
+
+// showRun shows the stats of a saved load test
+func (m *ResponsePane) showRun(run storage.LoadTestRun) {
+	m.LoadTestStats = run.Stats
+	m.shownRun = &run
+}
+
 // SetHeight sets the height of the response pane
 func (m *ResponsePane) SetHeight(height int) {
 	m.height = height
@@ -1,6 +1,10 @@
 package responsepane
 
-import "github.com/charmbracelet/bubbles/viewport"
+import (
+	"os"
+
+	"github.com/charmbracelet/bubbles/viewport"
+)
 
 // SetupResponsePane creates and initializes a new ResponsePane with default values
 func SetupResponsePane() ResponsePane {
@@ -10,5 +14,6 @@ func SetupResponsePane() ResponsePane {
 		height:     30,
 		activeTab:  int(TabBody), // Start on Body tab
 		isLoadTest: false,
+		graphics:   detectGraphics(os.Getenv),
 	}
 }
@@ -38,4 +38,24 @@ var (
 				Padding(0, 1)
 
 	faintStyle = lipgloss.NewStyle().Faint(true)
+
+	streamingStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Bold(true)
+
+	expiringStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true) // orange
+	expiredStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true) // red
+
+	betterStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Bold(true)  // green
+	worseStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true) // red
+
+	graphQLErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true) // red
+	graphQLDataStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Bold(true)  // green
 )
+
+// Timing waterfall styles, one per request phase
+var waterfallStyles = []lipgloss.Style{
+	lipgloss.NewStyle().Foreground(lipgloss.Color("39")),  // DNS - blue
+	lipgloss.NewStyle().Foreground(lipgloss.Color("214")), // Connect - orange
+	lipgloss.NewStyle().Foreground(lipgloss.Color("141")), // TLS - purple
+	lipgloss.NewStyle().Foreground(lipgloss.Color("42")),  // Waiting - green
+	lipgloss.NewStyle().Foreground(lipgloss.Color("205")), // Transfer - pink
+}
@@ -1,12 +1,19 @@
 package responsepane
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
 
 // Tab indices for normal mode
 const (
 	TabBody TabIndex = iota
 	TabHeaders
+	TabCookies
 	TabTiming
+	TabTLS
+	TabRedirects
 )
 
 // Tab indices for load test mode
@@ -14,21 +21,37 @@ const (
 	TabLoadTestOverview TabIndex = iota
 	TabLoadTestLatency
 	TabLoadTestErrors
+	TabLoadTestTimeline
+	TabLoadTestRuns
+	TabLoadTestCompare
 )
 
 // TabIndex represents a tab position
 type TabIndex int
 
+// Tab names in display order, matching the indices above
+var (
+	normalTabNames   = []string{"Body", "Headers", "Cookies", "Timing", "TLS", "Redirects"}
+	loadTestTabNames = []string{"Overview", "Latency", "Errors", "Timeline", "Runs", "Compare"}
+)
+
 // renderTabs renders the tab bar for normal response mode
 func (m ResponsePane) renderTabs() string {
-	tabs := []string{"[1] Body", "[2] Headers", "[3] Timing"}
-	return m.renderTabBar(tabs)
+	return m.renderTabBar(numberTabs(normalTabNames))
 }
 
 // renderLoadTestTabs renders the tab bar for load test mode
 func (m ResponsePane) renderLoadTestTabs() string {
-	tabs := []string{"[1] Overview", "[2] Latency", "[3] Errors"}
-	return m.renderTabBar(tabs)
+	return m.renderTabBar(numberTabs(loadTestTabNames))
+}
+
+// numberTabs prefixes each tab with its shortcut key
+func numberTabs(names []string) []string {
+	tabs := make([]string, len(names))
+	for i, name := range names {
+		tabs[i] = fmt.Sprintf("[%d] %s", i+1, name)
+	}
+	return tabs
 }
 
 // renderTabBar is a helper that renders a tab bar with active/inactive styling
@@ -50,7 +73,7 @@ func (m ResponsePane) renderTabBar(tabs []string) string {
 // getMaxTabs returns the maximum number of tabs based on current mode
 func (m ResponsePane) getMaxTabs() int {
 	if m.isLoadTest {
-		return 3 // Overview, Latency, Errors
+		return len(loadTestTabNames)
 	}
-	return 3 // Body, Headers, Timing
+	return len(normalTabNames)
 }
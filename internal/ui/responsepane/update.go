@@ -1,8 +1,8 @@
 package responsepane
 
 import (
-	"github.com/atotto/clipboard"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/owenHochwald/volt/internal/ui"
 )
 
 // Update handles Bubble Tea messages and state transitions
@@ -14,17 +14,16 @@ func (m *ResponsePane) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.isLoadTest && TabIndex(m.activeTab) == TabLoadTestRuns && m.handleRunsKey(msg) {
+			return m, nil
+		}
 		switch msg.String() {
 		// Direct tab access
-		case "1":
-			m.activeTab = int(TabBody)
-			m.updateViewportForActiveTab()
-		case "2":
-			m.activeTab = int(TabHeaders)
-			m.updateViewportForActiveTab()
-		case "3":
-			m.activeTab = int(TabTiming)
-			m.updateViewportForActiveTab()
+		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+			if tab := int(msg.String()[0] - '1'); tab < m.getMaxTabs() {
+				m.activeTab = tab
+				m.updateViewportForActiveTab()
+			}
 		// Tab navigation
 		case "h", tea.KeyLeft.String():
 			maxTabs := m.getMaxTabs()
@@ -34,10 +33,22 @@ func (m *ResponsePane) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			maxTabs := m.getMaxTabs()
 			m.activeTab = (m.activeTab + 1) % maxTabs
 			m.updateViewportForActiveTab()
+		// Raw bytes as hex
+		case "x":
+			if m.Response != nil && !m.isLoadTest {
+				m.hexView = !m.hexView
+				m.activeTab = int(TabBody)
+				m.updateViewportForActiveTab()
+			}
+		// Save the whole body, even the part too large to keep in memory
+		case "s":
+			if m.Response != nil && !m.isLoadTest && m.Response.Error == "" && !m.streaming {
+				return m, ui.SaveBodyCmd(m.Response)
+			}
 		// Copy handling
 		case "y", "Y":
 			if m.Response != nil && !m.isLoadTest {
-				return m, m.copyToClipboard(m.Response.Body)
+				return m, ui.CopyCmd(m.Response.Body, "response body")
 			}
 		}
 	}
@@ -48,12 +59,29 @@ func (m *ResponsePane) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
-// copyToClipboard copies content to the system clipboard
-func (m ResponsePane) copyToClipboard(content string) tea.Cmd {
-	return func() tea.Msg {
-		clipboard.WriteAll(content)
-		return nil
+// handleRunsKey moves through the saved load tests, opening one or comparing
+// with it, and reports whether the key was used
+func (m *ResponsePane) handleRunsKey(msg tea.KeyMsg) bool {
+	if len(m.runs) == 0 {
+		return false
+	}
+	switch msg.String() {
+	case "j", tea.KeyDown.String():
+		m.runCursor = min(m.runCursor+1, len(m.runs)-1)
+	case "k", tea.KeyUp.String():
+		m.runCursor = max(m.runCursor-1, 0)
+	case tea.KeyEnter.String():
+		m.showRun(m.runs[m.runCursor])
+		m.activeTab = int(TabLoadTestOverview)
+	case "c":
+		baseline := m.runs[m.runCursor]
+		m.baseline = &baseline
+		m.activeTab = int(TabLoadTestCompare)
+	default:
+		return false
 	}
+	m.updateViewportForActiveTab()
+	return true
 }
 
 // updateViewportForActiveTab updates the viewport content based on the active tab
@@ -70,16 +98,17 @@ func (m *ResponsePane) updateViewportForActiveTab() {
 	var content string
 	switch TabIndex(m.activeTab) {
 	case TabBody:
-		if m.Response.Error != "" {
-			content = m.Response.Error
-		} else {
-			contentType := m.Response.ParseContentType()
-			content = formatContentByType(m.Response.Body, contentType)
-		}
+		content = m.renderBody()
 	case TabHeaders:
 		content = m.renderHeaders()
+	case TabCookies:
+		content = m.renderCookies()
 	case TabTiming:
 		content = m.renderTiming()
+	case TabTLS:
+		content = m.renderTLS()
+	case TabRedirects:
+		content = m.renderRedirects()
 	}
 	m.viewport.SetContent(content)
 }
@@ -99,6 +128,24 @@ func (m *ResponsePane) updateLoadTestTabContent() {
 		content = m.renderLoadTestLatency()
 	case TabLoadTestErrors:
 		content = m.renderLoadTestErrors()
+	case TabLoadTestTimeline:
+		content = m.renderLoadTestTimeline()
+	case TabLoadTestRuns:
+		m.viewport.SetContent(m.renderLoadTestRuns())
+		m.scrollToRun()
+		return
+	case TabLoadTestCompare:
+		content = m.renderLoadTestCompare()
 	}
 	m.viewport.SetContent(content)
 }
+
+// scrollToRun keeps the selected run of the Runs tab in sight
+func (m *ResponsePane) scrollToRun() {
+	line := runsHeaderLines + m.runCursor
+	if line < m.viewport.YOffset {
+		m.viewport.SetYOffset(line)
+	} else if line >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(line - m.viewport.Height + 1)
+	}
+}
@@ -26,6 +26,9 @@ func (m ResponsePane) renderNormalView() string {
 	var statusBar string
 	if m.Response.Error != "" {
 		statusBar = errorStyle.Render("ERROR")
+		if attempts := len(m.Response.Attempts); attempts > 0 {
+			statusBar += faintStyle.Render(fmt.Sprintf(" after %d attempts", attempts+1))
+		}
 		m.viewport.SetContent(m.Response.Error)
 	} else {
 		statusBar = m.renderHeaderBar()
@@ -53,7 +56,9 @@ func (m ResponsePane) renderLoadTestView() string {
 
 	// Status line
 	status := "Load Test "
-	if m.LoadTestStats.EndTime.IsZero() {
+	if m.shownRun != nil {
+		status += m.shownRun.Name()
+	} else if m.LoadTestStats.EndTime.IsZero() {
 		status += "In Progress..."
 	} else {
 		status += "Complete"
@@ -82,8 +87,25 @@ func (m ResponsePane) renderHeaderBar() string {
 	} else {
 		duration += " (direct)"
 	}
-	size := fmt.Sprintf(" %s", utils.FormatSize(len(m.Response.Body)))
-	return lipgloss.JoinHorizontal(lipgloss.Left, " | ", status, " | ", duration, " | ", size)
+	if attempts := len(m.Response.Attempts); attempts > 0 {
+		duration += fmt.Sprintf(" attempt %d", attempts+1)
+	}
+	size := fmt.Sprintf(" %s", utils.FormatSize(int(m.Response.BodySize())))
+	if encoding := m.Response.ContentEncoding(); encoding != "" {
+		size += fmt.Sprintf(" (%s %s)", utils.FormatSize(m.Response.WireSize), encoding)
+	}
+	if m.hexView {
+		size += " [hex]"
+	}
+	if m.webSocket {
+		size = fmt.Sprintf(" %d frames", len(m.Response.Frames))
+		if m.streaming {
+			size += " | " + streamingStyle.Render("● connected") + faintStyle.Render(" esc to close")
+		}
+	} else if m.streaming {
+		size += " | " + streamingStyle.Render("● streaming") + faintStyle.Render(" esc to stop")
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Left, " | ", m.Response.Proto, " ", status, " | ", duration, " | ", size)
 }
 
 // renderActiveTabContent renders the content for the currently active tab
@@ -98,8 +120,14 @@ func (m ResponsePane) renderActiveTabContent() string {
 		return m.viewport.View()
 	case TabHeaders:
 		return m.renderHeaders()
+	case TabCookies:
+		return m.renderCookies()
 	case TabTiming:
 		return m.renderTiming()
+	case TabTLS:
+		return m.renderTLS()
+	case TabRedirects:
+		return m.renderRedirects()
 	default:
 		return "Something went wrong."
 	}
@@ -0,0 +1,83 @@
+package searchpane
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/owenHochwald/volt/internal/storage"
+	"github.com/owenHochwald/volt/internal/ui"
+)
+
+// resultLimit is how many results the palette lists
+const resultLimit = 50
+
+// SearchPane is the palette searching saved requests, and the history when
+// asked, as words are typed
+type SearchPane struct {
+	db storage.Storage
+
+	input   textinput.Model
+	history bool // the history and its responses are searched too
+
+	results []storage.SearchResult
+	cursor  int
+	err     error
+
+	height, width int
+
+	Focused bool
+}
+
+func (m SearchPane) Init() tea.Cmd {
+	return nil
+}
+
+func (m *SearchPane) SetFocused(focused bool) {
+	m.Focused = focused
+}
+
+func (m *SearchPane) SetHeight(height int) {
+	m.height = height
+}
+
+func (m *SearchPane) SetWidth(width int) {
+	m.width = width
+	m.input.Width = max(width-10, 10)
+}
+
+// Open focuses the input, keeping the last search and its results
+func (m *SearchPane) Open() tea.Cmd {
+	m.input.CursorEnd()
+	if m.query().Text != "" {
+		return tea.Batch(m.input.Focus(), m.search())
+	}
+	return m.input.Focus()
+}
+
+// query is the search typed
+func (m SearchPane) query() storage.SearchQuery {
+	return storage.SearchQuery{
+		Text:    strings.TrimSpace(m.input.Value()),
+		History: m.history,
+		Limit:   resultLimit,
+	}
+}
+
+// search searches again for what is typed, clearing the results when nothing is
+func (m *SearchPane) search() tea.Cmd {
+	query := m.query()
+	if query.Text == "" {
+		m.results, m.cursor, m.err = nil, 0, nil
+		return nil
+	}
+	return ui.SearchCmd(m.db, query)
+}
+
+// selected returns the result under the cursor
+func (m SearchPane) selected() (storage.SearchResult, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.results) {
+		return storage.SearchResult{}, false
+	}
+	return m.results[m.cursor], true
+}
@@ -0,0 +1,90 @@
+package searchpane
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/storage"
+	"github.com/owenHochwald/volt/internal/ui"
+)
+
+// run applies a message and the commands it leads to, returning the
+// messages the pane sent the app
+func run(m SearchPane, msg tea.Msg) (SearchPane, []tea.Msg) {
+	var sent []tea.Msg
+	queue := []tea.Msg{msg}
+	for len(queue) > 0 {
+		msg, queue = queue[0], queue[1:]
+		model, cmd := m.Update(msg)
+		m = model.(SearchPane)
+		for _, msg := range flatten(cmd) {
+			switch msg.(type) {
+			case OpenResultMsg, CloseSearchModalMsg:
+				sent = append(sent, msg)
+			default:
+				queue = append(queue, msg)
+			}
+		}
+	}
+	return m, sent
+}
+
+func flatten(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		var msgs []tea.Msg
+		for _, cmd := range msg {
+			msgs = append(msgs, flatten(cmd)...)
+		}
+		return msgs
+	case nil:
+		return nil
+	default:
+		return []tea.Msg{msg}
+	}
+}
+
+func TestSearchPane(t *testing.T) {
+	db := storage.NewMemoryStorage()
+	for _, request := range []*http.Request{
+		{Name: "List users", Method: http.GET, URL: "https://api.example/users"},
+		{Name: "Create order", Method: http.POST, URL: "https://api.example/orders"},
+	} {
+		if err := db.Save(request); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := SetupSearchPane(db)
+	m.Open()
+	for _, r := range "order" {
+		m, _ = run(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if len(m.results) != 1 || m.results[0].URL != "https://api.example/"+storage.MatchStart+"orders"+storage.MatchEnd {
+		t.Fatalf("results = %+v, want the order request", m.results)
+	}
+
+	// results of what was typed before are dropped
+	stale := m.query()
+	stale.Text = "orde"
+	m, _ = run(m, ui.SearchResultsMsg{Query: stale})
+	if len(m.results) != 1 {
+		t.Errorf("stale results replaced the current ones: %+v", m.results)
+	}
+
+	_, sent := run(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(sent) != 1 {
+		t.Fatalf("enter sent %v, want the result opened", sent)
+	}
+	if open, ok := sent[0].(OpenResultMsg); !ok || open.Result.Kind != storage.SearchRequest {
+		t.Errorf("enter sent %#v, want the request opened", sent[0])
+	}
+
+	if _, sent := run(m, tea.KeyMsg{Type: tea.KeyEscape}); len(sent) != 1 {
+		t.Errorf("esc sent %v, want the palette closed", sent)
+	}
+}
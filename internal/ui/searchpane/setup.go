@@ -0,0 +1,22 @@
+package searchpane
+
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/owenHochwald/volt/internal/storage"
+)
+
+// SetupSearchPane sets up the search palette for the given storage
+func SetupSearchPane(db storage.Storage) SearchPane {
+	input := textinput.New()
+	input.Placeholder = "name, URL, header or body"
+	input.Prompt = "/ "
+	input.CharLimit = 256
+	input.Width = 50
+
+	return SearchPane{
+		db:     db,
+		input:  input,
+		height: 25,
+		width:  60,
+	}
+}
@@ -0,0 +1,70 @@
+package searchpane
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/owenHochwald/volt/internal/storage"
+	"github.com/owenHochwald/volt/internal/ui"
+)
+
+// CloseSearchModalMsg signals the app to close the search palette
+type CloseSearchModalMsg struct{}
+
+// OpenResultMsg asks the app to open the saved request or history entry found
+type OpenResultMsg struct {
+	Result storage.SearchResult
+}
+
+func (m SearchPane) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ui.SearchResultsMsg:
+		// results of an older search are dropped
+		if msg.Query != m.query() {
+			return m, nil
+		}
+		m.results, m.err = msg.Results, msg.Err
+		m.cursor = min(m.cursor, max(len(m.results)-1, 0))
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case tea.KeyDown.String(), "ctrl+n":
+			if m.cursor < len(m.results)-1 {
+				m.cursor++
+			}
+			return m, nil
+		case tea.KeyUp.String(), "ctrl+p":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil
+		case tea.KeyTab.String():
+			m.history = !m.history
+			m.cursor = 0
+			return m, m.search()
+		case tea.KeyEnter.String():
+			result, ok := m.selected()
+			if !ok {
+				return m, nil
+			}
+			return m, func() tea.Msg {
+				return OpenResultMsg{Result: result}
+			}
+		case tea.KeyEscape.String(), "ctrl+f":
+			m.input.Blur()
+			return m, func() tea.Msg {
+				return CloseSearchModalMsg{}
+			}
+		}
+
+		previous := m.input.Value()
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		if m.input.Value() != previous {
+			m.cursor = 0
+			return m, tea.Batch(cmd, m.search())
+		}
+		return m, cmd
+	}
+
+	return m, nil
+}
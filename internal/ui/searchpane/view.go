@@ -0,0 +1,124 @@
+package searchpane
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/owenHochwald/volt/internal/storage"
+)
+
+var (
+	accentColor = lipgloss.Color("205")
+
+	kindStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	methodStyle   = lipgloss.NewStyle().Foreground(accentColor).Bold(true)
+	matchStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("226")).Bold(true)
+	selectedStyle = lipgloss.NewStyle().Background(lipgloss.Color("98")).Foreground(lipgloss.Color("255"))
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+)
+
+func (m SearchPane) View() string {
+	modalStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(accentColor).
+		Padding(1, 2).
+		Width(m.width).
+		Height(m.height)
+
+	scope := "saved requests"
+	if m.history {
+		scope = "saved requests and history"
+	}
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(accentColor).
+		Render("Search " + scope)
+
+	footer := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Render("↑/↓, ctrl+n/p: move • enter: open • tab: include history • esc: close")
+
+	return modalStyle.Render(lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		m.input.View(),
+		"",
+		m.renderResults(),
+		"",
+		footer,
+	))
+}
+
+// renderResults renders two lines per result, the second holding the
+// header or body the words were found in
+func (m SearchPane) renderResults() string {
+	switch {
+	case m.err != nil:
+		return errorStyle.Render("Search failed: " + m.err.Error())
+	case m.query().Text == "":
+		return kindStyle.Render("Type to search names, URLs, headers and bodies.")
+	case len(m.results) == 0:
+		return kindStyle.Render("No matches.")
+	}
+
+	// Keep the cursor visible when the results are taller than the modal
+	visible := max((m.height-10)/2, 1)
+	start := max(0, m.cursor-visible+1)
+	end := min(len(m.results), start+visible)
+	width := max(m.width-6, 20)
+	clip := lipgloss.NewStyle().MaxWidth(width)
+
+	var lines []string
+	for i := start; i < end; i++ {
+		result := m.results[i]
+		line := fmt.Sprintf("%-7s %-7s %s  %s", result.Kind, result.Method, describe(result), result.URL)
+		if i == m.cursor {
+			line = selectedStyle.Render(truncate(storage.Highlight(line, unmarked), width))
+		} else {
+			line = clip.Render(kindStyle.Render(fmt.Sprintf("%-7s ", result.Kind)) +
+				methodStyle.Render(fmt.Sprintf("%-7s ", result.Method)) +
+				highlight(describe(result)) + "  " + highlight(result.URL))
+		}
+		lines = append(lines, line)
+
+		if result.Snippet != "" {
+			snippet := strings.Join(strings.Fields(result.Snippet), " ")
+			lines = append(lines, clip.Render("                "+highlight(snippet)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// describe names a result: a saved request by its name, a history entry by
+// when it was sent and its status
+func describe(result storage.SearchResult) string {
+	if result.Kind != storage.SearchHistory {
+		return result.Name
+	}
+	status := "ERR"
+	if result.StatusCode != 0 {
+		status = fmt.Sprint(result.StatusCode)
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s %s %s", result.SentAt.Local().Format("2006-01-02 15:04"), status, result.Name))
+}
+
+// highlight renders the matches in text
+func highlight(text string) string {
+	return storage.Highlight(text, func(match string) string {
+		return matchStyle.Render(match)
+	})
+}
+
+func unmarked(match string) string {
+	return match
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -20,6 +20,8 @@ func GetShortcutTabs() []ShortcutTab {
 			Shortcuts: []Shortcut{
 				{"?", "Show this help"},
 				{"Shift+Tab", "Cycle panels"},
+				{"Alt+C", "Cookie jar"},
+				{"Ctrl+F", "Search requests and history"},
 				{"q, Ctrl+C", "Quit"},
 			},
 		},
@@ -27,7 +29,14 @@ func GetShortcutTabs() []ShortcutTab {
 			Name: "Sidebar",
 			Shortcuts: []Shortcut{
 				{"Enter/Space", "Select request"},
-				{"d", "Delete request"},
+				{"d", "Delete request or collection"},
+				{"h/l", "Collapse/expand collection"},
+				{"n/N", "New collection/folder"},
+				{"r", "Rename collection"},
+				{"m", "Move to another collection"},
+				{"i", "Import a file or curl command"},
+				{"H", "History (Enter replays, / filters)"},
+				{"L", "Load test runs of the request"},
 				{"/", "Filter requests"},
 				{"j/k", "Navigate up/down"},
 			},
@@ -39,15 +48,29 @@ func GetShortcutTabs() []ShortcutTab {
 				{"Tab", "Next field"},
 				{"h/l", "Change method"},
 				{"Ctrl+S", "Save request"},
+				{"Alt+S", "Save as new request"},
+				{"Alt+X", "Copy as curl, HTTPie, Go, Python or JS"},
 				{"Alt+L", "Toggle load test"},
+				{"Alt+K", "Toggle cookie jar"},
+				{"Alt+I", "Fetch GraphQL schema"},
+				{"Ctrl+N/P", "Pick GraphQL field"},
+				{"Ctrl+Y", "Complete GraphQL field"},
+				{"Alt+P", "Ping WebSocket"},
+				{"Alt+D", "Disconnect WebSocket"},
+				{"Alt+G", "List gRPC methods"},
 			},
 		},
 		{
 			Name: "Response",
 			Shortcuts: []Shortcut{
-				{"1,2,3", "Jump to tab"},
+				{"1-6", "Jump to tab"},
 				{"h/l", "Navigate tabs"},
 				{"y/Y", "Copy response"},
+				{"x", "Toggle hex view"},
+				{"s", "Save body to file"},
+				{"Enter", "Open load test run (Runs tab)"},
+				{"c", "Compare with load test run (Runs tab)"},
+				{"Esc", "Stop stream / close WebSocket"},
 				{"j/k", "Scroll"},
 			},
 		},
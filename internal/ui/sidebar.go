@@ -2,23 +2,70 @@ package ui
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/owenHochwald/volt/internal/http"
+	"github.com/owenHochwald/volt/internal/importer"
 	"github.com/owenHochwald/volt/internal/storage"
 )
 
 type RequestItem struct {
 	title, desc string
+	depth       int
 	Request     *http.Request
 }
 
-func (i RequestItem) Title() string       { return i.title }
-func (i RequestItem) Description() string { return i.desc }
+func (i RequestItem) Title() string       { return indent(i.depth) + i.title }
+func (i RequestItem) Description() string { return indent(i.depth) + i.desc }
 func (i RequestItem) FilterValue() string { return i.title }
 
+// CollectionItem is a collection, or a folder inside one, in the sidebar tree
+type CollectionItem struct {
+	Collection http.Collection
+	depth      int
+	expanded   bool
+	requests   int // requests directly inside
+}
+
+func (i CollectionItem) Title() string {
+	arrow := "▸ "
+	if i.expanded {
+		arrow = "▾ "
+	}
+	return indent(i.depth) + arrow + i.Collection.Name
+}
+
+func (i CollectionItem) Description() string {
+	desc := fmt.Sprintf("%d requests", i.requests)
+	if i.Collection.BaseURL != "" {
+		desc += " • " + i.Collection.BaseURL
+	}
+	return indent(i.depth) + "  " + desc
+}
+
+func (i CollectionItem) FilterValue() string { return i.Collection.Name }
+
+// indent returns the indentation of an item depth levels deep in the tree
+func indent(depth int) string {
+	return strings.Repeat("  ", depth)
+}
+
+// sidebarPrompt is what the name typed in the sidebar prompt is for
+type sidebarPrompt int
+
+const (
+	promptNone sidebarPrompt = iota
+	promptCreate
+	promptRename
+	promptHistoryFilter
+	promptImport
+)
+
 type SidebarPane struct {
 	panelFocused  bool
 	height, width int
@@ -28,7 +75,27 @@ type SidebarPane struct {
 
 	desiredCursorIndex int
 
-	db *storage.SQLiteStorage
+	requests    []http.Request
+	collections []http.Collection
+	expanded    map[int64]bool
+
+	// prompt reads the name of a collection being created or renamed, or the history filter
+	prompt       textinput.Model
+	promptAction sidebarPrompt
+	promptTarget int64 // the parent of a new collection, or the one renamed
+
+	// moving is the request or collection being moved, nil otherwise
+	moving list.Item
+
+	// deleting is the collection waiting for a confirmation to be deleted
+	deleting *http.Collection
+
+	// The history view lists sent requests matching historyQuery instead
+	showHistory  bool
+	historyList  list.Model
+	historyQuery string
+
+	db storage.Storage
 }
 
 func (s *SidebarPane) SetRequests(items []list.Item) {
@@ -52,26 +119,113 @@ func (s *SidebarPane) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return s, nil
 
 		}
-		items := make([]list.Item, 0, len(msg.Requests))
-		for _, req := range msg.Requests {
-			items = append(items, RequestItem{
-				title:   req.Name,
-				desc:    req.URL[max(len(req.URL)-10, 0):],
-				Request: &req,
-			})
-		}
-		s.SetRequests(items)
-		s.requestsList.Title = fmt.Sprintf("Saved (%d)", len(s.requestsList.Items()))
+		s.requests, s.collections = msg.Requests, msg.Collections
+		s.rebuild()
+		s.requestsList.Title = fmt.Sprintf("Saved (%d)", len(s.requests))
 
+		items := s.requestsList.Items()
 		if s.desiredCursorIndex >= 0 && len(items) > 0 {
 			cursorPos := min(s.desiredCursorIndex, len(items)-1)
 			s.requestsList.Select(cursorPos)
 			s.desiredCursorIndex = -1
 		}
 		return s, nil
+	case HistoryLoadedMsg:
+		if msg.Err == nil {
+			s.setHistory(msg.Entries)
+		}
+		return s, nil
+	case HistoryRecordedMsg:
+		if s.showHistory {
+			return s, LoadHistoryCmd(s.db, s.historyQuery)
+		}
+		return s, nil
 	case tea.KeyMsg:
+		if s.promptAction != promptNone {
+			return s.updatePrompt(msg)
+		}
+		if s.deleting != nil {
+			collection := s.deleting
+			s.deleting = nil
+			if msg.String() == "y" {
+				s.desiredCursorIndex = max(s.requestsList.Index()-1, 0)
+				return s, DeleteCollectionCmd(s.db, collection.ID)
+			}
+			return s, nil
+		}
+		if s.showHistory {
+			return s.updateHistory(msg)
+		}
+		if s.requestsList.FilterState() == list.Filtering {
+			break
+		}
+		if s.moving != nil {
+			switch msg.String() {
+			case tea.KeyEscape.String():
+				s.moving = nil
+				return s, nil
+			case "m", tea.KeyEnter.String():
+				return s, s.move(s.targetCollection())
+			case "M":
+				return s, s.move(0)
+			}
+		}
+
 		switch msg.String() {
+		case "H":
+			s.showHistory = true
+			return s, LoadHistoryCmd(s.db, s.historyQuery)
+		case "L":
+			if item, ok := s.SelectedItem(); ok && item.Request != nil && item.Request.ID != 0 {
+				return s, LoadLoadTestsCmd(s.db, item.Request.ID, true)
+			}
+			return s, nil
+		case "l", tea.KeyRight.String():
+			if item, ok := s.requestsList.SelectedItem().(CollectionItem); ok && !item.expanded {
+				s.expanded[item.Collection.ID] = true
+				s.rebuild()
+			}
+			return s, nil
+		case "h", tea.KeyLeft.String():
+			if item, ok := s.requestsList.SelectedItem().(CollectionItem); ok && item.expanded {
+				s.expanded[item.Collection.ID] = false
+				s.rebuild()
+				return s, nil
+			}
+			s.selectCollection(s.parentOf(s.requestsList.SelectedItem()))
+			return s, nil
+		case tea.KeyEnter.String(), " ":
+			if item, ok := s.requestsList.SelectedItem().(CollectionItem); ok {
+				s.expanded[item.Collection.ID] = !item.expanded
+				s.rebuild()
+			}
+			return s, nil
+		case "n":
+			return s, s.startPrompt(promptCreate, 0, "")
+		case "N":
+			return s, s.startPrompt(promptCreate, s.targetCollection(), "")
+		case "i":
+			return s, s.startPrompt(promptImport, 0, "")
+		case "r":
+			if item, ok := s.requestsList.SelectedItem().(CollectionItem); ok {
+				return s, s.startPrompt(promptRename, item.Collection.ID, item.Collection.Name)
+			}
+			return s, nil
+		case "m":
+			switch item := s.requestsList.SelectedItem().(type) {
+			case CollectionItem:
+				s.moving = item
+			case RequestItem:
+				if item.Request != nil && item.Request.ID != 0 {
+					s.moving = item
+				}
+			}
+			return s, nil
 		case "d":
+			if item, ok := s.requestsList.SelectedItem().(CollectionItem); ok {
+				s.deleting = &item.Collection
+				return s, nil
+			}
 			item, ok := s.SelectedItem()
 			if !ok || item.Request == nil || item.Request.ID == 0 {
 				return s, nil
@@ -117,18 +271,241 @@ func (s *SidebarPane) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return s, cmd
 }
 
+// updatePrompt handles keys while a collection name or the history filter is being typed
+func (s *SidebarPane) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case tea.KeyEscape.String():
+		s.promptAction = promptNone
+		s.prompt.Blur()
+		return s, nil
+	case tea.KeyEnter.String():
+		action, target, name := s.promptAction, s.promptTarget, s.prompt.Value()
+		s.promptAction = promptNone
+		s.prompt.Blur()
+		switch action {
+		case promptRename:
+			return s, RenameCollectionCmd(s.db, target, name)
+		case promptHistoryFilter:
+			s.historyQuery = strings.TrimSpace(name)
+			return s, LoadHistoryCmd(s.db, s.historyQuery)
+		case promptImport:
+			if importer.IsCurl(name) {
+				return s, func() tea.Msg { return ImportCurlMsg{Command: name} }
+			}
+			if path := strings.TrimSpace(name); path != "" {
+				return s, func() tea.Msg { return ImportFileMsg{Path: path} }
+			}
+			return s, nil
+		}
+		// show the new collection in its parent
+		s.expanded[target] = true
+		return s, CreateCollectionCmd(s.db, &http.Collection{ParentID: target, Name: name})
+	}
+
+	var cmd tea.Cmd
+	s.prompt, cmd = s.prompt.Update(msg)
+	return s, cmd
+}
+
+// startPrompt asks for the name of a collection, starting from value
+func (s *SidebarPane) startPrompt(action sidebarPrompt, target int64, value string) tea.Cmd {
+	s.promptAction, s.promptTarget = action, target
+	s.prompt.Prompt = "Name: "
+	s.prompt.CharLimit = 40
+	switch action {
+	case promptHistoryFilter:
+		s.prompt.Prompt = "Filter: "
+		s.prompt.CharLimit = 0
+	case promptImport:
+		s.prompt.Prompt = "Import file or curl command: "
+		s.prompt.CharLimit = 0
+	}
+	s.prompt.SetValue(value)
+	s.prompt.CursorEnd()
+	return s.prompt.Focus()
+}
+
+// move moves the item being moved into the collection, or to the top level when it is 0
+func (s *SidebarPane) move(collectionID int64) tea.Cmd {
+	item := s.moving
+	s.moving = nil
+	s.expanded[collectionID] = true
+	switch item := item.(type) {
+	case CollectionItem:
+		return MoveCollectionCmd(s.db, item.Collection.ID, collectionID)
+	case RequestItem:
+		return MoveRequestCmd(s.db, item.Request.ID, collectionID)
+	}
+	return nil
+}
+
+// targetCollection returns the selected collection, or the one the selected
+// request is in, as the place new folders and moved items go
+func (s *SidebarPane) targetCollection() int64 {
+	if item, ok := s.requestsList.SelectedItem().(CollectionItem); ok {
+		return item.Collection.ID
+	}
+	return s.parentOf(s.requestsList.SelectedItem())
+}
+
+// parentOf returns the collection an item is shown in, 0 at the top level
+func (s *SidebarPane) parentOf(item list.Item) int64 {
+	var id int64
+	switch item := item.(type) {
+	case CollectionItem:
+		id = item.Collection.ParentID
+	case RequestItem:
+		if item.Request != nil {
+			id = item.Request.CollectionID
+		}
+	}
+	if len(http.CollectionPath(s.collections, id)) == 0 {
+		return 0
+	}
+	return id
+}
+
+// selectCollection moves the cursor to a collection, if it is shown
+func (s *SidebarPane) selectCollection(id int64) {
+	for i, item := range s.requestsList.Items() {
+		if item, ok := item.(CollectionItem); ok && item.Collection.ID == id {
+			s.requestsList.Select(i)
+			return
+		}
+	}
+}
+
+// SelectRequest shows the saved requests with the collections holding a
+// request expanded, and moves the cursor to it
+func (s *SidebarPane) SelectRequest(id int64) (*http.Request, bool) {
+	i := slices.IndexFunc(s.requests, func(req http.Request) bool { return req.ID == id })
+	if i < 0 {
+		return nil, false
+	}
+	request := &s.requests[i]
+
+	s.showHistory, s.moving = false, nil
+	s.requestsList.ResetFilter()
+	for _, collection := range s.CollectionPath(request) {
+		s.expanded[collection.ID] = true
+	}
+	s.requestsList.SetItems(s.tree())
+	for i, item := range s.requestsList.Items() {
+		if itemKey(item) == itemKey(RequestItem{Request: request}) {
+			s.requestsList.Select(i)
+			break
+		}
+	}
+	return request, true
+}
+
+// rebuild lays out the tree again, keeping the cursor on the same item
+func (s *SidebarPane) rebuild() {
+	selected := itemKey(s.requestsList.SelectedItem())
+	s.requestsList.SetItems(s.tree())
+	for i, item := range s.requestsList.Items() {
+		if selected != "" && itemKey(item) == selected {
+			s.requestsList.Select(i)
+			return
+		}
+	}
+}
+
+// tree returns the collections and requests as a list, the contents of
+// expanded collections following them one level deeper. Collections go
+// before requests, and requests in unknown collections go at the top level.
+func (s *SidebarPane) tree() []list.Item {
+	var items []list.Item
+	var walk func(parentID int64, depth int)
+	walk = func(parentID int64, depth int) {
+		if depth > len(s.collections) {
+			return
+		}
+		for _, collection := range s.collections {
+			if s.parentOf(CollectionItem{Collection: collection}) != parentID {
+				continue
+			}
+			item := CollectionItem{Collection: collection, depth: depth, expanded: s.expanded[collection.ID]}
+			for _, req := range s.requests {
+				if req.CollectionID == collection.ID {
+					item.requests++
+				}
+			}
+			items = append(items, item)
+			if item.expanded {
+				walk(collection.ID, depth+1)
+			}
+		}
+		for i := range s.requests {
+			req := &s.requests[i]
+			item := RequestItem{
+				title:   req.Name,
+				desc:    req.URL[max(len(req.URL)-10, 0):],
+				depth:   depth,
+				Request: req,
+			}
+			if s.parentOf(item) == parentID {
+				items = append(items, item)
+			}
+		}
+	}
+	walk(0, 0)
+	return items
+}
+
+// itemKey identifies an item across rebuilds of the tree
+func itemKey(item list.Item) string {
+	switch item := item.(type) {
+	case CollectionItem:
+		return fmt.Sprintf("c%d", item.Collection.ID)
+	case RequestItem:
+		if item.Request != nil {
+			return fmt.Sprintf("r%d", item.Request.ID)
+		}
+	}
+	return ""
+}
+
+// Editing reports whether the sidebar is reading keys for itself: a name,
+// a confirmation, a filter or where to move an item
+func (s *SidebarPane) Editing() bool {
+	return s.promptAction != promptNone || s.deleting != nil || s.moving != nil ||
+		s.requestsList.FilterState() == list.Filtering
+}
+
+// CollectionPath returns the collections a request is in, outermost first
+func (s *SidebarPane) CollectionPath(request *http.Request) []http.Collection {
+	return http.CollectionPath(s.collections, request.CollectionID)
+}
+
 func (s *SidebarPane) View() string {
-	helpText := HelpStyle.Render("d: delete • enter: send •/: filter")
+	helpText := HelpStyle.Render("n/N: new collection/folder • r: rename • m: move • d: delete • i: import • /: filter • H: history • L: load tests")
+	list := s.requestsList.View()
+	if s.showHistory {
+		helpText = HelpStyle.Render("enter: replay • /: filter (method:GET status:4xx date:2006-01-02) • H: saved requests")
+		list = s.historyList.View()
+	}
+	switch {
+	case s.promptAction != promptNone:
+		helpText = HelpStyle.Render(s.prompt.View())
+	case s.deleting != nil:
+		helpText = HelpStyle.Render(fmt.Sprintf("Delete %s and everything in it? y/n", s.deleting.Name))
+	case s.moving != nil:
+		helpText = HelpStyle.Render("m/enter: move into selected • M: top level • esc: cancel")
+	}
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
-		s.requestsList.View(),
+		list,
 		lipgloss.NewStyle().Height(s.height-1).Render(""),
 		helpText,
 	)
 }
 
 func (s *SidebarPane) SelectedItem() (RequestItem, bool) {
+	if s.showHistory {
+		return RequestItem{}, false
+	}
 	if item := s.requestsList.SelectedItem(); item != nil {
 		if reqItem, ok := item.(RequestItem); ok {
 			return reqItem, true
@@ -141,9 +518,10 @@ func (s *SidebarPane) SetSize(width, height int) {
 	s.width = width
 	s.height = height
 	s.requestsList.SetSize(width, height)
+	s.historyList.SetSize(width, height)
 }
 
-func NewSidebar(db *storage.SQLiteStorage) *SidebarPane {
+func NewSidebar(db storage.Storage) *SidebarPane {
 	loadingItems := []list.Item{
 		RequestItem{
 			title:   "Loading...",
@@ -152,12 +530,17 @@ func NewSidebar(db *storage.SQLiteStorage) *SidebarPane {
 		},
 	}
 
+	prompt := textinput.New()
+
 	sidebar := &SidebarPane{
 		panelFocused: false,
 		height:       10,
 		width:        10,
 		db:           db,
 		requestsList: list.New(loadingItems, list.NewDefaultDelegate(), 0, 0),
+		expanded:     make(map[int64]bool),
+		prompt:       prompt,
+		historyList:  newHistoryList(),
 	}
 	sidebar.requestsList.Title = "Saved (Loading...)"
 	sidebar.requestsList.SetShowHelp(false)
@@ -57,6 +57,14 @@ var (
 	LabelStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("241"))
 
+	StatusStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")).
+			MarginLeft(2)
+
+	WarningStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196")).
+			Bold(true)
+
 	LoadTestBorderStyle = lipgloss.NewStyle().
 				BorderStyle(lipgloss.RoundedBorder()).
 				BorderForeground(lipgloss.Color("226")) // Yellow
@@ -16,3 +16,13 @@ func FormatSize(bytes int) string {
 	return fmt.Sprintf("%.1f %cB",
 		float64(bytes)/float64(div), "KMGTPE"[exp])
 }
+
+// FormatProgress describes how much of a download of total bytes was
+// received, total being negative when it isn't known
+func FormatProgress(received, total int64) string {
+	if total < 0 {
+		return "Downloading " + FormatSize(int(received))
+	}
+	return fmt.Sprintf("Downloading %s of %s (%d%%)",
+		FormatSize(int(received)), FormatSize(int(total)), received*100/max(total, 1))
+}